@@ -0,0 +1,456 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"Eini/tools/weather"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/agent.go
+//  功能: 多轮对话的 Agent 循环 (RAG + 工具调用)。
+//  流程: 检索知识 → 流式生成 → 若模型请求工具则执行 ToolsNode → 继续生成，
+//        直到模型给出最终回答或达到最大工具调用轮数。
+//  说明: 对话过程通过 ChatEvent 回调实时通知调用方，交互式终端等上层界面
+//        据此展示流式输出、工具调用和检索来源。
+//        工具通过 ToolsNode.Stream 执行，流式工具的输出以 tool_delta 事件实时转发，
+//        拼接后的完整结果作为工具消息返回给模型。
+//        模型请求调用需要审批的工具时，循环暂停并返回待审批信息，
+//        审批后通过 ResumeChat 继续 (见 approval.go)。
+//
+// =============================================================================
+
+// maxToolRounds 单轮对话中最多允许的工具调用轮数，防止模型反复调用工具陷入死循环
+const maxToolRounds = 5
+
+// toolTimeout 单次工具调用的最长执行时间
+const toolTimeout = 30 * time.Second
+
+// agentSystemPrompt 是 Agent 模式下的系统提示词
+const agentSystemPrompt = "你是一个智能助手，能够基于提供的知识回答问题并调用工具。" +
+	"如果知识库信息不足，可以调用 knowledge_search 工具继续检索；需要计算或查询天气时请调用相应工具。" +
+	"请根据上下文提供准确、有用的回答。"
+
+// ChatEventType 对话事件类型
+type ChatEventType string
+
+const (
+	ChatEventSources    ChatEventType = "sources"           // 检索到的知识来源
+	ChatEventToken      ChatEventType = "token"             // 模型输出的增量文本
+	ChatEventToolCall   ChatEventType = "tool_call"         // 模型请求调用工具
+	ChatEventToolDelta  ChatEventType = "tool_delta"        // 流式工具的增量输出
+	ChatEventToolResult ChatEventType = "tool_result"       // 工具执行结果 (完整输出)
+	ChatEventApproval   ChatEventType = "approval_required" // 工具调用等待审批，运行已暂停
+)
+
+// ChatEvent 对话过程中产生的事件
+type ChatEvent struct {
+	Type       ChatEventType      `json:"type"`
+	Content    string             `json:"content,omitempty"`      // token 文本、工具的增量输出或工具结果
+	ToolName   string             `json:"tool_name,omitempty"`    // 工具名称
+	ToolCallID string             `json:"tool_call_id,omitempty"` // 工具调用 ID
+	Arguments  string             `json:"arguments,omitempty"`    // 工具调用参数 (JSON)
+	Sources    []*schema.Document `json:"sources,omitempty"`      // 检索到的文档
+	RunID      string             `json:"run_id,omitempty"`       // 等待审批的运行 ID
+}
+
+// ChatRequest 单轮对话请求
+type ChatRequest struct {
+	History []*schema.Message // 之前的对话历史 (不含系统提示词)
+	Query   string            // 本轮用户输入
+	TopK    int               // 检索的文档数量，<= 0 时使用检索器默认值
+	Units   string            // 天气工具使用的单位制 (weather.UnitsMetric / weather.UnitsImperial)，为空时使用工具默认值
+}
+
+// ChatResult 单轮对话结果
+type ChatResult struct {
+	Messages []*schema.Message // 本轮新增的消息 (用户消息、助手消息和工具消息)，可直接追加到历史中
+	Answer   string            // 最终回答
+	Sources  []*schema.Document
+	Pending  *PendingApproval // 不为 nil 时表示运行因等待审批而暂停，Answer 为空
+}
+
+// Chat 执行一轮多轮对话: 检索知识后以流式方式生成回答，并在需要时调用工具。
+// onEvent 可以为 nil。
+func (s *ComprehensiveRAGSystem) Chat(ctx context.Context, req *ChatRequest, onEvent func(*ChatEvent)) (*ChatResult, error) {
+	if s.agentModel == nil || s.toolsNode == nil {
+		return nil, errors.New("Agent 尚未初始化")
+	}
+	if !validUnits(req.Units) {
+		return nil, fmt.Errorf("不支持的单位制: %q", req.Units)
+	}
+	emit := func(e *ChatEvent) {
+		if onEvent != nil {
+			onEvent(e)
+		}
+	}
+
+	// 1. 检索知识，作为本轮的上下文
+	docs, err := s.Retrieve(ctx, req.Query, req.TopK)
+	if err != nil {
+		return nil, fmt.Errorf("知识检索失败: %v", err)
+	}
+	emit(&ChatEvent{Type: ChatEventSources, Sources: docs})
+
+	// 2. 组装消息: 系统提示词 (含检索结果) + 历史 + 本轮问题。
+	// 检索结果只放在本轮的系统消息里，不写入历史，避免历史随轮数膨胀。
+	userMsg := schema.UserMessage(req.Query)
+	messages := make([]*schema.Message, 0, len(req.History)+2)
+	messages = append(messages, schema.SystemMessage(buildAgentSystemPrompt(docs)))
+	messages = append(messages, req.History...)
+	messages = append(messages, userMsg)
+
+	// 3. Agent 循环
+	run := newAgentRun(req.Query, messages, docs)
+	run.Units = req.Units
+	return s.runAgent(ctx, run, emit)
+}
+
+// ResumeChat 根据审批结果恢复暂停的运行。decisions 以工具调用 ID 为键，
+// 每个待审批的调用都必须给出决定。批准的调用正常执行，拒绝的调用把原因作为工具结果返回给模型。
+// 恢复后如果再次遇到需要审批的工具，会以同一个运行 ID 再次暂停。
+// 执行工具时失败，重新提交审批结果后只执行还没有结果的调用；
+// 工具执行后生成回答失败时检查点保留，再次调用 (decisions 为空) 会从生成回答继续，不会重复执行工具。
+func (s *ComprehensiveRAGSystem) ResumeChat(ctx context.Context, runID string, decisions map[string]*ApprovalDecision, onEvent func(*ChatEvent)) (*ChatResult, error) {
+	if s.agentModel == nil || s.toolsNode == nil {
+		return nil, errors.New("Agent 尚未初始化")
+	}
+	emit := func(e *ChatEvent) {
+		if onEvent != nil {
+			onEvent(e)
+		}
+	}
+
+	run, err := s.runs.claim(runID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.runs.release(run.ID)
+	if err := checkDecisions(run.Pending, decisions); err != nil {
+		return nil, err
+	}
+
+	emit(&ChatEvent{Type: ChatEventSources, Sources: run.Sources})
+	if len(run.Pending) == 0 {
+		log.Printf("[Approval] 继续运行 %s，工具调用已经执行过", run.ID)
+		return s.runAgent(ctx, run, emit)
+	}
+
+	log.Printf("[Approval] 恢复运行 %s，审批了 %d 个工具调用", run.ID, len(run.Pending))
+	emitToolCalls(run.lastMessage(), emit) // 恢复时重新发送工具调用事件，方便调用方把结果与调用对应起来
+
+	toolMsgs, err := s.executeTools(ctx, run, decisions, emit)
+	if err != nil {
+		return nil, err
+	}
+	run.append(toolMsgs...)
+	run.Pending = nil
+	run.Round++
+	// 工具已经执行，更新检查点后再生成回答，之后失败时重新提交不会再次执行工具
+	if err := s.runs.save(run); err != nil {
+		return nil, err
+	}
+	return s.runAgent(ctx, run, emit)
+}
+
+// PendingApprovals 列出所有等待审批的运行
+func (s *ComprehensiveRAGSystem) PendingApprovals() ([]*PendingApproval, error) {
+	return s.runs.list()
+}
+
+// PendingApproval 返回指定运行的待审批信息，运行不存在时返回 ErrRunNotFound
+func (s *ComprehensiveRAGSystem) PendingApproval(runID string) (*PendingApproval, error) {
+	run, err := s.runs.load(runID)
+	if err != nil {
+		return nil, err
+	}
+	return run.pendingApproval(), nil
+}
+
+// runAgent 从运行状态的当前轮次开始执行 Agent 循环。
+// 已经写入检查点的运行 (审批后恢复的运行) 每执行一轮工具都会更新检查点，得到最终回答后删除检查点；
+// 出错时检查点保留在最近一次执行工具后的状态。
+func (s *ComprehensiveRAGSystem) runAgent(ctx context.Context, run *agentRun, emit func(*ChatEvent)) (*ChatResult, error) {
+	for run.Round <= maxToolRounds {
+		msg, err := s.streamAssistant(ctx, run.Messages, emit)
+		if err != nil {
+			return nil, err
+		}
+		run.append(msg)
+
+		if len(msg.ToolCalls) == 0 {
+			s.finishRun(run)
+			return &ChatResult{Messages: run.NewMessages, Answer: msg.Content, Sources: run.Sources}, nil
+		}
+		if run.Round == maxToolRounds {
+			break
+		}
+
+		emitToolCalls(msg, emit)
+
+		// 有需要审批的工具时，本轮的所有调用都暂不执行，保存检查点后暂停
+		if pending := s.pendingCalls(msg); len(pending) > 0 {
+			run.Pending = pending
+			if err := s.runs.save(run); err != nil {
+				return nil, err
+			}
+			log.Printf("[Approval] 运行 %s 暂停，%d 个工具调用等待审批", run.ID, len(pending))
+			for _, call := range pending {
+				emit(&ChatEvent{
+					Type:       ChatEventApproval,
+					RunID:      run.ID,
+					ToolName:   call.ToolName,
+					ToolCallID: call.ToolCallID,
+					Arguments:  call.Arguments,
+				})
+			}
+			return &ChatResult{Messages: run.NewMessages, Sources: run.Sources, Pending: run.pendingApproval()}, nil
+		}
+
+		toolMsgs, err := s.executeTools(ctx, run, nil, emit)
+		if err != nil {
+			return nil, err
+		}
+		run.append(toolMsgs...)
+		run.Round++
+		if run.checkpointed {
+			if err := s.runs.save(run); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.finishRun(run)
+	return nil, fmt.Errorf("工具调用超过 %d 轮仍未得到最终回答", maxToolRounds)
+}
+
+// finishRun 运行结束 (得到最终回答或超过最大轮数) 后删除检查点
+func (s *ComprehensiveRAGSystem) finishRun(run *agentRun) {
+	if !run.checkpointed {
+		return
+	}
+	if err := s.runs.remove(run.ID); err != nil {
+		log.Printf("[Approval] 删除检查点 %s 失败: %v", run.ID, err)
+	}
+}
+
+// emitToolCalls 为助手消息中的每个工具调用发送 tool_call 事件
+func emitToolCalls(msg *schema.Message, emit func(*ChatEvent)) {
+	for _, call := range msg.ToolCalls {
+		emit(&ChatEvent{
+			Type:       ChatEventToolCall,
+			ToolName:   call.Function.Name,
+			ToolCallID: call.ID,
+			Arguments:  call.Function.Arguments,
+		})
+	}
+}
+
+// pendingCalls 返回助手消息中需要审批的工具调用
+func (s *ComprehensiveRAGSystem) pendingCalls(msg *schema.Message) []*PendingToolCall {
+	var pending []*PendingToolCall
+	for _, call := range msg.ToolCalls {
+		if s.approvalTools[call.Function.Name] {
+			pending = append(pending, &PendingToolCall{
+				ToolCallID: call.ID,
+				ToolName:   call.Function.Name,
+				Arguments:  call.Function.Arguments,
+			})
+		}
+	}
+	return pending
+}
+
+// executeTools 执行运行中最后一条助手消息里的工具调用，返回与调用顺序一致的工具消息。
+// decisions 中被拒绝的调用不会执行，而是返回包含拒绝原因的工具消息。
+// 已经写入检查点的运行逐个执行调用，每个调用完成后把结果写入检查点 (run.Results)，
+// 后面的调用失败时重新提交只执行还没有结果的调用；没有检查点的运行并行执行所有调用。
+func (s *ComprehensiveRAGSystem) executeTools(ctx context.Context, run *agentRun, decisions map[string]*ApprovalDecision, emit func(*ChatEvent)) ([]*schema.Message, error) {
+	msg := run.lastMessage()
+	var todo []schema.ToolCall
+	for _, call := range msg.ToolCalls {
+		if _, done := run.Results[call.ID]; done {
+			log.Printf("[Approval] 运行 %s 的工具调用 %s 已经执行过，使用检查点中的结果", run.ID, call.ID)
+			continue
+		}
+		if d, ok := decisions[call.ID]; !ok || d.Approved {
+			todo = append(todo, call)
+		}
+	}
+
+	batches := [][]schema.ToolCall{todo}
+	if run.checkpointed {
+		batches = make([][]schema.ToolCall, len(todo))
+		for i, call := range todo {
+			batches[i] = []schema.ToolCall{call}
+		}
+	}
+	if run.Results == nil {
+		run.Results = make(map[string]*schema.Message, len(msg.ToolCalls))
+	}
+	for _, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		approved := *msg
+		approved.ToolCalls = batch
+		toolMsgs, err := s.streamToolCalls(ctx, &approved, run.toolOptions(), emit)
+		if err != nil {
+			return nil, fmt.Errorf("工具调用失败: %v", err)
+		}
+		for _, tm := range toolMsgs {
+			run.Results[tm.ToolCallID] = tm
+		}
+		if run.checkpointed {
+			if err := s.runs.save(run); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	toolMsgs := make([]*schema.Message, 0, len(msg.ToolCalls))
+	for _, call := range msg.ToolCalls {
+		tm, ok := run.Results[call.ID]
+		if !ok {
+			d, rejected := decisions[call.ID]
+			if !rejected {
+				return nil, fmt.Errorf("工具调用 %s 没有返回结果", call.ID)
+			}
+			tm = rejectionMessage(call, d.Reason)
+		}
+		emit(&ChatEvent{
+			Type:       ChatEventToolResult,
+			ToolName:   tm.ToolName,
+			ToolCallID: tm.ToolCallID,
+			Content:    tm.Content,
+		})
+		toolMsgs = append(toolMsgs, tm)
+	}
+	run.Results = nil // 结果由调用方追加到消息中，随下一次保存检查点一起写入
+	return toolMsgs, nil
+}
+
+// streamToolCalls 以流式方式执行工具调用 (多个调用并行执行)，流式工具的每个输出块都以
+// tool_delta 事件转发，返回拼接后的工具消息。非流式工具的结果只有一个块，不发送 tool_delta 事件。
+func (s *ComprehensiveRAGSystem) streamToolCalls(ctx context.Context, msg *schema.Message, opts []tool.Option, emit func(*ChatEvent)) ([]*schema.Message, error) {
+	stream, err := s.toolsNode.Stream(ctx, msg, compose.WithToolOption(opts...))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	// 每个数据块是与工具调用等长的数组，只有产生输出的调用对应的位置不为 nil
+	outputs := make([]strings.Builder, len(msg.ToolCalls))
+	names := make([]string, len(msg.ToolCalls))
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, tm := range chunk {
+			if tm == nil || i >= len(outputs) {
+				continue
+			}
+			outputs[i].WriteString(tm.Content)
+			names[i] = tm.ToolName
+			if s.streamTools[tm.ToolName] && tm.Content != "" {
+				emit(&ChatEvent{Type: ChatEventToolDelta, ToolName: tm.ToolName, ToolCallID: tm.ToolCallID, Content: tm.Content})
+			}
+		}
+	}
+
+	toolMsgs := make([]*schema.Message, len(msg.ToolCalls))
+	for i, call := range msg.ToolCalls {
+		toolMsgs[i] = schema.ToolMessage(outputs[i].String(), call.ID, schema.WithToolName(cmp.Or(names[i], call.Function.Name)))
+	}
+	return toolMsgs, nil
+}
+
+// toolOptions 把运行的工具设置转换为调用选项。选项会传给每个工具，
+// 各工具只读取自己的选项类型 (见 weather.Options)，不认识的选项会被忽略。
+func (r *agentRun) toolOptions() []tool.Option {
+	var opts []tool.Option
+	if r.Units != "" {
+		opts = append(opts, weather.WithUnits(r.Units))
+	}
+	return opts
+}
+
+// checkDecisions 检查审批结果是否覆盖了所有待审批的调用，且没有多余的调用 ID
+func checkDecisions(pending []*PendingToolCall, decisions map[string]*ApprovalDecision) error {
+	ids := make(map[string]bool, len(pending))
+	for _, call := range pending {
+		ids[call.ToolCallID] = true
+		if d, ok := decisions[call.ToolCallID]; !ok || d == nil {
+			return fmt.Errorf("%w: 缺少工具调用 %s (%s) 的审批结果", ErrInvalidDecisions, call.ToolCallID, call.ToolName)
+		}
+	}
+	for id := range decisions {
+		if !ids[id] {
+			return fmt.Errorf("%w: 工具调用 %s 不在待审批列表中", ErrInvalidDecisions, id)
+		}
+	}
+	return nil
+}
+
+// streamAssistant 以流式方式调用模型，逐个分发 token 事件，并返回拼接后的完整消息
+func (s *ComprehensiveRAGSystem) streamAssistant(ctx context.Context, messages []*schema.Message, emit func(*ChatEvent)) (*schema.Message, error) {
+	stream, err := s.agentModel.Stream(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("生成回答失败: %v", err)
+	}
+	defer stream.Close()
+
+	var chunks []*schema.Message
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取模型输出失败: %v", err)
+		}
+		if chunk.Content != "" {
+			emit(&ChatEvent{Type: ChatEventToken, Content: chunk.Content})
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) == 0 {
+		return nil, errors.New("模型没有返回任何内容")
+	}
+
+	msg, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		return nil, fmt.Errorf("拼接模型输出失败: %v", err)
+	}
+	log.Printf("[Agent] 模型输出 %d 个片段，工具调用 %d 个", len(chunks), len(msg.ToolCalls))
+	return msg, nil
+}
+
+// buildAgentSystemPrompt 把检索结果拼接到系统提示词中
+func buildAgentSystemPrompt(docs []*schema.Document) string {
+	if len(docs) == 0 {
+		return agentSystemPrompt + "\n\n(知识库中没有检索到相关信息)"
+	}
+
+	prompt := agentSystemPrompt + "\n\n=== 知识库信息 ===\n"
+	for i, doc := range docs {
+		prompt += fmt.Sprintf("[知识片段 %d]\n%s\n\n", i+1, doc.Content)
+	}
+	return prompt
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// countingTool 记录调用次数的工具，failures 大于 0 时先失败 failures 次
+type countingTool struct {
+	name     string
+	calls    int
+	failures int
+}
+
+func (t *countingTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: t.name, Desc: t.name}, nil
+}
+
+func (t *countingTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	t.calls++
+	if t.failures > 0 {
+		t.failures--
+		return "", errors.New(t.name + " 暂时不可用")
+	}
+	return t.name + " 完成", nil
+}
+
+// answerModel 直接给出固定回答的模型
+type answerModel struct{}
+
+func (answerModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return schema.AssistantMessage("已完成", nil), nil
+}
+
+func (answerModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return schema.StreamReaderFromArray([]*schema.Message{schema.AssistantMessage("已完成", nil)}), nil
+}
+
+func (answerModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+// newApprovalSystem 创建只包含 Agent 循环所需组件的系统，tools 中的工具都需要审批
+func newApprovalSystem(t *testing.T, tools ...*countingTool) *ComprehensiveRAGSystem {
+	t.Helper()
+	baseTools := make([]tool.BaseTool, len(tools))
+	approvalTools := make(map[string]bool, len(tools))
+	for i, tl := range tools {
+		baseTools[i] = tl
+		approvalTools[tl.name] = true
+	}
+	toolsNode, err := compose.NewToolNode(context.Background(), &compose.ToolsNodeConfig{Tools: baseTools})
+	if err != nil {
+		t.Fatalf("创建 ToolsNode 失败: %v", err)
+	}
+	runs, err := newRunStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("创建检查点存储失败: %v", err)
+	}
+	return &ComprehensiveRAGSystem{
+		agentModel:    answerModel{},
+		toolsNode:     toolsNode,
+		streamTools:   map[string]bool{},
+		approvalTools: approvalTools,
+		runs:          runs,
+	}
+}
+
+func TestResumeChatSkipsCompletedTools(t *testing.T) {
+	first := &countingTool{name: "first"}
+	second := &countingTool{name: "second", failures: 1}
+	system := newApprovalSystem(t, first, second)
+
+	// 模型在一条消息中请求调用两个需要审批的工具，运行暂停
+	assistant := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call_1", Function: schema.FunctionCall{Name: "first", Arguments: "{}"}},
+		{ID: "call_2", Function: schema.FunctionCall{Name: "second", Arguments: "{}"}},
+	})
+	run := newAgentRun("执行两个工具", []*schema.Message{schema.UserMessage("执行两个工具")}, nil)
+	run.append(assistant)
+	run.Pending = system.pendingCalls(assistant)
+	if err := system.runs.save(run); err != nil {
+		t.Fatalf("保存检查点失败: %v", err)
+	}
+
+	decisions := map[string]*ApprovalDecision{"call_1": {Approved: true}, "call_2": {Approved: true}}
+	if _, err := system.ResumeChat(context.Background(), run.ID, decisions, nil); err == nil {
+		t.Fatal("第二个工具失败时 ResumeChat 应该返回错误")
+	}
+	if first.calls != 1 || second.calls != 1 {
+		t.Fatalf("第一次恢复后 first 调用 %d 次、second 调用 %d 次，期望各 1 次", first.calls, second.calls)
+	}
+
+	// 重新提交审批结果: 只执行还没有结果的 second
+	result, err := system.ResumeChat(context.Background(), run.ID, decisions, nil)
+	if err != nil {
+		t.Fatalf("重新提交后 ResumeChat 返回错误: %v", err)
+	}
+	if first.calls != 1 || second.calls != 2 {
+		t.Errorf("重新提交后 first 调用 %d 次、second 调用 %d 次，期望 1 次和 2 次", first.calls, second.calls)
+	}
+	if result.Answer != "已完成" {
+		t.Errorf("回答 = %q，期望 %q", result.Answer, "已完成")
+	}
+
+	// 工具消息按调用顺序排列，内容是各自的结果
+	var toolMsgs []*schema.Message
+	for _, msg := range result.Messages {
+		if msg.Role == schema.Tool {
+			toolMsgs = append(toolMsgs, msg)
+		}
+	}
+	if len(toolMsgs) != 2 || toolMsgs[0].Content != "first 完成" || toolMsgs[1].Content != "second 完成" {
+		t.Errorf("工具消息 = %v，期望 first 和 second 的结果", toolMsgs)
+	}
+	if _, err := system.runs.load(run.ID); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("得到最终回答后检查点应该被删除，load 返回 %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/approval.go
+//  功能: 工具调用审批 (human-in-the-loop)。
+//  流程: 模型请求调用需要审批的工具时，Agent 循环暂停并把运行状态写入检查点，
+//        同时发出 approval_required 事件；审批人通过 HTTP 接口或交互终端给出结果后，
+//        ResumeChat 从检查点恢复: 批准的调用正常执行，拒绝的调用把原因作为工具结果
+//        返回给模型，然后继续生成回答。
+//  说明: 检查点是 CHECKPOINT_DIR 下的 JSON 文件，进程重启后仍然可以恢复。
+//        写入时先写临时文件再重命名，避免进程中途退出留下不完整的检查点。
+//        恢复期间每执行完一个工具调用都会更新检查点，直到得到最终回答或再次暂停才删除；
+//        执行工具或生成回答失败 (工具出错、模型出错、请求超时、客户端断开) 时可以重新提交，已经执行的工具不会再次执行。
+//
+// =============================================================================
+
+// 审批相关的默认配置
+const (
+	defaultApprovalTools = "document_processor,http_fetch" // 默认需要审批的工具 (会写入知识库或访问外部网络)
+	defaultCheckpointDir = ".agent_runs"                   // 默认的检查点目录
+	approvalToolsNone    = "none"                          // APPROVAL_TOOLS 设为 none 时所有工具都无需审批
+)
+
+var (
+	// ErrRunNotFound 待审批的运行不存在 (已经恢复、已经完成或 ID 错误)
+	ErrRunNotFound = errors.New("待审批的运行不存在")
+	// ErrInvalidDecisions 审批结果与待审批的工具调用不匹配
+	ErrInvalidDecisions = errors.New("审批结果无效")
+	// ErrRunInProgress 运行正在被另一个请求恢复
+	ErrRunInProgress = errors.New("运行正在恢复中")
+)
+
+// runIDPattern 运行 ID 的格式，加载检查点前校验，防止通过 ID 访问检查点目录之外的文件
+var runIDPattern = regexp.MustCompile(`^run_[0-9a-f]{16}$`)
+
+// PendingToolCall 等待审批的工具调用
+type PendingToolCall struct {
+	ToolCallID string `json:"tool_call_id"`
+	ToolName   string `json:"tool_name"`
+	Arguments  string `json:"arguments"`
+}
+
+// PendingApproval 因等待审批而暂停的运行
+type PendingApproval struct {
+	RunID     string             `json:"run_id"`
+	Query     string             `json:"query"`
+	Calls     []*PendingToolCall `json:"calls"`
+	CreatedAt time.Time          `json:"created_at"`
+	// Interrupted 审批过的工具已经执行，之后生成回答时失败；重新提交 (不需要审批结果) 即可继续
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// ApprovalDecision 审批人对一次工具调用的决定
+type ApprovalDecision struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"` // 拒绝原因，会作为工具结果返回给模型
+}
+
+// agentRun Agent 循环的运行状态，暂停时整体写入检查点
+type agentRun struct {
+	ID          string             `json:"id"`
+	Query       string             `json:"query"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Messages    []*schema.Message  `json:"messages"`     // 发送给模型的完整消息 (含系统提示词)
+	NewMessages []*schema.Message  `json:"new_messages"` // 本轮新增的消息，对应 ChatResult.Messages
+	Sources     []*schema.Document `json:"sources"`
+	Round       int                `json:"round"`           // 当前的工具调用轮数
+	Pending     []*PendingToolCall `json:"pending"`         // 等待审批的工具调用
+	Units       string             `json:"units,omitempty"` // 天气工具的单位制，恢复时继续使用
+	// Results 本轮已经执行完的工具调用的结果，以工具调用 ID 为键；执行中途失败后恢复时跳过这些调用
+	Results map[string]*schema.Message `json:"results,omitempty"`
+
+	checkpointed bool // 是否已经写入检查点，写入后每执行一轮工具都要更新检查点
+}
+
+// newAgentRun 创建新的运行状态
+func newAgentRun(query string, messages []*schema.Message, sources []*schema.Document) *agentRun {
+	return &agentRun{
+		ID:          newRunID(),
+		Query:       query,
+		CreatedAt:   time.Now(),
+		Messages:    messages,
+		NewMessages: []*schema.Message{messages[len(messages)-1]},
+		Sources:     sources,
+	}
+}
+
+// append 追加消息到完整消息和本轮新增消息中
+func (r *agentRun) append(msgs ...*schema.Message) {
+	r.Messages = append(r.Messages, msgs...)
+	r.NewMessages = append(r.NewMessages, msgs...)
+}
+
+// lastMessage 返回最后一条消息。暂停时就是请求调用工具的助手消息
+func (r *agentRun) lastMessage() *schema.Message {
+	return r.Messages[len(r.Messages)-1]
+}
+
+// pendingApproval 返回运行的待审批信息
+func (r *agentRun) pendingApproval() *PendingApproval {
+	return &PendingApproval{RunID: r.ID, Query: r.Query, Calls: r.Pending, CreatedAt: r.CreatedAt, Interrupted: len(r.Pending) == 0}
+}
+
+// newRunID 生成随机的运行 ID
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "run_" + hex.EncodeToString(b)
+}
+
+// parseApprovalTools 解析 APPROVAL_TOOLS 配置 (逗号分隔的工具名称)
+func parseApprovalTools(value string) map[string]bool {
+	tools := make(map[string]bool)
+	if strings.TrimSpace(value) == approvalToolsNone {
+		return tools
+	}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			tools[name] = true
+		}
+	}
+	return tools
+}
+
+// rejectionMessage 生成拒绝工具调用时返回给模型的工具结果
+func rejectionMessage(call schema.ToolCall, reason string) *schema.Message {
+	if reason == "" {
+		reason = "未说明"
+	}
+	content := fmt.Sprintf("用户拒绝执行该工具调用，原因: %s。请不要重复调用，根据原因调整方案或直接回答用户。", reason)
+	return schema.ToolMessage(content, call.ID, schema.WithToolName(call.Function.Name))
+}
+
+// ================================
+// 检查点存储
+// ================================
+
+// runStore 把暂停的运行保存为检查点目录下的 JSON 文件
+type runStore struct {
+	dir     string
+	mu      sync.Mutex
+	claimed map[string]bool // 正在恢复的运行，同一个运行同时只能被一个调用方恢复
+}
+
+// newRunStore 创建检查点存储，目录不存在时自动创建
+func newRunStore(dir string) (*runStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建检查点目录失败: %w", err)
+	}
+	return &runStore{dir: dir, claimed: make(map[string]bool)}, nil
+}
+
+// path 返回运行对应的检查点文件路径
+func (s *runStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// save 原子地写入检查点: 先写入同目录下的临时文件，再重命名覆盖
+func (s *runStore) save(run *agentRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化检查点失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, run.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时检查点文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // 重命名成功后删除会失败，可以忽略
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入检查点失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入检查点失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(run.ID)); err != nil {
+		return fmt.Errorf("保存检查点失败: %w", err)
+	}
+	run.checkpointed = true
+	return nil
+}
+
+// load 读取检查点
+func (s *runStore) load(id string) (*agentRun, error) {
+	if !runIDPattern.MatchString(id) {
+		return nil, ErrRunNotFound
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %w", err)
+	}
+
+	var run agentRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("解析检查点 %s 失败: %w", id, err)
+	}
+	run.checkpointed = true
+	return &run, nil
+}
+
+// claim 读取检查点并取得运行的所有权，用完后必须调用 release。检查点保留到运行结束，
+// 恢复失败时可以重新提交。并发恢复同一个运行时只有一个调用方能成功，避免工具被重复执行。
+func (s *runStore) claim(id string) (*agentRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.claimed[id] {
+		return nil, ErrRunInProgress
+	}
+	run, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	s.claimed[id] = true
+	return run, nil
+}
+
+// release 释放 claim 取得的所有权
+func (s *runStore) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claimed, id)
+}
+
+// remove 删除检查点，运行得到最终回答后调用
+func (s *runStore) remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除检查点失败: %w", err)
+	}
+	return nil
+}
+
+// list 列出所有等待审批的运行，按创建时间排序。正在恢复的运行不列出，无法解析的检查点会被跳过并记录日志。
+func (s *runStore) list() ([]*PendingApproval, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点目录失败: %w", err)
+	}
+
+	pending := make([]*PendingApproval, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok || !runIDPattern.MatchString(id) || s.isClaimed(id) {
+			continue
+		}
+		run, err := s.load(id)
+		if err != nil {
+			log.Printf("[Approval] 跳过检查点 %s: %v", entry.Name(), err)
+			continue
+		}
+		pending = append(pending, run.pendingApproval())
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	return pending, nil
+}
+
+// isClaimed 判断运行是否正在恢复
+func (s *runStore) isClaimed(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claimed[id]
+}
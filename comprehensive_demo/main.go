@@ -5,13 +5,26 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"Eini/tools/codeeval"
+	"Eini/tools/middleware"
+	"Eini/tools/sqlquery"
+	"Eini/tools/weather"
+	"Eini/tools/webfetch"
+
 	// Eino 框架核心组件
 	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/model"
+	einoretriever "github.com/cloudwego/eino/components/retriever"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
@@ -45,11 +58,19 @@ import (
 
 // Config 应用程序配置结构
 type Config struct {
-	MilvusAddress    string `mapstructure:"MILVUS_ADDRESS"`    // Milvus 服务地址
-	MilvusCollection string `mapstructure:"MILVUS_COLLECTION"` // Milvus 集合名称
-	ArkAPIKey        string `mapstructure:"ARK_API_KEY"`       // Ark API Key
-	EmbedderModel    string `mapstructure:"EMBEDDER_MODEL"`    // 嵌入模型名称
-	ArkModel         string `mapstructure:"ARK_MODEL"`         // Ark 模型名称
+	MilvusAddress    string `mapstructure:"MILVUS_ADDRESS"`         // Milvus 服务地址
+	MilvusCollection string `mapstructure:"MILVUS_COLLECTION"`      // Milvus 集合名称
+	ArkAPIKey        string `mapstructure:"ARK_API_KEY"`            // Ark API Key
+	EmbedderModel    string `mapstructure:"EMBEDDER_MODEL"`         // 嵌入模型名称
+	ArkModel         string `mapstructure:"ARK_MODEL"`              // Ark 模型名称
+	VectorStore      string `mapstructure:"VECTOR_STORE"`           // 向量存储类型: milvus (默认) 或 memory
+	ApprovalTools    string `mapstructure:"APPROVAL_TOOLS"`         // 需要审批的工具，逗号分隔；none 表示都不需要审批
+	CheckpointDir    string `mapstructure:"CHECKPOINT_DIR"`         // 等待审批的运行的检查点目录
+	WeatherBaseURL   string `mapstructure:"WEATHER_BASE_URL"`       // 天气服务地址，为空时使用本地假天气服务
+	WeatherAPIKey    string `mapstructure:"WEATHER_API_KEY"`        // 天气服务的 API Key
+	FetchDomains     string `mapstructure:"FETCH_ALLOWED_DOMAINS"`  // http_fetch 允许访问的域名，逗号分隔；为空时不启用 http_fetch
+	FetchNetworks    string `mapstructure:"FETCH_ALLOWED_NETWORKS"` // http_fetch 例外允许连接的内网网段，逗号分隔，如 10.20.0.0/16
+	SQLDatabase      string `mapstructure:"SQL_DATABASE"`           // sql_query 查询的 SQLite 数据库文件；为空时不启用 sql_query
 }
 
 // Milvus 集合结构定义（必须跟Milvus集合结构一致）
@@ -86,7 +107,7 @@ var milvusSchema = []*entity.Field{
 
 // KnowledgeSearchTool 知识搜索工具 - 从向量数据库检索相关知识
 type KnowledgeSearchTool struct {
-	retriever *retriever.Retriever // KnowledgeSearchTool 实现了 tool.BaseTool 接口
+	retriever einoretriever.Retriever // KnowledgeSearchTool 实现了 tool.BaseTool 接口
 }
 
 // Info 返回知识搜索工具的信息
@@ -110,7 +131,7 @@ func (k *KnowledgeSearchTool) Info(ctx context.Context) (*schema.ToolInfo, error
 }
 
 // InvokableRun 执行知识搜索
-func (k *KnowledgeSearchTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (k *KnowledgeSearchTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	// 解析输入参数
 	var args struct {
 		Query string `json:"query"`
@@ -129,7 +150,7 @@ func (k *KnowledgeSearchTool) InvokableRun(ctx context.Context, argumentsInJSON
 	log.Printf("[KnowledgeSearchTool] 搜索知识: %s (TopK: %d)", args.Query, args.TopK)
 
 	// 执行检索
-	docs, err := k.retriever.Retrieve(ctx, args.Query)
+	docs, err := k.retriever.Retrieve(ctx, args.Query, einoretriever.WithTopK(args.TopK))
 	if err != nil {
 		return "", fmt.Errorf("知识检索失败: %v", err)
 	}
@@ -157,9 +178,13 @@ func (k *KnowledgeSearchTool) InvokableRun(ctx context.Context, argumentsInJSON
 	return string(resultBytes), nil
 }
 
+// indexBatchSize 文档处理工具每批索引的文档块数量
+const indexBatchSize = 10
+
 // DocumentProcessorTool 文档处理工具 - 分割和索引新文档
+// 流式调用时逐步输出分割和索引进度
 type DocumentProcessorTool struct {
-	indexer     *milvus.Indexer
+	indexer     indexer.Indexer
 	transformer document.Transformer
 }
 
@@ -189,7 +214,29 @@ func (d *DocumentProcessorTool) Info(ctx context.Context) (*schema.ToolInfo, err
 }
 
 // InvokableRun 执行文档处理和索引
-func (d *DocumentProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (d *DocumentProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	return d.process(ctx, argumentsInJSON, func(string) {})
+}
+
+// StreamableRun 执行文档处理和索引，先逐步输出处理进度，最后输出与 InvokableRun 相同的 JSON 结果
+func (d *DocumentProcessorTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	sr, sw := schema.Pipe[string](0)
+	go func() {
+		defer sw.Close()
+		result, err := d.process(ctx, argumentsInJSON, func(progress string) {
+			sw.Send(progress+"\n", nil)
+		})
+		if err != nil {
+			sw.Send("", err)
+			return
+		}
+		sw.Send(result, nil)
+	}()
+	return sr, nil
+}
+
+// process 分割并索引文档，每完成一步通过 progress 报告进度
+func (d *DocumentProcessorTool) process(ctx context.Context, argumentsInJSON string, progress func(string)) (string, error) {
 	var args struct {
 		Content  string                 `json:"content"`
 		DocID    string                 `json:"doc_id"`
@@ -223,11 +270,18 @@ func (d *DocumentProcessorTool) InvokableRun(ctx context.Context, argumentsInJSO
 	if err != nil {
 		return "", fmt.Errorf("文档分割失败: %v", err)
 	}
+	progress(fmt.Sprintf("文档 %s 已分割为 %d 个块", args.DocID, len(chunks)))
 
-	// 使用 Indexer 存储文档块
-	storedIDs, err := d.indexer.Store(ctx, chunks)
-	if err != nil {
-		return "", fmt.Errorf("文档索引失败: %v", err)
+	// 使用 Indexer 分批存储文档块，每批完成后报告进度
+	storedIDs := make([]string, 0, len(chunks))
+	for start := 0; start < len(chunks); start += indexBatchSize {
+		batch := chunks[start:min(start+indexBatchSize, len(chunks))]
+		ids, err := d.indexer.Store(ctx, batch)
+		if err != nil {
+			return "", fmt.Errorf("文档索引失败: %v", err)
+		}
+		storedIDs = append(storedIDs, ids...)
+		progress(fmt.Sprintf("已索引 %d/%d 个块", len(storedIDs), len(chunks)))
 	}
 
 	result := map[string]interface{}{
@@ -261,7 +315,7 @@ func (c *CalculatorTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 // InvokableRun 执行计算
-func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	var args struct {
 		Expression string `json:"expression"`
 	}
@@ -285,59 +339,14 @@ func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON strin
 	return string(resultBytes), nil
 }
 
-// WeatherTool 天气查询工具
-type WeatherTool struct{}
-
-// Info 返回天气工具信息
-func (w *WeatherTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name: "weather_query",
-		Desc: "查询城市天气信息",
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"city": {
-				Type:     "string",
-				Desc:     "城市名称",
-				Required: true,
-			},
-			"date": {
-				Type:     "string",
-				Desc:     "查询日期(YYYY-MM-DD格式)",
-				Required: false,
-			},
-		}),
-	}, nil
+// NewWeatherTool 创建使用指定数据源的天气查询工具，查询逻辑和数据源见 tools/weather
+func NewWeatherTool(provider weather.Provider) (*weather.Tool, error) {
+	return weather.New(&weather.Config{Provider: provider, Name: "weather_query"})
 }
 
-// InvokableRun 执行天气查询
-func (w *WeatherTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
-	var args struct {
-		City string `json:"city"`
-		Date string `json:"date"`
-	}
-
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("参数解析失败: %v", err)
-	}
-
-	if args.Date == "" {
-		args.Date = time.Now().Format("2006-01-02")
-	}
-
-	log.Printf("[WeatherTool] 查询天气: %s @ %s", args.City, args.Date)
-
-	// 模拟天气数据
-	weatherData := map[string]interface{}{
-		"city":        args.City,
-		"date":        args.Date,
-		"temperature": 25,
-		"humidity":    65,
-		"condition":   "晴朗",
-		"wind_speed":  "微风",
-		"description": fmt.Sprintf("%s今日天气晴朗，温度适宜", args.City),
-	}
-
-	result, _ := json.Marshal(weatherData)
-	return string(result), nil
+// validUnits 判断单位制是否受支持，空字符串表示使用默认值
+func validUnits(units string) bool {
+	return units == "" || units == weather.UnitsMetric || units == weather.UnitsImperial
 }
 
 // ================================
@@ -346,29 +355,45 @@ func (w *WeatherTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 
 // ComprehensiveRAGSystem 综合RAG系统
 type ComprehensiveRAGSystem struct {
-	config       *Config                                 // 系统配置
-	embedder     *embedder.Embedder                      // 嵌入模型
-	milvusClient cli.Client                              // Milvus 客户端
-	indexer      *milvus.Indexer                         // 向量索引器
-	retriever    *retriever.Retriever                    // 知识检索器
-	transformer  document.Transformer                    // 文档转换器
-	chatModel    *ark.ChatModel                          // 聊天模型
-	tools        []tool.BaseTool                         // 工具集
-	chain        *compose.Chain[string, *schema.Message] // 智能处理链
+	config        *Config                                 // 系统配置
+	embedder      *embedder.Embedder                      // 嵌入模型
+	milvusClient  cli.Client                              // Milvus 客户端 (内存存储时为 nil)
+	indexer       indexer.Indexer                         // 向量索引器
+	retriever     einoretriever.Retriever                 // 知识检索器
+	documents     documentManager                         // 文档管理 (删除、列出)
+	transformer   document.Transformer                    // 文档转换器
+	chatModel     model.ChatModel                         // 聊天模型
+	tools         []tool.BaseTool                         // 工具集
+	wrappedTools  []tool.BaseTool                         // 经过中间件包装的工具集 (Agent 和 MCP 模式使用)
+	chain         *compose.Chain[string, *schema.Message] // 智能处理链
+	agentModel    model.ChatModel                         // 绑定了工具的聊天模型 (Agent 模式使用)
+	toolsNode     *compose.ToolsNode                      // 工具执行节点 (Agent 模式使用)
+	toolMetrics   *middleware.Metrics                     // 工具调用指标 (Agent 模式使用)
+	streamTools   map[string]bool                         // 支持流式输出的工具名称，执行时实时转发增量输出
+	approvalTools map[string]bool                         // 需要审批的工具名称
+	runs          *runStore                               // 等待审批的运行的检查点
+	weatherTool   *weather.Tool                           // 天气查询工具
+	weatherServer *weather.FakeServer                     // 未配置天气服务时使用的本地假天气服务
+	sqlTool       *sqlquery.Tool                          // 商品数据查询工具 (配置了 SQL_DATABASE 时启用)
 }
 
 // NewComprehensiveRAGSystem 创建综合RAG系统实例
 func NewComprehensiveRAGSystem(ctx context.Context, config *Config) (*ComprehensiveRAGSystem, error) {
 	system := &ComprehensiveRAGSystem{config: config}
 
-	// 1. 初始化 Embedder
-	if err := system.initEmbedder(ctx); err != nil {
-		return nil, fmt.Errorf("初始化Embedder失败: %v", err)
-	}
+	// 1-2. 初始化向量存储: 内存存储不需要 Embedder 和 Milvus
+	if config.VectorStore == vectorStoreMemory {
+		system.initMemoryStore()
+	} else {
+		// 1. 初始化 Embedder
+		if err := system.initEmbedder(ctx); err != nil {
+			return nil, fmt.Errorf("初始化Embedder失败: %v", err)
+		}
 
-	// 2. 初始化 Milvus
-	if err := system.initMilvus(ctx); err != nil {
-		return nil, fmt.Errorf("初始化Milvus失败: %v", err)
+		// 2. 初始化 Milvus
+		if err := system.initMilvus(ctx); err != nil {
+			return nil, fmt.Errorf("初始化Milvus失败: %v", err)
+		}
 	}
 
 	// 3. 初始化 Transformer
@@ -391,6 +416,11 @@ func NewComprehensiveRAGSystem(ctx context.Context, config *Config) (*Comprehens
 		return nil, fmt.Errorf("构建Chain失败: %v", err)
 	}
 
+	// 7. 初始化工具调用审批
+	if err := system.initApproval(); err != nil {
+		return nil, fmt.Errorf("初始化审批失败: %v", err)
+	}
+
 	return system, nil
 }
 
@@ -454,11 +484,21 @@ func (s *ComprehensiveRAGSystem) initMilvus(ctx context.Context) error {
 	}
 	// 设置 Retriever
 	s.retriever = retriever
+	s.documents = &milvusDocuments{client: client, collection: s.config.MilvusCollection}
 
 	log.Println("✓ Milvus 组件初始化成功")
 	return nil
 }
 
+// initMemoryStore 初始化内存存储，它同时充当 Indexer 和 Retriever
+func (s *ComprehensiveRAGSystem) initMemoryStore() {
+	store := newMemoryStore(5)
+	s.indexer = store
+	s.retriever = store
+	s.documents = store
+	log.Println("✓ 内存向量存储初始化成功")
+}
+
 // setupMilvusCollection 设置Milvus集合
 func (s *ComprehensiveRAGSystem) setupMilvusCollection(ctx context.Context) error {
 	has, err := s.milvusClient.HasCollection(ctx, s.config.MilvusCollection)
@@ -544,23 +584,146 @@ func (s *ComprehensiveRAGSystem) initTools(ctx context.Context) error {
 
 	// 创建其他工具
 	calcTool := &CalculatorTool{}
-	weatherTool := &WeatherTool{}
+	weatherTool, err := s.newWeatherTool()
+	if err != nil {
+		return err
+	}
+	s.weatherTool = weatherTool
 
 	// 设置工具集
 	s.tools = []tool.BaseTool{knowledgeTool, docTool, calcTool, weatherTool}
 
+	// calculator 只能计算单个表达式，多步计算和数据处理交给沙箱中执行的 JavaScript
+	s.tools = append(s.tools, codeeval.New(nil))
+
+	// 配置了允许访问的域名时启用 http_fetch，获取的网页可以直接交给文档处理工具索引
+	if s.config.FetchDomains != "" {
+		fetchTool, err := webfetch.New(&webfetch.Config{
+			AllowedDomains:  splitList(s.config.FetchDomains),
+			AllowedNetworks: splitList(s.config.FetchNetworks),
+			DocumentTool:    docTool,
+		})
+		if err != nil {
+			return fmt.Errorf("创建 http_fetch 工具失败: %w", err)
+		}
+		s.tools = append(s.tools, fetchTool)
+		log.Printf("✓ http_fetch 允许访问: %s", s.config.FetchDomains)
+	}
+
+	// 配置了数据库文件时启用 sql_query，回答向量检索无法回答的统计和列表问题
+	if s.config.SQLDatabase != "" {
+		sqlTool, err := sqlquery.New(&sqlquery.Config{Path: s.config.SQLDatabase})
+		if err != nil {
+			return fmt.Errorf("创建 sql_query 工具失败: %w", err)
+		}
+		s.sqlTool = sqlTool
+		s.tools = append(s.tools, sqlTool)
+		log.Printf("✓ sql_query 使用数据库: %s (%d 个表和视图)", s.config.SQLDatabase, len(sqlTool.Tables()))
+	}
+
 	log.Printf("✓ 初始化了 %d 个工具", len(s.tools))
 	return nil
 }
 
+// newWeatherTool 创建天气工具: 配置了 WEATHER_BASE_URL 时请求该服务，否则启动本地假天气服务；
+// 两种情况都按 城市 + 日期 缓存结果
+func (s *ComprehensiveRAGSystem) newWeatherTool() (*weather.Tool, error) {
+	var provider weather.Provider
+	if s.config.WeatherBaseURL != "" {
+		p, err := weather.NewHTTPProvider(&weather.HTTPConfig{
+			BaseURL: s.config.WeatherBaseURL,
+			APIKey:  s.config.WeatherAPIKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+		log.Printf("✓ 天气服务: %s", s.config.WeatherBaseURL)
+	} else {
+		s.weatherServer = weather.NewFakeServer("")
+		provider = s.weatherServer.Provider()
+		log.Printf("✓ 未配置 WEATHER_BASE_URL，使用本地假天气服务: %s", s.weatherServer.URL)
+	}
+	return NewWeatherTool(weather.NewCachedProvider(provider, weather.CacheConfig{}))
+}
+
 // buildChain 构建智能处理链
 func (s *ComprehensiveRAGSystem) buildChain(ctx context.Context) error {
-	// 这里可以构建复杂的处理链
-	// 为演示目的，我们暂时不实现完整的Chain
+	// Agent 模式需要一个绑定了工具的聊天模型。
+	// BindTools 会修改模型实例本身，所以单独创建一个实例，
+	// 保证 ProcessUserQuery 使用的 chatModel 仍然只生成文本回答。
+	agentModel, err := ark.NewChatModel(ctx, &ark.ChatModelConfig{
+		APIKey: s.config.ArkAPIKey,
+		Model:  s.config.ArkModel,
+	})
+	if err != nil {
+		return err
+	}
+
+	toolInfos := make([]*schema.ToolInfo, 0, len(s.tools))
+	s.streamTools = make(map[string]bool)
+	for _, t := range s.tools {
+		info, err := t.Info(ctx)
+		if err != nil {
+			return err
+		}
+		toolInfos = append(toolInfos, info)
+		if _, ok := t.(tool.StreamableTool); ok {
+			s.streamTools[info.Name] = true
+		}
+	}
+	if err := agentModel.BindTools(toolInfos); err != nil {
+		return err
+	}
+	s.agentModel = agentModel
+
+	// 创建工具执行节点，负责执行模型返回的工具调用。
+	// 工具经过中间件包装: 统一记录日志和调用指标，执行前按参数定义校验参数
+	// (校验失败时把问题作为工具结果返回给模型修正)，并限制单次执行时间。
+	s.toolMetrics = middleware.NewMetrics()
+	wrappedTools, err := middleware.WrapAll(ctx, s.tools,
+		middleware.WithLogging(),
+		middleware.WithMetrics(s.toolMetrics),
+		middleware.WithValidation(),
+		middleware.WithTimeout(toolTimeout),
+	)
+	if err != nil {
+		return err
+	}
+	toolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: wrappedTools})
+	if err != nil {
+		return err
+	}
+	s.toolsNode = toolsNode
+	s.wrappedTools = wrappedTools
+
 	log.Println("✓ Chain 构建完成")
 	return nil
 }
 
+// initApproval 初始化工具调用审批: 解析需要审批的工具，打开检查点目录
+func (s *ComprehensiveRAGSystem) initApproval() error {
+	runs, err := newRunStore(s.config.CheckpointDir)
+	if err != nil {
+		return err
+	}
+	s.runs = runs
+	s.approvalTools = parseApprovalTools(s.config.ApprovalTools)
+
+	names := make([]string, 0, len(s.approvalTools))
+	for name := range s.approvalTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Printf("✓ 工具审批初始化完成，需要审批的工具: %v", names)
+
+	// 进程重启后，之前暂停的运行仍然可以恢复
+	if pending, err := runs.list(); err == nil && len(pending) > 0 {
+		log.Printf("  有 %d 个运行等待审批", len(pending))
+	}
+	return nil
+}
+
 // LoadInitialKnowledge 加载初始知识库
 func (s *ComprehensiveRAGSystem) LoadInitialKnowledge(ctx context.Context) error {
 	log.Println("\n=== 加载初始知识库 ===")
@@ -597,8 +760,8 @@ func (s *ComprehensiveRAGSystem) LoadInitialKnowledge(ctx context.Context) error
 	allChunks := make([]*schema.Document, 0)
 	// 遍历每个文档进行分割
 	for _, doc := range documents {
-		// 分割文档
-		chunks, err := s.transformer.Transform(ctx, []*schema.Document{doc})
+		// 分割文档，文档块带上 doc_id 以便按文档列出和删除
+		chunks, err := s.splitDocument(ctx, doc)
 		if err != nil {
 			return fmt.Errorf("分割文档 %s 失败: %v", doc.ID, err)
 		}
@@ -612,9 +775,11 @@ func (s *ComprehensiveRAGSystem) LoadInitialKnowledge(ctx context.Context) error
 		return fmt.Errorf("存储文档失败: %v", err)
 	}
 
-	// 加载集合到内存
-	if err := s.milvusClient.LoadCollection(ctx, s.config.MilvusCollection, false); err != nil {
-		return fmt.Errorf("加载集合失败: %v", err)
+	// 加载集合到内存 (仅 Milvus 需要)
+	if s.milvusClient != nil {
+		if err := s.milvusClient.LoadCollection(ctx, s.config.MilvusCollection, false); err != nil {
+			return fmt.Errorf("加载集合失败: %v", err)
+		}
 	}
 
 	log.Printf("✓ 成功加载 %d 个文档块到知识库", len(storedIDs))
@@ -648,9 +813,15 @@ func (s *ComprehensiveRAGSystem) ProcessUserQuery(ctx context.Context, query str
 		log.Printf("计算器工具结果: %s", calcResult)
 	}
 
+	// 演示代码执行工具: 多步计算
+	codeResult, err := codeeval.New(nil).InvokableRun(ctx,
+		`{"code": "const prices = input.prices; const total = prices.reduce((a, b) => a + b, 0); ({total, average: total / prices.length})", "input": {"prices": [199, 89.5, 45]}}`)
+	if err == nil {
+		log.Printf("代码执行工具结果: %s", codeResult)
+	}
+
 	// 演示天气工具
-	weatherTool := &WeatherTool{}
-	weatherResult, err := weatherTool.InvokableRun(ctx, `{"city": "北京"}`)
+	weatherResult, err := s.weatherTool.InvokableRun(ctx, `{"city": "北京"}`)
 	if err == nil {
 		log.Printf("天气工具结果: %s", truncateString(weatherResult, 150))
 	}
@@ -678,6 +849,12 @@ func (s *ComprehensiveRAGSystem) ProcessUserQuery(ctx context.Context, query str
 
 // Close 关闭系统资源
 func (s *ComprehensiveRAGSystem) Close() error {
+	if s.weatherServer != nil {
+		s.weatherServer.Close()
+	}
+	if s.sqlTool != nil {
+		s.sqlTool.Close()
+	}
 	if s.milvusClient != nil {
 		return s.milvusClient.Close()
 	}
@@ -699,7 +876,8 @@ func loadConfig() (*Config, error) {
 
 	// 设置环境变量前缀
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("未找到 config.yaml 文件，将从环境变量读取配置。")
+		// 使用 log 输出到 stderr，避免干扰 MCP stdio 模式下 stdout 上的协议消息
+		log.Println("未找到 config.yaml 文件，将从环境变量读取配置。")
 	}
 
 	// 读取文件或环境变量中的配置
@@ -709,6 +887,23 @@ func loadConfig() (*Config, error) {
 		ArkAPIKey:        viper.GetString("ARK_API_KEY"),
 		EmbedderModel:    viper.GetString("EMBEDDER_MODEL"),
 		ArkModel:         viper.GetString("ARK_MODEL"),
+		VectorStore:      viper.GetString("VECTOR_STORE"),
+		ApprovalTools:    viper.GetString("APPROVAL_TOOLS"),
+		CheckpointDir:    viper.GetString("CHECKPOINT_DIR"),
+		WeatherBaseURL:   viper.GetString("WEATHER_BASE_URL"),
+		WeatherAPIKey:    viper.GetString("WEATHER_API_KEY"),
+		FetchDomains:     viper.GetString("FETCH_ALLOWED_DOMAINS"),
+		FetchNetworks:    viper.GetString("FETCH_ALLOWED_NETWORKS"),
+		SQLDatabase:      viper.GetString("SQL_DATABASE"),
+	}
+	if config.VectorStore == "" {
+		config.VectorStore = vectorStoreMilvus
+	}
+	if config.ApprovalTools == "" {
+		config.ApprovalTools = defaultApprovalTools
+	}
+	if config.CheckpointDir == "" {
+		config.CheckpointDir = defaultCheckpointDir
 	}
 
 	// 验证配置
@@ -717,18 +912,24 @@ func loadConfig() (*Config, error) {
 
 // validateConfig 验证配置
 func validateConfig(config *Config) error {
-	if config.MilvusAddress == "" {
-		return fmt.Errorf("MILVUS_ADDRESS 必须设置")
-	}
-	if config.MilvusCollection == "" {
-		return fmt.Errorf("MILVUS_COLLECTION 必须设置")
+	switch config.VectorStore {
+	case vectorStoreMilvus:
+		if config.MilvusAddress == "" {
+			return fmt.Errorf("MILVUS_ADDRESS 必须设置")
+		}
+		if config.MilvusCollection == "" {
+			return fmt.Errorf("MILVUS_COLLECTION 必须设置")
+		}
+		if config.EmbedderModel == "" {
+			return fmt.Errorf("EMBEDDER_MODEL 必须设置")
+		}
+	case vectorStoreMemory:
+	default:
+		return fmt.Errorf("VECTOR_STORE 只能是 %s 或 %s", vectorStoreMilvus, vectorStoreMemory)
 	}
 	if config.ArkAPIKey == "" {
 		return fmt.Errorf("ARK_API_KEY 必须设置")
 	}
-	if config.EmbedderModel == "" {
-		return fmt.Errorf("EMBEDDER_MODEL 必须设置")
-	}
 	if config.ArkModel == "" {
 		return fmt.Errorf("ARK_MODEL 必须设置")
 	}
@@ -778,12 +979,24 @@ func evaluateSimpleExpression(expr string) float64 {
 	return result
 }
 
-// truncateString 截断字符串
+// truncateString 截断字符串 (按字符截断，避免切断多字节的中文字符)
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "..."
+	return string(runes[:maxLen]) + "..."
+}
+
+// splitList 解析逗号分隔的配置项，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // ================================
@@ -791,6 +1004,15 @@ func truncateString(s string, maxLen int) string {
 // ================================
 
 func main() {
+	mode := flag.String("mode", "demo", "运行模式: demo (依次处理内置演示查询)、repl (交互式对话)、server (HTTP 服务) 或 mcp (MCP 服务)")
+	load := flag.Bool("load", true, "启动时是否加载初始知识库")
+	verbose := flag.Bool("verbose", false, "repl 模式下是否输出组件日志")
+	addr := flag.String("addr", "127.0.0.1:8080", "server 模式以及 mcp 模式 (http 传输) 下的监听地址")
+	mcpTransport := flag.String("mcp-transport", "stdio", "mcp 模式下的传输方式: stdio 或 http (Streamable HTTP)")
+	mcpApprovalTools := flag.Bool("mcp-allow-approval-tools", false, "mcp 模式下是否提供需要审批的工具 (APPROVAL_TOOLS)，由 MCP 客户端负责确认")
+	timeout := flag.Duration("timeout", 60*time.Second, "server 模式下单个请求的超时时间")
+	flag.Parse()
+
 	log.Println("🚀 启动 Eino 综合演示系统")
 
 	// 加载配置
@@ -811,10 +1033,41 @@ func main() {
 	log.Println("✅ 综合RAG系统初始化完成")
 
 	// 加载初始知识库
-	if err := system.LoadInitialKnowledge(ctx); err != nil {
-		log.Fatalf("❌ 知识库加载失败: %v", err)
+	if *load {
+		if err := system.LoadInitialKnowledge(ctx); err != nil {
+			log.Fatalf("❌ 知识库加载失败: %v", err)
+		}
 	}
 
+	switch *mode {
+	case "demo":
+		runDemo(ctx, system)
+	case "repl":
+		// 交互模式下组件日志会打断流式输出，默认关闭
+		if !*verbose {
+			log.SetOutput(io.Discard)
+		}
+		if err := runREPL(ctx, system); err != nil {
+			// 恢复日志输出，否则退出原因不会打印
+			log.SetOutput(os.Stderr)
+			log.Fatalf("❌ 交互模式异常退出: %v", err)
+		}
+	case "server":
+		if err := runServer(ctx, system, *addr, *timeout); err != nil {
+			log.Fatalf("❌ HTTP 服务异常退出: %v", err)
+		}
+	case "mcp":
+		if err := runMCPServer(ctx, system, *mcpTransport, *addr, *mcpApprovalTools); err != nil {
+			log.Fatalf("❌ MCP 服务异常退出: %v", err)
+		}
+	default:
+		log.Fatalf("❌ 未知的运行模式: %s", *mode)
+	}
+}
+
+// runDemo 依次处理内置的演示查询
+func runDemo(ctx context.Context, system *ComprehensiveRAGSystem) {
+
 	// 演示查询处理
 	queries := []string{
 		"什么是 Eino 框架？",
@@ -824,7 +1077,7 @@ func main() {
 
 	// 遍历查询列表，依次处理每个查询
 	for i, query := range queries {
-		log.Printf("\n" + strings.Repeat("=", 60))
+		log.Println("\n" + strings.Repeat("=", 60))
 		log.Printf("演示查询 %d/%d", i+1, len(queries))
 
 		// 处理用户查询
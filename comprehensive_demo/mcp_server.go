@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/mcp_server.go
+//  功能: 以 MCP (Model Context Protocol) 服务的形式对外提供系统能力 (go run . -mode mcp)，
+//        让 Claude Desktop、Cursor 等外部 Agent 可以直接使用我们的工具和知识库。
+//  能力:
+//    tools      - 所有实现了 tool.InvokableTool 的工具，ToolInfo 转换为 MCP 工具的 JSON Schema；
+//                 与 Agent 循环使用同样经过中间件包装的工具 (参数校验、超时、指标)，
+//                 需要审批的工具默认不提供，因为 MCP 调用不经过审批
+//    resources  - kb://documents 列出已索引的文档，kb://documents/{id} 读取单篇文档
+//    prompts    - prompts.go 中的对话模板 (ChatTemplate)
+//  传输:
+//    stdio  - 通过标准输入输出通信，stdout 只能输出协议消息，日志统一写到 stderr
+//    http   - Streamable HTTP，端点为 http://<addr>/mcp
+//
+// =============================================================================
+
+const (
+	mcpServerName   = "eino-comprehensive-demo"
+	mcpServerVer    = "1.0.0"
+	mcpEndpointPath = "/mcp"
+
+	documentsURI      = "kb://documents"
+	documentURIPrefix = documentsURI + "/"
+)
+
+// NewMCPServer 创建 MCP 服务，注册工具、文档资源和对话模板。
+// allowApprovalTools 为 false 时不注册需要审批的工具。
+func NewMCPServer(ctx context.Context, system *ComprehensiveRAGSystem, allowApprovalTools bool) (*server.MCPServer, error) {
+	s := server.NewMCPServer(mcpServerName, mcpServerVer,
+		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(false, false),
+		server.WithPromptCapabilities(false),
+		server.WithRecovery(),
+		server.WithInstructions("Eino 综合 RAG 系统: 提供知识检索、计算器、天气查询等工具，"+
+			"以及知识库文档资源 (kb://documents) 和问答模板。"),
+	)
+
+	var skip map[string]bool
+	if !allowApprovalTools {
+		skip = system.approvalTools
+	}
+	if err := registerMCPTools(ctx, s, system.wrappedTools, skip); err != nil {
+		return nil, err
+	}
+	if err := registerMCPResources(ctx, s, system); err != nil {
+		return nil, err
+	}
+	registerMCPPrompts(s, system.chatTemplates())
+	return s, nil
+}
+
+// ================================
+// 工具
+// ================================
+
+// registerMCPTools 把可执行的工具注册为 MCP 工具，只实现了 BaseTool 的工具和 skip 中的工具会被跳过
+func registerMCPTools(ctx context.Context, s *server.MCPServer, tools []tool.BaseTool, skip map[string]bool) error {
+	for _, t := range tools {
+		invokable, ok := t.(tool.InvokableTool)
+		if !ok {
+			continue
+		}
+		info, err := t.Info(ctx)
+		if err != nil {
+			return fmt.Errorf("获取工具信息失败: %w", err)
+		}
+		if skip[info.Name] {
+			log.Printf("[MCP] 跳过需要审批的工具: %s", info.Name)
+			continue
+		}
+		inputSchema, err := toolInputSchema(info)
+		if err != nil {
+			return fmt.Errorf("转换工具 %s 的参数定义失败: %w", info.Name, err)
+		}
+
+		s.AddTool(mcp.NewToolWithRawSchema(info.Name, info.Desc, inputSchema), mcpToolHandler(info.Name, invokable))
+		log.Printf("[MCP] 注册工具: %s", info.Name)
+	}
+	return nil
+}
+
+// toolInputSchema 把 ToolInfo 的参数定义转换为 MCP 要求的 JSON Schema (type 必须为 object)
+func toolInputSchema(info *schema.ToolInfo) (json.RawMessage, error) {
+	if info.ParamsOneOf == nil {
+		return json.RawMessage(`{"type":"object","properties":{}}`), nil
+	}
+	params, err := info.ParamsOneOf.ToOpenAPIV3()
+	if err != nil {
+		return nil, err
+	}
+	if params.Type == "" {
+		params.Type = "object"
+	}
+	return json.Marshal(params)
+}
+
+// mcpToolHandler 把 MCP 工具调用转发给 Eino 工具。
+// 工具执行失败以 isError 结果返回给调用方 (由模型决定如何处理)，而不是作为协议错误。
+func mcpToolHandler(name string, t tool.InvokableTool) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := "{}"
+		if raw := request.GetRawArguments(); raw != nil {
+			data, err := json.Marshal(raw)
+			if err != nil {
+				return mcp.NewToolResultErrorf("参数序列化失败: %v", err), nil
+			}
+			args = string(data)
+		}
+
+		log.Printf("[MCP] 调用工具 %s，参数: %s", name, truncateString(args, 200))
+		result, err := t.InvokableRun(ctx, args)
+		if err != nil {
+			log.Printf("[MCP] 工具 %s 执行失败: %v", name, err)
+			return mcp.NewToolResultErrorFromErr("工具执行失败", err), nil
+		}
+		return mcp.NewToolResultText(result), nil
+	}
+}
+
+// ================================
+// 资源
+// ================================
+
+// registerMCPResources 注册文档列表资源、单篇文档的资源模板，
+// 并把启动时已索引的文档逐个注册为资源，方便客户端直接浏览。
+func registerMCPResources(ctx context.Context, s *server.MCPServer, system *ComprehensiveRAGSystem) error {
+	s.AddResource(
+		mcp.NewResource(documentsURI, "知识库文档列表",
+			mcp.WithResourceDescription("已索引文档的 ID、文档块数量和元数据"),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			docs, err := system.ListDocuments(ctx)
+			if err != nil {
+				return nil, err
+			}
+			data, err := json.MarshalIndent(map[string]any{"documents": docs}, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			}}, nil
+		},
+	)
+
+	readDocument := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readDocumentResource(ctx, system, request.Params.URI)
+	}
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(documentURIPrefix+"{id}", "知识库文档",
+			mcp.WithTemplateDescription("按文档 ID 读取文档内容 (各文档块按顺序拼接)"),
+			mcp.WithTemplateMIMEType("text/markdown"),
+		),
+		readDocument,
+	)
+
+	docs, err := system.ListDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("列出知识库文档失败: %w", err)
+	}
+	for _, doc := range docs {
+		s.AddResource(
+			mcp.NewResource(documentURIPrefix+doc.ID, doc.ID,
+				mcp.WithResourceDescription(fmt.Sprintf("知识库文档，共 %d 个文档块", doc.Chunks)),
+				mcp.WithMIMEType("text/markdown"),
+			),
+			readDocument,
+		)
+	}
+	log.Printf("[MCP] 注册文档资源: %d 篇", len(docs))
+	return nil
+}
+
+// readDocumentResource 读取 kb://documents/{id} 对应的文档
+func readDocumentResource(ctx context.Context, system *ComprehensiveRAGSystem, uri string) ([]mcp.ResourceContents, error) {
+	docID := strings.TrimPrefix(uri, documentURIPrefix)
+	if docID == "" || docID == uri {
+		return nil, fmt.Errorf("无效的文档 URI: %s", uri)
+	}
+	chunks, err := system.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("文档 %s 不存在", docID)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "text/markdown",
+		Text:     joinChunks(chunks),
+	}}, nil
+}
+
+// ================================
+// 对话模板
+// ================================
+
+// registerMCPPrompts 把对话模板注册为 MCP prompts
+func registerMCPPrompts(s *server.MCPServer, templates []*promptTemplate) {
+	for _, tpl := range templates {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(tpl.Description)}
+		for _, arg := range tpl.Arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+			if arg.Required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+		}
+
+		tpl := tpl
+		s.AddPrompt(mcp.NewPrompt(tpl.Name, opts...), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			messages, err := tpl.Render(ctx, request.Params.Arguments)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewGetPromptResult(tpl.Description, toPromptMessages(messages)), nil
+		})
+		log.Printf("[MCP] 注册模板: %s", tpl.Name)
+	}
+}
+
+// toPromptMessages 转换消息格式。MCP prompts 只有 user 和 assistant 两种角色，
+// 系统消息作为 user 消息发送。
+func toPromptMessages(messages []*schema.Message) []mcp.PromptMessage {
+	ret := make([]mcp.PromptMessage, 0, len(messages))
+	for _, msg := range messages {
+		role := mcp.RoleUser
+		if msg.Role == schema.Assistant {
+			role = mcp.RoleAssistant
+		}
+		ret = append(ret, mcp.NewPromptMessage(role, mcp.NewTextContent(msg.Content)))
+	}
+	return ret
+}
+
+// ================================
+// 启动
+// ================================
+
+// runMCPServer 按指定的传输方式启动 MCP 服务
+func runMCPServer(ctx context.Context, system *ComprehensiveRAGSystem, transport, addr string, allowApprovalTools bool) error {
+	s, err := NewMCPServer(ctx, system, allowApprovalTools)
+	if err != nil {
+		return err
+	}
+
+	switch transport {
+	case "stdio":
+		log.Println("🔌 MCP 服务已启动 (stdio)")
+		// ServeStdio 内部处理 SIGINT / SIGTERM
+		return server.ServeStdio(s)
+	case "http":
+		return serveMCPHTTP(ctx, s, addr)
+	default:
+		return fmt.Errorf("未知的 MCP 传输方式: %s", transport)
+	}
+}
+
+// serveMCPHTTP 以 Streamable HTTP 方式提供 MCP 服务，收到 SIGINT / SIGTERM 时优雅退出
+func serveMCPHTTP(ctx context.Context, s *server.MCPServer, addr string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.Handle(mcpEndpointPath, server.NewStreamableHTTPServer(s, server.WithEndpointPath(mcpEndpointPath)))
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("🔌 MCP 服务已启动: http://%s%s", addr, mcpEndpointPath)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("⏳ 正在关闭 MCP 服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("关闭 MCP 服务失败: %v", err)
+	}
+	log.Println("✓ MCP 服务已关闭")
+	return nil
+}
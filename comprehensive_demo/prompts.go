@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/prompts.go
+//  功能: 系统对外发布的对话模板 (ChatTemplate)，目前由 MCP 服务以 prompts 的形式提供。
+//  模板:
+//    knowledge_qa      - 检索知识库后基于背景知识回答问题
+//    document_summary  - 总结知识库中的某篇文档
+//    role_task         - 指定角色完成任务 (与 chattemplate_demo 中的示例一致)
+//  说明: 模板变量使用 FString 格式 ({变量名})，部分变量由 Prepare 在格式化前
+//        根据参数自动填充，例如 knowledge_qa 的 {knowledge} 来自知识库检索结果。
+//
+// =============================================================================
+
+// promptArgument 模板参数
+type promptArgument struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// promptTemplate 对外发布的对话模板
+type promptTemplate struct {
+	Name        string
+	Description string
+	Arguments   []promptArgument
+	Template    prompt.ChatTemplate
+	// Prepare 在格式化之前补充模板变量，可为空
+	Prepare func(ctx context.Context, vars map[string]any) error
+}
+
+// Render 使用参数格式化模板，返回消息列表
+func (p *promptTemplate) Render(ctx context.Context, args map[string]string) ([]*schema.Message, error) {
+	vars := make(map[string]any, len(args))
+	for _, arg := range p.Arguments {
+		value, ok := args[arg.Name]
+		if arg.Required && (!ok || strings.TrimSpace(value) == "") {
+			return nil, fmt.Errorf("缺少参数: %s", arg.Name)
+		}
+		vars[arg.Name] = value
+	}
+	if p.Prepare != nil {
+		if err := p.Prepare(ctx, vars); err != nil {
+			return nil, err
+		}
+	}
+	return p.Template.Format(ctx, vars)
+}
+
+// chatTemplates 返回系统发布的全部对话模板
+func (s *ComprehensiveRAGSystem) chatTemplates() []*promptTemplate {
+	return []*promptTemplate{
+		{
+			Name:        "knowledge_qa",
+			Description: "检索知识库，并基于检索到的背景知识回答问题",
+			Arguments: []promptArgument{
+				{Name: "question", Description: "用户问题", Required: true},
+				{Name: "top_k", Description: "检索的知识片段数量，默认使用检索器配置"},
+			},
+			Template: prompt.FromMessages(schema.FString,
+				schema.SystemMessage("你是一个严谨的问答助手，请结合以下知识信息提供准确、详细的回答。如果知识信息不足，请说明情况。\n\n=== 知识库信息 ===\n{knowledge}"),
+				schema.UserMessage("{question}"),
+			),
+			Prepare: func(ctx context.Context, vars map[string]any) error {
+				topK := 0
+				if v, _ := vars["top_k"].(string); v != "" {
+					n, err := strconv.Atoi(v)
+					if err != nil || n <= 0 {
+						return fmt.Errorf("top_k 必须是正整数: %s", v)
+					}
+					topK = n
+				}
+				docs, err := s.Retrieve(ctx, vars["question"].(string), topK)
+				if err != nil {
+					return fmt.Errorf("检索失败: %w", err)
+				}
+				vars["knowledge"] = formatKnowledge(docs)
+				return nil
+			},
+		},
+		{
+			Name:        "document_summary",
+			Description: "总结知识库中指定文档的要点",
+			Arguments: []promptArgument{
+				{Name: "doc_id", Description: "文档 ID，可通过 kb://documents 资源查看", Required: true},
+			},
+			Template: prompt.FromMessages(schema.FString,
+				schema.SystemMessage("你是一个专业的文档分析助手，擅长提炼文档的结构和要点。"),
+				schema.UserMessage("请总结文档《{doc_id}》的要点，使用分条列表输出：\n\n{content}"),
+			),
+			Prepare: func(ctx context.Context, vars map[string]any) error {
+				docID := vars["doc_id"].(string)
+				chunks, err := s.GetDocument(ctx, docID)
+				if err != nil {
+					return fmt.Errorf("读取文档失败: %w", err)
+				}
+				if len(chunks) == 0 {
+					return fmt.Errorf("文档 %s 不存在", docID)
+				}
+				vars["content"] = joinChunks(chunks)
+				return nil
+			},
+		},
+		{
+			Name:        "role_task",
+			Description: "让模型扮演指定角色完成任务",
+			Arguments: []promptArgument{
+				{Name: "role", Description: "模型扮演的角色，例如 翻译专家", Required: true},
+				{Name: "task", Description: "需要完成的任务", Required: true},
+			},
+			Template: prompt.FromMessages(schema.FString,
+				schema.SystemMessage("你是一个{role}。"),
+				schema.UserMessage("我的任务是：{task}。"),
+			),
+		},
+	}
+}
+
+// formatKnowledge 把检索结果格式化为知识片段列表
+func formatKnowledge(docs []*schema.Document) string {
+	if len(docs) == 0 {
+		return "(知识库中没有检索到相关信息)"
+	}
+	var sb strings.Builder
+	for i, doc := range docs {
+		sb.WriteString(fmt.Sprintf("[知识片段 %d]\n%s\n\n", i+1, doc.Content))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// joinChunks 按顺序拼接文档块内容
+func joinChunks(chunks []*schema.Document) string {
+	contents := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		contents = append(contents, chunk.Content)
+	}
+	return strings.Join(contents, "\n\n")
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"Eini/tools/weather"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/repl.go
+//  功能: 交互式终端对话模式 (go run . -mode repl)。
+//  特性:
+//  1. 多轮对话，保留对话历史
+//  2. 通过 ChatModel.Stream 流式输出回答
+//  3. 实时展示工具调用及其结果 (流式工具的输出边执行边展示)、检索到的知识来源
+//  4. 斜杠命令: /reset /sources /tools /topk N /units /save [文件] /pending /approve /reject /help /exit
+//  5. 对话记录导出为 Markdown 或 JSON
+//  6. 需要审批的工具调用在终端中逐个确认，也可以推迟到之后用 /approve /reject 处理
+//
+// =============================================================================
+
+// defaultREPLTopK 交互模式下默认检索的文档数量
+const defaultREPLTopK = 3
+
+// transcriptToolCall 对话记录中的一次工具调用
+type transcriptToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+}
+
+// transcriptSource 对话记录中的一个知识来源
+type transcriptSource struct {
+	ID       string         `json:"id"`
+	Content  string         `json:"content"`
+	MetaData map[string]any `json:"metadata,omitempty"`
+}
+
+// transcriptTurn 对话记录中的一轮对话
+type transcriptTurn struct {
+	Time      time.Time             `json:"time"`
+	Query     string                `json:"query"`
+	Answer    string                `json:"answer"`
+	ToolCalls []*transcriptToolCall `json:"tool_calls,omitempty"`
+	Sources   []*transcriptSource   `json:"sources,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// replSession 保存交互会话的状态
+type replSession struct {
+	system      *ComprehensiveRAGSystem
+	in          *bufio.Scanner
+	out         io.Writer
+	history     []*schema.Message  // 对话历史 (不含系统提示词)
+	topK        int                // 检索的文档数量
+	units       string             // 天气工具的单位制，为空时使用工具默认值
+	lastSources []*schema.Document // 最近一轮检索到的知识来源
+	turns       []*transcriptTurn  // 完整的对话记录
+}
+
+// runREPL 启动交互式对话，读取标准输入直到 EOF 或 /exit
+func runREPL(ctx context.Context, system *ComprehensiveRAGSystem) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	session := &replSession{system: system, in: scanner, out: os.Stdout, topK: defaultREPLTopK}
+
+	fmt.Fprintln(session.out, "\n💬 进入交互模式，输入问题开始对话，输入 /help 查看命令，/exit 退出")
+	if pending, err := system.PendingApprovals(); err == nil && len(pending) > 0 {
+		fmt.Fprintf(session.out, "⏸ 有 %d 个运行等待审批，输入 /pending 查看\n", len(pending))
+	}
+
+	for {
+		fmt.Fprint(session.out, "\n你> ")
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			if quit := session.handleCommand(ctx, line); quit {
+				return nil
+			}
+			continue
+		}
+		session.ask(ctx, line)
+	}
+
+	fmt.Fprintln(session.out)
+	return scanner.Err()
+}
+
+// ask 发送一轮对话，流式展示回答
+func (r *replSession) ask(ctx context.Context, query string) {
+	r.runTurn(ctx, &transcriptTurn{Time: time.Now(), Query: query}, func(ctx context.Context, onEvent func(*ChatEvent)) (*ChatResult, error) {
+		return r.system.Chat(ctx, &ChatRequest{History: r.history, Query: query, TopK: r.topK, Units: r.units}, onEvent)
+	})
+}
+
+// resume 以同一个决定处理运行中所有待审批的工具调用，并继续该运行
+func (r *replSession) resume(ctx context.Context, runID string, approved bool, reason string) {
+	pending, err := r.system.PendingApproval(runID)
+	if err != nil {
+		fmt.Fprintf(r.out, "❌ %v\n", err)
+		return
+	}
+	decisions := make(map[string]*ApprovalDecision, len(pending.Calls))
+	for _, call := range pending.Calls {
+		decisions[call.ToolCallID] = &ApprovalDecision{Approved: approved, Reason: reason}
+	}
+	r.runTurn(ctx, &transcriptTurn{Time: time.Now(), Query: pending.Query}, func(ctx context.Context, onEvent func(*ChatEvent)) (*ChatResult, error) {
+		return r.system.ResumeChat(ctx, runID, decisions, onEvent)
+	})
+}
+
+// runTurn 执行 (或恢复) 一轮对话并展示结果。运行因审批暂停时在终端中逐个询问，
+// 直到得到最终回答或审批被推迟。按 Ctrl+C 只会中断当前回答，不会退出程序。
+func (r *replSession) runTurn(ctx context.Context, turn *transcriptTurn, run chatRunner) {
+	turnCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	onEvent := r.eventHandler(turn)
+	fmt.Fprint(r.out, "\n助手> ")
+	result, err := run(turnCtx, onEvent)
+	fmt.Fprintln(r.out)
+
+	for err == nil && result.Pending != nil {
+		decisions, ok := r.promptApproval(result.Pending)
+		if !ok {
+			break
+		}
+		runID := result.Pending.RunID
+		fmt.Fprint(r.out, "\n助手> ")
+		result, err = r.system.ResumeChat(turnCtx, runID, decisions, onEvent)
+		fmt.Fprintln(r.out)
+	}
+
+	r.recordSources(turn)
+
+	if err != nil {
+		if errors.Is(turnCtx.Err(), context.Canceled) {
+			err = errors.New("已中断")
+		}
+		turn.Error = err.Error()
+		r.turns = append(r.turns, turn)
+		fmt.Fprintf(r.out, "❌ %v\n", err)
+		return
+	}
+
+	// 推迟审批: 本轮消息里的工具调用还没有结果，暂不写入历史
+	if result.Pending != nil {
+		turn.Error = fmt.Sprintf("等待审批 (运行 %s)", result.Pending.RunID)
+		r.turns = append(r.turns, turn)
+		fmt.Fprintf(r.out, "⏸ 运行 %s 等待审批，可稍后使用 /approve 或 /reject 处理\n", result.Pending.RunID)
+		return
+	}
+
+	turn.Answer = result.Answer
+	r.turns = append(r.turns, turn)
+	r.history = append(r.history, result.Messages...)
+
+	if len(result.Sources) > 0 {
+		fmt.Fprintf(r.out, "\n📚 参考来源 (%d): ", len(result.Sources))
+		ids := make([]string, 0, len(result.Sources))
+		for _, doc := range result.Sources {
+			ids = append(ids, doc.ID)
+		}
+		fmt.Fprintln(r.out, strings.Join(ids, ", "))
+	}
+}
+
+// eventHandler 返回展示对话事件并记录到对话记录中的回调
+func (r *replSession) eventHandler(turn *transcriptTurn) func(*ChatEvent) {
+	calls := make(map[string]*transcriptToolCall)
+	streamed := make(map[string]bool) // 已经实时展示过输出的工具调用
+	lastDelta := ""                   // 最近一次增量输出所属的工具调用
+	return func(e *ChatEvent) {
+		switch e.Type {
+		case ChatEventToken:
+			fmt.Fprint(r.out, e.Content)
+		case ChatEventSources:
+			r.lastSources = e.Sources
+		case ChatEventToolCall:
+			if _, ok := calls[e.ToolCallID]; ok {
+				break // 审批后恢复运行时会重新发送已经展示过的工具调用
+			}
+			call := &transcriptToolCall{ID: e.ToolCallID, Name: e.ToolName, Arguments: e.Arguments}
+			calls[e.ToolCallID] = call
+			turn.ToolCalls = append(turn.ToolCalls, call)
+			fmt.Fprintf(r.out, "\n  🔧 调用工具 %s %s\n", e.ToolName, e.Arguments)
+		case ChatEventApproval:
+			fmt.Fprintf(r.out, "  ⏸ 工具 %s 需要审批\n", e.ToolName)
+		case ChatEventToolDelta:
+			// 并行执行的工具输出交错到达，切换到另一个调用时换行并标出工具名称
+			if lastDelta != e.ToolCallID {
+				if lastDelta != "" {
+					fmt.Fprintln(r.out)
+				}
+				fmt.Fprintf(r.out, "  ↳ [%s] ", e.ToolName)
+				lastDelta = e.ToolCallID
+			}
+			streamed[e.ToolCallID] = true
+			fmt.Fprint(r.out, strings.ReplaceAll(e.Content, "\n", "\n      "))
+		case ChatEventToolResult:
+			if call, ok := calls[e.ToolCallID]; ok {
+				call.Result = e.Content
+			}
+			if streamed[e.ToolCallID] {
+				fmt.Fprintln(r.out) // 输出已经实时展示过
+				lastDelta = ""
+				break
+			}
+			fmt.Fprintf(r.out, "  ↳ %s\n", truncateString(e.Content, 200))
+		}
+	}
+}
+
+// recordSources 把最近一轮检索到的知识来源记录到对话记录中
+func (r *replSession) recordSources(turn *transcriptTurn) {
+	turn.Sources = turn.Sources[:0]
+	for _, doc := range r.lastSources {
+		turn.Sources = append(turn.Sources, &transcriptSource{ID: doc.ID, Content: doc.Content, MetaData: doc.MetaData})
+	}
+}
+
+// promptApproval 在终端中逐个询问待审批的工具调用。
+// 直接回车或输入结束时返回 false，表示推迟审批。
+func (r *replSession) promptApproval(pending *PendingApproval) (map[string]*ApprovalDecision, bool) {
+	decisions := make(map[string]*ApprovalDecision, len(pending.Calls))
+	for _, call := range pending.Calls {
+		fmt.Fprintf(r.out, "\n⚠️  工具 %s 请求执行，参数: %s\n", call.ToolName, call.Arguments)
+		fmt.Fprint(r.out, "批准执行? y 批准 / n 拒绝 / 输入拒绝原因 / 直接回车稍后处理: ")
+		if !r.in.Scan() {
+			return nil, false
+		}
+		answer := strings.TrimSpace(r.in.Text())
+		switch strings.ToLower(answer) {
+		case "":
+			return nil, false
+		case "y", "yes":
+			decisions[call.ToolCallID] = &ApprovalDecision{Approved: true}
+		case "n", "no":
+			decisions[call.ToolCallID] = &ApprovalDecision{Reason: "用户拒绝"}
+		default:
+			decisions[call.ToolCallID] = &ApprovalDecision{Reason: answer}
+		}
+	}
+	return decisions, true
+}
+
+// handleCommand 处理斜杠命令，返回 true 表示退出会话
+func (r *replSession) handleCommand(ctx context.Context, line string) bool {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+
+	switch cmd {
+	case "/exit", "/quit":
+		fmt.Fprintln(r.out, "👋 再见")
+		return true
+
+	case "/help":
+		fmt.Fprintln(r.out, `可用命令:
+  /reset        清空对话历史
+  /sources      查看最近一轮检索到的知识来源
+  /tools        列出可用工具
+  /topk N       设置每轮检索的文档数量 (当前值见 /topk)
+  /units [metric|imperial]  设置天气工具的单位制
+  /save [文件]  导出对话记录，.json 后缀导出为 JSON，否则为 Markdown
+  /pending      列出等待审批的运行 (包括之前会话中暂停的运行)
+  /approve ID   批准运行中所有待审批的工具调用并继续
+  /reject ID [原因]  拒绝运行中所有待审批的工具调用，原因会告知模型
+  /exit         退出`)
+
+	case "/reset":
+		r.history = nil
+		r.lastSources = nil
+		fmt.Fprintln(r.out, "✓ 对话历史已清空")
+
+	case "/sources":
+		if len(r.lastSources) == 0 {
+			fmt.Fprintln(r.out, "暂无检索来源")
+			break
+		}
+		for i, doc := range r.lastSources {
+			fmt.Fprintf(r.out, "[%d] %s\n", i+1, doc.ID)
+			if len(doc.MetaData) > 0 {
+				meta, _ := json.Marshal(doc.MetaData)
+				fmt.Fprintf(r.out, "    元数据: %s\n", meta)
+			}
+			fmt.Fprintf(r.out, "    内容: %s\n", truncateString(strings.ReplaceAll(doc.Content, "\n", " "), 200))
+		}
+
+	case "/tools":
+		for _, t := range r.system.tools {
+			info, err := t.Info(context.Background())
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(r.out, "  • %s - %s\n", info.Name, info.Desc)
+		}
+
+	case "/topk":
+		if len(args) == 0 {
+			fmt.Fprintf(r.out, "当前 TopK: %d\n", r.topK)
+			break
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			fmt.Fprintln(r.out, "❌ 用法: /topk N (N 为正整数)")
+			break
+		}
+		r.topK = n
+		fmt.Fprintf(r.out, "✓ TopK 已设置为 %d\n", n)
+
+	case "/units":
+		if len(args) == 0 {
+			fmt.Fprintf(r.out, "当前单位制: %s\n", cmp.Or(r.units, weather.UnitsMetric))
+			break
+		}
+		if !validUnits(args[0]) {
+			fmt.Fprintf(r.out, "❌ 用法: /units %s|%s\n", weather.UnitsMetric, weather.UnitsImperial)
+			break
+		}
+		r.units = args[0]
+		fmt.Fprintf(r.out, "✓ 单位制已设置为 %s\n", r.units)
+
+	case "/save":
+		path := fmt.Sprintf("transcript_%s.md", time.Now().Format("20060102_150405"))
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if err := r.saveTranscript(path); err != nil {
+			fmt.Fprintf(r.out, "❌ 保存失败: %v\n", err)
+			break
+		}
+		fmt.Fprintf(r.out, "✓ 对话记录已保存到 %s\n", path)
+
+	case "/pending":
+		pending, err := r.system.PendingApprovals()
+		if err != nil {
+			fmt.Fprintf(r.out, "❌ %v\n", err)
+			break
+		}
+		if len(pending) == 0 {
+			fmt.Fprintln(r.out, "暂无等待审批的运行")
+			break
+		}
+		for _, p := range pending {
+			fmt.Fprintf(r.out, "• %s (%s) 问题: %s\n", p.RunID, p.CreatedAt.Format("2006-01-02 15:04:05"), truncateString(p.Query, 60))
+			for _, call := range p.Calls {
+				fmt.Fprintf(r.out, "    🔧 %s %s\n", call.ToolName, truncateString(call.Arguments, 200))
+			}
+			if p.Interrupted {
+				fmt.Fprintln(r.out, "    ⚠️ 工具已经执行，生成回答时中断，使用 /approve 继续")
+			}
+		}
+
+	case "/approve":
+		if len(args) != 1 {
+			fmt.Fprintln(r.out, "❌ 用法: /approve 运行ID")
+			break
+		}
+		r.resume(ctx, args[0], true, "")
+
+	case "/reject":
+		if len(args) == 0 {
+			fmt.Fprintln(r.out, "❌ 用法: /reject 运行ID [原因]")
+			break
+		}
+		r.resume(ctx, args[0], false, strings.Join(args[1:], " "))
+
+	default:
+		fmt.Fprintf(r.out, "❌ 未知命令: %s，输入 /help 查看可用命令\n", cmd)
+	}
+	return false
+}
+
+// saveTranscript 根据文件后缀把对话记录导出为 JSON 或 Markdown
+func (r *replSession) saveTranscript(path string) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		data, err = json.MarshalIndent(r.turns, "", "  ")
+		if err != nil {
+			return err
+		}
+	} else {
+		data = []byte(renderTranscriptMarkdown(r.turns))
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// renderTranscriptMarkdown 把对话记录渲染为 Markdown
+func renderTranscriptMarkdown(turns []*transcriptTurn) string {
+	var sb strings.Builder
+	sb.WriteString("# 对话记录\n\n")
+	sb.WriteString(fmt.Sprintf("导出时间: %s\n", time.Now().Format(time.RFC3339)))
+
+	for i, turn := range turns {
+		sb.WriteString(fmt.Sprintf("\n## 第 %d 轮 (%s)\n\n", i+1, turn.Time.Format("15:04:05")))
+		sb.WriteString(fmt.Sprintf("**用户:** %s\n\n", turn.Query))
+
+		for _, call := range turn.ToolCalls {
+			sb.WriteString(fmt.Sprintf("> 🔧 `%s` `%s`\n>\n> %s\n\n", call.Name, call.Arguments, truncateString(call.Result, 500)))
+		}
+
+		if turn.Error != "" {
+			sb.WriteString(fmt.Sprintf("**错误:** %s\n", turn.Error))
+		} else {
+			sb.WriteString(fmt.Sprintf("**助手:** %s\n", turn.Answer))
+		}
+
+		if len(turn.Sources) > 0 {
+			sb.WriteString("\n参考来源:\n")
+			for _, src := range turn.Sources {
+				sb.WriteString(fmt.Sprintf("- `%s` %s\n", src.ID, truncateString(strings.ReplaceAll(src.Content, "\n", " "), 100)))
+			}
+		}
+	}
+	return sb.String()
+}
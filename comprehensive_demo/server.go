@@ -0,0 +1,655 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"Eini/tools/weather"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"golang.org/x/sync/singleflight"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/server.go
+//  功能: 以 HTTP 服务的形式对外提供 RAG 系统的能力 (go run . -mode server)。
+//  接口:
+//    POST   /v1/chat             对话 (JSON 或 SSE 流式，取决于请求中的 stream 字段)
+//    POST   /v1/retrieve         知识检索
+//    POST   /v1/documents        导入文档
+//    DELETE /v1/documents/{id}   删除文档
+//    GET    /v1/tools            列出可用工具
+//    GET    /v1/tools/metrics    工具调用指标 (调用次数、失败次数、耗时)
+//    GET    /v1/approvals        列出等待审批的运行
+//    GET    /v1/approvals/{id}   查看等待审批的运行
+//    POST   /v1/approvals/{id}   提交审批结果并恢复运行 (JSON 或 SSE 流式)
+//    GET    /healthz             存活检查
+//    GET    /readyz              就绪检查 (检查 Milvus 和模型服务)
+//  说明: Server 只依赖 ComprehensiveRAGSystem，配合 VECTOR_STORE=memory 可以直接用
+//        httptest 对 Handler() 做测试，无需启动 Milvus。
+//
+// =============================================================================
+
+// 就绪检查的缓存时间和超时时间。成功的结果缓存较久，避免频繁请求依赖；
+// 失败的结果只缓存很短的时间，依赖恢复后能尽快重新就绪。
+const (
+	readinessCacheTTL     = 30 * time.Second
+	readinessFailureTTL   = 3 * time.Second
+	readinessCheckTimeout = 5 * time.Second
+)
+
+// maxRequestBodyBytes 请求体的最大字节数 (导入文档时包含文档全文)，超过时返回 413
+const maxRequestBodyBytes = 4 << 20
+
+// defaultModelEndpoint Ark 模型服务的地址，就绪检查只检查它能否连通，不发起对话请求
+const defaultModelEndpoint = "https://ark.cn-beijing.volces.com/api/v3"
+
+// Server RAG 系统的 HTTP 服务
+type Server struct {
+	system         *ComprehensiveRAGSystem
+	requestTimeout time.Duration // 单个请求的超时时间
+	modelEndpoint  string        // 就绪检查使用的模型服务地址，默认 defaultModelEndpoint
+
+	readyGroup   singleflight.Group // 合并同时到达的就绪检查，同一时间只探测一次依赖
+	readyMu      sync.Mutex         // 保护下面的检查结果，探测依赖期间不持有
+	readyChecked time.Time          // 最近一次就绪检查的时间
+	readyResult  map[string]string  // 最近一次就绪检查的结果
+	readyOK      bool
+}
+
+// NewServer 创建 HTTP 服务
+func NewServer(system *ComprehensiveRAGSystem, requestTimeout time.Duration) *Server {
+	return &Server{system: system, requestTimeout: requestTimeout, modelEndpoint: defaultModelEndpoint}
+}
+
+// Handler 返回注册了所有路由的 http.Handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat", s.withTimeout(s.handleChat))
+	mux.HandleFunc("POST /v1/retrieve", s.withTimeout(s.handleRetrieve))
+	mux.HandleFunc("POST /v1/documents", s.withTimeout(s.handleAddDocument))
+	mux.HandleFunc("DELETE /v1/documents/{id}", s.withTimeout(s.handleDeleteDocument))
+	mux.HandleFunc("GET /v1/tools", s.handleListTools)
+	mux.HandleFunc("GET /v1/tools/metrics", s.handleToolMetrics)
+	mux.HandleFunc("GET /v1/approvals", s.handleListApprovals)
+	mux.HandleFunc("GET /v1/approvals/{id}", s.handleGetApproval)
+	mux.HandleFunc("POST /v1/approvals/{id}", s.withTimeout(s.handleResolveApproval))
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	return mux
+}
+
+// withTimeout 为请求设置超时时间
+func (s *Server) withTimeout(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.requestTimeout <= 0 {
+			h(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+		defer cancel()
+		h(w, r.WithContext(ctx))
+	}
+}
+
+// ================================
+// 请求和响应结构
+// ================================
+
+// chatMessage 对话历史中的一条消息
+type chatMessage struct {
+	Role    string `json:"role"` // user 或 assistant
+	Content string `json:"content"`
+}
+
+// chatRequest POST /v1/chat 的请求体
+type chatRequest struct {
+	Query   string         `json:"query"`
+	History []*chatMessage `json:"history,omitempty"`
+	TopK    int            `json:"top_k,omitempty"`
+	Stream  bool           `json:"stream,omitempty"` // true 时以 SSE 返回
+	Units   string         `json:"units,omitempty"`  // 天气工具的单位制: metric (默认) 或 imperial
+}
+
+// toolCallRecord 对话中的一次工具调用
+type toolCallRecord struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+}
+
+// chatResponse POST /v1/chat 和 POST /v1/approvals/{id} 的响应体 (非流式)
+type chatResponse struct {
+	Answer    string            `json:"answer"`
+	ToolCalls []*toolCallRecord `json:"tool_calls,omitempty"`
+	Sources   []*sourceDocument `json:"sources"`
+	Pending   *PendingApproval  `json:"pending,omitempty"` // 运行因等待审批而暂停时返回
+}
+
+// approvalRequest POST /v1/approvals/{id} 的请求体。
+// approved 作用于 decisions 中没有单独给出决定的所有调用。
+type approvalRequest struct {
+	Approved  *bool                        `json:"approved,omitempty"`
+	Reason    string                       `json:"reason,omitempty"`    // 拒绝原因
+	Decisions map[string]*ApprovalDecision `json:"decisions,omitempty"` // 以工具调用 ID 为键的逐个决定
+	Stream    bool                         `json:"stream,omitempty"`    // true 时以 SSE 返回
+}
+
+// sourceDocument 检索到的文档块
+type sourceDocument struct {
+	ID       string         `json:"id"`
+	Content  string         `json:"content"`
+	Score    float64        `json:"score"`
+	MetaData map[string]any `json:"metadata,omitempty"`
+}
+
+// retrieveRequest POST /v1/retrieve 的请求体
+type retrieveRequest struct {
+	Query string `json:"query"`
+	TopK  int    `json:"top_k,omitempty"`
+}
+
+// addDocumentRequest POST /v1/documents 的请求体
+type addDocumentRequest struct {
+	ID       string         `json:"id,omitempty"` // 为空时自动生成
+	Content  string         `json:"content"`
+	MetaData map[string]any `json:"metadata,omitempty"`
+}
+
+// toolDescription GET /v1/tools 返回的工具描述，参数以 JSON Schema 表示
+type toolDescription struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Parameters  *openapi3.Schema `json:"parameters,omitempty"`
+}
+
+// errorResponse 错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ================================
+// 接口实现
+// ================================
+
+// handleChat 处理对话请求
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req chatRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("query 不能为空"))
+		return
+	}
+	if !validUnits(req.Units) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("units 只能是 %s 或 %s", weather.UnitsMetric, weather.UnitsImperial))
+		return
+	}
+
+	history := make([]*schema.Message, 0, len(req.History))
+	for _, m := range req.History {
+		switch m.Role {
+		case string(schema.User):
+			history = append(history, schema.UserMessage(m.Content))
+		case string(schema.Assistant):
+			history = append(history, schema.AssistantMessage(m.Content, nil))
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Errorf("不支持的消息角色: %q", m.Role))
+			return
+		}
+	}
+	chatReq := &ChatRequest{History: history, Query: req.Query, TopK: req.TopK, Units: req.Units}
+
+	s.respondChat(w, r, req.Stream, func(ctx context.Context, onEvent func(*ChatEvent)) (*ChatResult, error) {
+		return s.system.Chat(ctx, chatReq, onEvent)
+	})
+}
+
+// chatRunner 执行 (或恢复) 一次对话运行
+type chatRunner func(ctx context.Context, onEvent func(*ChatEvent)) (*ChatResult, error)
+
+// respondChat 执行对话运行，以 JSON 或 SSE 的形式返回结果
+func (s *Server) respondChat(w http.ResponseWriter, r *http.Request, stream bool, run chatRunner) {
+	if stream {
+		s.streamChat(w, r, run)
+		return
+	}
+
+	calls := make(map[string]*toolCallRecord)
+	var records []*toolCallRecord
+	result, err := run(r.Context(), func(e *ChatEvent) {
+		switch e.Type {
+		case ChatEventToolCall:
+			rec := &toolCallRecord{ID: e.ToolCallID, Name: e.ToolName, Arguments: e.Arguments}
+			calls[e.ToolCallID] = rec
+			records = append(records, rec)
+		case ChatEventToolResult:
+			if rec, ok := calls[e.ToolCallID]; ok {
+				rec.Result = e.Content
+			}
+		}
+	})
+	if err != nil {
+		writeError(w, statusForError(r.Context(), err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &chatResponse{
+		Answer:    result.Answer,
+		ToolCalls: records,
+		Sources:   toSourceDocuments(result.Sources),
+		Pending:   result.Pending,
+	})
+}
+
+// streamChat 以 Server-Sent Events 的形式流式返回对话事件。
+// 每个 ChatEvent 作为一个 SSE 事件发送，最后发送 done 事件 (包含完整回答或待审批信息) 或 error 事件。
+func (s *Server) streamChat(w http.ResponseWriter, r *http.Request, run chatRunner) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("当前连接不支持流式响应"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data any) {
+		payload, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		flusher.Flush()
+	}
+
+	result, err := run(r.Context(), func(e *ChatEvent) {
+		if e.Type == ChatEventSources {
+			send(string(e.Type), toSourceDocuments(e.Sources))
+			return
+		}
+		send(string(e.Type), e)
+	})
+	if err != nil {
+		send("error", &errorResponse{Error: err.Error()})
+		return
+	}
+	done := map[string]any{"answer": result.Answer}
+	if result.Pending != nil {
+		done["pending"] = result.Pending
+	}
+	send("done", done)
+}
+
+// handleListApprovals 列出等待审批的运行
+func (s *Server) handleListApprovals(w http.ResponseWriter, r *http.Request) {
+	pending, err := s.system.PendingApprovals()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"approvals": pending})
+}
+
+// handleGetApproval 查看等待审批的运行
+func (s *Server) handleGetApproval(w http.ResponseWriter, r *http.Request) {
+	pending, err := s.system.PendingApproval(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusForError(r.Context(), err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// handleResolveApproval 提交审批结果并恢复运行
+func (s *Server) handleResolveApproval(w http.ResponseWriter, r *http.Request) {
+	var req approvalRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	runID := r.PathValue("id")
+	pending, err := s.system.PendingApproval(runID)
+	if err != nil {
+		writeError(w, statusForError(r.Context(), err), err)
+		return
+	}
+
+	// 没有单独给出决定的调用使用 approved / reason
+	decisions := make(map[string]*ApprovalDecision, len(pending.Calls))
+	for id, d := range req.Decisions {
+		decisions[id] = d
+	}
+	if req.Approved != nil {
+		for _, call := range pending.Calls {
+			if _, ok := decisions[call.ToolCallID]; !ok {
+				decisions[call.ToolCallID] = &ApprovalDecision{Approved: *req.Approved, Reason: req.Reason}
+			}
+		}
+	}
+
+	s.respondChat(w, r, req.Stream, func(ctx context.Context, onEvent func(*ChatEvent)) (*ChatResult, error) {
+		return s.system.ResumeChat(ctx, runID, decisions, onEvent)
+	})
+}
+
+// handleRetrieve 处理检索请求
+func (s *Server) handleRetrieve(w http.ResponseWriter, r *http.Request) {
+	var req retrieveRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("query 不能为空"))
+		return
+	}
+
+	docs, err := s.system.Retrieve(r.Context(), req.Query, req.TopK)
+	if err != nil {
+		writeError(w, statusForError(r.Context(), err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"query":     req.Query,
+		"documents": toSourceDocuments(docs),
+	})
+}
+
+// handleAddDocument 处理文档导入请求
+func (s *Server) handleAddDocument(w http.ResponseWriter, r *http.Request) {
+	var req addDocumentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, errors.New("content 不能为空"))
+		return
+	}
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("doc_%d", time.Now().UnixNano())
+	}
+
+	ids, err := s.system.AddDocument(r.Context(), &schema.Document{ID: req.ID, Content: req.Content, MetaData: req.MetaData})
+	if err != nil {
+		writeError(w, statusForError(r.Context(), err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":           req.ID,
+		"chunks_count": len(ids),
+		"stored_ids":   ids,
+	})
+}
+
+// handleDeleteDocument 处理文档删除请求
+func (s *Server) handleDeleteDocument(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deleted, err := s.system.DeleteDocument(r.Context(), id)
+	if err != nil {
+		writeError(w, statusForError(r.Context(), err), err)
+		return
+	}
+	if deleted == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("文档 %s 不存在", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "deleted_chunks": deleted})
+}
+
+// handleListTools 列出可用工具
+func (s *Server) handleListTools(w http.ResponseWriter, r *http.Request) {
+	tools := make([]*toolDescription, 0, len(s.system.tools))
+	for _, t := range s.system.tools {
+		info, err := t.Info(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		desc := &toolDescription{Name: info.Name, Description: info.Desc}
+		if info.ParamsOneOf != nil {
+			if desc.Parameters, err = info.ParamsOneOf.ToOpenAPIV3(); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		tools = append(tools, desc)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tools": tools})
+}
+
+// toolMetric GET /v1/tools/metrics 返回的单个工具的调用指标，耗时以毫秒表示
+type toolMetric struct {
+	Name         string    `json:"name"`
+	Calls        int64     `json:"calls"`
+	Errors       int64     `json:"errors"`
+	Timeouts     int64     `json:"timeouts"`
+	InFlight     int64     `json:"in_flight"`
+	AvgLatencyMS int64     `json:"avg_latency_ms"`
+	MaxLatencyMS int64     `json:"max_latency_ms"`
+	LastError    string    `json:"last_error,omitempty"`
+	LastCalledAt time.Time `json:"last_called_at"`
+}
+
+// handleToolMetrics 返回 Agent 模式下工具调用的指标
+func (s *Server) handleToolMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics := make([]*toolMetric, 0)
+	if s.system.toolMetrics != nil {
+		for _, st := range s.system.toolMetrics.Snapshot() {
+			metrics = append(metrics, &toolMetric{
+				Name:         st.Name,
+				Calls:        st.Calls,
+				Errors:       st.Errors,
+				Timeouts:     st.Timeouts,
+				InFlight:     st.InFlight,
+				AvgLatencyMS: st.AvgLatency().Milliseconds(),
+				MaxLatencyMS: st.MaxLatency.Milliseconds(),
+				LastError:    st.LastError,
+				LastCalledAt: st.LastCalledAt,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tools": metrics})
+}
+
+// handleHealth 存活检查: 进程能够响应即视为存活
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady 就绪检查: Milvus (如果使用) 和模型服务都可用时才视为就绪。
+// 检查使用独立的超时时间，不受探测请求本身超时或断开的影响；
+// 成功的结果缓存 readinessCacheTTL，失败的结果缓存 readinessFailureTTL。
+// 缓存过期时同时到达的请求共用一次检查，检查期间不持有锁，其他请求不会被网络请求阻塞在锁上。
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	s.readyMu.Lock()
+	checks, ok, checked := s.readyResult, s.readyOK, s.readyChecked
+	s.readyMu.Unlock()
+
+	ttl := readinessCacheTTL
+	if !ok {
+		ttl = readinessFailureTTL
+	}
+	if time.Since(checked) > ttl {
+		type readiness struct {
+			checks map[string]string
+			ok     bool
+		}
+		v, _, _ := s.readyGroup.Do("ready", func() (any, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), readinessCheckTimeout)
+			defer cancel()
+			checks, ok := s.checkDependencies(ctx)
+
+			s.readyMu.Lock()
+			s.readyResult, s.readyOK, s.readyChecked = checks, ok, time.Now()
+			s.readyMu.Unlock()
+			return readiness{checks, ok}, nil
+		})
+		checks, ok = v.(readiness).checks, v.(readiness).ok
+	}
+
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, map[string]any{"ready": ok, "checks": checks})
+}
+
+// checkDependencies 检查各个依赖的状态
+func (s *Server) checkDependencies(ctx context.Context) (map[string]string, bool) {
+	checks := make(map[string]string)
+	ok := true
+
+	if client := s.system.milvusClient; client != nil {
+		state, err := client.CheckHealth(ctx)
+		switch {
+		case err != nil:
+			checks["milvus"], ok = err.Error(), false
+		case !state.IsHealthy:
+			checks["milvus"], ok = fmt.Sprintf("unhealthy: %v", state.Reasons), false
+		default:
+			checks["milvus"] = "ok"
+		}
+	} else {
+		checks["vector_store"] = s.system.config.VectorStore
+	}
+
+	if err := s.checkModel(ctx); err != nil {
+		checks["model"], ok = err.Error(), false
+	} else {
+		checks["model"] = "ok"
+	}
+	return checks, ok
+}
+
+// checkModel 检查模型配置和模型服务能否连通。只请求模型列表接口，不产生对话费用；
+// 服务返回 401 / 403 说明 API Key 无效，5xx 说明服务不可用，其他响应都说明服务可以连通。
+func (s *Server) checkModel(ctx context.Context) error {
+	config := s.system.config
+	if config.ArkAPIKey == "" || config.ArkModel == "" {
+		return errors.New("未配置 ARK_API_KEY 或 ARK_MODEL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(s.modelEndpoint, "/")+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+config.ArkAPIKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("连接模型服务失败: %w", err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("API Key 无效: %s", resp.Status)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("模型服务不可用: %s", resp.Status)
+	}
+	return nil
+}
+
+// ================================
+// 辅助函数
+// ================================
+
+// decodeJSON 解析请求体，拒绝未知字段。失败时写入错误响应 (请求体超过 maxRequestBodyBytes 时为 413，其他为 400) 并返回 false
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("请求体超过 %d 字节", tooLarge.Limit))
+	} else {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("请求体解析失败: %v", err))
+	}
+	return false
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[Server] 写入响应失败: %v", err)
+	}
+}
+
+// writeError 写入错误响应
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
+
+// statusForError 根据错误类型选择状态码: 请求超时返回 504，其他返回 500
+func statusForError(ctx context.Context, err error) int {
+	switch {
+	case errors.Is(err, ErrRunNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidDecisions):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrRunInProgress):
+		return http.StatusConflict
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// toSourceDocuments 把检索结果转换为响应结构
+func toSourceDocuments(docs []*schema.Document) []*sourceDocument {
+	ret := make([]*sourceDocument, 0, len(docs))
+	for _, doc := range docs {
+		ret = append(ret, &sourceDocument{ID: doc.ID, Content: doc.Content, Score: doc.Score(), MetaData: doc.MetaData})
+	}
+	return ret
+}
+
+// runServer 启动 HTTP 服务，收到 SIGINT / SIGTERM 时优雅退出
+func runServer(ctx context.Context, system *ComprehensiveRAGSystem, addr string, requestTimeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           NewServer(system, requestTimeout).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("🌐 HTTP 服务已启动: http://%s", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	// 停止接收新请求，并等待进行中的请求完成
+	log.Println("⏳ 正在关闭 HTTP 服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), requestTimeout+5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("关闭 HTTP 服务失败: %v", err)
+	}
+	log.Println("✓ HTTP 服务已关闭")
+	return nil
+}
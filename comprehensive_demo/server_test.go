@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// newTestServer 创建使用内存存储、固定回答模型的服务，知识库中预先导入文档 faq。
+// modelEndpoint 为就绪检查使用的模型服务地址。
+func newTestServer(t *testing.T, modelEndpoint string) *Server {
+	t.Helper()
+	echo := &countingTool{name: "echo"}
+	system := newApprovalSystem(t, echo)
+	system.config = &Config{VectorStore: vectorStoreMemory, ArkAPIKey: "test-key", ArkModel: "test-model"}
+	system.tools = []tool.BaseTool{echo}
+	system.initMemoryStore()
+	if err := system.initTransformer(context.Background()); err != nil {
+		t.Fatalf("初始化 Transformer 失败: %v", err)
+	}
+	if _, err := system.AddDocument(context.Background(), &schema.Document{ID: "faq", Content: "## 退货\n7 天无理由退货"}); err != nil {
+		t.Fatalf("导入文档失败: %v", err)
+	}
+
+	server := NewServer(system, time.Minute)
+	server.modelEndpoint = modelEndpoint
+	return server
+}
+
+func TestServerRoutes(t *testing.T) {
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer model.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		wantStatus  int
+		wantType    string   // 期望的 Content-Type 前缀，为空时不检查
+		wantContent []string // 期望响应中包含的文本
+	}{
+		{name: "存活检查", method: "GET", path: "/healthz", wantStatus: http.StatusOK, wantContent: []string{`"ok"`}},
+		{name: "就绪检查", method: "GET", path: "/readyz", wantStatus: http.StatusOK, wantContent: []string{`"ready":true`}},
+		{name: "对话", method: "POST", path: "/v1/chat", body: `{"query": "可以退货吗？"}`, wantStatus: http.StatusOK, wantType: "application/json", wantContent: []string{`"answer":"已完成"`, `"faq_0000"`}},
+		{name: "流式对话", method: "POST", path: "/v1/chat", body: `{"query": "可以退货吗？", "stream": true}`, wantStatus: http.StatusOK, wantType: "text/event-stream",
+			wantContent: []string{"event: sources\n", "event: token\n", "event: done\ndata: {\"answer\":\"已完成\"}\n\n"}},
+		{name: "对话缺少 query", method: "POST", path: "/v1/chat", body: `{}`, wantStatus: http.StatusBadRequest, wantContent: []string{"query 不能为空"}},
+		{name: "对话单位制无效", method: "POST", path: "/v1/chat", body: `{"query": "天气", "units": "kelvin"}`, wantStatus: http.StatusBadRequest},
+		{name: "对话历史角色无效", method: "POST", path: "/v1/chat", body: `{"query": "你好", "history": [{"role": "system", "content": "x"}]}`, wantStatus: http.StatusBadRequest, wantContent: []string{"不支持的消息角色"}},
+		{name: "请求体不是 JSON", method: "POST", path: "/v1/chat", body: `{"query": `, wantStatus: http.StatusBadRequest, wantContent: []string{"请求体解析失败"}},
+		{name: "请求体包含未知字段", method: "POST", path: "/v1/retrieve", body: `{"query": "退货", "limit": 3}`, wantStatus: http.StatusBadRequest, wantContent: []string{"请求体解析失败"}},
+		{name: "请求体过大", method: "POST", path: "/v1/documents", body: `{"content": "` + strings.Repeat("x", maxRequestBodyBytes) + `"}`, wantStatus: http.StatusRequestEntityTooLarge, wantContent: []string{"请求体超过"}},
+		{name: "检索", method: "POST", path: "/v1/retrieve", body: `{"query": "退货", "top_k": 1}`, wantStatus: http.StatusOK, wantContent: []string{`"faq_0000"`}},
+		{name: "导入文档", method: "POST", path: "/v1/documents", body: `{"id": "shipping", "content": "## 发货\n48 小时内发货"}`, wantStatus: http.StatusCreated, wantContent: []string{`"chunks_count":1`}},
+		{name: "导入空文档", method: "POST", path: "/v1/documents", body: `{"content": ""}`, wantStatus: http.StatusBadRequest, wantContent: []string{"content 不能为空"}},
+		{name: "删除文档", method: "DELETE", path: "/v1/documents/faq", wantStatus: http.StatusOK, wantContent: []string{`"deleted_chunks":1`}},
+		{name: "删除不存在的文档", method: "DELETE", path: "/v1/documents/missing", wantStatus: http.StatusNotFound},
+		{name: "列出工具", method: "GET", path: "/v1/tools", wantStatus: http.StatusOK, wantContent: []string{`"name":"echo"`}},
+		{name: "工具指标", method: "GET", path: "/v1/tools/metrics", wantStatus: http.StatusOK, wantContent: []string{`"tools":[]`}},
+		{name: "列出待审批的运行", method: "GET", path: "/v1/approvals", wantStatus: http.StatusOK, wantContent: []string{`"approvals":[]`}},
+		{name: "待审批的运行不存在", method: "GET", path: "/v1/approvals/run_0000000000000000", wantStatus: http.StatusNotFound},
+		{name: "提交审批时运行不存在", method: "POST", path: "/v1/approvals/run_0000000000000000", body: `{"approved": true}`, wantStatus: http.StatusNotFound},
+		{name: "方法不允许", method: "GET", path: "/v1/chat", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestServer(t, model.URL).Handler()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			body := rec.Body.String()
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("状态码 = %d，期望 %d，响应: %.200s", rec.Code, tt.wantStatus, body)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("Content-Type = %q，期望以 %q 开头", got, tt.wantType)
+			}
+			for _, want := range tt.wantContent {
+				if !strings.Contains(body, want) {
+					t.Errorf("响应中没有 %q: %.500s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServerReadinessProbesOnce(t *testing.T) {
+	var probes atomic.Int32
+	release := make(chan struct{})
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer model.Close()
+	handler := newTestServer(t, model.URL).Handler()
+
+	// 缓存过期时同时到达的请求共用一次检查
+	const concurrent = 5
+	codes := make([]int, concurrent)
+	var wg sync.WaitGroup
+	for i := range concurrent {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+			codes[i] = rec.Code
+		}()
+	}
+	for probes.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond) // 让其他请求都进入等待
+	close(release)
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("第 %d 个请求的状态码 = %d，期望 %d", i, code, http.StatusServiceUnavailable)
+		}
+	}
+	if n := probes.Load(); n != 1 {
+		t.Errorf("探测了 %d 次模型服务，期望 1 次", n)
+	}
+
+	// 失败的结果在 readinessFailureTTL 内直接使用缓存
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || probes.Load() != 1 {
+		t.Errorf("缓存期内状态码 = %d、探测 %d 次，期望 %d、1 次", rec.Code, probes.Load(), http.StatusServiceUnavailable)
+	}
+}
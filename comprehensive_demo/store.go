@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/indexer"
+	einoretriever "github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	cli "github.com/milvus-io/milvus-sdk-go/v2/client"
+)
+
+// =============================================================================
+//
+//  文件: comprehensive_demo/store.go
+//  功能: 向量存储相关的实现与文档管理操作。
+//  1. memoryStore   - 内存存储，同时实现 Indexer 和 Retriever，不依赖 Milvus 和 Embedding，
+//                     适合本地调试以及配合 httptest 做接口测试 (VECTOR_STORE=memory)
+//  2. milvusDocuments - 按文档 ID 删除、列出 Milvus 中的文档块
+//  3. AddDocument / DeleteDocument / ListDocuments / GetDocument / Retrieve
+//     - 供 HTTP 服务、MCP 服务等上层调用的文档操作
+//
+// =============================================================================
+
+// 支持的向量存储类型
+const (
+	vectorStoreMilvus = "milvus"
+	vectorStoreMemory = "memory"
+)
+
+// documentManager 管理向量存储中的文档块
+type documentManager interface {
+	// DeleteDocument 按文档 ID 删除该文档的所有文档块
+	DeleteDocument(ctx context.Context, docID string) (int, error)
+	// ListChunks 列出存储中的所有文档块，不包含向量
+	ListChunks(ctx context.Context) ([]*schema.Document, error)
+}
+
+// maxListChunks 列出文档块时的数量上限 (Milvus 单次查询的上限)
+const maxListChunks = 16384
+
+// ================================
+// 内存存储
+// ================================
+
+// memoryStore 基于内存的文档存储。
+// 检索时按查询与文档内容的字符二元组 (bigram) 重合度打分，对中英文都能给出可用的排序结果。
+type memoryStore struct {
+	mu   sync.RWMutex
+	docs map[string]*schema.Document // 文档块 ID -> 文档块
+	topK int                         // 默认返回数量
+}
+
+// newMemoryStore 创建内存存储
+func newMemoryStore(topK int) *memoryStore {
+	return &memoryStore{docs: make(map[string]*schema.Document), topK: topK}
+}
+
+// Store 保存文档块，ID 相同的文档块会被覆盖
+func (m *memoryStore) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return nil, fmt.Errorf("文档块缺少 ID")
+		}
+		m.docs[doc.ID] = doc
+		ids = append(ids, doc.ID)
+	}
+	return ids, nil
+}
+
+// Retrieve 返回与查询最相关的文档块
+func (m *memoryStore) Retrieve(ctx context.Context, query string, opts ...einoretriever.Option) ([]*schema.Document, error) {
+	topK := m.topK
+	options := einoretriever.GetCommonOptions(&einoretriever.Options{TopK: &topK}, opts...)
+
+	queryGrams := bigrams(query)
+	m.mu.RLock()
+	results := make([]*schema.Document, 0, len(m.docs))
+	for _, doc := range m.docs {
+		score := bigramOverlap(queryGrams, bigrams(doc.Content))
+		if score <= 0 {
+			continue
+		}
+		if options.ScoreThreshold != nil && score < *options.ScoreThreshold {
+			continue
+		}
+		// 返回副本，避免调用方修改存储中的文档
+		results = append(results, (&schema.Document{
+			ID:       doc.ID,
+			Content:  doc.Content,
+			MetaData: copyMetaData(doc.MetaData),
+		}).WithScore(score))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score() != results[j].Score() {
+			return results[i].Score() > results[j].Score()
+		}
+		return results[i].ID < results[j].ID
+	})
+	if options.TopK != nil && *options.TopK > 0 && len(results) > *options.TopK {
+		results = results[:*options.TopK]
+	}
+	return results, nil
+}
+
+// DeleteDocument 删除 ID 或元数据 doc_id 等于 docID 的文档块
+func (m *memoryStore) DeleteDocument(ctx context.Context, docID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for id, doc := range m.docs {
+		if id == docID || doc.MetaData["doc_id"] == docID {
+			delete(m.docs, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ListChunks 列出所有文档块的副本
+func (m *memoryStore) ListChunks(ctx context.Context) ([]*schema.Document, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chunks := make([]*schema.Document, 0, len(m.docs))
+	for _, doc := range m.docs {
+		chunks = append(chunks, &schema.Document{
+			ID:       doc.ID,
+			Content:  doc.Content,
+			MetaData: copyMetaData(doc.MetaData),
+		})
+	}
+	return chunks, nil
+}
+
+// bigrams 把文本切分为小写的字符二元组集合
+func bigrams(text string) map[string]struct{} {
+	runes := []rune(strings.ToLower(text))
+	grams := make(map[string]struct{})
+	for i := 0; i+1 < len(runes); i++ {
+		if isSpaceOrPunct(runes[i]) || isSpaceOrPunct(runes[i+1]) {
+			continue
+		}
+		grams[string(runes[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+// bigramOverlap 计算查询二元组在文档中出现的比例，取值范围 [0, 1]
+func bigramOverlap(query, doc map[string]struct{}) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+	hit := 0
+	for g := range query {
+		if _, ok := doc[g]; ok {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(query))
+}
+
+// isSpaceOrPunct 判断字符是否为空白或常见标点
+func isSpaceOrPunct(r rune) bool {
+	return r <= ' ' || strings.ContainsRune("，。？！、；：“”‘’（）《》,.?!;:'\"()[]{}<>#*-_`", r)
+}
+
+// copyMetaData 浅拷贝元数据
+func copyMetaData(meta map[string]any) map[string]any {
+	if meta == nil {
+		return nil
+	}
+	ret := make(map[string]any, len(meta))
+	for k, v := range meta {
+		ret[k] = v
+	}
+	return ret
+}
+
+// ================================
+// Milvus 文档管理
+// ================================
+
+// milvusDocuments 删除、列出 Milvus 集合中的文档块
+type milvusDocuments struct {
+	client     cli.Client
+	collection string
+}
+
+// DeleteDocument 先查询出文档的所有文档块主键，再按主键删除
+func (d *milvusDocuments) DeleteDocument(ctx context.Context, docID string) (int, error) {
+	quoted := strconv.Quote(docID)
+	expr := fmt.Sprintf(`id == %s or metadata["doc_id"] == %s`, quoted, quoted)
+
+	rs, err := d.client.Query(ctx, d.collection, nil, expr, []string{"id"})
+	if err != nil {
+		return 0, fmt.Errorf("查询文档块失败: %v", err)
+	}
+	column := rs.GetColumn("id")
+	if column == nil || column.Len() == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, column.Len())
+	for i := 0; i < column.Len(); i++ {
+		id, err := column.GetAsString(i)
+		if err != nil {
+			return 0, fmt.Errorf("读取文档块 ID 失败: %v", err)
+		}
+		ids = append(ids, strconv.Quote(id))
+	}
+
+	if err := d.client.Delete(ctx, d.collection, "", fmt.Sprintf("id in [%s]", strings.Join(ids, ","))); err != nil {
+		return 0, fmt.Errorf("删除文档块失败: %v", err)
+	}
+	return len(ids), nil
+}
+
+// ListChunks 查询集合中的文档块，最多返回 maxListChunks 个
+func (d *milvusDocuments) ListChunks(ctx context.Context) ([]*schema.Document, error) {
+	rs, err := d.client.Query(ctx, d.collection, nil, `id != ""`,
+		[]string{"id", "content", "metadata"}, cli.WithLimit(maxListChunks))
+	if err != nil {
+		return nil, fmt.Errorf("查询文档块失败: %v", err)
+	}
+	idColumn, contentColumn, metaColumn := rs.GetColumn("id"), rs.GetColumn("content"), rs.GetColumn("metadata")
+	if idColumn == nil || contentColumn == nil {
+		return nil, nil
+	}
+
+	chunks := make([]*schema.Document, 0, idColumn.Len())
+	for i := 0; i < idColumn.Len(); i++ {
+		id, err := idColumn.GetAsString(i)
+		if err != nil {
+			return nil, fmt.Errorf("读取文档块 ID 失败: %v", err)
+		}
+		content, err := contentColumn.GetAsString(i)
+		if err != nil {
+			return nil, fmt.Errorf("读取文档块内容失败: %v", err)
+		}
+		doc := &schema.Document{ID: id, Content: content}
+		if metaColumn != nil {
+			if raw, err := metaColumn.GetAsString(i); err == nil && raw != "" {
+				_ = json.Unmarshal([]byte(raw), &doc.MetaData)
+			}
+		}
+		chunks = append(chunks, doc)
+	}
+	return chunks, nil
+}
+
+// ================================
+// 文档操作
+// ================================
+
+// AddDocument 分割文档并写入向量存储，返回写入的文档块 ID
+func (s *ComprehensiveRAGSystem) AddDocument(ctx context.Context, doc *schema.Document) ([]string, error) {
+	chunks, err := s.splitDocument(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.indexer.Store(ctx, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("文档索引失败: %v", err)
+	}
+	log.Printf("[AddDocument] 文档 %s 分割为 %d 块并完成索引", doc.ID, len(chunks))
+	return ids, nil
+}
+
+// splitDocument 分割文档并为文档块编号。
+// 文档块 ID 形如 <文档ID>_0000，元数据中的 doc_id 用于之后按文档删除、列出。
+func (s *ComprehensiveRAGSystem) splitDocument(ctx context.Context, doc *schema.Document) ([]*schema.Document, error) {
+	if doc.MetaData == nil {
+		doc.MetaData = make(map[string]any)
+	}
+	doc.MetaData["doc_id"] = doc.ID
+	doc.MetaData["processed_at"] = time.Now().Format(time.RFC3339)
+
+	chunks, err := s.transformer.Transform(ctx, []*schema.Document{doc})
+	if err != nil {
+		return nil, fmt.Errorf("文档分割失败: %v", err)
+	}
+	for i, chunk := range chunks {
+		chunk.ID = fmt.Sprintf("%s_%04d", doc.ID, i)
+		if chunk.MetaData == nil {
+			chunk.MetaData = make(map[string]any)
+		}
+		chunk.MetaData["doc_id"] = doc.ID
+		chunk.MetaData["chunk_index"] = i
+	}
+	return chunks, nil
+}
+
+// DeleteDocument 删除文档的所有文档块，返回删除的数量
+func (s *ComprehensiveRAGSystem) DeleteDocument(ctx context.Context, docID string) (int, error) {
+	if s.documents == nil {
+		return 0, fmt.Errorf("当前向量存储不支持删除")
+	}
+	return s.documents.DeleteDocument(ctx, docID)
+}
+
+// documentInfo 文档概要
+type documentInfo struct {
+	ID       string         `json:"id"`
+	Chunks   int            `json:"chunks"`
+	MetaData map[string]any `json:"metadata,omitempty"`
+}
+
+// ListDocuments 按元数据 doc_id 聚合文档块，返回按 ID 排序的文档列表。
+// 没有 doc_id 的文档块单独作为一个文档。
+func (s *ComprehensiveRAGSystem) ListDocuments(ctx context.Context) ([]*documentInfo, error) {
+	if s.documents == nil {
+		return nil, fmt.Errorf("当前向量存储不支持列出文档")
+	}
+	chunks, err := s.documents.ListChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*documentInfo)
+	for _, chunk := range chunks {
+		docID := chunkDocID(chunk)
+		info, ok := byID[docID]
+		if !ok {
+			info = &documentInfo{ID: docID, MetaData: copyMetaData(chunk.MetaData)}
+			// 文档级元数据中不保留文档块自身的字段
+			delete(info.MetaData, "chunk_index")
+			byID[docID] = info
+		}
+		info.Chunks++
+	}
+
+	docs := make([]*documentInfo, 0, len(byID))
+	for _, info := range byID {
+		docs = append(docs, info)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs, nil
+}
+
+// GetDocument 返回文档的所有文档块，按文档块 ID (即分割顺序) 排序；文档不存在时返回空切片
+func (s *ComprehensiveRAGSystem) GetDocument(ctx context.Context, docID string) ([]*schema.Document, error) {
+	if s.documents == nil {
+		return nil, fmt.Errorf("当前向量存储不支持读取文档")
+	}
+	chunks, err := s.documents.ListChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*schema.Document
+	for _, chunk := range chunks {
+		if chunkDocID(chunk) == docID {
+			ret = append(ret, chunk)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret, nil
+}
+
+// chunkDocID 返回文档块所属的文档 ID
+func chunkDocID(chunk *schema.Document) string {
+	if id, ok := chunk.MetaData["doc_id"].(string); ok && id != "" {
+		return id
+	}
+	return chunk.ID
+}
+
+// Retrieve 检索与查询相关的文档块，topK <= 0 时使用检索器默认值
+func (s *ComprehensiveRAGSystem) Retrieve(ctx context.Context, query string, topK int) ([]*schema.Document, error) {
+	var opts []einoretriever.Option
+	if topK > 0 {
+		opts = append(opts, einoretriever.WithTopK(topK))
+	}
+	return s.retriever.Retrieve(ctx, query, opts...)
+}
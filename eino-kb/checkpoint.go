@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: eino-kb/checkpoint.go
+//  功能: 导入进度的检查点，保证 ingest 被中断后可以从断点继续。
+//  说明: 检查点以 JSON 文件形式保存，每完成一个批次就原子地写回磁盘。
+//        文件内容 (sha256) 未变化且已完成的文件会被跳过；
+//        只完成了一部分的文件会从下一个未索引的文档块继续。
+//
+// =============================================================================
+
+// 检查点中文件的状态
+const (
+	fileStatusPartial = "partial" // 部分文档块已写入向量库
+	fileStatusDone    = "done"    // 全部文档块已写入向量库
+)
+
+// checkpointEntry 记录单个文件的导入进度
+type checkpointEntry struct {
+	SHA256     string    `json:"sha256"`      // 文件内容摘要，用于判断文件是否被修改
+	DocID      string    `json:"doc_id"`      // 文件对应的文档 ID
+	Status     string    `json:"status"`      // partial / done
+	Chunks     int       `json:"chunks"`      // 文件切分出的文档块总数
+	DoneChunks int       `json:"done_chunks"` // 已写入向量库的文档块数量
+	StoredIDs  []string  `json:"stored_ids"`  // 已写入的文档块 ID
+	UpdatedAt  time.Time `json:"updated_at"`  // 最近一次更新时间
+}
+
+// checkpoint 是整个导入任务的检查点
+type checkpoint struct {
+	path string // 检查点文件路径，为空表示不持久化
+
+	Collection string                      `json:"collection"` // 写入的集合名称
+	Files      map[string]*checkpointEntry `json:"files"`      // 绝对路径 -> 导入进度
+}
+
+// loadCheckpoint 读取检查点文件，文件不存在时返回一个空检查点
+func loadCheckpoint(path, collection string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, Collection: collection, Files: make(map[string]*checkpointEntry)}
+	if path == "" {
+		return cp, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %w", err)
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("解析检查点 %s 失败: %w", path, err)
+	}
+	if cp.Files == nil {
+		cp.Files = make(map[string]*checkpointEntry)
+	}
+
+	// 检查点属于其他集合时不能复用，否则会漏掉数据
+	if cp.Collection != collection {
+		return nil, fmt.Errorf("检查点 %s 属于集合 %q，与当前集合 %q 不一致，请使用 -force 或指定新的 -checkpoint", path, cp.Collection, collection)
+	}
+	return cp, nil
+}
+
+// lookup 返回文件对应的进度；文件内容变化时视为没有进度
+func (c *checkpoint) lookup(absPath, sha string) *checkpointEntry {
+	entry, ok := c.Files[absPath]
+	if !ok || entry.SHA256 != sha {
+		return nil
+	}
+	return entry
+}
+
+// record 更新文件的进度并立即保存
+func (c *checkpoint) record(absPath string, entry *checkpointEntry) error {
+	entry.UpdatedAt = time.Now()
+	c.Files[absPath] = entry
+	return c.save()
+}
+
+// save 以“写临时文件 + 重命名”的方式原子地保存检查点，避免中断时留下半个文件
+func (c *checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化检查点失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".eino-kb-checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("创建临时检查点失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入检查点失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入检查点失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("保存检查点失败: %w", err)
+	}
+	return nil
+}
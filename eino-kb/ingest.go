@@ -0,0 +1,524 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/markdown"
+	embedder "github.com/cloudwego/eino-ext/components/embedding/ark"
+	"github.com/cloudwego/eino-ext/components/indexer/milvus"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	cli "github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// =============================================================================
+//
+//  文件: eino-kb/ingest.go
+//  功能: 实现 "eino-kb ingest" 子命令。
+//  流程: load (fileLoader) → clean (textCleaner) → split (HeaderSplitter)
+//        → 大小限制 (chunkSizeLimiter) → embed + index (Milvus Indexer)
+//  特性: 进度输出、dry-run、逐文件汇总、基于检查点的断点续传。
+//
+// =============================================================================
+
+// 单个文件的导入结果状态
+const (
+	statusIndexed     = "indexed"     // 本次全部写入
+	statusResumed     = "resumed"     // 从检查点继续并完成写入
+	statusSkipped     = "skipped"     // 检查点显示已完成，跳过
+	statusDryRun      = "dry-run"     // 仅预览，未写入
+	statusFailed      = "failed"      // 处理失败
+	statusInterrupted = "interrupted" // 被中断，进度已保存
+)
+
+// ingestOptions 是 ingest 子命令的参数
+type ingestOptions struct {
+	dryRun         bool     // 只执行 load/clean/split，不连接向量库
+	checkpointPath string   // 检查点文件路径
+	force          bool     // 忽略检查点中的进度，重新导入全部文件
+	extensions     []string // 目录遍历时导入的文件扩展名
+	batchSize      int      // 每次写入向量库的文档块数量
+	verbose        bool     // 打印每个文档块的预览
+}
+
+// fileResult 记录单个文件的导入结果，用于最终汇总
+type fileResult struct {
+	path     string
+	status   string
+	chunks   int
+	stored   int
+	bytes    int
+	duration time.Duration
+	err      error
+}
+
+// ingestPipeline 组合了导入流程中用到的各个组件
+type ingestPipeline struct {
+	loader     document.Loader
+	cleaner    document.Transformer
+	splitter   document.Transformer
+	limiter    document.Transformer
+	indexer    *milvus.Indexer // dry-run 模式下为 nil
+	client     cli.Client      // dry-run 模式下为 nil
+	collection string
+}
+
+// runIngest 解析参数并执行导入
+func runIngest(ctx context.Context, args []string) error {
+	opts := &ingestOptions{}
+	var exts string
+
+	fset := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	fset.BoolVar(&opts.dryRun, "dry-run", false, "只执行加载、清洗和切分，不写入向量库")
+	fset.StringVar(&opts.checkpointPath, "checkpoint", ".eino-kb-checkpoint.json", "检查点文件路径，为空则不记录进度")
+	fset.BoolVar(&opts.force, "force", false, "忽略检查点中的进度，重新导入所有文件")
+	fset.StringVar(&exts, "ext", ".md,.markdown,.txt", "遍历目录时导入的文件扩展名，逗号分隔")
+	fset.IntVar(&opts.batchSize, "batch", 10, "每次写入向量库的文档块数量")
+	fset.BoolVar(&opts.verbose, "v", false, "打印每个文档块的预览")
+	fset.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: eino-kb ingest [参数] <文件|目录|glob>...")
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return errors.New("至少需要指定一个文件、目录或 glob")
+	}
+	if opts.batchSize <= 0 {
+		return errors.New("-batch 必须大于 0")
+	}
+	for _, ext := range strings.Split(exts, ",") {
+		if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			opts.extensions = append(opts.extensions, ext)
+		}
+	}
+
+	// 1. 收集待导入的文件
+	files, err := discoverFiles(fset.Args(), opts.extensions)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("没有找到可导入的文件")
+	}
+	fmt.Printf("共发现 %d 个待导入文件\n", len(files))
+
+	// 2. 初始化流水线组件
+	config := loadConfig()
+	pipeline, milvusClient, err := newIngestPipeline(ctx, config, opts.dryRun)
+	if err != nil {
+		return err
+	}
+	if milvusClient != nil {
+		defer milvusClient.Close()
+	}
+
+	// 3. 加载检查点（dry-run 不读写检查点）
+	cpPath := opts.checkpointPath
+	if opts.dryRun {
+		cpPath = ""
+	}
+	cp, err := loadCheckpoint(cpPath, config.MilvusCollection)
+	if err != nil {
+		if !opts.force {
+			return err
+		}
+		// -force 时丢弃无法复用的检查点，从头开始
+		cp, _ = loadCheckpoint("", config.MilvusCollection)
+		cp.path = cpPath
+	}
+
+	// 4. 逐个文件导入
+	results := make([]*fileResult, 0, len(files))
+	for i, path := range files {
+		if ctx.Err() != nil {
+			results = append(results, &fileResult{path: path, status: statusInterrupted})
+			continue
+		}
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(files), path)
+		res := pipeline.ingestFile(ctx, path, cp, opts)
+		results = append(results, res)
+		printFileResult(res)
+	}
+
+	// 5. 写入完成后加载集合，使数据可以被检索
+	if milvusClient != nil && ctx.Err() == nil {
+		if err := milvusClient.LoadCollection(context.Background(), config.MilvusCollection, false); err != nil {
+			return fmt.Errorf("加载集合失败: %w", err)
+		}
+	}
+
+	// 6. 输出汇总
+	failed, interrupted := printSummary(results)
+	switch {
+	case interrupted > 0 && cpPath == "":
+		return fmt.Errorf("导入被中断，%d 个文件未完成；未启用检查点，没有保存进度，重新执行将从头导入", interrupted)
+	case interrupted > 0:
+		return fmt.Errorf("导入被中断，%d 个文件未完成；进度已保存到 %s，重新执行相同命令即可继续", interrupted, cpPath)
+	case failed > 0:
+		return fmt.Errorf("%d 个文件导入失败", failed)
+	}
+	return nil
+}
+
+// discoverFiles 展开参数中的 glob 和目录，返回去重并排序后的文件列表。
+// 显式指定的文件总会被导入；目录中的文件只导入扩展名匹配的。
+func discoverFiles(args []string, extensions []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		abs, err := filepath.Abs(path)
+		if err != nil || seen[abs] {
+			return
+		}
+		seen[abs] = true
+		files = append(files, path)
+	}
+	matchExt := func(path string) bool {
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, e := range extensions {
+			if ext == e {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 glob %q: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  glob %q 没有匹配到任何文件\n", arg)
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("无法访问 %s: %w", match, err)
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				// 跳过隐藏目录，例如 .git
+				if d.IsDir() && path != match && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				if !d.IsDir() && matchExt(path) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("遍历目录 %s 失败: %w", match, err)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// newIngestPipeline 创建流水线组件；非 dry-run 模式下还会连接 Milvus 并准备集合
+func newIngestPipeline(ctx context.Context, config *Config, dryRun bool) (*ingestPipeline, cli.Client, error) {
+	splitter, err := markdown.NewHeaderSplitter(ctx, &markdown.HeaderConfig{
+		Headers: map[string]string{
+			"#":   "Header 1",
+			"##":  "Header 2",
+			"###": "Header 3",
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建 HeaderSplitter 失败: %w", err)
+	}
+
+	p := &ingestPipeline{
+		loader:   &fileLoader{},
+		cleaner:  &textCleaner{},
+		splitter: splitter,
+		limiter:  &chunkSizeLimiter{maxBytes: maxChunkBytes},
+	}
+	if dryRun {
+		return p, nil, nil
+	}
+
+	if err := validateConfig(config); err != nil {
+		return nil, nil, err
+	}
+
+	timeout := 30 * time.Second
+	emb, err := embedder.NewEmbedder(ctx, &embedder.EmbeddingConfig{
+		APIKey:  config.ArkAPIKey,
+		Model:   config.EmbedderModel,
+		Timeout: &timeout,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建 Embedder 失败: %w", err)
+	}
+
+	client, err := cli.NewClient(ctx, cli.Config{Address: config.MilvusAddress})
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接 Milvus 失败: %w", err)
+	}
+	if err := ensureCollection(ctx, client, config.MilvusCollection); err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	p.client, p.collection = client, config.MilvusCollection
+	p.indexer, err = milvus.NewIndexer(ctx, &milvus.IndexerConfig{
+		Client:     client,
+		Collection: config.MilvusCollection,
+		Embedding:  emb,
+		Fields:     milvusSchema,
+	})
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("创建 Indexer 失败: %w", err)
+	}
+	return p, client, nil
+}
+
+// ensureCollection 检查集合是否存在，不存在时创建集合和向量索引
+func ensureCollection(ctx context.Context, client cli.Client, collection string) error {
+	has, err := client.HasCollection(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("检查集合是否存在失败: %w", err)
+	}
+	if has {
+		return nil
+	}
+
+	fmt.Printf("集合 '%s' 不存在，正在创建...\n", collection)
+	schema := &entity.Schema{CollectionName: collection, Fields: milvusSchema, Description: "eino-kb 知识库"}
+	if err := client.CreateCollection(ctx, schema, entity.DefaultShardNumber); err != nil {
+		return fmt.Errorf("创建集合失败: %w", err)
+	}
+	binFlatIndex, err := entity.NewIndexBinFlat(entity.HAMMING, 128)
+	if err != nil {
+		return fmt.Errorf("创建 BIN_FLAT 索引对象失败: %w", err)
+	}
+	if err := client.CreateIndex(ctx, collection, "vector", binFlatIndex, false); err != nil {
+		return fmt.Errorf("为 'vector' 字段创建索引失败: %w", err)
+	}
+	return nil
+}
+
+// prepare 执行 load → clean → split → 大小限制，返回文档块和原始内容摘要
+func (p *ingestPipeline) prepare(ctx context.Context, path string) ([]*schema.Document, string, int, error) {
+	docs, err := p.loader.Load(ctx, document.Source{URI: path})
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	// 摘要基于原始内容计算，用于判断文件自上次导入后是否被修改
+	h := sha256.New()
+	size := 0
+	for _, doc := range docs {
+		h.Write([]byte(doc.Content))
+		size += len(doc.Content)
+	}
+	sha := hex.EncodeToString(h.Sum(nil))
+
+	steps := []struct {
+		name string
+		t    document.Transformer
+	}{
+		{"清洗", p.cleaner},
+		{"切分", p.splitter},
+		{"大小限制", p.limiter},
+	}
+	for _, step := range steps {
+		docs, err = step.t.Transform(ctx, docs)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("%s失败: %w", step.name, err)
+		}
+	}
+
+	ingestedAt := time.Now().Format(time.RFC3339)
+	for _, doc := range docs {
+		doc.MetaData["ingested_at"] = ingestedAt
+	}
+	return docs, sha, size, nil
+}
+
+// ingestFile 导入单个文件，并把进度写入检查点
+func (p *ingestPipeline) ingestFile(ctx context.Context, path string, cp *checkpoint, opts *ingestOptions) *fileResult {
+	start := time.Now()
+	res := &fileResult{path: path}
+	defer func() { res.duration = time.Since(start) }()
+
+	chunks, sha, size, err := p.prepare(ctx, path)
+	res.bytes = size
+	if err != nil {
+		res.status, res.err = statusFailed, err
+		return res
+	}
+	res.chunks = len(chunks)
+	fmt.Printf("  切分为 %d 个文档块 (%d 字节)\n", len(chunks), size)
+
+	if opts.verbose {
+		for _, chunk := range chunks {
+			fmt.Printf("    - %s %s\n", chunk.ID, preview(chunk.Content, 60))
+		}
+	}
+	if opts.dryRun {
+		res.status = statusDryRun
+		return res
+	}
+
+	absPath, _ := filepath.Abs(path)
+	entry := cp.lookup(absPath, sha)
+	if opts.force {
+		entry = nil
+	}
+	switch {
+	case entry != nil && entry.Status == fileStatusDone:
+		res.status, res.stored = statusSkipped, entry.DoneChunks
+		return res
+	case entry != nil && entry.Chunks == len(chunks):
+		fmt.Printf("  从检查点继续: 已完成 %d/%d\n", entry.DoneChunks, entry.Chunks)
+		res.status = statusResumed
+	default:
+		// Indexer 使用插入而不是更新，重新导入前先删除该文件上次写入的文档块，避免产生重复数据
+		if old := cp.Files[absPath]; old != nil && len(old.StoredIDs) > 0 {
+			if err := p.deleteChunks(ctx, old.StoredIDs); err != nil {
+				res.status, res.err = statusFailed, err
+				return res
+			}
+			fmt.Printf("  已删除上次导入的 %d 个文档块\n", len(old.StoredIDs))
+		}
+		entry = &checkpointEntry{SHA256: sha, DocID: docIDForPath(path), Status: fileStatusPartial, Chunks: len(chunks)}
+		res.status = statusIndexed
+	}
+
+	// 按批次写入向量库，每个批次完成后立即保存检查点
+	for offset := entry.DoneChunks; offset < len(chunks); offset += opts.batchSize {
+		end := offset + opts.batchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+
+		ids, err := p.indexer.Store(ctx, chunks[offset:end])
+		if err != nil {
+			res.stored = entry.DoneChunks
+			if ctx.Err() != nil {
+				res.status, res.err = statusInterrupted, ctx.Err()
+			} else {
+				res.status, res.err = statusFailed, fmt.Errorf("写入向量库失败: %w", err)
+			}
+			return res
+		}
+
+		entry.DoneChunks = end
+		entry.StoredIDs = append(entry.StoredIDs, ids...)
+		if end == len(chunks) {
+			entry.Status = fileStatusDone
+		}
+		if err := cp.record(absPath, entry); err != nil {
+			res.status, res.err = statusFailed, err
+			return res
+		}
+		fmt.Printf("  索引进度 %d/%d (%.0f%%)\n", end, len(chunks), float64(end)/float64(len(chunks))*100)
+	}
+
+	res.stored = entry.DoneChunks
+	return res
+}
+
+// deleteChunks 按 ID 删除向量库中的文档块
+func (p *ingestPipeline) deleteChunks(ctx context.Context, ids []string) error {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = strconv.Quote(id)
+	}
+	expr := fmt.Sprintf("id in [%s]", strings.Join(quoted, ","))
+	if err := p.client.Delete(ctx, p.collection, "", expr); err != nil {
+		return fmt.Errorf("删除旧文档块失败: %w", err)
+	}
+	return nil
+}
+
+// printFileResult 打印单个文件的处理结果
+func printFileResult(res *fileResult) {
+	switch res.status {
+	case statusFailed:
+		fmt.Printf("  ❌ 失败: %v\n", res.err)
+	case statusInterrupted:
+		fmt.Printf("  ⏸  已中断，已写入 %d/%d 个文档块\n", res.stored, res.chunks)
+	case statusSkipped:
+		fmt.Println("  ↷ 内容未变化且已导入，跳过")
+	case statusDryRun:
+		fmt.Println("  ✓ dry-run 完成，未写入向量库")
+	default:
+		fmt.Printf("  ✓ 完成，写入 %d 个文档块，耗时 %s\n", res.stored, res.duration.Round(time.Millisecond))
+	}
+}
+
+// printSummary 打印逐文件的汇总表，返回失败和被中断的文件数量
+func printSummary(results []*fileResult) (failed, interrupted int) {
+	fmt.Println("\n=== 导入汇总 ===")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "文件\t状态\t文档块\t已写入\t字节\t耗时\t")
+
+	var totalChunks, totalStored int
+	for _, res := range results {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t\n",
+			res.path, res.status, res.chunks, res.stored, res.bytes, res.duration.Round(time.Millisecond))
+		totalChunks += res.chunks
+		totalStored += res.stored
+		switch res.status {
+		case statusFailed:
+			failed++
+		case statusInterrupted:
+			interrupted++
+		}
+	}
+	w.Flush()
+
+	fmt.Printf("\n文件: %d, 文档块: %d, 已写入: %d, 失败: %d, 中断: %d\n",
+		len(results), totalChunks, totalStored, failed, interrupted)
+	return failed, interrupted
+}
+
+// preview 截取文本的前 n 个字符作为单行预览
+func preview(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
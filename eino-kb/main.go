@@ -0,0 +1,142 @@
+// Package main 实现 eino-kb 命令行工具，用于管理 RAG 系统的知识库。
+// 目前支持的子命令:
+//
+//	eino-kb ingest [flags] <路径或 glob>...   将目录/文件导入到配置的向量库
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"github.com/spf13/viper"
+)
+
+// =============================================================================
+//
+//  文件: eino-kb/main.go
+//  功能: eino-kb 命令行入口，负责加载配置并分发子命令。
+//  说明: 以前向知识库加载数据需要修改 Go 源码 (LoadInitialKnowledge / prepareDocument)
+//        并重新运行演示程序，eino-kb 把这一流程变成了一个可重复执行的命令。
+//
+// =============================================================================
+
+// Config 存储 eino-kb 的配置，字段与项目根目录的 config.yaml 保持一致
+type Config struct {
+	MilvusAddress    string
+	MilvusCollection string
+	ArkAPIKey        string
+	EmbedderModel    string
+}
+
+// milvusSchema 定义了 Milvus 集合的结构（必须与其他演示使用的集合结构一致）
+var milvusSchema = []*entity.Field{
+	{
+		Name:        "id",
+		DataType:    entity.FieldTypeVarChar,
+		TypeParams:  map[string]string{"max_length": "255"},
+		PrimaryKey:  true,
+		Description: "文档块的唯一标识符",
+	},
+	{
+		Name:        "vector",
+		DataType:    entity.FieldTypeBinaryVector,
+		TypeParams:  map[string]string{"dim": "81920"}, // 维度需与 embedding 模型匹配
+		Description: "文档内容的向量表示",
+	},
+	{
+		Name:        "content",
+		DataType:    entity.FieldTypeVarChar,
+		TypeParams:  map[string]string{"max_length": "8192"},
+		Description: "原始文本内容",
+	},
+	{
+		Name:        "metadata",
+		DataType:    entity.FieldTypeJSON,
+		Description: "文档元数据信息",
+	},
+}
+
+// loadConfig 从配置文件 (config.yaml) 或环境变量中加载配置
+func loadConfig() *Config {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("./")  // 在当前目录查找
+	viper.AddConfigPath("../") // 在上一级目录查找
+	viper.AutomaticEnv()       // 允许从环境变量读取
+
+	if err := viper.ReadInConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, "未找到 config.yaml 文件，将仅从环境变量读取配置。")
+	}
+
+	return &Config{
+		MilvusAddress:    viper.GetString("MILVUS_ADDRESS"),
+		MilvusCollection: viper.GetString("MILVUS_COLLECTION"),
+		ArkAPIKey:        viper.GetString("ARK_API_KEY"),
+		EmbedderModel:    viper.GetString("EMBEDDER_MODEL"),
+	}
+}
+
+// validateConfig 验证写入向量库所需的配置是否完整
+func validateConfig(config *Config) error {
+	if config.MilvusAddress == "" {
+		return errors.New("MILVUS_ADDRESS 必须设置")
+	}
+	if config.MilvusCollection == "" {
+		return errors.New("MILVUS_COLLECTION 必须设置")
+	}
+	if config.ArkAPIKey == "" {
+		return errors.New("ARK_API_KEY 必须设置")
+	}
+	if config.EmbedderModel == "" {
+		return errors.New("EMBEDDER_MODEL 必须设置")
+	}
+	return nil
+}
+
+// usage 打印命令行帮助信息
+func usage() {
+	fmt.Fprintln(os.Stderr, `eino-kb - Eino 知识库管理工具
+
+用法:
+  eino-kb <命令> [参数]
+
+命令:
+  ingest    将文件或目录导入到配置的向量库 (load → clean → split → embed → index)
+
+使用 "eino-kb <命令> -h" 查看命令的详细参数。`)
+}
+
+// main 是程序入口，负责分发子命令
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	// 收到 Ctrl+C / SIGTERM 时取消 context，让正在执行的命令有机会保存进度
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "ingest":
+		err = runIngest(ctx, os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
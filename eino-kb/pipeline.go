@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: eino-kb/pipeline.go
+//  功能: ingest 流水线中 load / clean 两个环节的组件实现。
+//  说明: fileLoader 实现 document.Loader 接口，textCleaner 和 chunkSizeLimiter
+//        实现 document.Transformer 接口，可以与 HeaderSplitter 等官方组件自由组合。
+//
+// =============================================================================
+
+// maxChunkBytes 是单个文档块允许的最大字节数。
+// Milvus 集合中 content 字段的 max_length 为 8192，这里预留一部分余量。
+const maxChunkBytes = 8000
+
+// --- Load: 本地文件加载器 ---
+
+// fileLoader 从本地文件系统加载文档
+type fileLoader struct{}
+
+// Load 读取 src.URI 指向的文件，返回一个包含完整内容的文档
+func (l *fileLoader) Load(ctx context.Context, src document.Source, opts ...document.LoaderOption) ([]*schema.Document, error) {
+	data, err := os.ReadFile(src.URI)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("文件 %s 不是有效的 UTF-8 文本", src.URI)
+	}
+
+	docID := docIDForPath(src.URI)
+	return []*schema.Document{{
+		ID:      docID,
+		Content: string(data),
+		MetaData: map[string]any{
+			"source":    filepath.ToSlash(src.URI),
+			"file_name": filepath.Base(src.URI),
+			"doc_id":    docID,
+		},
+	}}, nil
+}
+
+// docIDForPath 根据文件路径生成稳定的文档 ID，同一文件多次导入得到相同的 ID
+func docIDForPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	sum := sha1.Sum([]byte(filepath.ToSlash(abs)))
+	return "kb_" + hex.EncodeToString(sum[:8])
+}
+
+// chunkID 为切分后的文档块生成 ID: <文档ID>_<序号>
+func chunkID(docID string, index int) string {
+	return fmt.Sprintf("%s_%04d", docID, index)
+}
+
+// --- Clean: 文本清洗 ---
+
+var (
+	// multiBlankLines 匹配三个及以上的连续空行
+	multiBlankLines = regexp.MustCompile(`\n{3,}`)
+	// trailingSpaces 匹配行尾空白
+	trailingSpaces = regexp.MustCompile(`[ \t]+\n`)
+)
+
+// textCleaner 对原始文本做规范化处理:
+// 去除 BOM、统一换行符、去掉行尾空白和不可见控制字符、压缩多余空行
+type textCleaner struct{}
+
+// Transform 清洗每个文档的内容，清洗后为空的文档会被丢弃
+func (c *textCleaner) Transform(ctx context.Context, docs []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	ret := make([]*schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		content := strings.TrimPrefix(doc.Content, "\ufeff")
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		content = strings.ReplaceAll(content, "\r", "\n")
+		content = strings.Map(func(r rune) rune {
+			// 保留换行和制表符，去掉其他控制字符
+			if r < 0x20 && r != '\n' && r != '\t' {
+				return -1
+			}
+			return r
+		}, content)
+		content = trailingSpaces.ReplaceAllString(content, "\n")
+		content = multiBlankLines.ReplaceAllString(content, "\n\n")
+		content = strings.TrimSpace(content)
+		if content == "" {
+			continue
+		}
+
+		doc.Content = content
+		ret = append(ret, doc)
+	}
+	return ret, nil
+}
+
+// --- Split 之后: 文档块大小限制 ---
+
+// chunkSizeLimiter 把超过 maxBytes 的文档块按行（必要时按字符）继续切分，
+// 确保每个文档块都能写入 Milvus 的 content 字段
+type chunkSizeLimiter struct {
+	maxBytes int
+}
+
+// Transform 切分过大的文档块，并为所有文档块重新编号
+func (s *chunkSizeLimiter) Transform(ctx context.Context, docs []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var ret []*schema.Document
+	for _, doc := range docs {
+		for _, piece := range splitBySize(doc.Content, s.maxBytes) {
+			meta := make(map[string]any, len(doc.MetaData))
+			for k, v := range doc.MetaData {
+				meta[k] = v
+			}
+			ret = append(ret, &schema.Document{ID: doc.ID, Content: piece, MetaData: meta})
+		}
+	}
+
+	// HeaderSplitter 生成的 ID 在拆分后会重复，这里统一按最终顺序重新编号
+	counters := make(map[string]int)
+	for _, doc := range ret {
+		docID, _ := doc.MetaData["doc_id"].(string)
+		if docID == "" {
+			docID = doc.ID
+		}
+		doc.ID = chunkID(docID, counters[docID])
+		doc.MetaData["chunk_index"] = counters[docID]
+		counters[docID]++
+	}
+	return ret, nil
+}
+
+// splitBySize 在不超过 maxBytes 的前提下尽量按行边界切分文本。
+// HeaderSplitter 输出的文档块已去掉空行，所以这里以单行为最小单位。
+func splitBySize(text string, maxBytes int) []string {
+	if len(text) <= maxBytes {
+		return []string{text}
+	}
+
+	var (
+		pieces  []string
+		current strings.Builder
+	)
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			pieces = append(pieces, s)
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		// 单行本身就超长，只能按字符硬切
+		for len(line) > maxBytes {
+			flush()
+			cut := maxBytes
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			pieces = append(pieces, line[:cut])
+			line = line[cut:]
+		}
+		if current.Len() > 0 && current.Len()+len(line)+1 > maxBytes {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return pieces
+}
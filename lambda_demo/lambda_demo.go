@@ -4,12 +4,16 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
 
+	"Eini/tools/validation"
+
 	"github.com/cloudwego/eino/compose"
 )
 
@@ -157,33 +161,28 @@ func runTextProcessingDemo(ctx context.Context) {
 	fmt.Printf("  最终结果: %s\n", result)
 }
 
+// userRules 数据验证链使用的校验规则 (JSON 规则文件，见 tools/validation)
+//
+//go:embed user_rules.json
+var userRules []byte
+
 // 演示4: 数据验证链
 func runValidationChainDemo(ctx context.Context) {
-	chain := compose.NewChain[map[string]interface{}, string]()
-
-	// Step 1: 数据验证
-	validateData := compose.InvokableLambda(func(ctx context.Context, data map[string]interface{}) (map[string]interface{}, error) {
-		// 检查必需字段
-		requiredFields := []string{"name", "age"}
-		for _, field := range requiredFields {
-			if _, exists := data[field]; !exists {
-				return nil, fmt.Errorf("缺少必需字段: %s", field)
-			}
-		}
+	rules, err := validation.LoadSchema(userRules)
+	if err != nil {
+		log.Printf("加载校验规则失败: %v", err)
+		return
+	}
 
-		// 验证年龄
-		if age, ok := data["age"].(float64); ok {
-			if age < 0 || age > 150 {
-				return nil, fmt.Errorf("年龄无效: %.0f", age)
-			}
-		}
+	chain := compose.NewChain[map[string]interface{}, string]()
 
-		fmt.Printf("  步骤1 - 数据验证通过\n")
-		return data, nil
-	})
+	// Step 1: 数据验证，校验不通过时返回 *validation.ValidationError，列出每个字段的错误
+	validateData := validation.Lambda[map[string]interface{}](rules, "zh")
 
 	// Step 2: 数据标准化
 	normalizeData := compose.InvokableLambda(func(ctx context.Context, data map[string]interface{}) (map[string]string, error) {
+		fmt.Printf("  步骤1 - 数据验证通过\n")
+
 		result := make(map[string]string)
 		result["name"] = strings.TrimSpace(data["name"].(string))
 		result["age"] = fmt.Sprintf("%.0f", data["age"].(float64))
@@ -213,9 +212,10 @@ func runValidationChainDemo(ctx context.Context) {
 
 	// 测试有效数据
 	validData := map[string]interface{}{
-		"name": "  王五  ",
-		"age":  float64(30),
-		"city": "上海",
+		"name":  "  王五  ",
+		"age":   float64(30),
+		"city":  "上海",
+		"phone": "13812345678",
 	}
 
 	fmt.Printf("  输入: %+v\n", validData)
@@ -223,23 +223,41 @@ func runValidationChainDemo(ctx context.Context) {
 	result, err := runnable.Invoke(ctx, validData)
 	if err != nil {
 		log.Printf("处理失败: %v", err)
+	} else {
+		fmt.Printf("  最终结果:\n%s\n", result)
+	}
 
-		// 测试无效数据
-		fmt.Printf("\n  测试无效数据:\n")
-		invalidData := map[string]interface{}{
-			"name": "测试",
-			// 缺少 age 字段
-		}
+	// 测试无效数据: 缺少 age，年龄之外的错误也会一次性列出
+	fmt.Printf("\n  测试无效数据:\n")
+	invalidData := map[string]interface{}{
+		"name":  "测",
+		"phone": "12345",
+	}
 
-		fmt.Printf("  输入: %+v\n", invalidData)
-		_, err2 := runnable.Invoke(ctx, invalidData)
-		if err2 != nil {
-			fmt.Printf("  验证错误: %v\n", err2)
+	fmt.Printf("  输入: %+v\n", invalidData)
+	_, err = runnable.Invoke(ctx, invalidData)
+	var verr *validation.ValidationError
+	if errors.As(err, &verr) {
+		for _, fe := range verr.Errors {
+			fmt.Printf("  验证错误: %s (%s): %s\n", fe.Field, fe.Rule, fe.Message)
 		}
-		return
+	} else if err != nil {
+		fmt.Printf("  处理失败: %v\n", err)
 	}
 
-	fmt.Printf("  最终结果:\n%s\n", result)
+	// 同一份规则也可以作为工具提供给模型，校验结果按字段返回
+	fmt.Printf("\n  作为工具使用:\n")
+	validateTool, err := validation.NewTool("validate_user", "校验用户资料", rules)
+	if err != nil {
+		log.Printf("创建校验工具失败: %v", err)
+		return
+	}
+	output, err := validateTool.InvokableRun(ctx, `{"data": {"name": "Alice", "age": 200}, "locale": "en"}`)
+	if err != nil {
+		log.Printf("校验工具执行失败: %v", err)
+		return
+	}
+	fmt.Printf("  工具结果: %s\n", output)
 }
 
 // 演示2: 复杂数据处理链
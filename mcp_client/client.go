@@ -0,0 +1,311 @@
+package mcp_client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// =============================================================================
+//
+//  文件: mcp_client/client.go
+//  功能: 管理与 MCP 服务端的连接。
+//  1. Client  - 单个服务端的连接，负责初始化握手、超时控制和断线重连
+//  2. Toolset - 同时管理多个服务端，汇总它们的工具
+//  说明: 连接在第一次使用时建立；请求因传输层错误失败时 (子进程退出、HTTP 会话失效等)
+//        会丢弃旧连接，ListTools 重新建立连接后重试；工具调用可能不是幂等的，
+//        只有配置了 RetryCalls 时才重试。服务端返回的 JSON-RPC 错误和超时不会重试。
+//
+// =============================================================================
+
+// 客户端信息，在初始化握手时发送给服务端
+const (
+	clientName    = "eino-mcp-client"
+	clientVersion = "1.0.0"
+)
+
+// Client 与单个 MCP 服务端的连接，可以被多个工具并发使用
+type Client struct {
+	config *ServerConfig
+
+	mu   sync.Mutex
+	conn *client.Client // 当前连接，为 nil 时表示尚未连接或连接已断开
+}
+
+// NewClient 创建客户端并建立连接
+func NewClient(ctx context.Context, config *ServerConfig) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	c := &Client{config: config}
+	if _, err := c.session(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Name 返回服务名称
+func (c *Client) Name() string {
+	return c.config.Name
+}
+
+// ListTools 列出服务端提供的工具
+func (c *Client) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	var tools []mcp.Tool
+	err := c.do(ctx, c.config.connectTimeout(), c.config.maxRetries(), func(ctx context.Context, conn *client.Client) error {
+		result, err := conn.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			return err
+		}
+		tools = result.Tools
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("列出服务 %s 的工具失败: %w", c.config.Name, err)
+	}
+	return tools, nil
+}
+
+// CallTool 调用服务端的工具，name 为服务端的原始工具名称 (不带命名空间)。
+// 连接断开时只有配置了 RetryCalls 才重连后重试，避免非幂等的工具被执行两次。
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+
+	maxRetries := 0
+	if c.config.RetryCalls {
+		maxRetries = c.config.maxRetries()
+	}
+	var result *mcp.CallToolResult
+	err := c.do(ctx, c.config.callTimeout(), maxRetries, func(ctx context.Context, conn *client.Client) error {
+		var err error
+		result, err = conn.CallTool(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("调用 %s/%s 失败: %w", c.config.Name, name, err)
+	}
+	return result, nil
+}
+
+// Tools 把服务端的全部工具包装为 Eino 工具，工具名称带有命名空间前缀。
+// 参数定义无法转换的工具会被跳过并记录日志。
+func (c *Client) Tools(ctx context.Context) ([]tool.BaseTool, error) {
+	mcpTools, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make([]tool.BaseTool, 0, len(mcpTools))
+	for _, t := range mcpTools {
+		rt, err := newRemoteTool(c, t)
+		if err != nil {
+			log.Printf("[MCPClient] 跳过服务 %s 的工具 %s: %v", c.config.Name, t.Name, err)
+			continue
+		}
+		tools = append(tools, rt)
+	}
+	return tools, nil
+}
+
+// Close 关闭连接，stdio 传输会同时结束子进程
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// do 在连接上执行一次请求。
+// 请求因传输层错误失败时丢弃当前连接，重新连接后重试，最多重试 maxRetries 次。
+func (c *Client) do(ctx context.Context, timeout time.Duration, maxRetries int, fn func(ctx context.Context, conn *client.Client) error) error {
+	for attempt := 0; ; attempt++ {
+		conn, err := c.session(ctx)
+		if err != nil {
+			return err
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = fn(callCtx, conn)
+		timedOut := callCtx.Err() != nil
+		cancel()
+
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case timedOut:
+			return fmt.Errorf("请求超时 (%s)", timeout)
+		case !isConnectionError(err):
+			return err
+		}
+
+		c.discard(conn)
+		if attempt >= maxRetries {
+			return fmt.Errorf("连接已断开: %w", err)
+		}
+		log.Printf("[MCPClient] 服务 %s 连接断开，正在重连 (%d/%d): %v", c.config.Name, attempt+1, maxRetries, err)
+	}
+}
+
+// session 返回当前连接，尚未连接时建立新连接
+func (c *Client) session(ctx context.Context) (*client.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		return c.conn, nil
+	}
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("连接服务 %s 失败: %w", c.config.Name, err)
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+// discard 关闭已断开的连接。其他请求可能已经完成了重连，此时不做处理。
+func (c *Client) discard(conn *client.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == conn {
+		_ = conn.Close()
+		c.conn = nil
+	}
+}
+
+// connect 建立连接并完成初始化握手
+func (c *Client) connect(ctx context.Context) (*client.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.connectTimeout())
+	defer cancel()
+
+	var (
+		conn *client.Client
+		err  error
+	)
+	switch c.config.Transport() {
+	case TransportStdio:
+		// 子进程的生命周期与连接一致，不能绑定到本次请求的 ctx 上
+		conn, err = client.NewStdioMCPClient(c.config.Command, c.config.envList(), c.config.Args...)
+		if err != nil {
+			return nil, err
+		}
+		c.forwardStderr(conn)
+	case TransportHTTP:
+		conn, err = client.NewStreamableHttpClient(c.config.URL, transport.WithHTTPHeaders(c.config.Headers))
+		if err != nil {
+			return nil, err
+		}
+		if err := conn.Start(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: clientName, Version: clientVersion}
+	result, err := conn.Initialize(ctx, request)
+	if err != nil {
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("初始化超时 (%s)", c.config.connectTimeout())
+		}
+		return nil, fmt.Errorf("初始化失败: %w", err)
+	}
+
+	log.Printf("[MCPClient] 已连接服务 %s (%s %s，传输: %s)",
+		c.config.Name, result.ServerInfo.Name, result.ServerInfo.Version, c.config.Transport())
+	return conn, nil
+}
+
+// forwardStderr 把子进程的 stderr 输出转发到日志。
+// stderr 必须被持续读取，否则管道写满后子进程会阻塞。
+func (c *Client) forwardStderr(conn *client.Client) {
+	stderr, ok := client.GetStderr(conn)
+	if !ok {
+		return
+	}
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			log.Printf("[MCPClient][%s] %s", c.config.Name, scanner.Text())
+		}
+	}()
+}
+
+// isConnectionError 判断是否为传输层错误 (连接断开、会话失效等)，这类错误可以通过重连恢复
+func isConnectionError(err error) bool {
+	var transportErr *transport.Error
+	return errors.As(err, &transportErr)
+}
+
+// ================================
+// 多个服务端
+// ================================
+
+// Toolset 管理多个 MCP 服务端的连接
+type Toolset struct {
+	clients []*Client
+}
+
+// NewToolset 连接所有服务端，任意一个连接失败时关闭已建立的连接并返回错误
+func NewToolset(ctx context.Context, configs []*ServerConfig) (*Toolset, error) {
+	ts := &Toolset{}
+	for _, cfg := range configs {
+		c, err := NewClient(ctx, cfg)
+		if err != nil {
+			_ = ts.Close()
+			return nil, err
+		}
+		ts.clients = append(ts.clients, c)
+	}
+	return ts, nil
+}
+
+// Clients 返回所有服务端的客户端
+func (ts *Toolset) Clients() []*Client {
+	return ts.clients
+}
+
+// Tools 汇总所有服务端的工具
+func (ts *Toolset) Tools(ctx context.Context) ([]tool.BaseTool, error) {
+	var tools []tool.BaseTool
+	for _, c := range ts.clients {
+		serverTools, err := c.Tools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, serverTools...)
+	}
+	return tools, nil
+}
+
+// Close 关闭所有连接
+func (ts *Toolset) Close() error {
+	var errs []error
+	for _, c := range ts.clients {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭服务 %s 失败: %w", c.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,176 @@
+package mcp_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: mcp_client/config.go
+//  功能: MCP 服务端的连接配置。
+//  说明: 配置文件沿用 Claude Desktop / Cursor 等客户端通用的 mcpServers 格式，
+//        已有的 MCP 配置可以直接复用:
+//
+//    {
+//      "mcpServers": {
+//        "kb":     {"url": "http://127.0.0.1:8080/mcp", "headers": {"Authorization": "Bearer xxx"}},
+//        "files":  {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]},
+//        "search": {"command": "./search-server", "env": {"API_KEY": "xxx"}, "timeout": "10s"}
+//      }
+//    }
+//
+// =============================================================================
+
+// 默认超时时间
+const (
+	DefaultConnectTimeout = 10 * time.Second // 启动进程 / 建立连接并完成初始化握手
+	DefaultCallTimeout    = 30 * time.Second // 单次工具调用
+)
+
+// 传输方式
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http" // Streamable HTTP
+)
+
+// namePattern 服务名称只允许字母、数字、下划线和连字符，保证拼接后的工具名称符合模型 API 的要求
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ServerConfig 单个 MCP 服务端的配置
+type ServerConfig struct {
+	// Name 服务名称，同时作为工具名称的命名空间: <Name>__<工具名>
+	Name string `json:"-"`
+
+	// stdio 传输: 启动子进程并通过标准输入输出通信
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+
+	// http 传输: 连接 Streamable HTTP 端点
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// ConnectTimeout 建立连接的超时时间，默认 DefaultConnectTimeout
+	ConnectTimeout Duration `json:"connect_timeout,omitempty"`
+	// Timeout 单次工具调用的超时时间，默认 DefaultCallTimeout
+	Timeout Duration `json:"timeout,omitempty"`
+	// MaxRetries 连接断开时重连并重试的次数，默认 1；设为 -1 表示不重试
+	MaxRetries int `json:"max_retries,omitempty"`
+	// RetryCalls 工具调用因连接断开失败时是否重连后重试，默认 false。
+	// 无法判断断开前服务端是否已经执行，非幂等的工具重试可能被执行两次，只有服务端的工具都是幂等的时才应开启；
+	// 不开启时只重试初始化握手和 ListTools，工具调用失败后丢弃旧连接，下一次调用重新连接。
+	RetryCalls bool `json:"retry_calls,omitempty"`
+}
+
+// Transport 根据配置判断传输方式
+func (c *ServerConfig) Transport() string {
+	if c.URL != "" {
+		return TransportHTTP
+	}
+	return TransportStdio
+}
+
+// Validate 校验配置
+func (c *ServerConfig) Validate() error {
+	if !namePattern.MatchString(c.Name) {
+		return fmt.Errorf("服务名称 %q 只能包含字母、数字、下划线和连字符", c.Name)
+	}
+	if (c.Command == "") == (c.URL == "") {
+		return fmt.Errorf("服务 %s 必须且只能配置 command 或 url 其中之一", c.Name)
+	}
+	return nil
+}
+
+func (c *ServerConfig) connectTimeout() time.Duration {
+	if c.ConnectTimeout > 0 {
+		return time.Duration(c.ConnectTimeout)
+	}
+	return DefaultConnectTimeout
+}
+
+func (c *ServerConfig) callTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout)
+	}
+	return DefaultCallTimeout
+}
+
+func (c *ServerConfig) maxRetries() int {
+	switch {
+	case c.MaxRetries < 0:
+		return 0
+	case c.MaxRetries == 0:
+		return 1
+	default:
+		return c.MaxRetries
+	}
+}
+
+// envList 把环境变量转换为 KEY=VALUE 形式，并按名称排序保证顺序稳定
+func (c *ServerConfig) envList() []string {
+	env := make([]string, 0, len(c.Env))
+	for k, v := range c.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// LoadConfig 读取 mcpServers 格式的配置文件，返回按名称排序的服务配置
+func LoadConfig(path string) ([]*ServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 MCP 配置失败: %w", err)
+	}
+
+	var file struct {
+		MCPServers map[string]*ServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析 MCP 配置失败: %w", err)
+	}
+
+	configs := make([]*ServerConfig, 0, len(file.MCPServers))
+	for name, cfg := range file.MCPServers {
+		cfg.Name = name
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		configs = append(configs, cfg)
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Name < configs[j].Name })
+	return configs, nil
+}
+
+// Duration 支持 "10s" 字符串或毫秒数两种 JSON 格式的时长
+type Duration time.Duration
+
+// UnmarshalJSON 解析 "10s" 或 10000 (毫秒)
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("无效的时长 %q: %w", s, err)
+		}
+		*d = Duration(v)
+		return nil
+	}
+
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return fmt.Errorf("时长必须是字符串 (如 \"10s\") 或毫秒数")
+	}
+	*d = Duration(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+// MarshalJSON 输出为 "10s" 格式
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,148 @@
+package mcp_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// =============================================================================
+//
+//  文件: mcp_client/tool.go
+//  功能: 把远程 MCP 工具包装为 Eino 的 tool.InvokableTool，
+//        可以和本地工具一起注册到 ToolsNode 或绑定到 ChatModel。
+//  说明: 工具名称为 <服务名称>__<工具名称>，避免不同服务端的同名工具冲突；
+//        MCP 工具的 inputSchema (JSON Schema) 转换为 ToolInfo 的 OpenAPI v3 参数定义。
+//        远程工具返回 isError 时不返回 error (ToolsNode 遇到 error 会中断整个调用)，
+//        而是把错误信息作为结果返回给模型；只有参数有误或请求失败 (超时、连接断开) 时才返回 error。
+//
+// =============================================================================
+
+// NamespaceSeparator 服务名称与工具名称之间的分隔符
+const NamespaceSeparator = "__"
+
+// remoteTool 远程 MCP 工具
+type remoteTool struct {
+	client *Client
+	name   string           // 服务端的原始工具名称
+	info   *schema.ToolInfo // 带命名空间的工具信息
+}
+
+// newRemoteTool 根据 MCP 工具定义创建 Eino 工具
+func newRemoteTool(c *Client, t mcp.Tool) (*remoteTool, error) {
+	params, err := toParamsOneOf(t)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteTool{
+		client: c,
+		name:   t.Name,
+		info: &schema.ToolInfo{
+			Name:        c.Name() + NamespaceSeparator + t.Name,
+			Desc:        t.Description,
+			ParamsOneOf: params,
+		},
+	}, nil
+}
+
+// Info 返回带命名空间的工具信息
+func (r *remoteTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return r.info, nil
+}
+
+// InvokableRun 调用远程工具，返回其文本内容。
+// 远程工具返回 isError 时返回 toolError 的 JSON，由模型决定如何处理。
+func (r *remoteTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var args map[string]any
+	if strings.TrimSpace(argumentsInJSON) != "" {
+		if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+			return "", fmt.Errorf("解析参数失败: %w", err)
+		}
+	}
+
+	result, err := r.client.CallTool(ctx, r.name, args)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := resultText(result)
+	if err != nil {
+		return "", err
+	}
+	if result.IsError {
+		data, err := json.Marshal(&toolError{IsError: true, Error: output})
+		if err != nil {
+			return "", fmt.Errorf("序列化错误结果失败: %w", err)
+		}
+		return string(data), nil
+	}
+	return output, nil
+}
+
+// toolError 远程工具返回 isError 时的结果
+type toolError struct {
+	IsError bool   `json:"is_error"`
+	Error   string `json:"error"`
+}
+
+// toParamsOneOf 把 MCP 工具的 inputSchema 转换为 Eino 的参数定义
+func toParamsOneOf(t mcp.Tool) (*schema.ParamsOneOf, error) {
+	raw := t.RawInputSchema
+	if raw == nil {
+		inputSchema := t.InputSchema
+		if inputSchema.Type == "" {
+			inputSchema.Type = "object"
+		}
+		if inputSchema.Properties == nil {
+			inputSchema.Properties = map[string]any{}
+		}
+		var err error
+		if raw, err = json.Marshal(inputSchema); err != nil {
+			return nil, fmt.Errorf("序列化参数定义失败: %w", err)
+		}
+	}
+
+	s := &openapi3.Schema{}
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("参数定义不是有效的 JSON Schema: %w", err)
+	}
+	return schema.NewParamsOneOfByOpenAPIV3(s), nil
+}
+
+// resultText 把工具结果转换为文本。
+// 多个文本内容按行拼接；没有文本内容时使用结构化结果；图片等二进制内容以占位说明代替。
+func resultText(result *mcp.CallToolResult) (string, error) {
+	parts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			parts = append(parts, c.Text)
+		case mcp.ImageContent:
+			parts = append(parts, fmt.Sprintf("[图片: %s]", c.MIMEType))
+		case mcp.AudioContent:
+			parts = append(parts, fmt.Sprintf("[音频: %s]", c.MIMEType))
+		case mcp.EmbeddedResource:
+			if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+				parts = append(parts, text.Text)
+			} else {
+				parts = append(parts, "[二进制资源]")
+			}
+		case mcp.ResourceLink:
+			parts = append(parts, fmt.Sprintf("[资源: %s]", c.URI))
+		}
+	}
+	if len(parts) == 0 && result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return "", fmt.Errorf("序列化结构化结果失败: %w", err)
+		}
+		return string(data), nil
+	}
+	return strings.Join(parts, "\n"), nil
+}
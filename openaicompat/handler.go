@@ -0,0 +1,440 @@
+// Package openaicompat 把任意 compose.Runnable[[]*schema.Message, *schema.Message]
+// 包装为 OpenAI 兼容的 /v1/chat/completions 接口，
+// 让 IDE 插件、评测工具等只支持 OpenAI API 的客户端可以直接调用 Eino 编排的 Chain / Graph。
+package openaicompat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// =============================================================================
+//
+//  文件: openaicompat/handler.go
+//  功能: OpenAI 兼容接口的 HTTP Handler。
+//  接口:
+//    POST /v1/chat/completions  对话补全 (支持 stream)
+//    GET  /v1/models            列出可用的模型名称
+//  特性:
+//  1. 按请求中的 model 字段路由到不同的编译后 Chain
+//  2. 流式响应按 OpenAI 的 chat.completion.chunk 格式输出
+//  3. tool_calls 双向透传: 请求中的 tools 通过 WithChatModelOption 传给 ChatModel，
+//     Chain 输出的 ToolCalls 原样返回给客户端
+//  4. 返回 usage 字段 (取决于底层 ChatModel 是否提供 token 用量)
+//
+// =============================================================================
+
+// Runnable 是可以被适配器服务的 Eino 可执行对象，通常由 Chain 或 Graph 编译得到
+type Runnable = compose.Runnable[[]*schema.Message, *schema.Message]
+
+// Config 适配器配置
+type Config struct {
+	// Models 模型名称到 Runnable 的映射，客户端通过请求中的 model 字段选择
+	Models map[string]Runnable
+	// DefaultModel 请求未指定 model 时使用的模型名称，可为空
+	DefaultModel string
+	// OwnedBy GET /v1/models 中返回的 owned_by 字段，默认为 "eino"
+	OwnedBy string
+}
+
+// Handler 实现 OpenAI 兼容接口的 http.Handler
+type Handler struct {
+	models       map[string]Runnable
+	defaultModel string
+	ownedBy      string
+	created      int64
+	mux          *http.ServeMux
+}
+
+// NewHandler 根据配置创建 Handler
+func NewHandler(config *Config) (*Handler, error) {
+	if config == nil || len(config.Models) == 0 {
+		return nil, errors.New("至少需要配置一个模型")
+	}
+	if config.DefaultModel != "" {
+		if _, ok := config.Models[config.DefaultModel]; !ok {
+			return nil, fmt.Errorf("默认模型 %q 不在 Models 中", config.DefaultModel)
+		}
+	}
+
+	h := &Handler{
+		models:       config.Models,
+		defaultModel: config.DefaultModel,
+		ownedBy:      config.OwnedBy,
+		created:      time.Now().Unix(),
+		mux:          http.NewServeMux(),
+	}
+	if h.ownedBy == "" {
+		h.ownedBy = "eino"
+	}
+	h.mux.HandleFunc("POST /v1/chat/completions", h.handleChatCompletions)
+	h.mux.HandleFunc("GET /v1/models", h.handleListModels)
+	return h, nil
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// handleListModels 返回所有已注册的模型名称
+func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(h.models))
+	for name := range h.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := &ModelList{Object: "list", Data: make([]*ModelInfo, 0, len(names))}
+	for _, name := range names {
+		list.Data = append(list.Data, &ModelInfo{ID: name, Object: "model", Created: h.created, OwnedBy: h.ownedBy})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleChatCompletions 处理对话补全请求
+func (h *Handler) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", fmt.Sprintf("请求体解析失败: %v", err))
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "messages 不能为空")
+		return
+	}
+
+	// 1. 按模型名称路由
+	modelName := req.Model
+	if modelName == "" {
+		modelName = h.defaultModel
+	}
+	runnable, ok := h.models[modelName]
+	if !ok {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", fmt.Sprintf("模型 %q 不存在", req.Model))
+		return
+	}
+
+	// 2. 转换消息和调用选项
+	messages, err := toSchemaMessages(req.Messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+	opts, err := toCallOptions(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+
+	id := newCompletionID()
+	if req.Stream {
+		h.streamCompletion(r.Context(), w, runnable, messages, opts, &req, id, modelName)
+		return
+	}
+
+	// 3. 非流式调用
+	out, err := runnable.Invoke(r.Context(), messages, opts...)
+	if err != nil {
+		log.Printf("[OpenAICompat] 模型 %s 调用失败: %v", modelName, err)
+		writeError(w, http.StatusInternalServerError, "server_error", "", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   modelName,
+		Choices: []*Choice{{
+			Index:        0,
+			Message:      fromSchemaMessage(out),
+			FinishReason: finishReason(out.ResponseMeta, len(out.ToolCalls) > 0),
+		}},
+		Usage: toUsage(out.ResponseMeta),
+	})
+}
+
+// streamCompletion 以 SSE 的形式输出 chat.completion.chunk，最后输出 data: [DONE]
+func (h *Handler) streamCompletion(ctx context.Context, w http.ResponseWriter, runnable Runnable,
+	messages []*schema.Message, opts []compose.Option, req *ChatCompletionRequest, id, modelName string) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "server_error", "", "当前连接不支持流式响应")
+		return
+	}
+
+	stream, err := runnable.Stream(ctx, messages, opts...)
+	if err != nil {
+		log.Printf("[OpenAICompat] 模型 %s 流式调用失败: %v", modelName, err)
+		writeError(w, http.StatusInternalServerError, "server_error", "", err.Error())
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	created := time.Now().Unix()
+	send := func(v any) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+	newChunk := func(delta *Delta, finish *string) *ChatCompletionChunk {
+		return &ChatCompletionChunk{
+			ID: id, Object: "chat.completion.chunk", Created: created, Model: modelName,
+			Choices: []*ChunkChoice{{Index: 0, Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	// 第一个块只包含角色
+	send(newChunk(&Delta{Role: string(schema.Assistant)}, nil))
+
+	var (
+		meta         *schema.ResponseMeta
+		hasToolCalls bool
+	)
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// 响应头已经发出，只能以 OpenAI 错误对象的形式写入流中
+			log.Printf("[OpenAICompat] 模型 %s 流式输出中断: %v", modelName, err)
+			send(&ErrorResponse{Error: &ErrorDetail{Message: err.Error(), Type: "server_error"}})
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			flusher.Flush()
+			return
+		}
+
+		if msg.ResponseMeta != nil {
+			meta = mergeResponseMeta(meta, msg.ResponseMeta)
+		}
+		if msg.Content == "" && len(msg.ToolCalls) == 0 {
+			continue
+		}
+		delta := &Delta{Content: msg.Content}
+		for i, tc := range msg.ToolCalls {
+			hasToolCalls = true
+			delta.ToolCalls = append(delta.ToolCalls, fromSchemaToolCall(tc, i))
+		}
+		send(newChunk(delta, nil))
+	}
+
+	reason := finishReason(meta, hasToolCalls)
+	send(newChunk(&Delta{}, &reason))
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		usage := toUsage(meta)
+		if usage == nil {
+			usage = &Usage{}
+		}
+		send(&ChatCompletionChunk{
+			ID: id, Object: "chat.completion.chunk", Created: created, Model: modelName,
+			Choices: []*ChunkChoice{}, Usage: usage,
+		})
+	}
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// ================================
+// 格式转换
+// ================================
+
+// toSchemaMessages 把 OpenAI 格式的消息转换为 schema.Message
+func toSchemaMessages(msgs []*Message) ([]*schema.Message, error) {
+	ret := make([]*schema.Message, 0, len(msgs))
+	for i, m := range msgs {
+		if m == nil {
+			return nil, fmt.Errorf("messages[%d] 不能为空", i)
+		}
+		msg := &schema.Message{Name: m.Name}
+		if m.Content != nil {
+			msg.Content = m.Content.Text
+		}
+
+		switch m.Role {
+		case "system", "developer":
+			msg.Role = schema.System
+		case "user":
+			msg.Role = schema.User
+		case "assistant":
+			msg.Role = schema.Assistant
+			for _, tc := range m.ToolCalls {
+				msg.ToolCalls = append(msg.ToolCalls, schema.ToolCall{
+					ID:       tc.ID,
+					Type:     "function",
+					Function: schema.FunctionCall{Name: tc.Function.Name, Arguments: tc.Function.Arguments},
+				})
+			}
+		case "tool":
+			if m.ToolCallID == "" {
+				return nil, fmt.Errorf("messages[%d]: tool 消息必须包含 tool_call_id", i)
+			}
+			msg.Role = schema.Tool
+			msg.ToolCallID = m.ToolCallID
+		default:
+			return nil, fmt.Errorf("messages[%d]: 不支持的角色 %q", i, m.Role)
+		}
+		ret = append(ret, msg)
+	}
+	return ret, nil
+}
+
+// toCallOptions 把请求中的采样参数和工具定义转换为 ChatModel 调用选项
+func toCallOptions(req *ChatCompletionRequest) ([]compose.Option, error) {
+	var modelOpts []model.Option
+	if req.Temperature != nil {
+		modelOpts = append(modelOpts, model.WithTemperature(*req.Temperature))
+	}
+	if req.TopP != nil {
+		modelOpts = append(modelOpts, model.WithTopP(*req.TopP))
+	}
+	if req.MaxTokens != nil {
+		modelOpts = append(modelOpts, model.WithMaxTokens(*req.MaxTokens))
+	}
+	if len(req.Stop) > 0 {
+		modelOpts = append(modelOpts, model.WithStop(req.Stop))
+	}
+
+	if len(req.Tools) > 0 {
+		infos := make([]*schema.ToolInfo, 0, len(req.Tools))
+		for i, t := range req.Tools {
+			if t == nil || t.Function == nil || t.Function.Name == "" {
+				return nil, fmt.Errorf("tools[%d] 缺少 function.name", i)
+			}
+			info := &schema.ToolInfo{Name: t.Function.Name, Desc: t.Function.Description}
+			if len(t.Function.Parameters) > 0 {
+				params := &openapi3.Schema{}
+				if err := json.Unmarshal(t.Function.Parameters, params); err != nil {
+					return nil, fmt.Errorf("tools[%d].function.parameters 不是有效的 JSON Schema: %v", i, err)
+				}
+				info.ParamsOneOf = schema.NewParamsOneOfByOpenAPIV3(params)
+			}
+			infos = append(infos, info)
+		}
+		modelOpts = append(modelOpts, model.WithTools(infos))
+	}
+
+	if len(modelOpts) == 0 {
+		return nil, nil
+	}
+	return []compose.Option{compose.WithChatModelOption(modelOpts...)}, nil
+}
+
+// fromSchemaMessage 把 Chain 的输出转换为 OpenAI 格式的消息
+func fromSchemaMessage(msg *schema.Message) *Message {
+	ret := &Message{Role: string(schema.Assistant)}
+	if msg.Content != "" || len(msg.ToolCalls) == 0 {
+		ret.Content = &Content{Text: msg.Content}
+	}
+	for i, tc := range msg.ToolCalls {
+		call := fromSchemaToolCall(tc, i)
+		call.Index = nil // 非流式响应中不需要 index
+		ret.ToolCalls = append(ret.ToolCalls, call)
+	}
+	return ret
+}
+
+// fromSchemaToolCall 转换工具调用，流式场景下优先使用模型给出的 index
+func fromSchemaToolCall(tc schema.ToolCall, pos int) *ToolCall {
+	index := pos
+	if tc.Index != nil {
+		index = *tc.Index
+	}
+	typ := tc.Type
+	if typ == "" && tc.ID != "" {
+		typ = "function"
+	}
+	return &ToolCall{
+		Index:    &index,
+		ID:       tc.ID,
+		Type:     typ,
+		Function: FunctionCall{Name: tc.Function.Name, Arguments: tc.Function.Arguments},
+	}
+}
+
+// finishReason 优先使用模型返回的结束原因，否则根据是否有工具调用推断
+func finishReason(meta *schema.ResponseMeta, hasToolCalls bool) string {
+	if meta != nil && meta.FinishReason != "" {
+		return meta.FinishReason
+	}
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	return "stop"
+}
+
+// mergeResponseMeta 合并流式块中的元信息，后出现的非空字段覆盖先出现的
+func mergeResponseMeta(dst, src *schema.ResponseMeta) *schema.ResponseMeta {
+	if dst == nil {
+		dst = &schema.ResponseMeta{}
+	}
+	if src.FinishReason != "" {
+		dst.FinishReason = src.FinishReason
+	}
+	if src.Usage != nil {
+		dst.Usage = src.Usage
+	}
+	return dst
+}
+
+// toUsage 转换 token 用量，底层模型未提供时返回 nil
+func toUsage(meta *schema.ResponseMeta) *Usage {
+	if meta == nil || meta.Usage == nil {
+		return nil
+	}
+	return &Usage{
+		PromptTokens:     meta.Usage.PromptTokens,
+		CompletionTokens: meta.Usage.CompletionTokens,
+		TotalTokens:      meta.Usage.TotalTokens,
+	}
+}
+
+// ================================
+// 辅助函数
+// ================================
+
+// newCompletionID 生成形如 chatcmpl-xxxx 的响应 ID
+func newCompletionID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("chatcmpl-%d", time.Now().UnixNano())
+	}
+	return "chatcmpl-" + hex.EncodeToString(b)
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[OpenAICompat] 写入响应失败: %v", err)
+	}
+}
+
+// writeError 写入 OpenAI 格式的错误响应
+func writeError(w http.ResponseWriter, status int, typ, code, message string) {
+	writeJSON(w, status, &ErrorResponse{Error: &ErrorDetail{Message: message, Type: typ, Code: code}})
+}
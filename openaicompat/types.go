@@ -0,0 +1,202 @@
+package openaicompat
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// =============================================================================
+//
+//  文件: openaicompat/types.go
+//  功能: OpenAI Chat Completions API 的请求 / 响应结构定义。
+//  说明: 只定义了本适配器用到的字段，未知字段在解析时会被忽略，
+//        保证 IDE 插件、评测工具等客户端发送的额外参数不会导致请求失败。
+//
+// =============================================================================
+
+// ChatCompletionRequest 对应 POST /v1/chat/completions 的请求体
+type ChatCompletionRequest struct {
+	Model         string          `json:"model"`
+	Messages      []*Message      `json:"messages"`
+	Stream        bool            `json:"stream,omitempty"`
+	StreamOptions *StreamOptions  `json:"stream_options,omitempty"`
+	Temperature   *float32        `json:"temperature,omitempty"`
+	TopP          *float32        `json:"top_p,omitempty"`
+	MaxTokens     *int            `json:"max_tokens,omitempty"`
+	Stop          StringOrStrings `json:"stop,omitempty"`
+	Tools         []*Tool         `json:"tools,omitempty"`
+}
+
+// StreamOptions 流式请求的附加选项
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"` // 为 true 时在 [DONE] 之前额外发送一个只包含 usage 的块
+}
+
+// Message 对话消息
+type Message struct {
+	Role       string      `json:"role"`
+	Content    *Content    `json:"content,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	ToolCalls  []*ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+}
+
+// Content 消息内容。OpenAI 允许内容是字符串，也允许是由多个片段组成的数组，
+// 这里统一解析为文本；非文本片段 (如图片) 会被忽略。
+type Content struct {
+	Text string
+}
+
+// UnmarshalJSON 同时支持字符串和片段数组两种格式
+func (c *Content) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &c.Text)
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return errors.New("content 必须是字符串或片段数组")
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.Type == "text" {
+			texts = append(texts, p.Text)
+		}
+	}
+	c.Text = strings.Join(texts, "\n")
+	return nil
+}
+
+// MarshalJSON 输出为字符串
+func (c *Content) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Text)
+}
+
+// StringOrStrings 兼容 stop 参数的字符串和字符串数组两种格式
+type StringOrStrings []string
+
+// UnmarshalJSON 同时支持 "a" 和 ["a", "b"]
+func (s *StringOrStrings) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var v string
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*s = []string{v}
+		return nil
+	}
+	var v []string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// Tool 请求中声明的工具
+type Tool struct {
+	Type     string        `json:"type"`
+	Function *FunctionSpec `json:"function"`
+}
+
+// FunctionSpec 函数工具的定义，parameters 为 JSON Schema
+type FunctionSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall 模型发起的工具调用
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"` // 仅在流式响应中使用
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall 工具调用的函数名和参数
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// Usage token 用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatCompletionResponse 非流式响应
+type ChatCompletionResponse struct {
+	ID      string    `json:"id"`
+	Object  string    `json:"object"` // 固定为 chat.completion
+	Created int64     `json:"created"`
+	Model   string    `json:"model"`
+	Choices []*Choice `json:"choices"`
+	Usage   *Usage    `json:"usage,omitempty"`
+}
+
+// Choice 非流式响应中的一个候选回答
+type Choice struct {
+	Index        int      `json:"index"`
+	Message      *Message `json:"message"`
+	FinishReason string   `json:"finish_reason"`
+}
+
+// ChatCompletionChunk 流式响应中的一个块
+type ChatCompletionChunk struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"` // 固定为 chat.completion.chunk
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []*ChunkChoice `json:"choices"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// ChunkChoice 流式响应块中的增量内容
+type ChunkChoice struct {
+	Index        int     `json:"index"`
+	Delta        *Delta  `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// Delta 增量消息
+type Delta struct {
+	Role      string      `json:"role,omitempty"`
+	Content   string      `json:"content,omitempty"`
+	ToolCalls []*ToolCall `json:"tool_calls,omitempty"`
+}
+
+// ModelList GET /v1/models 的响应
+type ModelList struct {
+	Object string       `json:"object"` // 固定为 list
+	Data   []*ModelInfo `json:"data"`
+}
+
+// ModelInfo 模型信息
+type ModelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"` // 固定为 model
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// ErrorResponse OpenAI 格式的错误响应
+type ErrorResponse struct {
+	Error *ErrorDetail `json:"error"`
+}
+
+// ErrorDetail 错误详情
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
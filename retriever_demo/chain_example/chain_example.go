@@ -15,11 +15,8 @@ import (
 	"github.com/spf13/viper"
 )
 
-// Run 是此包的入口函数，用于执行 RAG Chain 示例。
-func Run() {
-	ctx := context.Background()
-
-	// --- 1. 初始化所有组件 ---
+// newComponents 初始化 RAG Chain 所需的 Retriever 和 ChatModel
+func newComponents(ctx context.Context) (*milvus.Retriever, *ark.ChatModel, error) {
 	timeout := 30 * time.Second
 	embedderComponent, err := embedder.NewEmbedder(ctx, &embedder.EmbeddingConfig{
 		APIKey:  viper.GetString("ARK_API_KEY"),
@@ -27,14 +24,14 @@ func Run() {
 		Timeout: &timeout,
 	})
 	if err != nil {
-		log.Fatalf("创建 Embedder 失败: %v", err)
+		return nil, nil, fmt.Errorf("创建 Embedder 失败: %w", err)
 	}
 
 	client, err := cli.NewClient(ctx, cli.Config{
 		Address: viper.GetString("MILVUS_ADDRESS"),
 	})
 	if err != nil {
-		log.Fatalf("创建 Milvus 客户端失败: %v", err)
+		return nil, nil, fmt.Errorf("创建 Milvus 客户端失败: %w", err)
 	}
 
 	retrieverCfg := &milvus.RetrieverConfig{
@@ -45,7 +42,7 @@ func Run() {
 	}
 	retriever, err := milvus.NewRetriever(ctx, retrieverCfg)
 	if err != nil {
-		log.Fatalf("创建 Milvus Retriever 失败: %v", err)
+		return nil, nil, fmt.Errorf("创建 Milvus Retriever 失败: %w", err)
 	}
 
 	model, err := ark.NewChatModel(ctx, &ark.ChatModelConfig{
@@ -53,7 +50,19 @@ func Run() {
 		Model:  viper.GetString("ARK_MODEL"),
 	})
 	if err != nil {
-		log.Fatalf("创建 ChatModel 失败: %v", err)
+		return nil, nil, fmt.Errorf("创建 ChatModel 失败: %w", err)
+	}
+	return retriever, model, nil
+}
+
+// Run 是此包的入口函数，用于执行 RAG Chain 示例。
+func Run() {
+	ctx := context.Background()
+
+	// --- 1. 初始化所有组件 ---
+	retriever, model, err := newComponents(ctx)
+	if err != nil {
+		log.Fatal(err)
 	}
 	fmt.Println("所有 RAG 组件初始化成功！")
 
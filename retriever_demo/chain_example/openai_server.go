@@ -0,0 +1,117 @@
+package chain_example
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"Eini/openaicompat"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: retriever_demo/chain_example/openai_server.go
+//  功能: 把 RAG Chain 以 OpenAI 兼容接口的形式对外提供服务。
+//  说明: Run 中的 Chain 输入是单个 query 字符串，并通过闭包变量在节点间传递 query，
+//        不适合并发请求。这里构建一个以 []*schema.Message 为输入的 Chain:
+//        取最后一条用户消息作为检索 query，保留之前的对话历史，整个过程不依赖共享状态。
+//  模型路由:
+//    eino-rag   - 检索增强的 RAG Chain
+//    eino-chat  - 直接调用 ChatModel，不检索
+//
+// =============================================================================
+
+// BuildMessagesRAGChain 构建输入为对话消息、输出为回答消息的 RAG Chain
+func BuildMessagesRAGChain(ctx context.Context, r retriever.Retriever, cm model.BaseChatModel) (compose.Runnable[[]*schema.Message, *schema.Message], error) {
+	buildMessages := func(ctx context.Context, in []*schema.Message) ([]*schema.Message, error) {
+		// 取最后一条用户消息作为 query
+		var query string
+		for i := len(in) - 1; i >= 0; i-- {
+			if in[i].Role == schema.User {
+				query = in[i].Content
+				break
+			}
+		}
+		if query == "" {
+			return nil, fmt.Errorf("消息中没有用户问题")
+		}
+
+		docs, err := r.Retrieve(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("检索失败: %w", err)
+		}
+
+		system := "你是一个严谨的问答助手，请严格根据提供的背景知识回答。如果知识不足，请说明情况。"
+		if len(docs) > 0 {
+			var sb strings.Builder
+			sb.WriteString(system)
+			sb.WriteString("\n\n--- 背景知识 ---\n")
+			for i, doc := range docs {
+				sb.WriteString(fmt.Sprintf("[%d] %s\n", i+1, doc.Content))
+			}
+			system = sb.String()
+		} else {
+			system = "你是一个知识渊博的问答助手。背景知识库中没有与问题相关的信息，请直接回答问题。"
+		}
+
+		// 系统提示词 + 客户端发来的对话 (客户端自带的系统消息会被保留在后面)
+		out := make([]*schema.Message, 0, len(in)+1)
+		out = append(out, schema.SystemMessage(system))
+		out = append(out, in...)
+		return out, nil
+	}
+
+	chain := compose.NewChain[[]*schema.Message, *schema.Message]()
+	chain.AppendLambda(compose.InvokableLambda(buildMessages))
+	chain.AppendChatModel(cm)
+	return chain.Compile(ctx)
+}
+
+// BuildPlainChatChain 构建一个只包含 ChatModel 的 Chain，用于演示按模型名称路由
+func BuildPlainChatChain(ctx context.Context, cm model.BaseChatModel) (compose.Runnable[[]*schema.Message, *schema.Message], error) {
+	chain := compose.NewChain[[]*schema.Message, *schema.Message]()
+	chain.AppendChatModel(cm)
+	return chain.Compile(ctx)
+}
+
+// ServeOpenAI 启动 OpenAI 兼容的 HTTP 服务
+func ServeOpenAI(addr string) {
+	ctx := context.Background()
+
+	retriever, model, err := newComponents(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ragChain, err := BuildMessagesRAGChain(ctx, retriever, model)
+	if err != nil {
+		log.Fatalf("编译 RAG Chain 失败: %v", err)
+	}
+	chatChain, err := BuildPlainChatChain(ctx, model)
+	if err != nil {
+		log.Fatalf("编译 Chat Chain 失败: %v", err)
+	}
+
+	handler, err := openaicompat.NewHandler(&openaicompat.Config{
+		Models: map[string]openaicompat.Runnable{
+			"eino-rag":  ragChain,
+			"eino-chat": chatChain,
+		},
+		DefaultModel: "eino-rag",
+	})
+	if err != nil {
+		log.Fatalf("创建 OpenAI 兼容 Handler 失败: %v", err)
+	}
+
+	fmt.Printf("OpenAI 兼容服务已启动: http://%s/v1 (模型: eino-rag, eino-chat)\n", addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("HTTP 服务异常退出: %v", err)
+	}
+}
@@ -98,7 +98,7 @@ func main() {
 	}
 
 	// --- 选择要运行的示例 ---
-	exampleToRun := "rag" // 可选值: "standalone", "rag"
+	exampleToRun := "rag" // 可选值: "standalone", "rag", "openai"
 
 	switch exampleToRun {
 	case "standalone":
@@ -107,7 +107,10 @@ func main() {
 	case "rag":
 		fmt.Println("\n--- 正在运行: RAG Chain 示例 ---")
 		chain_example.Run()
+	case "openai":
+		fmt.Println("\n--- 正在运行: OpenAI 兼容服务示例 ---")
+		chain_example.ServeOpenAI("127.0.0.1:8081")
 	default:
-		fmt.Println("无效的示例名称。请在 main.go 中设置 exampleToRun 为 'standalone' 或 'rag'。")
+		fmt.Println("无效的示例名称。请在 main.go 中设置 exampleToRun 为 'standalone'、'rag' 或 'openai'。")
 	}
 }
@@ -7,7 +7,12 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"strings"
+	"unicode/utf8"
 
+	"Eini/tools/textutil"
+
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -55,7 +60,7 @@ func (c *CalculatorTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 
 // InvokableRun 是工具的核心执行逻辑。
 // 它接收一个包含参数的 JSON 字符串，执行相应的计算，并返回一个包含结果的 JSON 字符串。
-func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	// 定义一个匿名结构体来解析输入的 JSON 参数
 	var args struct {
 		Operation string  `json:"operation"`
@@ -113,35 +118,90 @@ func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON strin
 // --- 示例 2: 文本处理 Tool ---
 
 // TextProcessorTool 定义了一个用于处理文本的工具。
+// 所有操作都按 Unicode 字符 (rune) 而不是字节处理，中文等多字节文本也能得到正确结果；
+// 具体的文本处理逻辑见 tools/textutil。
 type TextProcessorTool struct{}
 
+// textActions 文本处理工具支持的动作
+var textActions = []string{
+	"uppercase", "lowercase", "length", "reverse", "count",
+	"to_fullwidth", "to_halfwidth", "to_traditional", "to_simplified", "pinyin", "slugify",
+	"regex_extract", "regex_replace", "diff",
+}
+
+// chineseConversions 简繁转换的地区变体 -> (简转繁, 繁转简) 的 OpenCC 配置
+var chineseConversions = map[string][2]string{
+	"tw":       {textutil.S2TW, textutil.TW2S},   // 台湾正体 (默认)
+	"twp":      {textutil.S2TWP, textutil.TW2SP}, // 台湾正体，同时转换常用词汇 (软件 <-> 軟體)
+	"hk":       {textutil.S2HK, textutil.HK2S},   // 香港繁体
+	"standard": {textutil.S2T, textutil.T2S},     // OpenCC 标准繁体
+}
+
 // Info 返回文本处理工具的元数据。
 func (t *TextProcessorTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 	return &schema.ToolInfo{
 		Name: "text_processor",
-		Desc: "处理文本，支持转换大小写、计算长度、反转字符串",
+		Desc: "处理文本: 大小写转换、长度、反转、字数统计、全角/半角转换、简繁转换、汉字转拼音、生成 slug、正则提取/替换、文本比较",
 		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
 			"action": {
 				Type:     "string",
 				Desc:     "处理动作",
 				Required: true,
-				Enum:     []string{"uppercase", "lowercase", "length", "reverse"},
+				Enum:     textActions,
 			},
 			"text": {
 				Type:     "string",
-				Desc:     "要处理的文本",
+				Desc:     "要处理的文本 (diff 时为原文)",
 				Required: true,
 			},
+			"pattern": {
+				Type: "string",
+				Desc: "正则表达式 (RE2 语法)，regex_extract / regex_replace 时必填",
+			},
+			"replacement": {
+				Type: "string",
+				Desc: "替换内容，可以用 $1、${name} 引用捕获组，regex_replace 时使用",
+			},
+			"other_text": {
+				Type: "string",
+				Desc: "要比较的新文本，diff 时必填",
+			},
+			"diff_mode": {
+				Type: "string",
+				Desc: "比较粒度，默认 line",
+				Enum: []string{textutil.DiffLine, textutil.DiffWord, textutil.DiffChar},
+			},
+			"pinyin_style": {
+				Type: "string",
+				Desc: "拼音风格: tone (zhōng)、number (zhong1) 或 plain (zhong)，默认 tone",
+				Enum: []string{textutil.PinyinTone, textutil.PinyinNumber, textutil.PinyinPlain},
+			},
+			"variant": {
+				Type: "string",
+				Desc: "简繁转换的繁体地区变体，默认 tw",
+				Enum: []string{"tw", "twp", "hk", "standard"},
+			},
+			"separator": {
+				Type: "string",
+				Desc: "slug 的分隔符，默认 -",
+			},
 		}),
 	}, nil
 }
 
 // InvokableRun 执行文本处理逻辑。
-func (t *TextProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (t *TextProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	// 定义用于解析参数的结构体
 	var args struct {
-		Action string `json:"action"`
-		Text   string `json:"text"`
+		Action      string `json:"action"`
+		Text        string `json:"text"`
+		Pattern     string `json:"pattern"`
+		Replacement string `json:"replacement"`
+		OtherText   string `json:"other_text"`
+		DiffMode    string `json:"diff_mode"`
+		PinyinStyle string `json:"pinyin_style"`
+		Variant     string `json:"variant"`
+		Separator   string `json:"separator"`
 	}
 
 	// 解析 JSON 参数
@@ -149,40 +209,54 @@ func (t *TextProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON st
 		return "", fmt.Errorf("参数解析失败: %v", err)
 	}
 
-	log.Printf("[TextProcessorTool] 处理文本: %s('%s')", args.Action, args.Text)
+	log.Printf("[TextProcessorTool] 处理文本: %s('%s')", args.Action, truncateRunes(args.Text, 30))
 
 	var result interface{} // 使用 interface{} 因为不同操作的返回结构不同
+	var err error
+
+	// 转换类的动作统一返回原文和结果
+	converted := func(s string) map[string]string {
+		return map[string]string{"original": args.Text, "result": s}
+	}
 
 	// 根据 'action' 参数执行不同的文本处理
 	switch args.Action {
 	case "uppercase":
-		result = map[string]string{
-			"original": args.Text,
-			"result":   fmt.Sprintf("%s", args.Text), // 转换为大写
-		}
+		result = converted(strings.ToUpper(args.Text))
 	case "lowercase":
-		result = map[string]string{
-			"original": args.Text,
-			"result":   fmt.Sprintf("%s", args.Text), // 转换为小写
-		}
+		result = converted(strings.ToLower(args.Text))
 	case "length":
 		result = map[string]interface{}{
 			"text":   args.Text,
-			"length": len(args.Text), // 计算字符串长度
+			"length": utf8.RuneCountInString(args.Text), // 字符数，"你好" 为 2
+			"bytes":  len(args.Text),                    // UTF-8 字节数，"你好" 为 6
 		}
 	case "reverse":
-		// 反转字符串（支持 Unicode）
-		runes := []rune(args.Text)
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		result = map[string]string{
-			"original": args.Text,
-			"result":   string(runes),
-		}
+		result = converted(textutil.Reverse(args.Text))
+	case "count":
+		result = textutil.Count(args.Text)
+	case "to_fullwidth":
+		result = converted(textutil.ToFullWidth(args.Text))
+	case "to_halfwidth":
+		result = converted(textutil.ToHalfWidth(args.Text))
+	case "to_traditional", "to_simplified":
+		result, err = convertChinese(args.Text, args.Action, args.Variant)
+	case "pinyin":
+		result, err = textutil.Pinyin(args.Text, args.PinyinStyle)
+	case "slugify":
+		result = converted(textutil.Slugify(args.Text, args.Separator))
+	case "regex_extract":
+		result, err = textutil.Extract(args.Text, args.Pattern, 0)
+	case "regex_replace":
+		result, err = textutil.Replace(args.Text, args.Pattern, args.Replacement)
+	case "diff":
+		result, err = textutil.Diff(args.Text, args.OtherText, args.DiffMode)
 	default:
 		return "", fmt.Errorf("不支持的动作: %s", args.Action)
 	}
+	if err != nil {
+		return "", err
+	}
 
 	// 将结果序列化为 JSON 并返回
 	responseBytes, err := json.Marshal(result)
@@ -193,6 +267,35 @@ func (t *TextProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON st
 	return string(responseBytes), nil
 }
 
+// convertChinese 按地区变体进行简繁转换
+func convertChinese(text, action, variant string) (map[string]string, error) {
+	if variant == "" {
+		variant = "tw"
+	}
+	conversions, ok := chineseConversions[variant]
+	if !ok {
+		return nil, fmt.Errorf("不支持的繁体地区变体: %s", variant)
+	}
+	conversion := conversions[0]
+	if action == "to_simplified" {
+		conversion = conversions[1]
+	}
+	converted, err := textutil.ConvertChinese(text, conversion)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"original": text, "result": converted, "conversion": conversion}, nil
+}
+
+// truncateRunes 按字符截断文本，用于日志输出
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
 // --- 示例 3: 数学函数 Tool ---
 
 // MathTool 定义了一个用于执行高级数学函数的工具。
@@ -220,7 +323,7 @@ func (m *MathTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 // InvokableRun 执行数学函数计算。
-func (m *MathTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (m *MathTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	// 定义用于解析参数的结构体
 	var args struct {
 		Function string  `json:"function"`
@@ -314,12 +417,28 @@ func demonstrateBasicTools() {
 	fmt.Printf("工具名称: %s\n", textInfo.Name)
 	fmt.Printf("工具描述: %s\n", textInfo.Desc)
 
-	// 调用并传入 "reverse" 动作
-	textResult, err := textProcessor.InvokableRun(ctx, `{"action":"reverse","text":"Hello World"}`)
-	if err != nil {
-		log.Printf("文本处理执行失败: %v", err)
-	} else {
-		fmt.Printf("处理结果: %s\n", textResult)
+	// 依次调用各个动作，中文文本按字符而不是字节处理
+	for _, args := range []string{
+		`{"action":"uppercase","text":"Hello World, 你好"}`,
+		`{"action":"length","text":"你好，Eino"}`,
+		`{"action":"reverse","text":"Hello 世界"}`,
+		`{"action":"count","text":"Eino 是一个 LLM 应用开发框架。It's written in Go! 支持 3.14 这样的数字吗？\n\n第二段。"}`,
+		`{"action":"to_halfwidth","text":"ＡＢＣ　１２３，全角！"}`,
+		`{"action":"to_traditional","text":"软件开发的头发问题"}`,
+		`{"action":"to_simplified","text":"軟體開發的頭髮問題","variant":"twp"}`,
+		`{"action":"pinyin","text":"重庆银行","pinyin_style":"tone"}`,
+		`{"action":"slugify","text":"Eino 工具使用指南：Café & Crème!"}`,
+		`{"action":"regex_extract","text":"订单 A-001 金额 ¥128，订单 B-017 金额 ¥64","pattern":"(?P<id>[A-Z]-\\d+) 金额 ¥(\\d+)"}`,
+		`{"action":"regex_replace","text":"会议在 2024-08-19 和 2024-09-01","pattern":"(\\d{4})-(\\d{2})-(\\d{2})","replacement":"$1年$2月$3日"}`,
+		`{"action":"diff","text":"今天天气很好，我们去公园散步。","other_text":"今天天气不错，我们去河边散步吧。","diff_mode":"word"}`,
+		`{"action":"diff","text":"第一行\n第二行\n第三行\n","other_text":"第一行\n第二行（已修改）\n第三行\n第四行\n"}`,
+	} {
+		textResult, err := textProcessor.InvokableRun(ctx, args)
+		if err != nil {
+			log.Printf("文本处理执行失败: %v", err)
+		} else {
+			fmt.Printf("处理结果: %s\n", textResult)
+		}
 	}
 
 	// 4. 演示数学函数工具
@@ -0,0 +1,133 @@
+// package main 表明这是一个可执行程序
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"Eini/tools/codeeval"
+)
+
+// =============================================================================
+//
+//  文件: codeeval_example.go
+//  功能: 演示 tools/codeeval 提供的 code_eval 工具: 在沙箱中执行 JavaScript，
+//        处理输入数据、捕获 stdout、返回 JSON 结果，以及沙箱的隔离和资源限制。
+//  说明: 解释器是纯 Go 实现的 goja，不需要安装 Node.js。
+//
+// =============================================================================
+
+// run 执行一段代码并打印结果
+func run(codeTool *codeeval.Tool, code string, input any) error {
+	args := map[string]any{"code": code}
+	if input != nil {
+		args["input"] = input
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	output, err := codeTool.InvokableRun(context.Background(), string(data))
+	if err != nil {
+		return err
+	}
+	var result codeeval.Result
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", code)
+	if result.Stdout != "" {
+		fmt.Printf("  stdout: %q (截断: %v)\n", result.Stdout, result.StdoutTruncated)
+	}
+	if result.Error != "" {
+		fmt.Printf("  错误: %s\n", result.Error)
+	} else if result.Result != nil {
+		fmt.Printf("  结果: %s\n", result.Result)
+	}
+	fmt.Printf("  耗时: %dms\n", result.ElapsedMS)
+	return nil
+}
+
+// demonstrateCodeEval 依次演示计算、数据处理、错误、沙箱和资源限制
+func demonstrateCodeEval() error {
+	codeTool := codeeval.New(&codeeval.Config{Timeout: time.Second, MaxOutputBytes: 200})
+	info, _ := codeTool.Info(context.Background())
+	fmt.Printf("工具: %s\n描述: %s\n", info.Name, info.Desc)
+
+	// 1. 计算器只能计算单个二元运算，多步计算交给脚本
+	fmt.Println("\n=== 1. 多步计算 ===")
+	steps := []string{
+		"const rate = 0.035 / 12; const n = 360; const payment = 1000000 * rate / (1 - Math.pow(1 + rate, -n)); ({monthly: Math.round(payment * 100) / 100, interest: Math.round(payment * n - 1000000)})",
+		"const d = (a, b) => Math.round((new Date(b) - new Date(a)) / 86400000); d('2024-01-01', '2024-12-25')",
+		"let a = 0n, b = 1n; for (let i = 0; i < 100; i++) [a, b] = [b, a + b]; a.toString()",
+	}
+	for _, code := range steps {
+		if err := run(codeTool, code, nil); err != nil {
+			return err
+		}
+	}
+
+	// 2. 通过 input 传入数据，console.log 的输出作为 stdout 返回，顶层 return 的值作为结果
+	fmt.Println("\n=== 2. 数据处理 ===")
+	orders := map[string]any{"orders": []map[string]any{
+		{"id": 1, "city": "北京", "amount": 199.0, "status": "paid"},
+		{"id": 2, "city": "上海", "amount": 89.5, "status": "cancelled"},
+		{"id": 3, "city": "北京", "amount": 45.0, "status": "paid"},
+		{"id": 4, "city": "深圳", "amount": 320.0, "status": "paid"},
+		{"id": 5, "city": "上海", "amount": 12.8, "status": "paid"},
+	}}
+	code := `const paid = input.orders.filter(o => o.status === "paid");
+console.log("有效订单", paid.length, "笔");
+const byCity = {};
+for (const o of paid) byCity[o.city] = (byCity[o.city] || 0) + o.amount;
+return Object.entries(byCity).sort((a, b) => b[1] - a[1]).map(([city, total]) => ({city, total}));`
+	if err := run(codeTool, code, orders); err != nil {
+		return err
+	}
+	if err := run(codeTool, `for (let i = 0; i < 100; i++) console.log("第", i, "行")`, nil); err != nil {
+		return err
+	}
+
+	// 3. 错误作为结果返回给模型修正，包含错误在代码中的位置
+	fmt.Println("\n=== 3. 错误 ===")
+	for _, code := range []string{
+		"const total = ;",
+		"const items = input.items;\nitems.map(x => x.price)",
+		"const o = {}; o.self = o; o",
+	} {
+		if err := run(codeTool, code, nil); err != nil {
+			return err
+		}
+	}
+
+	// 4. 沙箱: 没有文件系统、网络、模块和定时器，也没有可以一次分配大块内存的二进制数据类型
+	fmt.Println("\n=== 4. 沙箱 ===")
+	if err := run(codeTool, "[typeof require, typeof process, typeof fetch, typeof setTimeout, typeof ArrayBuffer]", nil); err != nil {
+		return err
+	}
+
+	// 5. 资源限制: 执行时间、内存、调用深度以及内置函数处理的数组和字符串长度
+	fmt.Println("\n=== 5. 资源限制 ===")
+	for _, code := range []string{
+		"while (true) {}",
+		"const cache = {}; for (let i = 0; ; i++) cache['k' + i] = 'v'.repeat(1000) + i",
+		"function f(n) { return f(n + 1) } f(0)",
+		"new Array(1e8).fill(0).length",
+		"'x'.repeat(2 ** 30).length",
+	} {
+		if err := run(codeTool, code, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// main 是程序的入口点。
+func main() {
+	if err := demonstrateCodeEval(); err != nil {
+		log.Fatalf("code_eval 示例失败: %v", err)
+	}
+}
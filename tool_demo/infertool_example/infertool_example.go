@@ -3,10 +3,13 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"log"
+	"math"
 	"net/mail"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -84,9 +87,10 @@ func createUser(ctx context.Context, req *CreateUserRequest) (*CreateUserRespons
 // 它包含了一个嵌套的结构体切片 `[]OrderItem`。
 type CalculateOrderRequest struct {
 	Items       []OrderItem `json:"items" jsonschema:"required,description=订单商品列表"`
-	CouponCode  string      `json:"coupon_code" jsonschema:"description=优惠券代码"`
-	ShippingFee float64     `json:"shipping_fee" jsonschema:"minimum=0,description=运费,default=10"`
-	TaxRate     float64     `json:"tax_rate" jsonschema:"minimum=0,maximum=1,description=税率,default=0.1"`
+	CouponCode  string      `json:"coupon_code,omitempty" jsonschema:"description=优惠券代码"`
+	ShippingFee float64     `json:"shipping_fee,omitempty" jsonschema:"minimum=0,description=运费"`
+	TaxRate     float64     `json:"tax_rate,omitempty" jsonschema:"minimum=0,maximum=1,description=统一税率，不填时按促销规则文件中的类目税率计算"`
+	Confirm     bool        `json:"confirm,omitempty" jsonschema:"description=是否确认下单，确认后记录优惠的使用次数"`
 }
 
 // OrderItem 定义了订单中的单个商品项。
@@ -96,63 +100,47 @@ type OrderItem struct {
 	Name      string  `json:"name" jsonschema:"required,description=商品名称"`
 	Price     float64 `json:"price" jsonschema:"required,minimum=0,description=单价"`
 	Quantity  int     `json:"quantity" jsonschema:"required,minimum=1,description=数量"`
+	Category  string  `json:"category,omitempty" jsonschema:"description=商品类目，用于类目促销和类目税率"`
 }
 
 // CalculateOrderResponse 定义了计算订单价格工具的输出。
 type CalculateOrderResponse struct {
-	SubTotal      float64     `json:"sub_total"`      // 商品总价
-	Discount      float64     `json:"discount"`       // 折扣金额
-	ShippingFee   float64     `json:"shipping_fee"`   // 运费
-	Tax           float64     `json:"tax"`            // 税费
-	Total         float64     `json:"total"`          // 最终总价
-	Items         []OrderItem `json:"items"`          // 订单项详情
-	CouponApplied bool        `json:"coupon_applied"` // 是否成功应用优惠券
+	SubTotal      float64       `json:"sub_total"`      // 商品总价
+	Discount      float64       `json:"discount"`       // 商品折扣金额 (不含运费减免)
+	ShippingFee   float64       `json:"shipping_fee"`   // 减免后的运费
+	Tax           float64       `json:"tax"`            // 税费
+	Total         float64       `json:"total"`          // 最终总价
+	Items         []OrderItem   `json:"items"`          // 订单项详情
+	CouponApplied bool          `json:"coupon_applied"` // 是否成功应用优惠券
+	AppliedRules  []AppliedRule `json:"applied_rules"`  // 生效的促销规则
+	SkippedRules  []SkippedRule `json:"skipped_rules"`  // 未生效的促销规则及原因
+	Explanation   []string      `json:"explanation"`    // 逐行的计算说明
 }
 
-// calculateOrder 是计算订单总价的业务函数。
-func calculateOrder(ctx context.Context, req *CalculateOrderRequest) (*CalculateOrderResponse, error) {
-	log.Printf("[CalculateOrder] 正在计算订单，商品数量: %d", len(req.Items))
-
-	// 计算商品小计
-	var subTotal float64
-	for _, item := range req.Items {
-		subTotal += item.Price * float64(item.Quantity)
-	}
-
-	// 应用简单的优惠券逻辑
-	var discount float64
-	couponApplied := false
-	if req.CouponCode == "SAVE10" { // 假设 "SAVE10" 是一个有效的优惠券
-		discount = subTotal * 0.1
-		couponApplied = true
-	}
-
-	// 计算税费和总价
-	taxableAmount := subTotal - discount + req.ShippingFee
-	tax := taxableAmount * req.TaxRate
-	total := taxableAmount + tax
-
-	// 返回包含所有计算细节的响应
-	return &CalculateOrderResponse{
-		SubTotal:      subTotal,
-		Discount:      discount,
-		ShippingFee:   req.ShippingFee,
-		Tax:           tax,
-		Total:         total,
-		Items:         req.Items,
-		CouponApplied: couponApplied,
-	}, nil
-}
+// defaultPromotionRules 是示例使用的促销规则，实际使用时可以通过 LoadPromotionRulesFile 从配置文件加载。
+// 计算逻辑见 promotions.go 中的 PromotionEngine.CalculateOrder。
+//
+//go:embed promotions.yaml
+var defaultPromotionRules []byte
 
 // --- 示例 3: 数据分析工具 ---
 
+// defaultPercentiles percentiles 操作默认计算的百分位
+var defaultPercentiles = []float64{25, 50, 75, 90, 95, 99}
+
 // AnalyzeDataRequest 定义了数据分析工具的输入。
 // `items={enum=[...]}` 用于定义字符串切片中每个元素的有效值。
+// 可选字段带有 `omitempty`，推断出的 schema 中不会被标记为必填；
+// Precision 使用指针类型，以便区分"未传入 (使用默认值 2)"和"传入 0"。
 type AnalyzeDataRequest struct {
-	Dataset      []float64 `json:"dataset" jsonschema:"required,description=要分析的数据集"`
-	Operations   []string  `json:"operations" jsonschema:"required,description=分析操作列表,items={enum=[mean,median,mode,std,min,max]}"`
-	Precision    int       `json:"precision" jsonschema:"minimum=0,maximum=10,description=小数点精度,default=2"`
-	IncludeChart bool      `json:"include_chart" jsonschema:"description=是否包含图表信息,default=false"`
+	Dataset       []float64 `json:"dataset" jsonschema:"required,description=要分析的数据集"`
+	PairedDataset []float64 `json:"paired_dataset,omitempty" jsonschema:"description=与 dataset 逐项对应的第二组数据 (如 dataset 为广告投入、paired_dataset 为销售额)，用于 correlation 和 regression"`
+	Operations    []string  `json:"operations" jsonschema:"required,description=分析操作列表,items={enum=[mean,median,mode,variance,std,min,max,sum,percentiles,outliers,histogram,correlation,regression]}"`
+	Percentiles   []float64 `json:"percentiles,omitempty" jsonschema:"description=percentiles 操作计算的百分位 (0 到 100)，默认 25、50、75、90、95、99"`
+	Bins          int       `json:"bins,omitempty" jsonschema:"minimum=0,maximum=50,description=histogram 操作的区间数，0 表示自动选择"`
+	Population    bool      `json:"population,omitempty" jsonschema:"description=variance 和 std 是否按总体计算 (除以 n)，默认按样本计算 (除以 n-1),default=false"`
+	Precision     *int      `json:"precision,omitempty" jsonschema:"minimum=0,maximum=10,description=小数点精度,default=2"`
+	IncludeChart  bool      `json:"include_chart,omitempty" jsonschema:"description=是否包含图表信息,default=false"`
 }
 
 // AnalyzeDataResponse 定义了数据分析工具的输出，包含多个嵌套结构。
@@ -165,75 +153,143 @@ type AnalyzeDataResponse struct {
 // DataSummary 提供了数据集的摘要统计。
 type DataSummary struct {
 	Count    int     `json:"count"`
+	Distinct int     `json:"distinct"` // 不同取值的数量
 	Min      float64 `json:"min"`
 	Max      float64 `json:"max"`
 	Range    float64 `json:"range"`
-	DataType string  `json:"data_type"`
+	DataType string  `json:"data_type"` // integer (全部为整数) 或 numerical
 }
 
-// ChartInfo 包含了用于生成图表的建议信息。
+// ChartInfo 包含了根据数据形态推荐的图表类型和绘图数据 (见 recommendChart)。
 type ChartInfo struct {
-	RecommendedType string    `json:"recommended_type"` // 建议的图表类型
-	XAxis           []string  `json:"x_axis"`           // X轴数据
-	YAxis           []float64 `json:"y_axis"`           // Y轴数据
+	RecommendedType string             `json:"recommended_type"`   // 建议的图表类型: line / scatter / bar / histogram / box_plot
+	Reason          string             `json:"reason"`             // 推荐理由
+	XAxis           []string           `json:"x_axis,omitempty"`   // X轴数据
+	YAxis           []float64          `json:"y_axis,omitempty"`   // Y轴数据
+	BoxPlot         *FiveNumberSummary `json:"box_plot,omitempty"` // 箱线图的五数概括
 }
 
 // analyzeData 是执行数据分析的业务函数。
 func analyzeData(ctx context.Context, req *AnalyzeDataRequest) (*AnalyzeDataResponse, error) {
-	log.Printf("[AnalyzeData] 正在分析数据集，数据点数量: %d", len(req.Dataset))
+	log.Printf("[AnalyzeData] 正在分析数据集，数据点数量: %d，操作: %v", len(req.Dataset), req.Operations)
 
 	if len(req.Dataset) == 0 {
 		return nil, fmt.Errorf("数据集不能为空") // 对于系统级错误，返回 non-nil error
 	}
+	if len(req.PairedDataset) > 0 && len(req.PairedDataset) != len(req.Dataset) {
+		return nil, fmt.Errorf("paired_dataset 的长度 (%d) 必须与 dataset (%d) 相同", len(req.PairedDataset), len(req.Dataset))
+	}
+	precision := 2
+	if req.Precision != nil {
+		precision = *req.Precision
+	}
+	round := func(v float64) float64 { return roundTo(v, precision) }
 
+	data := req.Dataset
+	sorted := sortedCopy(data)
 	results := make(map[string]interface{})
 
 	// 执行请求的分析操作
 	for _, op := range req.Operations {
 		switch op {
 		case "mean":
-			sum := 0.0
-			for _, v := range req.Dataset {
-				sum += v
-			}
-			results[op] = roundTo(sum/float64(len(req.Dataset)), req.Precision)
+			results[op] = round(mean(data))
+		case "median":
+			results[op] = round(percentile(sorted, 50))
+		case "mode":
+			results[op] = modes(data)
+		case "variance":
+			results[op] = round(variance(data, !req.Population))
+		case "std":
+			results[op] = round(math.Sqrt(variance(data, !req.Population)))
 		case "min":
-			min := req.Dataset[0]
-			for _, v := range req.Dataset[1:] {
-				if v < min {
-					min = v
-				}
-			}
-			results[op] = min
+			results[op] = sorted[0]
 		case "max":
-			max := req.Dataset[0]
-			for _, v := range req.Dataset[1:] {
-				if v > max {
-					max = v
+			results[op] = sorted[len(sorted)-1]
+		case "sum":
+			results[op] = round(sum(data))
+		case "percentiles":
+			ps := req.Percentiles
+			if len(ps) == 0 {
+				ps = defaultPercentiles
+			}
+			values := make(map[string]float64, len(ps))
+			for _, p := range ps {
+				if p < 0 || p > 100 {
+					return nil, fmt.Errorf("百分位必须在 0 到 100 之间: %v", p)
 				}
+				values["p"+strconv.FormatFloat(p, 'f', -1, 64)] = round(percentile(sorted, p))
+			}
+			results[op] = values
+		case "outliers":
+			report := iqrOutliers(data)
+			report.Q1, report.Q3, report.IQR = round(report.Q1), round(report.Q3), round(report.IQR)
+			report.LowerFence, report.UpperFence = round(report.LowerFence), round(report.UpperFence)
+			results[op] = report
+		case "histogram":
+			bins := req.Bins
+			switch {
+			case bins > maxHistogramBins:
+				// InferTool 不会校验 jsonschema 标签中的 maximum，需要在这里检查
+				return nil, fmt.Errorf("区间数不能超过 %d: %d", maxHistogramBins, bins)
+			case bins <= 0:
+				bins = histogramBinCount(data)
+			}
+			hist := histogram(data, bins)
+			for i := range hist {
+				hist[i].Lower, hist[i].Upper = round(hist[i].Lower), round(hist[i].Upper)
+			}
+			results[op] = hist
+		case "correlation":
+			if len(req.PairedDataset) == 0 {
+				return nil, fmt.Errorf("correlation 需要提供 paired_dataset")
+			}
+			r, err := pearson(data, req.PairedDataset)
+			if err != nil {
+				return nil, err
+			}
+			results[op] = CorrelationResult{Pearson: round(r), RSquared: round(r * r), Description: describeCorrelation(r)}
+		case "regression":
+			// 没有配对数据时以序号为自变量，斜率即每个数据点的平均变化量
+			xs, ys, x := indexes(len(data)), data, "index"
+			if len(req.PairedDataset) > 0 {
+				xs, ys, x = data, req.PairedDataset, "dataset"
+			}
+			slope, intercept, r2, err := linearRegression(xs, ys)
+			if err != nil {
+				return nil, err
+			}
+			sign := "+"
+			if intercept < 0 {
+				sign = "-"
 			}
-			results[op] = max
-			// 其他操作（median, mode, std）在此省略以保持示例简洁
+			results[op] = RegressionResult{
+				Slope:     round(slope),
+				Intercept: round(intercept),
+				RSquared:  round(r2),
+				X:         x,
+				Equation:  fmt.Sprintf("y = %s * %s %s %s", formatValue(round(slope)), x, sign, formatValue(math.Abs(round(intercept)))),
+			}
+		default:
+			return nil, fmt.Errorf("不支持的分析操作: %s", op)
 		}
 	}
 
 	// 生成数据摘要
-	min, max := req.Dataset[0], req.Dataset[0]
-	for _, v := range req.Dataset {
-		if v < min {
-			min = v
-		}
-		if v > max {
-			max = v
+	dataType := "integer"
+	for _, v := range data {
+		if v != math.Trunc(v) {
+			dataType = "numerical"
+			break
 		}
 	}
-
 	summary := DataSummary{
-		Count:    len(req.Dataset),
-		Min:      min,
-		Max:      max,
-		Range:    max - min,
-		DataType: "numerical",
+		Count:    len(data),
+		Distinct: len(uniqueSorted(data)),
+		Min:      sorted[0],
+		Max:      sorted[len(sorted)-1],
+		Range:    round(sorted[len(sorted)-1] - sorted[0]),
+		DataType: dataType,
 	}
 
 	response := &AnalyzeDataResponse{
@@ -241,18 +297,9 @@ func analyzeData(ctx context.Context, req *AnalyzeDataRequest) (*AnalyzeDataResp
 		DataSummary: summary,
 	}
 
-	// 如果请求包含图表信息，则生成它
+	// 如果请求包含图表信息，则根据数据形态推荐图表类型
 	if req.IncludeChart {
-		xAxis := make([]string, len(req.Dataset))
-		for i := range req.Dataset {
-			xAxis[i] = fmt.Sprintf("Point %d", i+1)
-		}
-
-		response.ChartInfo = &ChartInfo{
-			RecommendedType: "line", // 推荐使用折线图
-			XAxis:           xAxis,
-			YAxis:           req.Dataset,
-		}
+		response.ChartInfo = recommendChart(data, req.PairedDataset)
 	}
 
 	return response, nil
@@ -349,13 +396,10 @@ func processText(ctx context.Context, req *ProcessTextRequest) (*ProcessTextResp
 
 // --- 辅助函数 ---
 
-// roundTo 将浮点数四舍五入到指定的小数位数。
+// roundTo 将浮点数四舍五入到指定的小数位数 (负数同样远离零舍入)。
 func roundTo(value float64, precision int) float64 {
-	factor := 1.0
-	for i := 0; i < precision; i++ {
-		factor *= 10
-	}
-	return float64(int(value*factor+0.5)) / factor
+	factor := math.Pow10(precision)
+	return math.Round(value*factor) / factor
 }
 
 // --- 演示函数 ---
@@ -395,14 +439,19 @@ func demonstrateInferTool() {
 
 	// 2. 使用 InferTool 创建订单计算工具
 	fmt.Println("--- 2. 创建订单计算工具 ---")
-	orderTool, err := utils.InferTool("calculate_order", "根据商品列表、优惠券和运费计算订单总价", calculateOrder)
+	// 促销规则从 YAML 加载，工具函数可以是引擎的方法
+	promotions, err := LoadPromotionRules(defaultPromotionRules, "yaml")
+	if err != nil {
+		log.Fatalf("加载促销规则失败: %v", err)
+	}
+	orderTool, err := utils.InferTool("calculate_order", "根据商品列表、优惠券和运费计算订单总价，应用促销规则并逐行说明计算过程", promotions.CalculateOrder)
 	if err != nil {
 		log.Fatalf("创建订单工具失败: %v", err)
 	}
 	orderResult, err := orderTool.InvokableRun(ctx, `{
 		"items": [
-			{"product_id": "P001", "name": "苹果", "price": 5.99, "quantity": 3},
-			{"product_id": "P002", "name": "香蕉", "price": 3.99, "quantity": 2}
+			{"product_id": "P001", "name": "苹果", "price": 5.99, "quantity": 3, "category": "fruit"},
+			{"product_id": "P002", "name": "香蕉", "price": 3.99, "quantity": 2, "category": "fruit"}
 		],
 		"coupon_code": "SAVE10",
 		"shipping_fee": 15.0,
@@ -414,6 +463,29 @@ func demonstrateInferTool() {
 		fmt.Printf("计算结果: %s\n\n", orderResult)
 	}
 
+	// 满减、类目税率和独享优惠券: NEW50 不与其他优惠同享，引擎选择优惠更多的方案
+	for _, coupon := range []string{"", "NEW50"} {
+		req := &CalculateOrderRequest{
+			Items: []OrderItem{
+				{ProductID: "P101", Name: "蓝牙耳机", Price: 199, Quantity: 1, Category: "electronics"},
+				{ProductID: "P001", Name: "苹果", Price: 5.99, Quantity: 5, Category: "fruit"},
+				{ProductID: "P201", Name: "T恤", Price: 79, Quantity: 2, Category: "clothing"},
+			},
+			CouponCode:  coupon,
+			ShippingFee: 12,
+		}
+		resp, err := promotions.CalculateOrder(ctx, req)
+		if err != nil {
+			log.Printf("订单计算失败: %v", err)
+			continue
+		}
+		fmt.Printf("优惠券 %q 的计算说明:\n", coupon)
+		for _, line := range resp.Explanation {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
+
 	// 3. 使用 InferTool 创建数据分析工具
 	fmt.Println("--- 3. 创建数据分析工具 ---")
 	analysisTool, err := utils.InferTool("analyze_data", "对给定的数值数据集进行统计分析", analyzeData)
@@ -421,8 +493,9 @@ func demonstrateInferTool() {
 		log.Fatalf("创建分析工具失败: %v", err)
 	}
 	analysisResult, err := analysisTool.InvokableRun(ctx, `{
-		"dataset": [1.2, 3.4, 5.6, 7.8, 9.0, 2.1, 4.3, 6.5],
-		"operations": ["mean", "min", "max"],
+		"dataset": [1.2, 3.4, 5.6, 7.8, 9.0, 2.1, 4.3, 6.5, 48.0],
+		"operations": ["mean", "median", "mode", "std", "min", "max", "percentiles", "outliers", "histogram", "regression"],
+		"percentiles": [10, 50, 90],
 		"precision": 3,
 		"include_chart": true
 	}`)
@@ -432,6 +505,19 @@ func demonstrateInferTool() {
 		fmt.Printf("分析结果: %s\n\n", analysisResult)
 	}
 
+	// 提供配对数据时可以计算相关系数和线性回归，图表推荐为散点图
+	pairedResult, err := analysisTool.InvokableRun(ctx, `{
+		"dataset": [10, 15, 20, 25, 30, 35],
+		"paired_dataset": [120, 150, 185, 210, 248, 270],
+		"operations": ["correlation", "regression"],
+		"include_chart": true
+	}`)
+	if err != nil {
+		log.Printf("分析工具执行失败: %v", err)
+	} else {
+		fmt.Printf("相关性分析结果: %s\n\n", pairedResult)
+	}
+
 	// 4. 使用 InferTool 创建文本处理工具
 	fmt.Println("--- 4. 创建文本处理工具 ---")
 	textTool, err := utils.InferTool("process_text", "对输入文本进行多种处理和分析", processText)
@@ -0,0 +1,623 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+//
+//  文件: promotions.go
+//  功能: 订单计算工具使用的促销规则引擎，规则从 YAML / JSON 定义中加载。
+//  规则类型:
+//    - percentage:    按比例折扣，value 为折扣百分比 (10 表示减 10%)
+//    - fixed:         立减 value 元，per_unit 为 true 时每件立减
+//    - threshold:     满减，满 min_amount 减 value，repeat 为 true 时每满 min_amount 减 value
+//    - free_shipping: 免运费
+//  适用范围 (scope): order (整单)、item (product_ids 中的商品)、category (categories 中的类目)
+//  叠加规则:
+//    - 规则按 priority 从高到低依次计算，后面的规则基于前面规则折扣后的金额
+//      (min_amount 门槛同样按折扣后的金额判断)
+//    - 同一 group 中只有优先级最高的可用规则生效
+//    - exclusive 规则不与其他规则叠加: 引擎分别计算"单独使用独享规则"和"叠加使用其他规则"，
+//      选择优惠更多的方案
+//  其他限制: 需要优惠券的规则 (coupon)、有效期 (starts_at / expires_at)、总使用次数 (usage_limit)
+//
+// =============================================================================
+
+// 规则类型
+const (
+	RuleTypePercentage   = "percentage"
+	RuleTypeFixed        = "fixed"
+	RuleTypeThreshold    = "threshold"
+	RuleTypeFreeShipping = "free_shipping"
+)
+
+// 规则适用范围
+const (
+	ScopeOrder    = "order"
+	ScopeItem     = "item"
+	ScopeCategory = "category"
+)
+
+// PromotionConfig 促销规则文件的内容
+type PromotionConfig struct {
+	Tax   TaxConfig        `json:"tax" yaml:"tax"`
+	Rules []*PromotionRule `json:"rules" yaml:"rules"`
+}
+
+// TaxConfig 税率配置，类目税率优先于默认税率
+type TaxConfig struct {
+	DefaultRate   float64            `json:"default_rate" yaml:"default_rate"`
+	CategoryRates map[string]float64 `json:"category_rates" yaml:"category_rates"`
+}
+
+// PromotionRule 一条促销规则
+type PromotionRule struct {
+	ID          string   `json:"id" yaml:"id"`
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type" yaml:"type"`
+	Value       float64  `json:"value" yaml:"value"`
+	Scope       string   `json:"scope" yaml:"scope"` // 默认 order
+	ProductIDs  []string `json:"product_ids" yaml:"product_ids"`
+	Categories  []string `json:"categories" yaml:"categories"`
+	Coupon      string   `json:"coupon" yaml:"coupon"`             // 需要的优惠券代码 (不区分大小写)，为空表示自动生效
+	MinAmount   float64  `json:"min_amount" yaml:"min_amount"`     // 适用商品金额门槛
+	MinQuantity int      `json:"min_quantity" yaml:"min_quantity"` // 适用商品件数门槛
+	PerUnit     bool     `json:"per_unit" yaml:"per_unit"`         // fixed: 每件立减
+	Repeat      bool     `json:"repeat" yaml:"repeat"`             // threshold: 每满 min_amount 减一次
+	MaxDiscount float64  `json:"max_discount" yaml:"max_discount"` // 优惠上限，0 表示不限
+	Priority    int      `json:"priority" yaml:"priority"`
+	Group       string   `json:"group" yaml:"group"`
+	Exclusive   bool     `json:"exclusive" yaml:"exclusive"`
+	StartsAt    string   `json:"starts_at" yaml:"starts_at"`     // 2006-01-02 或 RFC3339
+	ExpiresAt   string   `json:"expires_at" yaml:"expires_at"`   // 日期格式时当天全天有效
+	UsageLimit  int      `json:"usage_limit" yaml:"usage_limit"` // 总使用次数上限，0 表示不限
+
+	starts, expires time.Time
+}
+
+// label 用于说明的规则名称，如 "[SAVE10] 全场九折券"
+func (r *PromotionRule) label() string {
+	return fmt.Sprintf("[%s] %s", r.ID, r.Name)
+}
+
+// AppliedRule 生效的规则
+type AppliedRule struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Discount float64 `json:"discount"`
+	Detail   string  `json:"detail"`
+}
+
+// SkippedRule 未生效的规则及原因
+type SkippedRule struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// PromotionEngine 促销规则引擎，可以被多个 goroutine 同时使用
+type PromotionEngine struct {
+	config PromotionConfig
+	now    func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]int // 规则 ID -> 已使用次数
+}
+
+// LoadPromotionRulesFile 从文件加载促销规则，按扩展名识别 .json / .yaml / .yml
+func LoadPromotionRulesFile(path string) (*PromotionEngine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取促销规则失败: %w", err)
+	}
+	return LoadPromotionRules(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// LoadPromotionRules 解析促销规则，format 为 json、yaml 或 yml。未知字段视为错误，避免拼写错误的规则被静默忽略。
+func LoadPromotionRules(data []byte, format string) (*PromotionEngine, error) {
+	var config PromotionConfig
+	switch strings.ToLower(format) {
+	case "json":
+		dec := json.NewDecoder(strings.NewReader(string(data)))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&config); err != nil {
+			return nil, fmt.Errorf("解析促销规则失败: %w", err)
+		}
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(strings.NewReader(string(data)))
+		dec.KnownFields(true)
+		if err := dec.Decode(&config); err != nil {
+			return nil, fmt.Errorf("解析促销规则失败: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的促销规则格式: %s", format)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	// 按优先级从高到低排序，优先级相同时保持定义顺序
+	slices.SortStableFunc(config.Rules, func(a, b *PromotionRule) int { return b.Priority - a.Priority })
+	return &PromotionEngine{config: config, now: time.Now, usage: make(map[string]int)}, nil
+}
+
+// validate 检查规则定义并解析有效期
+func (c *PromotionConfig) validate() error {
+	if c.Tax.DefaultRate < 0 || c.Tax.DefaultRate > 1 {
+		return fmt.Errorf("默认税率必须在 0 到 1 之间: %v", c.Tax.DefaultRate)
+	}
+	for category, rate := range c.Tax.CategoryRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("类目 %s 的税率必须在 0 到 1 之间: %v", category, rate)
+		}
+	}
+
+	ids := make(map[string]bool, len(c.Rules))
+	for i, r := range c.Rules {
+		if r.ID == "" {
+			return fmt.Errorf("第 %d 条促销规则缺少 id", i+1)
+		}
+		if ids[r.ID] {
+			return fmt.Errorf("促销规则 id 重复: %s", r.ID)
+		}
+		ids[r.ID] = true
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("促销规则 %s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// validate 检查单条规则
+func (r *PromotionRule) validate() error {
+	if r.Name == "" {
+		r.Name = r.ID
+	}
+	if r.Scope == "" {
+		r.Scope = ScopeOrder
+	}
+
+	switch r.Type {
+	case RuleTypePercentage:
+		if r.Value <= 0 || r.Value > 100 {
+			return fmt.Errorf("折扣百分比必须在 (0, 100] 之间: %v", r.Value)
+		}
+	case RuleTypeFixed:
+		if r.Value <= 0 {
+			return fmt.Errorf("立减金额必须大于 0: %v", r.Value)
+		}
+	case RuleTypeThreshold:
+		if r.Value <= 0 || r.MinAmount <= 0 {
+			return errors.New("满减规则的 value 和 min_amount 必须大于 0")
+		}
+	case RuleTypeFreeShipping:
+		if r.Scope != ScopeOrder {
+			return errors.New("免运费规则只能作用于整单")
+		}
+	default:
+		return fmt.Errorf("不支持的规则类型: %s", r.Type)
+	}
+
+	switch r.Scope {
+	case ScopeOrder:
+	case ScopeItem:
+		if len(r.ProductIDs) == 0 {
+			return errors.New("scope 为 item 时必须指定 product_ids")
+		}
+	case ScopeCategory:
+		if len(r.Categories) == 0 {
+			return errors.New("scope 为 category 时必须指定 categories")
+		}
+	default:
+		return fmt.Errorf("不支持的适用范围: %s", r.Scope)
+	}
+
+	if r.MinAmount < 0 || r.MinQuantity < 0 || r.MaxDiscount < 0 || r.UsageLimit < 0 {
+		return errors.New("min_amount、min_quantity、max_discount 和 usage_limit 不能为负数")
+	}
+
+	var err error
+	if r.starts, err = parseRuleTime(r.StartsAt, false); err != nil {
+		return fmt.Errorf("starts_at: %w", err)
+	}
+	if r.expires, err = parseRuleTime(r.ExpiresAt, true); err != nil {
+		return fmt.Errorf("expires_at: %w", err)
+	}
+	if !r.starts.IsZero() && !r.expires.IsZero() && !r.starts.Before(r.expires) {
+		return errors.New("starts_at 必须早于 expires_at")
+	}
+	return nil
+}
+
+// parseRuleTime 解析规则的有效期，支持 RFC3339 和日期 (按本地时区)。
+// endOfDay 为 true 时日期表示当天结束，即第二天零点 (不含)。
+func parseRuleTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无法解析时间 %s，应为 2006-01-02 或 RFC3339 格式", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// orderLine 计算过程中的一个订单项
+type orderLine struct {
+	item     OrderItem
+	gross    float64 // 单价 × 数量
+	discount float64 // 已分摊到该项的折扣
+}
+
+// remaining 折扣后的金额
+func (l *orderLine) remaining() float64 {
+	return l.gross - l.discount
+}
+
+// pricing 一种规则组合的计算结果
+type pricing struct {
+	lines            []orderLine
+	shippingDiscount float64
+	applied          []AppliedRule
+	skipped          []SkippedRule
+}
+
+// discount 商品折扣和运费减免的总额
+func (p *pricing) discount() float64 {
+	total := p.shippingDiscount
+	for _, l := range p.lines {
+		total += l.discount
+	}
+	return roundMoney(total)
+}
+
+// CalculateOrder 计算订单价格: 商品小计 -> 促销和优惠券 -> 运费 -> 税费，
+// 并逐行说明每一步的计算过程和每条规则生效或未生效的原因
+func (e *PromotionEngine) CalculateOrder(ctx context.Context, req *CalculateOrderRequest) (*CalculateOrderResponse, error) {
+	log.Printf("[CalculateOrder] 正在计算订单，商品数量: %d，优惠券: %q", len(req.Items), req.CouponCode)
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("订单中没有商品")
+	}
+	if req.ShippingFee < 0 || req.TaxRate < 0 || req.TaxRate > 1 {
+		return nil, errors.New("运费不能为负数，税率必须在 0 到 1 之间")
+	}
+
+	var explanation []string
+	lines := make([]orderLine, len(req.Items))
+	var subTotal float64
+	for i, item := range req.Items {
+		if item.Quantity < 1 || item.Price < 0 {
+			return nil, fmt.Errorf("商品 %s 的数量必须大于 0，单价不能为负数", item.ProductID)
+		}
+		gross := roundMoney(item.Price * float64(item.Quantity))
+		lines[i] = orderLine{item: item, gross: gross}
+		subTotal += gross
+		explanation = append(explanation, fmt.Sprintf("商品 %s (%s): %.2f × %d = %.2f", item.Name, item.ProductID, item.Price, item.Quantity, gross))
+	}
+	subTotal = roundMoney(subTotal)
+	explanation = append(explanation, fmt.Sprintf("商品小计: %.2f", subTotal))
+
+	// 筛选候选规则: 需要优惠券的规则只在提供了对应优惠券时参与计算
+	now := e.now()
+	var stackable, exclusive []*PromotionRule
+	var unavailable []SkippedRule
+	couponFound := req.CouponCode == ""
+	for _, r := range e.config.Rules {
+		if r.Coupon != "" {
+			if !strings.EqualFold(r.Coupon, req.CouponCode) {
+				continue
+			}
+			couponFound = true
+		}
+		if reason := e.unavailable(r, now); reason != "" {
+			unavailable = append(unavailable, SkippedRule{ID: r.ID, Name: r.Name, Reason: reason})
+			continue
+		}
+		if r.Exclusive {
+			exclusive = append(exclusive, r)
+		} else {
+			stackable = append(stackable, r)
+		}
+	}
+	if !couponFound {
+		explanation = append(explanation, fmt.Sprintf("优惠券 %s 不存在", req.CouponCode))
+	}
+
+	// 叠加方案与每个独享规则单独使用的方案比较，选择优惠最多的
+	best := applyRules(lines, req.ShippingFee, stackable)
+	for _, r := range exclusive {
+		alone := applyRules(lines, req.ShippingFee, []*PromotionRule{r})
+		if len(alone.applied) == 0 {
+			best.skipped = append(best.skipped, alone.skipped...)
+			continue
+		}
+		if alone.discount() > best.discount() {
+			reason := fmt.Sprintf("不与其他优惠同享，已选择优惠更多的 %s (优惠 %.2f，其他优惠合计 %.2f)", r.label(), alone.discount(), best.discount())
+			for _, a := range best.applied {
+				alone.skipped = append(alone.skipped, SkippedRule{ID: a.ID, Name: a.Name, Reason: reason})
+			}
+			alone.skipped = append(alone.skipped, best.skipped...)
+			best = alone
+		} else {
+			best.skipped = append(best.skipped, SkippedRule{ID: r.ID, Name: r.Name, Reason: fmt.Sprintf(
+				"不与其他优惠同享，单独使用优惠 %.2f，不如其他优惠合计 %.2f", alone.discount(), best.discount())})
+		}
+	}
+	best.skipped = append(unavailable, best.skipped...)
+
+	couponApplied := false
+	for _, a := range best.applied {
+		explanation = append(explanation, fmt.Sprintf("✓ [%s] %s: %s", a.ID, a.Name, a.Detail))
+		if r := e.rule(a.ID); r != nil && r.Coupon != "" {
+			couponApplied = true
+		}
+	}
+	for _, s := range best.skipped {
+		explanation = append(explanation, fmt.Sprintf("✗ [%s] %s: %s", s.ID, s.Name, s.Reason))
+	}
+
+	// 运费和税费: 商品按类目税率 (请求中指定 tax_rate 时统一使用该税率) 计税，运费按默认税率计税
+	shipping := roundMoney(req.ShippingFee - best.shippingDiscount)
+	explanation = append(explanation, fmt.Sprintf("运费: %.2f", shipping))
+
+	var tax, goodsDiscount float64
+	for _, l := range best.lines {
+		rate := e.taxRate(l.item.Category, req.TaxRate)
+		tax += l.remaining() * rate
+		goodsDiscount += l.discount
+	}
+	shippingRate := e.taxRate("", req.TaxRate)
+	tax = roundMoney(tax + shipping*shippingRate)
+	goodsDiscount = roundMoney(goodsDiscount)
+	explanation = append(explanation, e.describeTax(best.lines, shipping, req.TaxRate, tax))
+
+	total := roundMoney(subTotal - goodsDiscount + shipping + tax)
+	explanation = append(explanation, fmt.Sprintf("合计: %.2f - %.2f + %.2f + %.2f = %.2f", subTotal, goodsDiscount, shipping, tax, total))
+
+	if req.Confirm {
+		if err := e.redeem(best.applied); err != nil {
+			return nil, err
+		}
+		explanation = append(explanation, "已确认下单，记录优惠使用次数")
+	}
+
+	return &CalculateOrderResponse{
+		SubTotal:      subTotal,
+		Discount:      goodsDiscount,
+		ShippingFee:   shipping,
+		Tax:           tax,
+		Total:         total,
+		Items:         req.Items,
+		CouponApplied: couponApplied,
+		AppliedRules:  best.applied,
+		SkippedRules:  best.skipped,
+		Explanation:   explanation,
+	}, nil
+}
+
+// unavailable 检查规则的有效期和使用次数，可用时返回空字符串
+func (e *PromotionEngine) unavailable(r *PromotionRule, now time.Time) string {
+	switch {
+	case !r.starts.IsZero() && now.Before(r.starts):
+		return fmt.Sprintf("活动尚未开始 (%s 开始)", r.StartsAt)
+	case !r.expires.IsZero() && !now.Before(r.expires):
+		return fmt.Sprintf("已过期 (有效期至 %s)", r.ExpiresAt)
+	}
+	if r.UsageLimit > 0 {
+		e.mu.Lock()
+		used := e.usage[r.ID]
+		e.mu.Unlock()
+		if used >= r.UsageLimit {
+			return fmt.Sprintf("已达到使用次数上限 (%d 次)", r.UsageLimit)
+		}
+	}
+	return ""
+}
+
+// redeem 确认下单时记录生效规则的使用次数，任一规则在计算之后达到上限时整体失败
+func (e *PromotionEngine) redeem(applied []AppliedRule) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, a := range applied {
+		if r := e.rule(a.ID); r.UsageLimit > 0 && e.usage[a.ID] >= r.UsageLimit {
+			return fmt.Errorf("优惠 %s 已达到使用次数上限，请重新计算订单", r.label())
+		}
+	}
+	for _, a := range applied {
+		e.usage[a.ID]++
+	}
+	return nil
+}
+
+// rule 按 ID 查找规则
+func (e *PromotionEngine) rule(id string) *PromotionRule {
+	for _, r := range e.config.Rules {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// taxRate 商品类目的税率，override 大于 0 时统一使用 override
+func (e *PromotionEngine) taxRate(category string, override float64) float64 {
+	if override > 0 {
+		return override
+	}
+	if rate, ok := e.config.Tax.CategoryRates[category]; ok && category != "" {
+		return rate
+	}
+	return e.config.Tax.DefaultRate
+}
+
+// describeTax 说明税费的计算过程，按税率分组列出计税金额
+func (e *PromotionEngine) describeTax(lines []orderLine, shipping, override, tax float64) string {
+	bases := make(map[float64]float64)
+	var rates []float64
+	add := func(rate, amount float64) {
+		if _, ok := bases[rate]; !ok {
+			rates = append(rates, rate)
+		}
+		bases[rate] += amount
+	}
+	for _, l := range lines {
+		add(e.taxRate(l.item.Category, override), l.remaining())
+	}
+	add(e.taxRate("", override), shipping)
+
+	parts := make([]string, len(rates))
+	for i, rate := range rates {
+		parts[i] = fmt.Sprintf("%.2f × %g%%", roundMoney(bases[rate]), rate*100)
+	}
+	return fmt.Sprintf("税费: %s = %.2f", strings.Join(parts, " + "), tax)
+}
+
+// applyRules 按顺序对订单项应用规则，返回新的计算结果 (不修改传入的 lines)
+func applyRules(lines []orderLine, shippingFee float64, rules []*PromotionRule) *pricing {
+	p := &pricing{lines: slices.Clone(lines), applied: []AppliedRule{}}
+	groups := make(map[string]string) // group -> 已生效的规则
+
+	for _, r := range rules {
+		skip := func(reason string) {
+			p.skipped = append(p.skipped, SkippedRule{ID: r.ID, Name: r.Name, Reason: reason})
+		}
+		if owner, ok := groups[r.Group]; ok && r.Group != "" {
+			skip(fmt.Sprintf("与 %s 同属 %s 组，同组只能使用一个", owner, r.Group))
+			continue
+		}
+
+		// 适用的订单项及其折扣后的金额和件数
+		var eligible []int
+		var amount float64
+		quantity := 0
+		for i := range p.lines {
+			if r.matches(&p.lines[i].item) {
+				eligible = append(eligible, i)
+				amount += p.lines[i].remaining()
+				quantity += p.lines[i].item.Quantity
+			}
+		}
+		amount = roundMoney(amount)
+		if len(eligible) == 0 {
+			skip("订单中没有适用的商品")
+			continue
+		}
+		if quantity < r.MinQuantity {
+			skip(fmt.Sprintf("适用商品共 %d 件，未满 %d 件", quantity, r.MinQuantity))
+			continue
+		}
+		if amount < r.MinAmount {
+			skip(fmt.Sprintf("适用商品金额 %.2f 未满 %.2f，还差 %.2f", amount, r.MinAmount, roundMoney(r.MinAmount-amount)))
+			continue
+		}
+
+		var discount float64
+		var detail string
+		switch r.Type {
+		case RuleTypePercentage:
+			discount = amount * r.Value / 100
+			detail = fmt.Sprintf("%.2f × %g%% = %.2f", amount, r.Value, roundMoney(discount))
+		case RuleTypeFixed:
+			discount = r.Value
+			detail = fmt.Sprintf("立减 %.2f", r.Value)
+			if r.PerUnit {
+				discount = r.Value * float64(quantity)
+				detail = fmt.Sprintf("每件立减 %.2f × %d 件 = %.2f", r.Value, quantity, discount)
+			}
+		case RuleTypeThreshold:
+			times := 1
+			if r.Repeat {
+				times = int(math.Floor(amount / r.MinAmount))
+			}
+			discount = r.Value * float64(times)
+			detail = fmt.Sprintf("适用金额 %.2f，满 %.2f 减 %.2f", amount, r.MinAmount, r.Value)
+			if times > 1 {
+				detail += fmt.Sprintf(" × %d = %.2f", times, discount)
+			}
+		case RuleTypeFreeShipping:
+			discount = shippingFee - p.shippingDiscount
+			if discount <= 0 {
+				skip("订单没有运费")
+				continue
+			}
+			p.shippingDiscount += discount
+			groups[r.Group] = r.label()
+			p.applied = append(p.applied, AppliedRule{ID: r.ID, Name: r.Name, Type: r.Type, Discount: roundMoney(discount), Detail: fmt.Sprintf("免运费 %.2f", discount)})
+			continue
+		}
+
+		if r.MaxDiscount > 0 && discount > r.MaxDiscount {
+			discount = r.MaxDiscount
+			detail += fmt.Sprintf("，超过上限按 %.2f 计", r.MaxDiscount)
+		}
+		if discount > amount {
+			discount = amount
+			detail += fmt.Sprintf("，不超过适用金额 %.2f", amount)
+		}
+		discount = roundMoney(discount)
+		if discount <= 0 {
+			skip("适用商品金额已为 0")
+			continue
+		}
+
+		p.allocate(eligible, amount, discount)
+		groups[r.Group] = r.label()
+		p.applied = append(p.applied, AppliedRule{ID: r.ID, Name: r.Name, Type: r.Type, Discount: discount, Detail: detail})
+	}
+	return p
+}
+
+// matches 判断订单项是否在规则的适用范围内
+func (r *PromotionRule) matches(item *OrderItem) bool {
+	switch r.Scope {
+	case ScopeItem:
+		return slices.Contains(r.ProductIDs, item.ProductID)
+	case ScopeCategory:
+		return item.Category != "" && slices.Contains(r.Categories, item.Category)
+	default:
+		return true
+	}
+}
+
+// allocate 把折扣按折扣后的金额比例分摊到适用的订单项，最后一项承担舍入误差，
+// 使后续规则和按类目计税都基于分摊后的金额
+func (p *pricing) allocate(eligible []int, amount, discount float64) {
+	left := discount
+	for n, i := range eligible {
+		l := &p.lines[i]
+		share := left
+		if n < len(eligible)-1 && amount > 0 {
+			share = roundMoney(discount * l.remaining() / amount)
+		}
+		share = min(share, l.remaining())
+		l.discount = roundMoney(l.discount + share)
+		left = roundMoney(left - share)
+	}
+}
+
+// roundMoney 金额四舍五入到分
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// =============================================================================
+//
+//  文件: stats.go
+//  功能: 数据分析工具使用的描述统计函数: 均值、中位数、众数、方差 / 标准差、
+//        百分位数、IQR 异常值、直方图、皮尔逊相关系数和最小二乘线性回归，
+//        以及根据数据形态推荐图表类型。
+//  说明: 百分位数使用线性插值 (与 numpy 和 Excel PERCENTILE.INC 相同)；
+//        函数不修改传入的切片，需要排序时先复制。
+//
+// =============================================================================
+
+// FiveNumberSummary 五数概括，用于箱线图
+type FiveNumberSummary struct {
+	Min    float64 `json:"min"`
+	Q1     float64 `json:"q1"`
+	Median float64 `json:"median"`
+	Q3     float64 `json:"q3"`
+	Max    float64 `json:"max"`
+}
+
+// OutlierReport IQR 法检测的异常值: 小于 Q1 - 1.5*IQR 或大于 Q3 + 1.5*IQR
+type OutlierReport struct {
+	Q1         float64   `json:"q1"`
+	Q3         float64   `json:"q3"`
+	IQR        float64   `json:"iqr"`
+	LowerFence float64   `json:"lower_fence"`
+	UpperFence float64   `json:"upper_fence"`
+	Outliers   []Outlier `json:"outliers"`
+}
+
+// Outlier 一个异常值及其在数据集中的位置 (从 0 开始)
+type Outlier struct {
+	Index int     `json:"index"`
+	Value float64 `json:"value"`
+}
+
+// HistogramBin 直方图的一个区间 [Lower, Upper)，最后一个区间包含 Upper
+type HistogramBin struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int     `json:"count"`
+}
+
+// ModeResult 众数，出现次数最多的值可能有多个；所有值都只出现一次时没有众数
+type ModeResult struct {
+	Values    []float64 `json:"values"`
+	Frequency int       `json:"frequency"`
+}
+
+// CorrelationResult 皮尔逊相关系数
+type CorrelationResult struct {
+	Pearson     float64 `json:"pearson"`
+	RSquared    float64 `json:"r_squared"`
+	Description string  `json:"description"`
+}
+
+// RegressionResult 最小二乘线性回归 y = Slope*x + Intercept
+type RegressionResult struct {
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"r_squared"`
+	X         string  `json:"x"` // 自变量: dataset (有配对数据时) 或 index (按序号回归，反映趋势)
+	Equation  string  `json:"equation"`
+}
+
+// sum 求和
+func sum(xs []float64) float64 {
+	total := 0.0
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
+
+// mean 算术平均数
+func mean(xs []float64) float64 {
+	return sum(xs) / float64(len(xs))
+}
+
+// variance 方差，sample 为 true 时使用样本方差 (除以 n-1)，否则使用总体方差 (除以 n)
+func variance(xs []float64, sample bool) float64 {
+	n := len(xs)
+	if n < 2 {
+		return 0
+	}
+	m := mean(xs)
+	ss := 0.0
+	for _, x := range xs {
+		ss += (x - m) * (x - m)
+	}
+	if sample {
+		return ss / float64(n-1)
+	}
+	return ss / float64(n)
+}
+
+// sortedCopy 返回排好序的副本
+func sortedCopy(xs []float64) []float64 {
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// percentile 在已排序的数据上计算第 p 百分位数 (0 <= p <= 100)，相邻两个值之间线性插值
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// fiveNumbers 计算五数概括
+func fiveNumbers(sorted []float64) FiveNumberSummary {
+	return FiveNumberSummary{
+		Min:    sorted[0],
+		Q1:     percentile(sorted, 25),
+		Median: percentile(sorted, 50),
+		Q3:     percentile(sorted, 75),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// modes 计算众数，多个值出现次数相同时按从小到大返回全部
+func modes(xs []float64) ModeResult {
+	counts := make(map[float64]int, len(xs))
+	best := 0
+	for _, x := range xs {
+		counts[x]++
+		best = max(best, counts[x])
+	}
+	result := ModeResult{Values: []float64{}, Frequency: best}
+	if best < 2 {
+		return result
+	}
+	for x, c := range counts {
+		if c == best {
+			result.Values = append(result.Values, x)
+		}
+	}
+	slices.Sort(result.Values)
+	return result
+}
+
+// iqrOutliers 用 IQR 法检测异常值，按在数据集中的位置返回
+func iqrOutliers(xs []float64) OutlierReport {
+	sorted := sortedCopy(xs)
+	q1, q3 := percentile(sorted, 25), percentile(sorted, 75)
+	iqr := q3 - q1
+	report := OutlierReport{
+		Q1:         q1,
+		Q3:         q3,
+		IQR:        iqr,
+		LowerFence: q1 - 1.5*iqr,
+		UpperFence: q3 + 1.5*iqr,
+		Outliers:   []Outlier{},
+	}
+	for i, x := range xs {
+		if x < report.LowerFence || x > report.UpperFence {
+			report.Outliers = append(report.Outliers, Outlier{Index: i, Value: x})
+		}
+	}
+	return report
+}
+
+// maxHistogramBins 直方图最多的区间数，与 bins 参数的 maximum 一致
+const maxHistogramBins = 50
+
+// histogramBinCount 自动选择直方图的区间数: 优先使用 Freedman-Diaconis 规则
+// (区间宽度 2*IQR/n^(1/3)，对偏态数据更稳健)，IQR 为 0 时退回 Sturges 规则 (log2(n)+1)，
+// 结果不超过数据点数量，并限制在 1 到 maxHistogramBins 之间
+func histogramBinCount(xs []float64) int {
+	n := float64(len(xs))
+	sorted := sortedCopy(xs)
+	spread := sorted[len(sorted)-1] - sorted[0]
+	if spread == 0 {
+		return 1
+	}
+	bins := int(math.Ceil(math.Log2(n))) + 1
+	if iqr := percentile(sorted, 75) - percentile(sorted, 25); iqr > 0 {
+		width := 2 * iqr / math.Cbrt(n)
+		bins = int(math.Ceil(spread / width))
+	}
+	return min(max(bins, 1), len(xs), maxHistogramBins)
+}
+
+// histogram 把数据分为 bins 个等宽区间并计数，所有值相同时只有一个区间
+func histogram(xs []float64, bins int) []HistogramBin {
+	lo, hi := slices.Min(xs), slices.Max(xs)
+	if lo == hi {
+		return []HistogramBin{{Lower: lo, Upper: hi, Count: len(xs)}}
+	}
+
+	width := (hi - lo) / float64(bins)
+	result := make([]HistogramBin, bins)
+	for i := range result {
+		result[i].Lower = lo + float64(i)*width
+		result[i].Upper = lo + float64(i+1)*width
+	}
+	result[bins-1].Upper = hi // 避免浮点误差使最大值落在区间外
+	for _, x := range xs {
+		i := min(int((x-lo)/width), bins-1)
+		result[i].Count++
+	}
+	return result
+}
+
+// pearson 皮尔逊相关系数，任一组数据没有变化 (方差为 0) 时返回错误
+func pearson(xs, ys []float64) (float64, error) {
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, errors.New("数据没有变化 (方差为 0)，无法计算相关系数")
+	}
+	return sxy / math.Sqrt(sxx*syy), nil
+}
+
+// describeCorrelation 按相关系数的绝对值描述相关程度
+func describeCorrelation(r float64) string {
+	direction := "正"
+	if r < 0 {
+		direction = "负"
+	}
+	switch a := math.Abs(r); {
+	case a >= 0.8:
+		return "强" + direction + "相关"
+	case a >= 0.5:
+		return "中等" + direction + "相关"
+	case a >= 0.3:
+		return "弱" + direction + "相关"
+	default:
+		return "几乎不相关"
+	}
+}
+
+// linearRegression 最小二乘线性回归，自变量没有变化时返回错误
+func linearRegression(xs, ys []float64) (slope, intercept, rSquared float64, err error) {
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, 0, 0, errors.New("自变量没有变化 (方差为 0)，无法进行线性回归")
+	}
+	slope = sxy / sxx
+	intercept = my - slope*mx
+	rSquared = 1.0 // 因变量没有变化时直线完全拟合
+	if syy > 0 {
+		rSquared = sxy * sxy / (sxx * syy)
+	}
+	return slope, intercept, rSquared, nil
+}
+
+// indexes 返回 0, 1, ..., n-1，用于按序号回归
+func indexes(n int) []float64 {
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	return xs
+}
+
+// recommendChart 根据数据形态推荐图表类型:
+//   - 有配对数据: 散点图 (scatter)，展示两组数据的关系
+//   - 取值少且重复多 (如评分、计数): 柱状图 (bar)，展示每个取值的频数
+//   - 数据点较多 (>= 30): 直方图 (histogram)，展示分布
+//   - 有明显的线性趋势 (按序号回归 |r| >= 0.7): 折线图 (line)
+//   - 有异常值: 箱线图 (box_plot)，突出异常值
+//   - 其他: 折线图 (line)
+func recommendChart(xs, paired []float64) *ChartInfo {
+	if len(paired) > 0 {
+		return &ChartInfo{
+			RecommendedType: "scatter",
+			Reason:          "提供了配对数据，散点图可以展示两组数据之间的关系",
+			XAxis:           formatValues(xs),
+			YAxis:           paired,
+		}
+	}
+
+	counts := make(map[float64]int, len(xs))
+	for _, x := range xs {
+		counts[x]++
+	}
+	if len(counts) <= 10 && len(xs) >= 2*len(counts) {
+		values := uniqueSorted(xs)
+		freq := make([]float64, len(values))
+		for i, v := range values {
+			freq[i] = float64(counts[v])
+		}
+		return &ChartInfo{
+			RecommendedType: "bar",
+			Reason:          fmt.Sprintf("只有 %d 个不同的取值且重复较多，柱状图可以直接比较各取值的频数", len(values)),
+			XAxis:           formatValues(values),
+			YAxis:           freq,
+		}
+	}
+
+	if len(xs) >= 30 {
+		bins := histogram(xs, histogramBinCount(xs))
+		chart := &ChartInfo{
+			RecommendedType: "histogram",
+			Reason:          fmt.Sprintf("共 %d 个数据点，直方图可以展示数据的分布形态", len(xs)),
+		}
+		for _, b := range bins {
+			chart.XAxis = append(chart.XAxis, fmt.Sprintf("[%s, %s)", formatValue(b.Lower), formatValue(b.Upper)))
+			chart.YAxis = append(chart.YAxis, float64(b.Count))
+		}
+		return chart
+	}
+
+	labels := make([]string, len(xs))
+	for i := range xs {
+		labels[i] = fmt.Sprintf("Point %d", i+1)
+	}
+	if len(xs) >= 3 {
+		if r, err := pearson(indexes(len(xs)), xs); err == nil && math.Abs(r) >= 0.7 {
+			trend := "上升"
+			if r < 0 {
+				trend = "下降"
+			}
+			return &ChartInfo{
+				RecommendedType: "line",
+				Reason:          fmt.Sprintf("数据随序号呈%s趋势 (r = %.2f)，折线图可以展示变化趋势", trend, r),
+				XAxis:           labels,
+				YAxis:           xs,
+			}
+		}
+	}
+	if len(xs) >= 5 {
+		if report := iqrOutliers(xs); len(report.Outliers) > 0 {
+			summary := fiveNumbers(sortedCopy(xs))
+			return &ChartInfo{
+				RecommendedType: "box_plot",
+				Reason:          fmt.Sprintf("存在 %d 个异常值，箱线图可以同时展示数据的集中范围和异常值", len(report.Outliers)),
+				BoxPlot:         &summary,
+				YAxis:           xs,
+			}
+		}
+	}
+	return &ChartInfo{
+		RecommendedType: "line",
+		Reason:          "数据点较少且没有明显的分布特征，折线图可以展示每个数据点",
+		XAxis:           labels,
+		YAxis:           xs,
+	}
+}
+
+// uniqueSorted 去重并排序
+func uniqueSorted(xs []float64) []float64 {
+	return slices.Compact(sortedCopy(xs))
+}
+
+// formatValues 把数值格式化为坐标轴标签
+func formatValues(xs []float64) []string {
+	labels := make([]string, len(xs))
+	for i, x := range xs {
+		labels[i] = formatValue(x)
+	}
+	return labels
+}
+
+// formatValue 用最短的形式格式化数值，如 3、2.5
+func formatValue(x float64) string {
+	return strconv.FormatFloat(x, 'g', 6, 64)
+}
@@ -0,0 +1,248 @@
+// package main 表明这是一个可执行程序
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"Eini/mcp_client"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// =============================================================================
+//
+//  文件: mcp_client_example.go
+//  功能: 演示如何通过 mcp_client 把远程 MCP 服务端的工具当作 Eino 工具使用。
+//  说明: 示例自带一个本地桩服务 (stub server)，不依赖任何外部服务:
+//        - HTTP 传输: 在本进程内启动 Streamable HTTP 服务
+//        - stdio 传输: 以 -stub-stdio 参数重新启动本程序作为子进程
+//        远程工具和本地工具一起注册到 ToolsNode，并演示超时、工具错误和断线重连。
+//
+// =============================================================================
+
+// --- 本地桩服务 ---
+
+// newStubServer 创建提供 echo / add / slow / fail 四个工具的 MCP 服务
+func newStubServer(name string) *server.MCPServer {
+	s := server.NewMCPServer(name, "0.1.0", server.WithToolCapabilities(false))
+
+	s.AddTool(mcp.NewTool("echo",
+		mcp.WithDescription("原样返回输入的文本"),
+		mcp.WithString("text", mcp.Required(), mcp.Description("要返回的文本")),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		text, err := req.RequireString("text")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("[%s] %s", name, text)), nil
+	})
+
+	s.AddTool(mcp.NewTool("add",
+		mcp.WithDescription("计算两个数字之和"),
+		mcp.WithNumber("a", mcp.Required(), mcp.Description("第一个数字")),
+		mcp.WithNumber("b", mcp.Required(), mcp.Description("第二个数字")),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		a, errA := req.RequireFloat("a")
+		b, errB := req.RequireFloat("b")
+		if err := errors.Join(errA, errB); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultStructured(map[string]float64{"sum": a + b}, fmt.Sprintf("%g + %g = %g", a, b, a+b)), nil
+	})
+
+	s.AddTool(mcp.NewTool("slow",
+		mcp.WithDescription("等待指定的秒数后返回，用于演示调用超时"),
+		mcp.WithNumber("seconds", mcp.Required(), mcp.Description("等待的秒数")),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		seconds := req.GetFloat("seconds", 1)
+		select {
+		case <-time.After(time.Duration(seconds * float64(time.Second))):
+			return mcp.NewToolResultText("完成"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	s.AddTool(mcp.NewTool("fail",
+		mcp.WithDescription("总是返回错误，用于演示工具错误的处理"),
+	), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("桩服务模拟的错误"), nil
+	})
+
+	return s
+}
+
+// startHTTPStub 在指定地址启动 HTTP 桩服务，返回服务端用于之后关闭
+func startHTTPStub(addr string) (*http.Server, string, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", err
+	}
+	srv := &http.Server{Handler: server.NewStreamableHTTPServer(newStubServer("http-stub"))}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[Stub] HTTP 桩服务异常退出: %v", err)
+		}
+	}()
+	return srv, ln.Addr().String(), nil
+}
+
+// --- 本地工具 ---
+
+// UpperRequest 本地工具的输入参数
+type UpperRequest struct {
+	Text string `json:"text" jsonschema:"required,description=要转换的文本"`
+}
+
+// newLocalTool 创建一个本地工具，演示远程工具与本地工具混用
+func newLocalTool() (tool.InvokableTool, error) {
+	return utils.InferTool("to_upper", "把文本转换为大写", func(ctx context.Context, req *UpperRequest) (string, error) {
+		return strings.ToUpper(req.Text), nil
+	})
+}
+
+// --- 演示 ---
+
+// demonstrateMCPClient 连接桩服务，列出并调用远程工具
+func demonstrateMCPClient() error {
+	ctx := context.Background()
+
+	httpStub, httpAddr, err := startHTTPStub("127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("启动 HTTP 桩服务失败: %w", err)
+	}
+	// 桩服务在重连演示中会被替换，关闭时使用最新的实例 (晚于客户端关闭)
+	defer func() { _ = httpStub.Close() }()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// 两个服务端分别使用 HTTP 和 stdio 传输，工具名称以服务名称作为命名空间。
+	// 桩服务的工具都是幂等的，remote_http 开启 RetryCalls，连接断开时工具调用也重连后重试
+	configs := []*mcp_client.ServerConfig{
+		{Name: "remote_http", URL: "http://" + httpAddr + "/mcp", RetryCalls: true},
+		{Name: "remote_stdio", Command: executable, Args: []string{"-stub-stdio"}, Timeout: mcp_client.Duration(2 * time.Second)},
+	}
+	toolset, err := mcp_client.NewToolset(ctx, configs)
+	if err != nil {
+		return err
+	}
+	defer toolset.Close()
+
+	remoteTools, err := toolset.Tools(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 1. 列出远程工具，参数定义由 JSON Schema 转换而来
+	fmt.Println("=== 1. 远程工具列表 ===")
+	for _, t := range remoteTools {
+		info, _ := t.Info(ctx)
+		params, _ := info.ParamsOneOf.ToOpenAPIV3()
+		data, _ := json.Marshal(params)
+		fmt.Printf("- %s: %s\n  参数: %s\n", info.Name, info.Desc, data)
+	}
+
+	// 2. 远程工具与本地工具一起注册到 ToolsNode
+	fmt.Println("\n=== 2. 通过 ToolsNode 调用远程工具和本地工具 ===")
+	localTool, err := newLocalTool()
+	if err != nil {
+		return err
+	}
+	toolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{
+		Tools: append(remoteTools, localTool),
+	})
+	if err != nil {
+		return err
+	}
+
+	// 模拟模型返回的工具调用
+	assistant := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call_1", Function: schema.FunctionCall{Name: "remote_http__add", Arguments: `{"a": 1.5, "b": 2}`}},
+		{ID: "call_2", Function: schema.FunctionCall{Name: "remote_stdio__echo", Arguments: `{"text": "来自 stdio 的问候"}`}},
+		{ID: "call_3", Function: schema.FunctionCall{Name: "to_upper", Arguments: `{"text": "local tool"}`}},
+	})
+	results, err := toolsNode.Invoke(ctx, assistant)
+	if err != nil {
+		return err
+	}
+	for _, msg := range results {
+		fmt.Printf("%s (%s) -> %s\n", msg.ToolName, msg.ToolCallID, msg.Content)
+	}
+
+	tools := make(map[string]tool.InvokableTool)
+	for _, t := range remoteTools {
+		info, _ := t.Info(ctx)
+		tools[info.Name] = t.(tool.InvokableTool)
+	}
+
+	// 3. 超时: remote_stdio 的调用超时时间为 2 秒
+	fmt.Println("\n=== 3. 调用超时 ===")
+	start := time.Now()
+	if _, err := tools["remote_stdio__slow"].InvokableRun(ctx, `{"seconds": 5}`); err != nil {
+		fmt.Printf("调用失败 (耗时 %.1fs): %v\n", time.Since(start).Seconds(), err)
+	}
+
+	// 4. 远程工具返回 isError 时作为结果返回给模型，而不是中断调用的 error
+	fmt.Println("\n=== 4. 工具错误 ===")
+	failResult, err := tools["remote_http__fail"].InvokableRun(ctx, `{}`)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("工具返回错误: %s\n", failResult)
+
+	// 5. 断线重连: 重启 HTTP 桩服务后旧会话失效，下一次调用时自动重连 (remote_http 开启了 RetryCalls)
+	fmt.Println("\n=== 5. 断线重连 ===")
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := httpStub.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	httpStub, _, err = startHTTPStub(httpAddr)
+	if err != nil {
+		return fmt.Errorf("重启 HTTP 桩服务失败: %w", err)
+	}
+	fmt.Println("HTTP 桩服务已重启")
+
+	result, err := tools["remote_http__echo"].InvokableRun(ctx, `{"text": "重连后的调用"}`)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("调用成功: %s\n", result)
+	return nil
+}
+
+// main 是程序的入口点。
+func main() {
+	stubStdio := flag.Bool("stub-stdio", false, "以 stdio 传输运行桩服务 (由示例自身启动，无需手动使用)")
+	flag.Parse()
+
+	if *stubStdio {
+		// stdout 用于协议通信，日志写到 stderr (由客户端转发到日志)
+		if err := server.ServeStdio(newStubServer("stdio-stub")); err != nil {
+			log.Fatalf("stdio 桩服务异常退出: %v", err)
+		}
+		return
+	}
+
+	if err := demonstrateMCPClient(); err != nil {
+		log.Fatalf("MCP 客户端示例失败: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// package main 表明这是一个可执行程序
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"Eini/tools/middleware"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: middleware_example.go
+//  功能: 演示如何使用 tools/middleware 为工具组合超时、重试、缓存、限流和指标统计。
+//  说明: 示例中同时包装了手写工具、InferTool 生成的工具和 InferStreamTool 生成的流式工具，
+//        包装后工具的 Info 保持不变，可以直接注册到 ToolsNode。
+//
+// =============================================================================
+
+// --- 手写工具: 前两次调用失败的不稳定服务 ---
+
+// FlakyStockTool 模拟不稳定的股票行情服务，每三次调用中前两次失败
+type FlakyStockTool struct {
+	calls atomic.Int64
+}
+
+// Info 返回工具的元信息和参数定义
+func (f *FlakyStockTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: "stock_price",
+		Desc: "查询股票的最新价格",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"symbol": {Type: schema.String, Desc: "股票代码，如 AAPL", Required: true},
+		}),
+	}, nil
+}
+
+// InvokableRun 查询股票价格
+func (f *FlakyStockTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var args struct {
+		Symbol string `json:"symbol"`
+	}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+	if n := f.calls.Add(1); n%3 != 0 {
+		return "", fmt.Errorf("行情服务暂时不可用 (第 %d 次调用)", n)
+	}
+	return fmt.Sprintf(`{"symbol": %q, "price": 189.5}`, args.Symbol), nil
+}
+
+// --- InferTool: 耗时不定的查询 ---
+
+// SearchRequest 搜索参数
+type SearchRequest struct {
+	Query   string `json:"query" jsonschema:"required,description=搜索关键词"`
+	DelayMS int    `json:"delay_ms,omitempty" jsonschema:"description=模拟的查询耗时 (毫秒)"`
+}
+
+// SearchResponse 搜索结果
+type SearchResponse struct {
+	Query   string   `json:"query"`
+	Results []string `json:"results"`
+}
+
+// searchCalls 记录搜索工具实际执行的次数，用于观察缓存效果
+var searchCalls atomic.Int64
+
+func newSearchTool() (tool.InvokableTool, error) {
+	return utils.InferTool("search", "搜索文档", func(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+		searchCalls.Add(1)
+		select {
+		case <-time.After(time.Duration(req.DelayMS) * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return &SearchResponse{Query: req.Query, Results: []string{req.Query + " 入门", req.Query + " 最佳实践"}}, nil
+	})
+}
+
+// --- InferStreamTool: 流式输出 ---
+
+// CountdownRequest 倒计时参数
+type CountdownRequest struct {
+	From int `json:"from" jsonschema:"required,description=从几开始倒数"`
+}
+
+func newCountdownTool() (tool.StreamableTool, error) {
+	return utils.InferStreamTool("countdown", "倒计时，逐个输出数字", func(ctx context.Context, req *CountdownRequest) (*schema.StreamReader[string], error) {
+		sr, sw := schema.Pipe[string](0)
+		go func() {
+			defer sw.Close()
+			for i := req.From; i > 0; i-- {
+				time.Sleep(50 * time.Millisecond)
+				if closed := sw.Send(fmt.Sprintf("%d ", i), nil); closed {
+					return
+				}
+			}
+			sw.Send("发射!", nil)
+		}()
+		return sr, nil
+	})
+}
+
+// --- 演示 ---
+
+func demonstrateMiddleware() error {
+	ctx := context.Background()
+	metrics := middleware.NewMetrics()
+
+	// 1. 手写工具: 重试掩盖偶发失败
+	fmt.Println("=== 1. 重试 (手写工具) ===")
+	stock, err := middleware.Wrap(ctx, &FlakyStockTool{},
+		middleware.WithMetrics(metrics),
+		middleware.WithRetry(middleware.RetryConfig{MaxAttempts: 3, Backoff: 50 * time.Millisecond}),
+	)
+	if err != nil {
+		return err
+	}
+	result, err := stock.(tool.InvokableTool).InvokableRun(ctx, `{"symbol": "AAPL"}`)
+	fmt.Printf("结果: %s, 错误: %v\n", result, err)
+
+	// 2. InferTool: 缓存 + 超时 + 参数校验
+	fmt.Println("\n=== 2. 缓存、超时和参数校验 (InferTool) ===")
+	searchTool, err := newSearchTool()
+	if err != nil {
+		return err
+	}
+	search, err := middleware.Wrap(ctx, searchTool,
+		middleware.WithMetrics(metrics),
+		middleware.WithValidation(),
+		middleware.WithResultCache(middleware.CacheConfig{TTL: time.Minute}),
+		middleware.WithTimeout(300*time.Millisecond),
+	)
+	if err != nil {
+		return err
+	}
+	info, _ := search.Info(ctx)
+	fmt.Printf("包装后的工具信息不变: %s - %s\n", info.Name, info.Desc)
+
+	invokable := search.(tool.InvokableTool)
+	for _, args := range []string{
+		`{"query": "eino", "delay_ms": 100}`,
+		`{"delay_ms": 100, "query": "eino"}`, // 字段顺序不同，仍然命中缓存
+		`{"query": "milvus", "delay_ms": 1000}`,
+		`{"delay_ms": 10}`, // 缺少必填参数
+	} {
+		start := time.Now()
+		result, err := invokable.InvokableRun(ctx, args)
+		fmt.Printf("参数 %s\n  耗时 %s，结果: %s，错误: %v\n", args, time.Since(start).Round(time.Millisecond), result, err)
+	}
+	fmt.Printf("搜索工具实际执行了 %d 次\n", searchCalls.Load())
+
+	// 3. 限流: 每秒 5 次，突发 2 次
+	fmt.Println("\n=== 3. 限流 ===")
+	limited, err := middleware.Wrap(ctx, searchTool,
+		middleware.WithRateLimit(middleware.RateLimitConfig{Rate: 5, Burst: 2}),
+	)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := limited.(tool.InvokableTool).InvokableRun(ctx, `{"query": "rate"}`); err != nil {
+			return err
+		}
+		fmt.Printf("第 %d 次调用完成，距开始 %s\n", i+1, time.Since(start).Round(10*time.Millisecond))
+	}
+
+	// 4. 流式工具: 日志 + 指标 + 超时覆盖整个流
+	fmt.Println("\n=== 4. 流式工具 (InferStreamTool) ===")
+	countdownTool, err := newCountdownTool()
+	if err != nil {
+		return err
+	}
+	countdown, err := middleware.Wrap(ctx, countdownTool,
+		middleware.WithLogging(),
+		middleware.WithMetrics(metrics),
+		middleware.WithTimeout(200*time.Millisecond),
+	)
+	if err != nil {
+		return err
+	}
+	for _, args := range []string{`{"from": 3}`, `{"from": 10}`} {
+		sr, err := countdown.(tool.StreamableTool).StreamableRun(ctx, args)
+		if err != nil {
+			return err
+		}
+		var sb strings.Builder
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				sb.WriteString(fmt.Sprintf(" [错误: %v]", err))
+				break
+			}
+			sb.WriteString(chunk)
+		}
+		sr.Close()
+		fmt.Printf("参数 %s -> %s\n", args, sb.String())
+	}
+
+	// 5. 指标
+	fmt.Println("\n=== 5. 调用指标 ===")
+	for _, s := range metrics.Snapshot() {
+		fmt.Printf("%-12s 调用 %d 次，失败 %d 次 (超时 %d 次)，平均耗时 %s，最长耗时 %s\n",
+			s.Name, s.Calls, s.Errors, s.Timeouts, s.AvgLatency().Round(time.Millisecond), s.MaxLatency.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// main 是程序的入口点。
+func main() {
+	if err := demonstrateMiddleware(); err != nil {
+		log.Fatalf("中间件示例失败: %v", err)
+	}
+}
@@ -3,12 +3,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
+	"Eini/tools/validation"
+
 	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/cloudwego/eino/schema"
 )
@@ -99,16 +102,19 @@ func formatString(ctx context.Context, req *StringFormatRequest) (*StringFormatR
 
 // --- 示例 3: 数据验证函数 ---
 
+// ValidationRequest 的校验规则通过 validate / label 标签声明 (见 tools/validation)
 type ValidationRequest struct {
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Age      int    `json:"age"`
-	Username string `json:"username"`
+	Email    string `json:"email" validate:"required,email" label:"zh=邮箱,en=Email"`
+	Phone    string `json:"phone" validate:"required,cn_mobile" label:"zh=手机号,en=Phone"`
+	Age      int    `json:"age" validate:"range=0..150" label:"zh=年龄,en=Age"`
+	Username string `json:"username" validate:"required,min_len=3,max_len=20" label:"zh=用户名,en=Username"`
+	IDCard   string `json:"id_card,omitempty" validate:"cn_id_card" label:"zh=身份证号,en=ID card"`
+	Locale   string `json:"locale,omitempty"`
 }
 
 type ValidationResponse struct {
-	IsValid bool     `json:"is_valid"`
-	Errors  []string `json:"errors"`
+	IsValid bool                     `json:"is_valid"`
+	Errors  []*validation.FieldError `json:"errors"`
 }
 
 // validateUserData 用户数据验证函数
@@ -116,45 +122,30 @@ type ValidationResponse struct {
 //   - ctx: 上下文对象
 //   - req: 包含用户数据的验证请求
 // 返回:
-//   - 包含验证结果和错误信息的响应对象
-//   - 错误信息（本例中总是返回 nil）
+//   - 包含验证结果和按字段列出的错误信息的响应对象
+//   - 错误信息（校验规则本身无效时返回）
 func validateUserData(ctx context.Context, req *ValidationRequest) (*ValidationResponse, error) {
 	log.Printf("[ValidateUserData] 验证用户数据: %s", req.Username)
 
-	var errors []string
-
-	// 验证邮箱格式：必须包含 @ 和 . 符号
-	if !strings.Contains(req.Email, "@") || !strings.Contains(req.Email, ".") {
-		errors = append(errors, "邮箱格式不正确")
-	}
-
-	// 验证手机号长度：必须为11位
-	if len(req.Phone) != 11 {
-		errors = append(errors, "手机号长度不正确")
-	}
-
-	// 验证年龄范围：0-150岁
-	if req.Age < 0 || req.Age > 150 {
-		errors = append(errors, "年龄不在有效范围内")
-	}
-
-	// 验证用户名长度：3-20个字符
-	if len(req.Username) < 3 || len(req.Username) > 20 {
-		errors = append(errors, "用户名长度应在3-20个字符之间")
+	err := validation.Struct(req, req.Locale)
+	var verr *validation.ValidationError
+	switch {
+	case err == nil:
+		return &ValidationResponse{IsValid: true, Errors: []*validation.FieldError{}}, nil
+	case errors.As(err, &verr):
+		return &ValidationResponse{IsValid: false, Errors: verr.Errors}, nil
+	default:
+		return nil, err
 	}
-
-	return &ValidationResponse{
-		IsValid: len(errors) == 0,
-		Errors:  errors,
-	}, nil
 }
 
 // --- 示例 4: 数据转换函数 ---
 
 type ConversionRequest struct {
-	Value    string `json:"value"`
-	FromUnit string `json:"from_unit"`
-	ToUnit   string `json:"to_unit"`
+	Value     string `json:"value"`
+	FromUnit  string `json:"from_unit"`
+	ToUnit    string `json:"to_unit"`
+	Dimension string `json:"dimension"`
 }
 
 type ConversionResponse struct {
@@ -162,51 +153,53 @@ type ConversionResponse struct {
 	ConvertedValue float64 `json:"converted_value"`
 	FromUnit       string  `json:"from_unit"`
 	ToUnit         string  `json:"to_unit"`
+	Dimension      string  `json:"dimension"`
+	Result         string  `json:"result"`
 	Formula        string  `json:"formula"`
 }
 
-// convertUnits 单位转换函数（温度转换示例）
+// convertUnits 单位转换函数，支持长度、质量、体积、面积、时间、速度、数据大小、能量、压强和温度
+// (单位定义和换算见 units.go)
 // 参数:
 //   - ctx: 上下文对象
-//   - req: 包含转换值和单位的请求
+//   - req: 包含转换值和单位的请求；value 可以自带单位，也可以是复合写法，如 "5 ft 3 in"、"3斤2两"
 // 返回:
 //   - 包含转换结果和转换公式的响应对象
-//   - 错误信息（解析失败或不支持的转换时）
+//   - 错误信息（解析失败、单位不支持或量纲不一致时）
 func convertUnits(ctx context.Context, req *ConversionRequest) (*ConversionResponse, error) {
 	log.Printf("[ConvertUnits] 转换 %s from %s to %s", req.Value, req.FromUnit, req.ToUnit)
 
-	// 解析输入的数值
-	value, err := strconv.ParseFloat(req.Value, 64)
+	if req.Dimension != "" && !slices.Contains(Dimensions, req.Dimension) {
+		return nil, fmt.Errorf("不支持的量纲: %s", req.Dimension)
+	}
+	to, err := LookupUnit(req.ToUnit, req.Dimension)
 	if err != nil {
-		return nil, fmt.Errorf("无法解析数值: %v", err)
+		return nil, err
+	}
+	// 解析输入的数值，未指定量纲时以目标单位的量纲为准
+	quantities, err := ParseQuantity(req.Value, req.FromUnit, to.Dimension)
+	if err != nil {
+		return nil, err
+	}
+	result, formula, err := Convert(quantities, to)
+	if err != nil {
+		return nil, err
 	}
 
-	var result float64
-	var formula string
-
-	// 简单的温度转换示例，支持摄氏度、华氏度、开尔文温度之间的转换
-	switch {
-	case req.FromUnit == "celsius" && req.ToUnit == "fahrenheit":
-		result = value*9/5 + 32
-		formula = "°F = °C × 9/5 + 32"
-	case req.FromUnit == "fahrenheit" && req.ToUnit == "celsius":
-		result = (value - 32) * 5 / 9
-		formula = "°C = (°F - 32) × 5/9"
-	case req.FromUnit == "celsius" && req.ToUnit == "kelvin":
-		result = value + 273.15
-		formula = "K = °C + 273.15"
-	case req.FromUnit == "kelvin" && req.ToUnit == "celsius":
-		result = value - 273.15
-		formula = "°C = K - 273.15"
-	default:
-		return nil, fmt.Errorf("不支持从 %s 到 %s 的转换", req.FromUnit, req.ToUnit)
+	from := make([]string, 0, len(quantities))
+	for _, q := range quantities {
+		if !slices.Contains(from, q.Unit.Symbol) {
+			from = append(from, q.Unit.Symbol)
+		}
 	}
 
 	return &ConversionResponse{
 		OriginalValue:  req.Value,
 		ConvertedValue: result,
-		FromUnit:       req.FromUnit,
-		ToUnit:         req.ToUnit,
+		FromUnit:       strings.Join(from, " + "),
+		ToUnit:         to.Symbol,
+		Dimension:      to.Dimension,
+		Result:         formatNumber(result) + " " + to.Symbol,
 		Formula:        formula,
 	}, nil
 }
@@ -278,6 +271,8 @@ func demonstrateNewTool() {
 			"phone":    {Type: "string", Desc: "手机号", Required: true},
 			"age":      {Type: "integer", Desc: "年龄", Required: true},
 			"username": {Type: "string", Desc: "用户名", Required: true},
+			"id_card":  {Type: "string", Desc: "18 位居民身份证号"},
+			"locale":   {Type: "string", Desc: "错误消息的语言", Enum: []string{"zh", "en"}},
 		}),
 	}
 
@@ -297,31 +292,57 @@ func demonstrateNewTool() {
 		fmt.Printf("验证结果: %s\n\n", validationResult)
 	}
 
+	// 不合法的数据: 返回按字段列出的错误，locale 指定错误消息的语言
+	for _, locale := range []string{"zh", "en"} {
+		invalidResult, err := validationTool.InvokableRun(ctx, fmt.Sprintf(`{
+			"email": "user@example",
+			"phone": "12812345678",
+			"age": 200,
+			"username": "al",
+			"id_card": "110105194912310021",
+			"locale": %q
+		}`, locale))
+		if err != nil {
+			log.Printf("验证工具执行失败: %v", err)
+		} else {
+			fmt.Printf("验证结果 (%s): %s\n\n", locale, invalidResult)
+		}
+	}
+
 	// 4. 包装单位转换函数为工具
 	conversionToolInfo := &schema.ToolInfo{
-		Name: "convert_temperature",
-		Desc: "转换温度单位",
+		Name: "convert_units",
+		Desc: "在同一量纲的单位之间换算，支持公制、英制 / 美制和中国市制单位 (里、尺、斤、两、亩等)，数据大小区分 kB (1000) 和 KiB (1024)。返回结果和换算公式",
 		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"value":     {Type: "string", Desc: "要转换的温度值", Required: true},
-			"from_unit": {Type: "string", Desc: "原始单位", Required: true, Enum: []string{"celsius", "fahrenheit", "kelvin"}},
-			"to_unit":   {Type: "string", Desc: "目标单位", Required: true, Enum: []string{"celsius", "fahrenheit", "kelvin"}},
+			"value":     {Type: "string", Desc: "要转换的值，可以带单位或使用复合写法，如 25、5 ft 3 in、1小时30分钟、3斤2两", Required: true},
+			"from_unit": {Type: "string", Desc: "原始单位，value 中已带单位时可以省略，如 °C、mile、斤、MiB"},
+			"to_unit":   {Type: "string", Desc: "目标单位，如 °F、km、kg、GB", Required: true},
+			"dimension": {Type: "string", Desc: "量纲，指定后会校验单位是否属于该量纲", Enum: Dimensions},
 		}),
 	}
 
 	conversionTool := utils.NewTool(conversionToolInfo, convertUnits)
 
-	fmt.Println("--- 温度转换工具测试 ---")
-	// 测试温度单位转换功能
-	conversionResult, err := conversionTool.InvokableRun(ctx, `{
-		"value": "25",
-		"from_unit": "celsius",
-		"to_unit": "fahrenheit"
-	}`)
-	if err != nil {
-		log.Printf("转换工具执行失败: %v", err)
-	} else {
-		fmt.Printf("转换结果: %s\n\n", conversionResult)
+	fmt.Println("--- 单位转换工具测试 ---")
+	// 测试多个量纲的单位转换，包括复合写法和市制单位
+	for _, args := range []string{
+		`{"value": "25", "from_unit": "celsius", "to_unit": "fahrenheit", "dimension": "temperature"}`,
+		`{"value": "5 ft 3 in", "to_unit": "cm"}`,
+		`{"value": "3斤2两", "to_unit": "kg"}`,
+		`{"value": "1.5", "from_unit": "GiB", "to_unit": "MB", "dimension": "data"}`,
+		`{"value": "1小时30分钟", "to_unit": "min"}`,
+		`{"value": "10", "from_unit": "亩", "to_unit": "m2"}`,
+		`{"value": "100", "from_unit": "km/h", "to_unit": "mph"}`,
+		`{"value": "5", "from_unit": "kg", "to_unit": "m"}`,
+	} {
+		conversionResult, err := conversionTool.InvokableRun(ctx, args)
+		if err != nil {
+			log.Printf("转换工具执行失败: %v", err)
+			continue
+		}
+		fmt.Printf("转换结果: %s\n", conversionResult)
 	}
+	fmt.Println()
 }
 
 // main 函数：程序入口点，执行所有演示
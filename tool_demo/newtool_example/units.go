@@ -0,0 +1,367 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+//
+//  文件: units.go
+//  功能: 单位转换工具使用的单位换算引擎，按量纲组织单位:
+//        长度、质量、体积、面积、时间、速度、数据大小、能量、压强和温度，
+//        包含公制、英制 / 美制和中国市制单位 (里、丈、尺、寸、斤、两、钱、亩)。
+//  说明: 每个单位记录换算到该量纲基准单位的方式: 基准值 = (值 + Offset) × Factor，
+//        只有温度的 Offset 不为 0。两个单位之间的换算因此总是线性的: to = from × a + b，
+//        换算公式直接由 a、b 生成。
+//        数据大小区分 SI 前缀 (kB = 1000 B) 和 IEC 前缀 (KiB = 1024 B)，
+//        b 表示比特、B 表示字节，所以数据单位的符号区分大小写。
+//
+// =============================================================================
+
+// 支持的量纲
+const (
+	DimensionLength      = "length"
+	DimensionMass        = "mass"
+	DimensionVolume      = "volume"
+	DimensionArea        = "area"
+	DimensionTime        = "time"
+	DimensionSpeed       = "speed"
+	DimensionData        = "data"
+	DimensionEnergy      = "energy"
+	DimensionPressure    = "pressure"
+	DimensionTemperature = "temperature"
+)
+
+// Dimensions 所有支持的量纲，用作工具参数的枚举值
+var Dimensions = []string{
+	DimensionLength, DimensionMass, DimensionVolume, DimensionArea, DimensionTime,
+	DimensionSpeed, DimensionData, DimensionEnergy, DimensionPressure, DimensionTemperature,
+}
+
+// Unit 一个计量单位
+type Unit struct {
+	Symbol    string   // 显示用的符号，如 m、°C、KiB
+	Dimension string   // 所属量纲
+	Factor    float64  // 基准值 = (值 + Offset) × Factor
+	Offset    float64  // 只有温度不为 0
+	Symbols   []string // 区分大小写的其他写法 (数据单位必须区分 b / B)
+	Aliases   []string // 不区分大小写的其他写法，如 meter、metre、公里
+}
+
+// 各量纲的基准单位: m、kg、m³、m²、s、m/s、B、J、Pa、K
+var units = []*Unit{
+	// 长度
+	{Symbol: "m", Dimension: DimensionLength, Factor: 1, Aliases: []string{"meter", "meters", "metre", "metres", "米"}},
+	{Symbol: "km", Dimension: DimensionLength, Factor: 1e3, Aliases: []string{"kilometer", "kilometers", "kilometre", "公里", "千米"}},
+	{Symbol: "cm", Dimension: DimensionLength, Factor: 1e-2, Aliases: []string{"centimeter", "centimeters", "centimetre", "厘米"}},
+	{Symbol: "mm", Dimension: DimensionLength, Factor: 1e-3, Aliases: []string{"millimeter", "millimeters", "millimetre", "毫米"}},
+	{Symbol: "µm", Dimension: DimensionLength, Factor: 1e-6, Aliases: []string{"um", "micrometer", "micron", "微米"}},
+	{Symbol: "nm", Dimension: DimensionLength, Factor: 1e-9, Aliases: []string{"nanometer", "纳米"}},
+	{Symbol: "in", Dimension: DimensionLength, Factor: 0.0254, Symbols: []string{`"`, "″", "''"}, Aliases: []string{"inch", "inches", "英寸"}},
+	{Symbol: "ft", Dimension: DimensionLength, Factor: 0.3048, Symbols: []string{"'", "′"}, Aliases: []string{"foot", "feet", "英尺"}},
+	{Symbol: "yd", Dimension: DimensionLength, Factor: 0.9144, Aliases: []string{"yard", "yards", "码"}},
+	{Symbol: "mi", Dimension: DimensionLength, Factor: 1609.344, Aliases: []string{"mile", "miles", "英里"}},
+	{Symbol: "nmi", Dimension: DimensionLength, Factor: 1852, Aliases: []string{"nautical mile", "nautical miles", "海里"}},
+	{Symbol: "里", Dimension: DimensionLength, Factor: 500, Aliases: []string{"li", "市里"}},
+	{Symbol: "丈", Dimension: DimensionLength, Factor: 10.0 / 3, Aliases: []string{"zhang", "市丈"}},
+	{Symbol: "尺", Dimension: DimensionLength, Factor: 1.0 / 3, Aliases: []string{"chi", "市尺"}},
+	{Symbol: "寸", Dimension: DimensionLength, Factor: 1.0 / 30, Aliases: []string{"cun", "市寸"}},
+
+	// 质量
+	{Symbol: "kg", Dimension: DimensionMass, Factor: 1, Aliases: []string{"kilogram", "kilograms", "千克", "公斤"}},
+	{Symbol: "g", Dimension: DimensionMass, Factor: 1e-3, Aliases: []string{"gram", "grams", "克"}},
+	{Symbol: "mg", Dimension: DimensionMass, Factor: 1e-6, Aliases: []string{"milligram", "milligrams", "毫克"}},
+	{Symbol: "t", Dimension: DimensionMass, Factor: 1e3, Aliases: []string{"tonne", "tonnes", "metric ton", "吨", "公吨"}},
+	{Symbol: "lb", Dimension: DimensionMass, Factor: 0.45359237, Aliases: []string{"lbs", "pound", "pounds", "磅"}},
+	{Symbol: "oz", Dimension: DimensionMass, Factor: 0.028349523125, Aliases: []string{"ounce", "ounces", "盎司"}},
+	{Symbol: "st", Dimension: DimensionMass, Factor: 6.35029318, Aliases: []string{"stone", "英石"}},
+	{Symbol: "斤", Dimension: DimensionMass, Factor: 0.5, Aliases: []string{"jin", "市斤"}},
+	{Symbol: "两", Dimension: DimensionMass, Factor: 0.05, Aliases: []string{"liang", "市两"}},
+	{Symbol: "钱", Dimension: DimensionMass, Factor: 0.005, Aliases: []string{"qian", "市钱"}},
+
+	// 体积
+	{Symbol: "m³", Dimension: DimensionVolume, Factor: 1, Aliases: []string{"cubic meter", "cubic meters", "立方米", "方"}},
+	{Symbol: "L", Dimension: DimensionVolume, Factor: 1e-3, Aliases: []string{"l", "liter", "liters", "litre", "litres", "升", "公升"}},
+	{Symbol: "mL", Dimension: DimensionVolume, Factor: 1e-6, Aliases: []string{"ml", "milliliter", "milliliters", "millilitre", "毫升"}},
+	{Symbol: "cm³", Dimension: DimensionVolume, Factor: 1e-6, Aliases: []string{"cc", "cubic centimeter", "立方厘米"}},
+	{Symbol: "gal", Dimension: DimensionVolume, Factor: 3.785411784e-3, Aliases: []string{"gallon", "gallons", "us gal", "加仑", "美制加仑"}},
+	{Symbol: "imp gal", Dimension: DimensionVolume, Factor: 4.54609e-3, Aliases: []string{"imperial gallon", "imperial gallons", "英制加仑"}},
+	{Symbol: "qt", Dimension: DimensionVolume, Factor: 9.46352946e-4, Aliases: []string{"quart", "quarts", "夸脱"}},
+	{Symbol: "pt", Dimension: DimensionVolume, Factor: 4.73176473e-4, Aliases: []string{"pint", "pints", "品脱"}},
+	{Symbol: "cup", Dimension: DimensionVolume, Factor: 2.365882365e-4, Aliases: []string{"cups", "杯"}},
+	{Symbol: "fl oz", Dimension: DimensionVolume, Factor: 2.95735295625e-5, Aliases: []string{"floz", "fluid ounce", "fluid ounces", "液量盎司"}},
+	{Symbol: "ft³", Dimension: DimensionVolume, Factor: 0.028316846592, Aliases: []string{"cubic foot", "cubic feet", "立方英尺"}},
+	{Symbol: "in³", Dimension: DimensionVolume, Factor: 1.6387064e-5, Aliases: []string{"cubic inch", "cubic inches", "立方英寸"}},
+
+	// 面积
+	{Symbol: "m²", Dimension: DimensionArea, Factor: 1, Aliases: []string{"sq m", "square meter", "square meters", "平方米", "平米"}},
+	{Symbol: "km²", Dimension: DimensionArea, Factor: 1e6, Aliases: []string{"sq km", "square kilometer", "square kilometers", "平方千米", "平方公里"}},
+	{Symbol: "cm²", Dimension: DimensionArea, Factor: 1e-4, Aliases: []string{"sq cm", "square centimeter", "平方厘米"}},
+	{Symbol: "ha", Dimension: DimensionArea, Factor: 1e4, Aliases: []string{"hectare", "hectares", "公顷"}},
+	{Symbol: "ac", Dimension: DimensionArea, Factor: 4046.8564224, Aliases: []string{"acre", "acres", "英亩"}},
+	{Symbol: "ft²", Dimension: DimensionArea, Factor: 0.09290304, Aliases: []string{"sq ft", "square foot", "square feet", "平方英尺"}},
+	{Symbol: "in²", Dimension: DimensionArea, Factor: 6.4516e-4, Aliases: []string{"sq in", "square inch", "square inches", "平方英寸"}},
+	{Symbol: "mi²", Dimension: DimensionArea, Factor: 2589988.110336, Aliases: []string{"sq mi", "square mile", "square miles", "平方英里"}},
+	{Symbol: "亩", Dimension: DimensionArea, Factor: 10000.0 / 15, Aliases: []string{"mu", "市亩"}},
+
+	// 时间 (年按儒略年 365.25 天，月按平均 30.436875 天)
+	{Symbol: "s", Dimension: DimensionTime, Factor: 1, Aliases: []string{"sec", "secs", "second", "seconds", "秒"}},
+	{Symbol: "ms", Dimension: DimensionTime, Factor: 1e-3, Aliases: []string{"millisecond", "milliseconds", "毫秒"}},
+	{Symbol: "µs", Dimension: DimensionTime, Factor: 1e-6, Aliases: []string{"us", "microsecond", "microseconds", "微秒"}},
+	{Symbol: "ns", Dimension: DimensionTime, Factor: 1e-9, Aliases: []string{"nanosecond", "nanoseconds", "纳秒"}},
+	{Symbol: "min", Dimension: DimensionTime, Factor: 60, Aliases: []string{"mins", "minute", "minutes", "分钟", "分"}},
+	{Symbol: "h", Dimension: DimensionTime, Factor: 3600, Aliases: []string{"hr", "hrs", "hour", "hours", "小时", "时"}},
+	{Symbol: "d", Dimension: DimensionTime, Factor: 86400, Aliases: []string{"day", "days", "天", "日"}},
+	{Symbol: "wk", Dimension: DimensionTime, Factor: 604800, Aliases: []string{"week", "weeks", "周", "星期"}},
+	{Symbol: "mo", Dimension: DimensionTime, Factor: 2629746, Aliases: []string{"month", "months", "月"}},
+	{Symbol: "yr", Dimension: DimensionTime, Factor: 31557600, Aliases: []string{"year", "years", "年"}},
+
+	// 速度
+	{Symbol: "m/s", Dimension: DimensionSpeed, Factor: 1, Aliases: []string{"mps", "米/秒", "米每秒"}},
+	{Symbol: "km/h", Dimension: DimensionSpeed, Factor: 1e3 / 3600, Aliases: []string{"kph", "kmh", "公里/小时", "千米/小时", "公里每小时"}},
+	{Symbol: "mph", Dimension: DimensionSpeed, Factor: 0.44704, Aliases: []string{"mi/h", "英里/小时", "英里每小时"}},
+	{Symbol: "ft/s", Dimension: DimensionSpeed, Factor: 0.3048, Aliases: []string{"fps", "英尺/秒"}},
+	{Symbol: "kn", Dimension: DimensionSpeed, Factor: 1852.0 / 3600, Aliases: []string{"knot", "knots", "kt", "节"}},
+
+	// 数据大小: 基准单位为字节，SI 前缀按 1000 进位，IEC 前缀按 1024 进位
+	{Symbol: "bit", Dimension: DimensionData, Factor: 0.125, Symbols: []string{"b"}, Aliases: []string{"bits", "比特", "位"}},
+	{Symbol: "B", Dimension: DimensionData, Factor: 1, Aliases: []string{"byte", "bytes", "字节"}},
+	{Symbol: "kbit", Dimension: DimensionData, Factor: 125, Symbols: []string{"kb", "Kb"}, Aliases: []string{"kilobit", "kilobits"}},
+	{Symbol: "Mbit", Dimension: DimensionData, Factor: 125e3, Symbols: []string{"Mb"}, Aliases: []string{"megabit", "megabits"}},
+	{Symbol: "Gbit", Dimension: DimensionData, Factor: 125e6, Symbols: []string{"Gb"}, Aliases: []string{"gigabit", "gigabits"}},
+	{Symbol: "Tbit", Dimension: DimensionData, Factor: 125e9, Symbols: []string{"Tb"}, Aliases: []string{"terabit", "terabits"}},
+	{Symbol: "kB", Dimension: DimensionData, Factor: 1e3, Symbols: []string{"KB"}, Aliases: []string{"kilobyte", "kilobytes"}},
+	{Symbol: "MB", Dimension: DimensionData, Factor: 1e6, Aliases: []string{"megabyte", "megabytes"}},
+	{Symbol: "GB", Dimension: DimensionData, Factor: 1e9, Aliases: []string{"gigabyte", "gigabytes"}},
+	{Symbol: "TB", Dimension: DimensionData, Factor: 1e12, Aliases: []string{"terabyte", "terabytes"}},
+	{Symbol: "PB", Dimension: DimensionData, Factor: 1e15, Aliases: []string{"petabyte", "petabytes"}},
+	{Symbol: "Kibit", Dimension: DimensionData, Factor: 128, Aliases: []string{"kibibit", "kibibits"}},
+	{Symbol: "Mibit", Dimension: DimensionData, Factor: 131072, Aliases: []string{"mebibit", "mebibits"}},
+	{Symbol: "Gibit", Dimension: DimensionData, Factor: 134217728, Aliases: []string{"gibibit", "gibibits"}},
+	{Symbol: "KiB", Dimension: DimensionData, Factor: 1 << 10, Aliases: []string{"kibibyte", "kibibytes"}},
+	{Symbol: "MiB", Dimension: DimensionData, Factor: 1 << 20, Aliases: []string{"mebibyte", "mebibytes"}},
+	{Symbol: "GiB", Dimension: DimensionData, Factor: 1 << 30, Aliases: []string{"gibibyte", "gibibytes"}},
+	{Symbol: "TiB", Dimension: DimensionData, Factor: 1 << 40, Aliases: []string{"tebibyte", "tebibytes"}},
+	{Symbol: "PiB", Dimension: DimensionData, Factor: 1 << 50, Aliases: []string{"pebibyte", "pebibytes"}},
+
+	// 能量
+	{Symbol: "J", Dimension: DimensionEnergy, Factor: 1, Aliases: []string{"joule", "joules", "焦", "焦耳"}},
+	{Symbol: "kJ", Dimension: DimensionEnergy, Factor: 1e3, Aliases: []string{"kilojoule", "kilojoules", "千焦"}},
+	{Symbol: "MJ", Dimension: DimensionEnergy, Factor: 1e6, Aliases: []string{"megajoule", "megajoules", "兆焦"}},
+	{Symbol: "cal", Dimension: DimensionEnergy, Factor: 4.184, Aliases: []string{"calorie", "calories", "卡", "卡路里"}},
+	{Symbol: "kcal", Dimension: DimensionEnergy, Factor: 4184, Aliases: []string{"kilocalorie", "kilocalories", "千卡", "大卡"}},
+	{Symbol: "Wh", Dimension: DimensionEnergy, Factor: 3600, Aliases: []string{"watt hour", "watt hours", "瓦时"}},
+	{Symbol: "kWh", Dimension: DimensionEnergy, Factor: 3.6e6, Aliases: []string{"kilowatt hour", "kilowatt hours", "千瓦时", "度"}},
+	{Symbol: "BTU", Dimension: DimensionEnergy, Factor: 1055.05585262, Aliases: []string{"btu", "英热单位"}},
+	{Symbol: "eV", Dimension: DimensionEnergy, Factor: 1.602176634e-19, Aliases: []string{"electronvolt", "electronvolts", "电子伏特"}},
+
+	// 压强
+	{Symbol: "Pa", Dimension: DimensionPressure, Factor: 1, Aliases: []string{"pascal", "pascals", "帕", "帕斯卡"}},
+	{Symbol: "hPa", Dimension: DimensionPressure, Factor: 100, Aliases: []string{"hectopascal", "百帕"}},
+	{Symbol: "kPa", Dimension: DimensionPressure, Factor: 1e3, Aliases: []string{"kilopascal", "千帕"}},
+	{Symbol: "MPa", Dimension: DimensionPressure, Factor: 1e6, Aliases: []string{"megapascal", "兆帕"}},
+	{Symbol: "bar", Dimension: DimensionPressure, Factor: 1e5, Aliases: []string{"bars", "巴"}},
+	{Symbol: "mbar", Dimension: DimensionPressure, Factor: 100, Aliases: []string{"millibar", "毫巴"}},
+	{Symbol: "atm", Dimension: DimensionPressure, Factor: 101325, Aliases: []string{"atmosphere", "atmospheres", "标准大气压", "大气压"}},
+	{Symbol: "psi", Dimension: DimensionPressure, Factor: 6894.757293168, Aliases: []string{"lbf/in²", "磅力每平方英寸"}},
+	{Symbol: "mmHg", Dimension: DimensionPressure, Factor: 133.322387415, Aliases: []string{"毫米汞柱"}},
+	{Symbol: "inHg", Dimension: DimensionPressure, Factor: 3386.389, Aliases: []string{"英寸汞柱"}},
+	{Symbol: "Torr", Dimension: DimensionPressure, Factor: 101325.0 / 760, Aliases: []string{"torr", "托"}},
+
+	// 温度: 基准单位为开尔文
+	{Symbol: "K", Dimension: DimensionTemperature, Factor: 1, Aliases: []string{"kelvin", "开", "开尔文"}},
+	{Symbol: "°C", Dimension: DimensionTemperature, Factor: 1, Offset: 273.15, Symbols: []string{"C", "℃"}, Aliases: []string{"celsius", "degc", "摄氏度"}},
+	{Symbol: "°F", Dimension: DimensionTemperature, Factor: 5.0 / 9, Offset: 459.67, Symbols: []string{"F", "℉"}, Aliases: []string{"fahrenheit", "degf", "华氏度"}},
+	{Symbol: "°R", Dimension: DimensionTemperature, Factor: 5.0 / 9, Symbols: []string{"R"}, Aliases: []string{"rankine", "兰氏度"}},
+}
+
+var (
+	unitsBySymbol = make(map[string]*Unit) // 区分大小写
+	unitsByAlias  = make(map[string]*Unit) // 小写
+)
+
+func init() {
+	for _, u := range units {
+		for _, s := range append([]string{u.Symbol}, u.Symbols...) {
+			unitsBySymbol[normalizeUnitText(s)] = u
+		}
+		for _, a := range u.Aliases {
+			unitsByAlias[strings.ToLower(normalizeUnitText(a))] = u
+		}
+	}
+}
+
+var (
+	// 末尾的数字表示平方或立方: m2 -> m²，ft3 -> ft³ (只处理末尾，避免把 5ft3in 中的 3 当作立方)
+	trailingPowerPattern = regexp.MustCompile(`[A-Za-zµ]([23])$`)
+	// 数值: 整数、小数和科学计数法
+	numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?(?:[eE][-+]?\d+)?|\.\d+`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+
+	unitTextReplacer = strings.NewReplacer("μ", "µ", "º", "°", "^2", "²", "^3", "³")
+	superscripts     = map[string]string{"2": "²", "3": "³"}
+)
+
+// normalizeUnitText 统一单位的写法: 平方和立方 (m^2、m2 -> m²)、μ (希腊字母) 和 µ (微符号)、多余空白
+func normalizeUnitText(s string) string {
+	s = unitTextReplacer.Replace(strings.TrimSpace(s))
+	if m := trailingPowerPattern.FindStringSubmatchIndex(s); m != nil {
+		s = s[:m[2]] + superscripts[s[m[2]:m[3]]]
+	}
+	return spacePattern.ReplaceAllString(s, " ")
+}
+
+// LookupUnit 查找单位: 先按区分大小写的符号查找，再按不区分大小写的名称查找。
+// dimension 不为空时，找到的单位必须属于该量纲。
+func LookupUnit(text, dimension string) (*Unit, error) {
+	key := normalizeUnitText(text)
+	u, ok := unitsBySymbol[key]
+	if !ok {
+		u, ok = unitsByAlias[strings.ToLower(key)]
+	}
+	if !ok {
+		if dimension != "" {
+			return nil, fmt.Errorf("不支持的单位: %s (%s 支持: %s)", text, dimension, strings.Join(UnitsOf(dimension), ", "))
+		}
+		return nil, fmt.Errorf("不支持的单位: %s", text)
+	}
+	if dimension != "" && u.Dimension != dimension {
+		return nil, fmt.Errorf("单位 %s 属于 %s，不属于 %s", text, u.Dimension, dimension)
+	}
+	return u, nil
+}
+
+// Quantity 带单位的数值
+type Quantity struct {
+	Value float64
+	Unit  *Unit
+}
+
+// ParseQuantity 解析带单位的数值，支持复合写法 (如 "5 ft 3 in"、"5'3\""、"1小时30分钟"、"3斤2两")。
+// 只有一个数值且没有写单位时使用 defaultUnit；复合写法的各部分必须属于同一量纲，温度不支持复合写法。
+func ParseQuantity(input, defaultUnit, dimension string) ([]Quantity, error) {
+	text := strings.TrimSpace(normalizeUnitText(input))
+	negative := false
+	switch {
+	case strings.HasPrefix(text, "-"):
+		negative, text = true, strings.TrimSpace(text[1:])
+	case strings.HasPrefix(text, "+"):
+		text = strings.TrimSpace(text[1:])
+	}
+
+	locs := numberPattern.FindAllStringIndex(text, -1)
+	if len(locs) == 0 || locs[0][0] != 0 {
+		return nil, fmt.Errorf("无法解析数值: %s", input)
+	}
+
+	quantities := make([]Quantity, 0, len(locs))
+	for i, loc := range locs {
+		value, err := strconv.ParseFloat(text[loc[0]:loc[1]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析数值 %s: %w", text[loc[0]:loc[1]], err)
+		}
+		if negative {
+			value = -value
+		}
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		unitText := strings.TrimSpace(text[loc[1]:end])
+		if unitText == "" {
+			if len(locs) > 1 {
+				return nil, fmt.Errorf("复合数值 %s 的每一部分都必须带单位", input)
+			}
+			if defaultUnit == "" {
+				return nil, fmt.Errorf("数值 %s 没有单位，请提供 from_unit", input)
+			}
+			unitText = defaultUnit
+		}
+		u, err := LookupUnit(unitText, dimension)
+		if err != nil {
+			return nil, err
+		}
+		if len(quantities) > 0 && u.Dimension != quantities[0].Unit.Dimension {
+			return nil, fmt.Errorf("复合数值 %s 的单位 %s 和 %s 不属于同一量纲", input, quantities[0].Unit.Symbol, u.Symbol)
+		}
+		quantities = append(quantities, Quantity{Value: value, Unit: u})
+	}
+	if len(quantities) > 1 && quantities[0].Unit.Dimension == DimensionTemperature {
+		return nil, fmt.Errorf("温度不支持复合写法: %s", input)
+	}
+	return quantities, nil
+}
+
+// linear 从 from 换算到 to 的线性系数: to = from × a + b
+func linear(from, to *Unit) (a, b float64) {
+	a = from.Factor / to.Factor
+	b = from.Offset*from.Factor/to.Factor - to.Offset
+	return a, b
+}
+
+// Convert 把 quantities 的和换算为 to 单位，返回结果和换算公式，如 "m = ft × 0.3048 + in × 0.0254"
+func Convert(quantities []Quantity, to *Unit) (float64, string, error) {
+	var result float64
+	terms := make([]string, 0, len(quantities))
+	seen := make(map[*Unit]bool, len(quantities))
+	for _, q := range quantities {
+		if q.Unit.Dimension != to.Dimension {
+			return 0, "", fmt.Errorf("不能从 %s (%s) 换算到 %s (%s)", q.Unit.Symbol, q.Unit.Dimension, to.Symbol, to.Dimension)
+		}
+		a, b := linear(q.Unit, to)
+		result += q.Value*a + b
+		if seen[q.Unit] {
+			continue
+		}
+		seen[q.Unit] = true
+
+		term := q.Unit.Symbol
+		if a != 1 {
+			term += " × " + formatNumber(a)
+		}
+		switch {
+		case b > 0:
+			term += " + " + formatNumber(b)
+		case b < 0:
+			term += " - " + formatNumber(-b)
+		}
+		terms = append(terms, term)
+	}
+	return roundSignificant(result), to.Symbol + " = " + strings.Join(terms, " + "), nil
+}
+
+// UnitsOf 列出量纲下的所有单位符号，按换算系数从小到大排列
+func UnitsOf(dimension string) []string {
+	var list []*Unit
+	for _, u := range units {
+		if u.Dimension == dimension {
+			list = append(list, u)
+		}
+	}
+	slices.SortStableFunc(list, func(x, y *Unit) int { return cmp.Compare(x.Factor, y.Factor) })
+	symbols := make([]string, len(list))
+	for i, u := range list {
+		symbols[i] = u.Symbol
+	}
+	return symbols
+}
+
+// roundSignificant 保留 12 位有效数字，去掉浮点运算的尾差 (如 76.99999999999999)
+func roundSignificant(v float64) float64 {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return v
+	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 12, 64), 64)
+	return r
+}
+
+// formatNumber 用最短的形式格式化数值，最多 10 位有效数字
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(roundSignificant(v), 'g', 10, 64)
+}
@@ -0,0 +1,151 @@
+// package main 表明这是一个可执行程序
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"Eini/tools/middleware"
+	"Eini/tools/openapitool"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: openapi_example.go
+//  功能: 演示如何通过 tools/openapitool 由 OpenAPI 3 文档生成 Eino 工具。
+//  说明: 默认使用 openapitool.FakeServer 提供的本地商品服务 (文档 + 接口)，不依赖外部服务；
+//        也可以通过 -spec 指定其他文档，凭证通过 -cred 名称=值 传入。
+//        生成的工具不需要手写 ToolInfo 和参数结构体，参数定义、请求组装和认证都来自文档。
+//
+// =============================================================================
+
+// credentialFlags 可以重复传入的 -cred 参数
+type credentialFlags map[string]string
+
+func (c credentialFlags) String() string { return fmt.Sprint(map[string]string(c)) }
+
+func (c credentialFlags) Set(value string) error {
+	name, secret, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("凭证格式应为 安全方案名称=值: %s", value)
+	}
+	c[name] = secret
+	return nil
+}
+
+// demonstrateOpenAPITools 读取文档、生成工具并调用
+func demonstrateOpenAPITools(specURL string, credentials map[string]string) error {
+	ctx := context.Background()
+
+	external := specURL != ""
+	if !external {
+		server := openapitool.NewFakeServer("demo-key", "demo-token")
+		defer server.Close()
+		specURL = server.SpecURL()
+		credentials = server.Credentials()
+		fmt.Printf("本地商品服务: %s\n", specURL)
+	}
+
+	doc, err := openapitool.LoadSpec(ctx, specURL, nil)
+	if err != nil {
+		return err
+	}
+
+	// 1. 每个操作生成一个工具，凭证按安全方案名称配置
+	fmt.Println("\n=== 1. 生成的工具 ===")
+	tools, err := openapitool.NewTools(&openapitool.Config{
+		Spec:             doc,
+		Credentials:      credentials,
+		MaxResponseBytes: 2048,
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range tools {
+		info, _ := t.Info(ctx)
+		params, _ := info.ParamsOneOf.ToOpenAPIV3()
+		data, _ := json.Marshal(params)
+		fmt.Printf("- %s: %s\n  参数: %s\n", info.Name, info.Desc, data)
+	}
+	if external {
+		return nil // 外部文档只列出工具，不调用未知的接口
+	}
+
+	// 2. 加上参数校验后注册到 ToolsNode，模拟模型返回的工具调用
+	fmt.Println("\n=== 2. 通过 ToolsNode 调用 ===")
+	checkedTools, err := middleware.WrapAll(ctx, tools, middleware.WithValidation())
+	if err != nil {
+		return err
+	}
+	toolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: checkedTools})
+	if err != nil {
+		return err
+	}
+	assistant := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call_1", Function: schema.FunctionCall{Name: "listProducts", Arguments: `{"category": "books", "tags": ["畅销"], "max_price": 100, "limit": 3}`}},
+		{ID: "call_2", Function: schema.FunctionCall{Name: "getProduct", Arguments: `{"id": 5}`}},
+		{ID: "call_3", Function: schema.FunctionCall{Name: "createOrder", Arguments: `{"Idempotency-Key": "demo-001", "body": {"items": [{"product_id": 5, "quantity": 2}], "note": "工作日送达"}}`}},
+		{ID: "call_4", Function: schema.FunctionCall{Name: "getProduct", Arguments: `{"id": 999}`}},
+		{ID: "call_5", Function: schema.FunctionCall{Name: "createOrder", Arguments: `{"body": {"items": [{"product_id": 5, "quantity": 0}]}}`}},
+	})
+	results, err := toolsNode.Invoke(ctx, assistant)
+	if err != nil {
+		return err
+	}
+	for _, msg := range results {
+		fmt.Printf("%s (%s) -> %s\n", msg.ToolName, msg.ToolCallID, msg.Content)
+	}
+
+	// 3. 响应超过 MaxResponseBytes 时截短其中的数组，并说明保留了多少项
+	fmt.Println("\n=== 3. 响应裁剪 ===")
+	byName := make(map[string]tool.InvokableTool)
+	for _, t := range tools {
+		info, _ := t.Info(ctx)
+		byName[info.Name] = t.(tool.InvokableTool)
+	}
+	output, err := byName["listProducts"].InvokableRun(ctx, `{"limit": 200}`)
+	if err != nil {
+		return err
+	}
+	var resp openapitool.Response
+	if err := json.Unmarshal([]byte(output), &resp); err != nil {
+		return err
+	}
+	fmt.Printf("原始 %d 字节，返回 %d 字节，裁剪: %v\n", resp.Bytes, len(output), resp.Truncated)
+	for _, o := range resp.Omitted {
+		fmt.Printf("  %s: 保留 %d / %d 项\n", o.Path, o.Kept, o.Total)
+	}
+
+	// 4. 没有配置凭证时，服务返回 401，状态码和错误信息作为结果返回给模型
+	fmt.Println("\n=== 4. 缺少凭证 ===")
+	anonymous, err := openapitool.New(&openapitool.Config{Spec: doc, Operations: []string{"getProduct"}})
+	if err != nil {
+		return err
+	}
+	output, err = anonymous[0].InvokableRun(ctx, `{"id": 1}`)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s -> %s\n", anonymous[0].Route(), output)
+	return nil
+}
+
+// main 是程序的入口点。
+func main() {
+	credentials := credentialFlags{}
+	specURL := flag.String("spec", "", "OpenAPI 文档的地址或文件路径，默认使用本地商品服务")
+	flag.Var(credentials, "cred", "凭证，格式为 安全方案名称=值，可以重复传入")
+	flag.Parse()
+
+	if err := demonstrateOpenAPITools(*specURL, credentials); err != nil {
+		log.Fatalf("OpenAPI 工具示例失败: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+// package main 表明这是一个可执行程序
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"Eini/tools/sqlquery"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: sqlquery_example.go
+//  功能: 演示 tools/sqlquery 提供的 sql_query 工具和 text-to-SQL 链: 工具描述中的数据库结构、
+//        查询结果、只读检查、行数和时间限制，以及模型起草 SQL -> 执行 -> 根据错误修正的循环。
+//  说明: 使用 sqlquery.CreateDemoDB 在临时目录生成商品数据库；
+//        text-to-SQL 使用按脚本回复的模拟模型，第一次故意写错列名，不需要真实的模型服务。
+//
+// =============================================================================
+
+// scriptedSQLModel 按顺序返回预设回复的模拟模型
+type scriptedSQLModel struct {
+	replies []string
+	calls   int
+}
+
+func (m *scriptedSQLModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if m.calls >= len(m.replies) {
+		return nil, errors.New("没有更多预设回复")
+	}
+	reply := m.replies[m.calls]
+	m.calls++
+	fmt.Printf("  [模型] 第 %d 次调用，收到 %d 条消息\n", m.calls, len(input))
+	return schema.AssistantMessage(reply, nil), nil
+}
+
+func (m *scriptedSQLModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+// printResult 以表格形式打印查询结果
+func printResult(result *sqlquery.Result) {
+	fmt.Printf("  列: %v\n", result.Columns)
+	for _, row := range result.Rows {
+		fmt.Printf("  %v\n", row)
+	}
+	fmt.Printf("  共 %d 行, 截断: %v\n", result.RowCount, result.Truncated)
+}
+
+// demonstrateSQLQuery 依次演示工具描述、查询、安全检查、限制和 text-to-SQL
+func demonstrateSQLQuery() error {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "sqlquery_example")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "shop.db")
+	if err := sqlquery.CreateDemoDB(path); err != nil {
+		return err
+	}
+
+	queryTool, err := sqlquery.New(&sqlquery.Config{
+		Path:        path,
+		Description: "金额单位为元，订单状态: paid 已付款, shipped 已发货, cancelled 已取消。",
+		MaxRows:     10,
+		Timeout:     time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	defer queryTool.Close()
+
+	// 1. 工具描述中包含数据库结构，模型不需要先查询表结构
+	fmt.Println("=== 1. 工具描述 ===")
+	info, _ := queryTool.Info(ctx)
+	fmt.Printf("工具: %s\n描述: %s\n", info.Name, info.Desc)
+
+	// 2. 统计和列表查询，结果以列名 + 行的 JSON 表格返回
+	fmt.Println("\n=== 2. 查询 ===")
+	for _, query := range []string{
+		"SELECT c.name AS category, count(*) AS products FROM products p JOIN categories c ON c.id = p.category_id GROUP BY c.name ORDER BY products DESC",
+		"SELECT name, units, revenue FROM product_sales ORDER BY revenue DESC LIMIT 3",
+		"WITH monthly AS (SELECT substr(created_at, 1, 7) AS month, count(*) AS n FROM orders WHERE status != 'cancelled' GROUP BY month) SELECT * FROM monthly ORDER BY month LIMIT 4",
+	} {
+		fmt.Println(query)
+		output, err := queryTool.InvokableRun(ctx, fmt.Sprintf(`{"sql": %q}`, query))
+		if err != nil {
+			return err
+		}
+		var result sqlquery.Result
+		if err := json.Unmarshal([]byte(output), &result); err != nil {
+			return err
+		}
+		printResult(&result)
+	}
+
+	// 3. 只读检查: 通过解析判断语句类型，字符串和注释中的内容不会被误判
+	fmt.Println("\n=== 3. 只读检查 ===")
+	for _, query := range []string{
+		"SELECT name FROM products WHERE name = '; DELETE FROM products' -- 字符串中的分号",
+		"DELETE FROM orders",
+		"SELECT 1; DROP TABLE products",
+		"WITH t AS (SELECT 1) UPDATE products SET price = 0",
+		"PRAGMA table_info(products)",
+		"SELECT load_extension('/tmp/evil.so')",
+	} {
+		result, err := queryTool.Query(ctx, query)
+		if err != nil {
+			fmt.Printf("%s\n  -> 拒绝: %v, 错误: %v\n", query, errors.Is(err, sqlquery.ErrNotReadOnly), err)
+			continue
+		}
+		fmt.Printf("%s\n  -> 允许执行: %d 行\n", query, result.RowCount)
+	}
+	// 作为工具调用时，拒绝的原因和提示作为结果返回给模型，而不是中断 ToolsNode
+	output, err := queryTool.InvokableRun(ctx, `{"sql": "DELETE FROM orders"}`)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("工具调用 DELETE FROM orders\n  -> %s\n", output)
+
+	// 4. 限制: 超过 MaxRows 的结果被截断，超时的查询被中断；SQL 错误作为结果返回给模型
+	fmt.Println("\n=== 4. 行数和时间限制 ===")
+	for _, query := range []string{
+		"SELECT id, status, created_at FROM orders",
+		"WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n) SELECT count(*) FROM n",
+		"SELECT total FROM orders",
+	} {
+		output, err := queryTool.InvokableRun(ctx, fmt.Sprintf(`{"sql": %q}`, query))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n  -> %s\n", query, output)
+	}
+
+	// 5. text-to-SQL: 第一次写错列名，根据错误信息修正后执行成功
+	fmt.Println("\n=== 5. text-to-SQL ===")
+	chatModel := &scriptedSQLModel{replies: []string{
+		"```sql\nSELECT name, sales FROM products ORDER BY sales DESC LIMIT 3\n```",
+		"products 表中没有 sales 列，改用 product_sales 视图:\n```sql\nSELECT name, units FROM product_sales ORDER BY units DESC LIMIT 3\n```",
+	}}
+	chain, err := sqlquery.NewText2SQL(ctx, &sqlquery.Text2SQLConfig{Model: chatModel, Query: queryTool})
+	if err != nil {
+		return err
+	}
+	answer, err := chain.Ask(ctx, "销量最高的三个商品是哪些？")
+	if err != nil {
+		return err
+	}
+	for i, attempt := range answer.Attempts {
+		fmt.Printf("第 %d 次: %s\n", i+1, attempt.SQL)
+		if attempt.Error != "" {
+			fmt.Printf("  错误: %s\n", attempt.Error)
+		}
+	}
+	if answer.Result == nil {
+		return fmt.Errorf("text-to-SQL 没有得到结果: %s", answer.Error)
+	}
+	fmt.Printf("最终 SQL: %s\n", answer.SQL)
+	printResult(answer.Result)
+	return nil
+}
+
+// main 是程序的入口点。
+func main() {
+	if err := demonstrateSQLQuery(); err != nil {
+		log.Fatalf("sql_query 示例失败: %v", err)
+	}
+}
@@ -6,9 +6,16 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"Eini/tools/loganalyzer"
+	"Eini/tools/streamutil"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -53,32 +60,17 @@ func (s *StreamTextGeneratorTool) Info(ctx context.Context) (*schema.ToolInfo, e
 
 // InvokableRun 实现非流式调用接口，返回完整的生成结果
 // 该方法内部调用 StreamableRun 并收集所有流式输出
-func (s *StreamTextGeneratorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
-	// 对于流式工具，InvokableRun 通常返回完整结果
+func (s *StreamTextGeneratorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	// 对于流式工具，InvokableRun 通常返回完整结果: 收集所有流式输出块并拼接
 	reader, err := s.StreamableRun(ctx, argumentsInJSON, opts...)
 	if err != nil {
 		return "", err
 	}
-	defer reader.Close()
-
-	// 收集所有流式输出块并拼接成完整结果
-	var result strings.Builder
-	for {
-		chunk, err := reader.Recv()
-		if err != nil {
-			if err == io.EOF {
-				break // 流结束
-			}
-			return "", err
-		}
-		result.WriteString(chunk)
-	}
-
-	return result.String(), nil
+	return streamutil.Join(reader)
 }
 
 // StreamableRun 实现流式调用接口，返回可以逐步读取结果的 StreamReader
-func (s *StreamTextGeneratorTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (*schema.StreamReader[string], error) {
+func (s *StreamTextGeneratorTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
 	// 定义输入参数结构体
 	var args struct {
 		Topic   string `json:"topic"`    // 生成文本的主题
@@ -101,63 +93,26 @@ func (s *StreamTextGeneratorTool) StreamableRun(ctx context.Context, argumentsIn
 
 	log.Printf("[StreamTextGenerator] 开始生成关于 '%s' 的文本，共 %d 段", args.Topic, args.Length)
 
-	// 创建用于流式数据传递的 channels
-	// resultChan: 传递生成的文本内容，缓冲大小为段落数+1（包括完成消息）
-	// errorChan: 传递错误信息，缓冲大小为1
-	resultChan := make(chan string, args.Length+1)
-	errorChan := make(chan error, 1)
-
-	// 启动 goroutine 异步生成内容
-	go func() {
-		// 确保 channels 在函数结束时被关闭
-		defer close(resultChan)
-		defer close(errorChan)
-
+	// 在独立的 goroutine 中逐段生成内容，取消、背压、错误和关闭顺序由 streamutil.Produce 处理
+	return streamutil.Produce(ctx, 1, func(ctx context.Context, emit func(string) error) error {
 		// 生成指定主题和数量的段落内容
 		paragraphs := generateTopicParagraphs(args.Topic, args.Length)
 
 		for i, paragraph := range paragraphs {
-			select {
-			case <-ctx.Done():
-				// 确保错误被发送后再返回
-				select {
-				case errorChan <- ctx.Err():
-				default:
-				}
-				return
-			case resultChan <- fmt.Sprintf("段落 %d: %s\n\n", i+1, paragraph):
-				// 延迟（除了最后一段）
-				if i < len(paragraphs)-1 {
-					select {
-					case <-ctx.Done():
-						select {
-						case errorChan <- ctx.Err():
-						default:
-						}
-						return
-					case <-time.After(time.Duration(args.DelayMs) * time.Millisecond):
-					}
+			if err := emit(fmt.Sprintf("段落 %d: %s\n\n", i+1, paragraph)); err != nil {
+				return err
+			}
+			// 延迟（除了最后一段）
+			if i < len(paragraphs)-1 {
+				if err := streamutil.Sleep(ctx, time.Duration(args.DelayMs)*time.Millisecond); err != nil {
+					return err
 				}
 			}
 		}
 
 		// 发送完成消息
-		select {
-		case <-ctx.Done():
-			select {
-			case errorChan <- ctx.Err():
-			default:
-			}
-			return
-		case resultChan <- fmt.Sprintf("--- 关于 '%s' 的文本生成完成 ---", args.Topic):
-		}
-	}()
-
-	// 创建 StreamReader
-	// 注意: 实际使用时需要根据 Eino 框架的具体实现来创建 StreamReader
-	reader := createMockStreamReader(resultChan, errorChan)
-
-	return reader, nil
+		return emit(fmt.Sprintf("--- 关于 '%s' 的文本生成完成 ---", args.Topic))
+	}), nil
 }
 
 // --- 示例 2: 流式数据处理工具 ---
@@ -193,30 +148,16 @@ func (d *StreamDataProcessorTool) Info(ctx context.Context) (*schema.ToolInfo, e
 }
 
 // InvokableRun 实现数据处理工具的非流式调用接口
-func (d *StreamDataProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (d *StreamDataProcessorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	reader, err := d.StreamableRun(ctx, argumentsInJSON, opts...)
 	if err != nil {
 		return "", err
 	}
-	defer reader.Close()
-
-	var result strings.Builder
-	for {
-		chunk, err := reader.Recv()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", err
-		}
-		result.WriteString(chunk)
-	}
-
-	return result.String(), nil
+	return streamutil.Join(reader)
 }
 
 // StreamableRun 实现数据处理工具的流式调用接口，分批处理数据并返回进度
-func (d *StreamDataProcessorTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (*schema.StreamReader[string], error) {
+func (d *StreamDataProcessorTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
 	var args struct {
 		Data      []float64 `json:"data"`
 		Operation string    `json:"operation"`
@@ -233,78 +174,47 @@ func (d *StreamDataProcessorTool) StreamableRun(ctx context.Context, argumentsIn
 
 	log.Printf("[StreamDataProcessor] 处理 %d 个数据点，操作: %s", len(args.Data), args.Operation)
 
-	resultChan := make(chan string, len(args.Data)/args.BatchSize+2)
-	errorChan := make(chan error, 1)
-
-	go func() {
-		defer close(resultChan)
-		defer close(errorChan)
-
+	return streamutil.Produce(ctx, 1, func(ctx context.Context, emit func(string) error) error {
 		totalBatches := (len(args.Data) + args.BatchSize - 1) / args.BatchSize
 
 		for batchIndex := 0; batchIndex < totalBatches; batchIndex++ {
-			select {
-			case <-ctx.Done():
-				select {
-				case errorChan <- ctx.Err():
+			start := batchIndex * args.BatchSize
+			end := min(start+args.BatchSize, len(args.Data))
+
+			batch := args.Data[start:end]
+			processedBatch := make([]float64, len(batch))
+
+			// 处理当前批次
+			for i, value := range batch {
+				switch args.Operation {
+				case "square":
+					processedBatch[i] = value * value
+				case "double":
+					processedBatch[i] = value * 2
+				case "increment":
+					processedBatch[i] = value + 1
 				default:
+					processedBatch[i] = value
 				}
-				return
-			default:
-				start := batchIndex * args.BatchSize
-				end := start + args.BatchSize
-				if end > len(args.Data) {
-					end = len(args.Data)
-				}
-
-				batch := args.Data[start:end]
-				processedBatch := make([]float64, len(batch))
-
-				// 处理当前批次
-				for i, value := range batch {
-					switch args.Operation {
-					case "square":
-						processedBatch[i] = value * value
-					case "double":
-						processedBatch[i] = value * 2
-					case "increment":
-						processedBatch[i] = value + 1
-					default:
-						processedBatch[i] = value
-					}
-				}
-
-				// 发送批次处理结果
-				batchResult := map[string]interface{}{
-					"batch_index":    batchIndex + 1,
-					"total_batches":  totalBatches,
-					"processed_data": processedBatch,
-					"original_data":  batch,
-					"progress":       fmt.Sprintf("%.1f%%", float64(batchIndex+1)/float64(totalBatches)*100),
-				}
+			}
 
-				resultBytes, _ := json.Marshal(batchResult)
+			// 发送批次处理结果
+			batchResult := map[string]interface{}{
+				"batch_index":    batchIndex + 1,
+				"total_batches":  totalBatches,
+				"processed_data": processedBatch,
+				"original_data":  batch,
+				"progress":       fmt.Sprintf("%.1f%%", float64(batchIndex+1)/float64(totalBatches)*100),
+			}
 
-				select {
-				case <-ctx.Done():
-					select {
-					case errorChan <- ctx.Err():
-					default:
-					}
-					return
-				case resultChan <- string(resultBytes) + "\n":
-				}
+			resultBytes, _ := json.Marshal(batchResult)
+			if err := emit(string(resultBytes) + "\n"); err != nil {
+				return err
+			}
 
-				// 模拟处理延迟，同时检查上下文取消
-				select {
-				case <-ctx.Done():
-					select {
-					case errorChan <- ctx.Err():
-					default:
-					}
-					return
-				case <-time.After(200 * time.Millisecond):
-				}
+			// 模拟处理延迟，同时检查上下文取消
+			if err := streamutil.Sleep(ctx, 200*time.Millisecond); err != nil {
+				return err
 			}
 		}
 
@@ -317,148 +227,30 @@ func (d *StreamDataProcessorTool) StreamableRun(ctx context.Context, argumentsIn
 			"total_batches": totalBatches,
 		}
 		summaryBytes, _ := json.Marshal(summary)
-
-		select {
-		case <-ctx.Done():
-			select {
-			case errorChan <- ctx.Err():
-			default:
-			}
-			return
-		case resultChan <- string(summaryBytes):
-		}
-	}()
-
-	return createMockStreamReader(resultChan, errorChan), nil
+		return emit(string(summaryBytes))
+	}), nil
 }
 
 // --- 示例 3: 流式日志分析工具 ---
 
-// StreamLogAnalyzerTool 实现了支持流式输出的日志分析工具
-// 该工具演示了如何实时分析日志内容并逐步返回分析结果
-type StreamLogAnalyzerTool struct{}
+// 日志分析工具由 tools/loganalyzer 实现: 解析 JSON lines、logfmt、nginx、Go log 和普通文本日志，
+// 流式返回各级别数量、按模板聚类的错误、时间分桶速率、异常尖峰和堆栈分组。
 
-// Info 返回日志分析工具的基本信息和参数定义
-func (l *StreamLogAnalyzerTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name: "stream_log_analyzer",
-		Desc: "实时分析日志内容并流式返回分析结果",
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"log_content": {
-				Type:     "string",
-				Desc:     "要分析的日志内容",
-				Required: true,
-			},
-			"analysis_types": {
-				Type:     "array",
-				Desc:     "分析类型列表",
-				Required: true,
-			},
-		}),
-	}, nil
-}
-
-// InvokableRun 实现日志分析工具的非流式调用接口
-func (l *StreamLogAnalyzerTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
-	reader, err := l.StreamableRun(ctx, argumentsInJSON, opts...)
-	if err != nil {
-		return "", err
-	}
-	defer reader.Close()
-
-	var result strings.Builder
-	for {
-		chunk, err := reader.Recv()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", err
-		}
-		result.WriteString(chunk)
+// sampleLog 生成演示用的混合格式日志: 10 分钟内的正常请求，第 7 分钟数据库连接错误集中爆发
+func sampleLog() string {
+	var b strings.Builder
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&b, `{"time":"2024-08-19T10:%02d:01Z","level":"info","msg":"request %d handled in %dms"}`+"\n", i, 1000+i, 20+i*3)
+		fmt.Fprintf(&b, "time=2024-08-19T10:%02d:05Z level=warn msg=\"slow query\" duration=%dms\n", i, 800+i*10)
+		fmt.Fprintf(&b, `10.0.0.%d - - [19/Aug/2024:10:%02d:10 +0000] "GET /api/users/%d HTTP/1.1" 200 512 "-" "curl/8.0"`+"\n", i, i, 100+i)
 	}
-
-	return result.String(), nil
-}
-
-// StreamableRun 实现日志分析工具的流式调用接口，逐步分析日志并返回结果
-func (l *StreamLogAnalyzerTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (*schema.StreamReader[string], error) {
-	var args struct {
-		LogContent    string   `json:"log_content"`
-		AnalysisTypes []string `json:"analysis_types"`
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&b, "2024-08-19 10:07:%02d ERROR connect to 10.0.1.%d:5432 failed: timeout after 30s\n", 20+i, i)
+		b.WriteString("goroutine 12 [running]:\nmain.(*DB).dial(0xc000123000)\n\t/app/db.go:42 +0x1d\nmain.handler()\n\t/app/api.go:88 +0x3f\n")
 	}
-
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return nil, fmt.Errorf("参数解析失败: %v", err)
-	}
-
-	log.Printf("[StreamLogAnalyzer] 分析日志，长度: %d", len(args.LogContent))
-
-	resultChan := make(chan string, len(args.AnalysisTypes)+2)
-	errorChan := make(chan error, 1)
-
-	go func() {
-		defer close(resultChan)
-		defer close(errorChan)
-
-		lines := strings.Split(args.LogContent, "\n")
-
-		for _, analysisType := range args.AnalysisTypes {
-			select {
-			case <-ctx.Done():
-				select {
-				case errorChan <- ctx.Err():
-				default:
-				}
-				return
-			default:
-				// 模拟分析时间，同时检查上下文取消
-				select {
-				case <-ctx.Done():
-					select {
-					case errorChan <- ctx.Err():
-					default:
-					}
-					return
-				case <-time.After(300 * time.Millisecond):
-				}
-
-				result := performLogAnalysis(lines, analysisType)
-
-				analysisResult := map[string]interface{}{
-					"analysis_type": analysisType,
-					"result":        result,
-					"timestamp":     time.Now().Format(time.RFC3339),
-				}
-
-				resultBytes, _ := json.Marshal(analysisResult)
-
-				select {
-				case <-ctx.Done():
-					select {
-					case errorChan <- ctx.Err():
-					default:
-					}
-					return
-				case resultChan <- string(resultBytes) + "\n":
-				}
-			}
-		}
-
-		// 发送分析完成消息
-		completionMsg := `{"status": "analysis_completed", "total_types": ` + fmt.Sprintf("%d", len(args.AnalysisTypes)) + `}`
-		select {
-		case <-ctx.Done():
-			select {
-			case errorChan <- ctx.Err():
-			default:
-			}
-			return
-		case resultChan <- completionMsg:
-		}
-	}()
-
-	return createMockStreamReader(resultChan, errorChan), nil
+	b.WriteString(`10.0.0.9 - - [19/Aug/2024:10:07:40 +0000] "POST /api/orders/42?retry=1 HTTP/1.1" 502 0 "-" "curl/8.0"` + "\n")
+	b.WriteString("2024/08/19 10:08:00 worker.go:17: ERROR job 550e8400-e29b-41d4-a716-446655440000 failed\n")
+	return b.String()
 }
 
 // --- 辅助函数 ---
@@ -493,91 +285,6 @@ func generateTopicParagraphs(topic string, count int) []string {
 	return paragraphs
 }
 
-// performLogAnalysis 执行指定类型的日志分析
-// 参数:
-//
-//	lines: 日志行数组
-//	analysisType: 分析类型（error_count, warning_count, line_count, unique_ips）
-//
-// 返回:
-//
-//	interface{}: 分析结果（类型根据分析类型而定）
-func performLogAnalysis(lines []string, analysisType string) interface{} {
-	switch analysisType {
-	case "error_count":
-		// 统计包含 "error" 的日志行数量
-		count := 0
-		for _, line := range lines {
-			if strings.Contains(strings.ToLower(line), "error") {
-				count++
-			}
-		}
-		return count
-	case "warning_count":
-		// 统计包含 "warning" 的日志行数量
-		count := 0
-		for _, line := range lines {
-			if strings.Contains(strings.ToLower(line), "warning") {
-				count++
-			}
-		}
-		return count
-	case "line_count":
-		// 返回总行数
-		return len(lines)
-	case "unique_ips":
-		// 统计唯一IP地址数量（简化实现）
-		ips := make(map[string]bool)
-		for _, line := range lines {
-			// 简单的IP提取（实际应该用正则表达式）
-			parts := strings.Fields(line)
-			if len(parts) > 0 && strings.Contains(parts[0], ".") {
-				ips[parts[0]] = true
-			}
-		}
-		return len(ips)
-	default:
-		return "unknown_analysis_type"
-	}
-}
-
-// createMockStreamReader 创建模拟的 StreamReader
-func createMockStreamReader(resultChan <-chan string, errorChan <-chan error) *schema.StreamReader[string] {
-	// 使用 schema.Pipe 创建一个 StreamReader 和 StreamWriter 对
-	reader, writer := schema.Pipe[string](100) // 缓冲区大小为100
-	
-	// 启动一个 goroutine 将 channel 数据写入到 StreamWriter 中
-	go func() {
-		defer writer.Close() // 确保写入器最终被关闭
-		
-		for {
-			select {
-			case data, ok := <-resultChan:
-				if !ok {
-					// resultChan 已关闭，正常结束
-					return
-				}
-				closed := writer.Send(data, nil)
-				if closed {
-					// 写入器已关闭，退出
-					return
-				}
-			case err := <-errorChan:
-				if err != nil {
-					// 发生错误，发送错误并退出
-					writer.Send("", err)
-					return
-				}
-			}
-		}
-	}()
-	
-	return reader
-}
-
-// 注意：我们现在使用 Eino 框架提供的 schema.Pipe 来创建真正的 StreamReader，
-// 不再需要自定义的 MockStreamReader 实现
-
 // --- 演示函数 ---
 
 // demonstrateStreamableTools 演示各种流式工具的使用方法
@@ -591,8 +298,8 @@ func demonstrateStreamableTools() {
 	textGenerator := &StreamTextGeneratorTool{}
 
 	fmt.Println("开始流式生成文本...")
-	// 调用流式文本生成工具，生成关于"人工智能"主题的3段文本
-	textResult, err := textGenerator.InvokableRun(ctx, `{
+	// 直接调用 StreamableRun，每生成一段就立即输出，而不是等全部生成后再拼接
+	textReader, err := textGenerator.StreamableRun(ctx, `{
 		"topic": "人工智能",
 		"length": 3,
 		"delay_ms": 1000
@@ -600,7 +307,19 @@ func demonstrateStreamableTools() {
 	if err != nil {
 		log.Printf("文本生成失败: %v", err)
 	} else {
-		fmt.Printf("生成结果:\n%s\n", textResult)
+		for {
+			chunk, err := textReader.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				log.Printf("文本生成中断: %v", err)
+				break
+			}
+			fmt.Printf("[%s] 收到数据块: %s", time.Now().Format("15:04:05"), chunk)
+		}
+		textReader.Close()
+		fmt.Println()
 	}
 
 	// 2. 测试流式数据处理工具
@@ -620,31 +339,165 @@ func demonstrateStreamableTools() {
 		fmt.Printf("处理结果:\n%s\n", dataResult)
 	}
 
-	// 3. 测试流式日志分析工具
+	// 3. 测试流式日志分析工具: 日志文件放在临时目录中，工具只能读取该目录内的文件
 	fmt.Println("--- 流式日志分析工具 ---")
-	logAnalyzer := &StreamLogAnalyzerTool{}
-
-	// 模拟日志内容，包含不同级别的日志信息
-	logContent := `2024-08-19 10:00:01 INFO Starting application
-2024-08-19 10:00:02 ERROR Failed to connect to database
-2024-08-19 10:00:03 WARNING Connection retry attempt 1
-2024-08-19 10:00:04 INFO Connection established
-2024-08-19 10:00:05 ERROR Authentication failed`
+	dir, err := os.MkdirTemp("", "logs")
+	if err != nil {
+		log.Printf("创建日志目录失败: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), []byte(sampleLog()), 0o644); err != nil {
+		log.Printf("写入日志文件失败: %v", err)
+		return
+	}
+	logAnalyzer, err := loganalyzer.New(&loganalyzer.Config{Root: dir, ProgressLines: 20})
+	if err != nil {
+		log.Printf("创建日志分析工具失败: %v", err)
+		return
+	}
+	defer logAnalyzer.Close()
 
 	fmt.Println("开始流式分析日志...")
-	// 调用流式日志分析工具，分析错误数量、警告数量和总行数
-	logResult, err := logAnalyzer.InvokableRun(ctx, fmt.Sprintf(`{
-		"log_content": %q,
-		"analysis_types": ["error_count", "warning_count", "line_count"]
-	}`, logContent))
+	// 每行一个 JSON 对象: 读取过程中的进度，然后是各项分析结果
+	logReader, err := logAnalyzer.StreamableRun(ctx, `{
+		"path": "app.log",
+		"analyses": ["levels", "top_errors", "anomalies", "stack_traces"],
+		"top_n": 3
+	}`)
 	if err != nil {
 		log.Printf("日志分析失败: %v", err)
-	} else {
-		fmt.Printf("分析结果:\n%s\n", logResult)
+		return
+	}
+	defer logReader.Close()
+	for {
+		chunk, err := logReader.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("日志分析中断: %v", err)
+			break
+		}
+		fmt.Print(chunk)
+	}
+}
+
+// demonstrateToolsNodeStream 演示通过 ToolsNode 以流式方式并行执行多个工具调用。
+// 每个工具的输出块一产生就带着工具调用 ID 转发给调用方 (如终端或 SSE 客户端)，
+// 全部结束后按调用拼接出完整结果，作为工具消息返回给模型
+func demonstrateToolsNodeStream() {
+	ctx := context.Background()
+
+	fmt.Println("--- 通过 ToolsNode 流式执行多个工具 ---")
+	// 未配置日志目录的日志分析工具只能分析内联内容
+	logAnalyzer, err := loganalyzer.New(nil)
+	if err != nil {
+		log.Printf("创建日志分析工具失败: %v", err)
+		return
+	}
+	toolsNode, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{
+		Tools: []tool.BaseTool{&StreamTextGeneratorTool{}, &StreamDataProcessorTool{}, logAnalyzer},
+	})
+	if err != nil {
+		log.Printf("创建 ToolsNode 失败: %v", err)
+		return
+	}
+
+	// 模拟模型在一条消息中请求调用三个流式工具
+	msg := schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "call_text", Function: schema.FunctionCall{Name: "stream_text_generator", Arguments: `{"topic": "流式处理", "length": 2, "delay_ms": 300}`}},
+		{ID: "call_data", Function: schema.FunctionCall{Name: "stream_data_processor", Arguments: `{"data": [1, 2, 3, 4, 5], "operation": "double", "batch_size": 2}`}},
+		{ID: "call_log", Function: schema.FunctionCall{Name: "log_analyzer", Arguments: `{"content": "INFO start\nERROR failed\nINFO done", "analyses": ["levels", "top_errors"]}`}},
+	})
+
+	stream, err := toolsNode.Stream(ctx, msg)
+	if err != nil {
+		log.Printf("工具调用失败: %v", err)
+		return
+	}
+	defer stream.Close()
+
+	// ToolsNode.Stream 的每个数据块是与工具调用等长的数组，只有产生输出的调用对应的位置不为 nil
+	outputs := make([]strings.Builder, len(msg.ToolCalls))
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("工具输出中断: %v", err)
+			return
+		}
+		for i, tm := range chunk {
+			if tm == nil {
+				continue
+			}
+			outputs[i].WriteString(tm.Content)
+			fmt.Printf("  [%s] %s: %s\n", tm.ToolCallID, tm.ToolName, strings.TrimSpace(tm.Content))
+		}
+	}
+
+	// 拼接后的完整结果作为工具消息回到模型的上下文中
+	fmt.Println("返回给模型的工具消息:")
+	for i, call := range msg.ToolCalls {
+		toolMsg := schema.ToolMessage(outputs[i].String(), call.ID, schema.WithToolName(call.Function.Name))
+		fmt.Printf("  %s (%s): %d 字节\n", toolMsg.ToolName, toolMsg.ToolCallID, len(toolMsg.Content))
+	}
+}
+
+// demonstrateStreamCombinators 演示 streamutil 中的流组合函数:
+// 对数据处理工具的输出做过滤、转换、分批和复制，并为文本生成工具设置数据块之间的超时
+func demonstrateStreamCombinators() {
+	ctx := context.Background()
+
+	fmt.Println("--- 流组合函数 ---")
+	reader, err := (&StreamDataProcessorTool{}).StreamableRun(ctx, `{"data": [1, 2, 3, 4, 5, 6], "operation": "square", "batch_size": 1}`)
+	if err != nil {
+		log.Printf("数据处理失败: %v", err)
+		return
+	}
+
+	// Filter: 只保留批次结果，去掉最后的汇总; Map: 提取处理后的数据
+	batches := streamutil.Filter(reader, func(chunk string) bool {
+		return strings.Contains(chunk, "batch_index")
+	})
+	values := streamutil.Map(batches, func(chunk string) (float64, error) {
+		var batch struct {
+			ProcessedData []float64 `json:"processed_data"`
+		}
+		if err := json.Unmarshal([]byte(chunk), &batch); err != nil {
+			return 0, err
+		}
+		return batch.ProcessedData[0], nil
+	})
+
+	// Batch: 每 4 个一组，上游 500ms 内凑不满时提前输出; Tee: 复制成两个流分别消费
+	copies := streamutil.Tee(streamutil.Batch(values, 4, 500*time.Millisecond), 2)
+	groups, err := streamutil.Collect(copies[0])
+	fmt.Printf("分批结果: %v (错误: %v)\n", groups, err)
+	sum := 0.0
+	groupsCopy, _ := streamutil.Collect(copies[1])
+	for _, group := range groupsCopy {
+		for _, v := range group {
+			sum += v
+		}
+	}
+	fmt.Printf("另一个副本计算的总和: %.0f\n", sum)
+
+	// Timeout: 段落之间间隔 1 秒，超过 300ms 没有新数据时中止
+	textReader, err := (&StreamTextGeneratorTool{}).StreamableRun(ctx, `{"topic": "超时", "length": 3, "delay_ms": 1000}`)
+	if err != nil {
+		log.Printf("文本生成失败: %v", err)
+		return
 	}
+	chunks, err := streamutil.Collect(streamutil.Timeout(textReader, 300*time.Millisecond))
+	fmt.Printf("超时前收到 %d 个数据块，错误: %v\n", len(chunks), err)
 }
 
 // main 程序入口点，运行流式工具演示
 func main() {
 	demonstrateStreamableTools()
+	demonstrateToolsNodeStream()
+	demonstrateStreamCombinators()
 }
@@ -3,11 +3,22 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"Eini/tools/filemanager"
+	"Eini/tools/schemacheck"
+	"Eini/tools/translator"
+	"Eini/tools/weather"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -37,74 +48,26 @@ type InvokableTool interface {
 	// 参数:
 	//   ctx: 上下文对象，用于取消和超时控制
 	//   argumentsInJSON: JSON 格式的工具参数
-	//   opts: 可选的执行选项
+	//   opts: 可选的执行选项，每个工具通过 tool.GetImplSpecificOptions 读取自己的选项类型
 	// 返回:
 	//   string: 工具执行结果（JSON 格式）
 	//   error: 执行过程中的错误
-	InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error)
+	InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error)
 }
 
 // --- 工具实现 ---
 
-// WeatherTool 天气查询工具
-// 实现了 InvokableTool 接口，提供城市天气信息查询功能
-// 支持指定城市和日期的天气查询，如果不指定日期则查询当天天气
-type WeatherTool struct{}
-
-// Info 返回天气工具的元信息和参数定义
-func (w *WeatherTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name: "get_weather", // 工具名称，用于工具调用时的标识
-		Desc: "查询指定城市的天气信息", // 工具描述，帮助 LLM 理解工具功能
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"city": {
-				Type:     "string",
-				Desc:     "城市名称",
-				Required: true, // 必需参数
-			},
-			"date": {
-				Type:     "string",
-				Desc:     "查询日期 (YYYY-MM-DD)",
-				Required: false, // 可选参数，不提供时默认为当天
-			},
-		}),
-	}, nil
+// NewWeatherTool 创建使用 provider 查询天气的工具 (工具名称为 get_weather)
+// 直接使用 tools/weather，数据来自 weather.Provider (HTTP 天气服务或本地假服务)，
+// 城市名称支持中文和英文，支持单日 (date) 和日期范围 (start_date / end_date) 查询，
+// 结果按 城市 + 日期 缓存
+func NewWeatherTool(provider weather.Provider) (*weather.Tool, error) {
+	return weather.New(&weather.Config{Provider: provider})
 }
 
-// InvokableRun 执行天气查询逻辑
-func (w *WeatherTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
-	// 定义参数结构体，用于解析 JSON 参数
-	var args struct {
-		City string `json:"city"` // 城市名称
-		Date string `json:"date"` // 查询日期（可选）
-	}
-
-	// 解析 JSON 参数到结构体
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("参数解析失败: %v", err)
-	}
-
-	// 如果未指定日期，使用当前日期
-	if args.Date == "" {
-		args.Date = time.Now().Format("2006-01-02")
-	}
-
-	log.Printf("[WeatherTool] 查询 %s 在 %s 的天气", args.City, args.Date)
-
-	// 模拟天气数据生成（实际应用中这里会调用真实的天气 API）
-	weatherData := map[string]interface{}{
-		"city":        args.City,                                // 城市名称
-		"date":        args.Date,                                // 查询日期
-		"temperature": 25,                                       // 温度（摄氏度）
-		"humidity":    60,                                       // 湿度（百分比）
-		"condition":   "晴朗",                                     // 天气状况
-		"wind_speed":  "5 km/h",                                 // 风速
-		"description": fmt.Sprintf("%s 今天天气晴朗，温度适宜", args.City), // 天气描述
-	}
-
-	// 将结果序列化为 JSON 字符串返回
-	result, _ := json.Marshal(weatherData)
-	return string(result), nil
+// WithUnits 设置天气查询结果使用的单位制: metric (摄氏度，默认) 或 imperial (华氏度)，即 weather.WithUnits
+func WithUnits(units string) tool.Option {
+	return weather.WithUnits(units)
 }
 
 // CalculatorTool 计算器工具
@@ -128,7 +91,7 @@ func (c *CalculatorTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 // InvokableRun 执行数学计算逻辑
-func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
+func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
 	// 定义参数结构体
 	var args struct {
 		Expression string `json:"expression"` // 要计算的数学表达式
@@ -158,127 +121,95 @@ func (c *CalculatorTool) InvokableRun(ctx context.Context, argumentsInJSON strin
 }
 
 // TranslatorTool 翻译工具
-// 实现文本翻译功能，支持多种语言之间的转换
-// 支持自动检测源语言或手动指定源语言
-type TranslatorTool struct{}
+// 包装 tools/translator，把翻译交给 ChatModel 完成: 自动检测源语言、按项目术语表统一术语译法、
+// 长文本分块翻译，置信度由模型给出
+type TranslatorTool struct {
+	translator *translator.Translator
+}
+
+// TranslatorOptions 翻译工具的调用选项
+type TranslatorOptions struct {
+	DefaultFromLang string // 参数中没有指定源语言时使用的源语言，默认 auto (自动检测)
+}
+
+// WithDefaultFromLang 设置默认源语言，模型在参数中指定的 from_lang 优先
+func WithDefaultFromLang(lang string) tool.Option {
+	return tool.WrapImplSpecificOptFn(func(o *TranslatorOptions) {
+		o.DefaultFromLang = lang
+	})
+}
+
+// NewTranslatorTool 创建使用 chatModel 翻译、按 glossary 统一术语的翻译工具
+func NewTranslatorTool(chatModel model.BaseChatModel, glossary *translator.Glossary) (*TranslatorTool, error) {
+	t, err := translator.New(&translator.Config{Model: chatModel, Glossary: glossary})
+	if err != nil {
+		return nil, err
+	}
+	return &TranslatorTool{translator: t}, nil
+}
 
 // Info 返回翻译工具的元信息和参数定义
 func (t *TranslatorTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name: "translator", // 工具名称
-		Desc: "翻译文本",       // 工具描述
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"text": {
-				Type:     "string",
-				Desc:     "要翻译的文本",
-				Required: true, // 必需参数
-			},
-			"from_lang": {
-				Type:     "string",
-				Desc:     "源语言",
-				Required: false, // 可选参数，不指定时自动检测
-			},
-			"to_lang": {
-				Type:     "string",
-				Desc:     "目标语言",
-				Required: true, // 必需参数
-			},
-		}),
-	}, nil
+	return t.translator.Info(ctx)
 }
 
 // InvokableRun 执行文本翻译逻辑
-func (t *TranslatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
-	// 定义参数结构体
-	var args struct {
-		Text     string `json:"text"`      // 待翻译的原文
-		FromLang string `json:"from_lang"` // 源语言（可选）
-		ToLang   string `json:"to_lang"`   // 目标语言（必需）
-	}
-
-	// 解析 JSON 参数
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+func (t *TranslatorTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var req translator.Request
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
 		return "", fmt.Errorf("参数解析失败: %v", err)
 	}
 
-	// 如果未指定源语言，设置为自动检测
-	if args.FromLang == "" {
-		args.FromLang = "auto"
+	// 如果未指定源语言，使用调用选项中的默认源语言 (默认为自动检测)
+	if req.FromLang == "" {
+		options := tool.GetImplSpecificOptions(&TranslatorOptions{DefaultFromLang: "auto"}, opts...)
+		req.FromLang = options.DefaultFromLang
 	}
 
-	log.Printf("[TranslatorTool] 翻译 '%s' 从 %s 到 %s", args.Text, args.FromLang, args.ToLang)
-
-	// 执行翻译逻辑（这里使用模拟翻译，实际应用中会调用翻译 API）
-	translatedText := simulateTranslation(args.Text, args.FromLang, args.ToLang)
+	log.Printf("[TranslatorTool] 翻译 '%s' 从 %s 到 %s", truncateText(req.Text, 50), req.FromLang, req.ToLang)
 
-	// 构造翻译结果响应
-	response := map[string]interface{}{
-		"original_text":   args.Text,      // 原始文本
-		"translated_text": translatedText, // 翻译后的文本
-		"from_language":   args.FromLang,  // 源语言
-		"to_language":     args.ToLang,    // 目标语言
-		"confidence":      0.95,           // 翻译置信度（模拟值）
+	result, err := t.translator.Translate(ctx, &req)
+	if err != nil {
+		return "", err
 	}
-
-	// 序列化结果并返回
-	result, _ := json.Marshal(response)
-	return string(result), nil
+	data, _ := json.Marshal(result)
+	return string(data), nil
 }
 
 // FileManagerTool 文件管理工具
-// 实现基本的文件系统操作功能，包括文件和目录的创建、读取、删除、列表等操作
-// 注意：这是演示实现，实际应用中需要考虑安全性和权限控制
-type FileManagerTool struct{}
-
-// Info 返回文件管理工具的元信息和参数定义
-func (f *FileManagerTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
-	return &schema.ToolInfo{
-		Name: "file_manager", // 工具名称
-		Desc: "管理文件和目录",      // 工具描述
-		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
-			"action": {
-				Type:     "string",
-				Desc:     "操作类型",
-				Required: true,
-				Enum:     []string{"list", "create", "read", "delete", "info"}, // 支持的操作类型枚举
-			},
-			"path": {
-				Type:     "string",
-				Desc:     "文件路径",
-				Required: true, // 文件路径是必需参数
-			},
-			"content": {
-				Type:     "string",
-				Desc:     "文件内容（创建文件时使用）",
-				Required: false, // 仅在创建文件时需要
-			},
-		}),
-	}, nil
+// 包装 tools/filemanager 提供真实的文件系统操作，所有操作限定在根目录内，
+// 支持 list / read / create / write / append / delete / info / search，
+// 并提供路径穿越防护、大小限制、只读模式、扩展名白名单和审计日志
+type FileManagerTool struct {
+	fm *filemanager.FileManager
 }
 
-// InvokableRun 执行文件管理操作逻辑
-func (f *FileManagerTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...interface{}) (string, error) {
-	// 定义参数结构体
-	var args struct {
-		Action  string `json:"action"`  // 操作类型（list/create/read/delete/info）
-		Path    string `json:"path"`    // 目标文件或目录路径
-		Content string `json:"content"` // 文件内容（仅在创建文件时使用）
-	}
-
-	// 解析 JSON 参数
-	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
-		return "", fmt.Errorf("参数解析失败: %v", err)
+// NewFileManagerTool 创建限定在 root 目录内的文件管理工具
+func NewFileManagerTool(root string) (*FileManagerTool, error) {
+	fm, err := filemanager.New(&filemanager.Config{
+		Root:              root,
+		AllowedExtensions: []string{".txt", ".md", ".log"}, // 只允许读写文本文件
+		MaxWriteBytes:     64 << 10,                        // 单次写入最多 64KB
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &FileManagerTool{fm: fm}, nil
+}
 
-	log.Printf("[FileManagerTool] 执行 %s 操作，路径: %s", args.Action, args.Path)
+// Close 释放根目录句柄
+func (f *FileManagerTool) Close() error {
+	return f.fm.Close()
+}
 
-	// 执行文件操作（这里使用模拟实现，实际应用中会进行真实的文件系统操作）
-	// 注意：实际应用中需要添加安全检查，防止路径遍历攻击等安全问题
-	result := simulateFileOperation(args.Action, args.Path, args.Content)
+// Info 返回文件管理工具的元信息和参数定义
+func (f *FileManagerTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return f.fm.Info(ctx)
+}
 
-	// 序列化结果并返回
-	resultBytes, _ := json.Marshal(result)
-	return string(resultBytes), nil
+// InvokableRun 执行文件管理操作逻辑
+func (f *FileManagerTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	return f.fm.InvokableRun(ctx, argumentsInJSON, opts...)
 }
 
 // --- ToolsNode 演示 ---
@@ -292,10 +223,39 @@ func demonstrateToolsNode() {
 
 	// 1. 创建各种工具实例
 	// 每个工具都实现了 InvokableTool 接口，提供特定的功能
-	weatherTool := &WeatherTool{}         // 天气查询工具
-	calculatorTool := &CalculatorTool{}   // 数学计算工具
-	translatorTool := &TranslatorTool{}   // 文本翻译工具
-	fileManagerTool := &FileManagerTool{} // 文件管理工具
+	// 天气查询工具: 演示使用本地假天气服务，实际项目中用 weather.NewHTTPProvider 连接真实的天气服务
+	weatherServer := weather.NewFakeServer("")
+	defer weatherServer.Close()
+	weatherCache := weather.NewCachedProvider(weatherServer.Provider(), weather.CacheConfig{})
+	weatherTool, err := NewWeatherTool(weatherCache)
+	if err != nil {
+		log.Fatalf("创建天气工具失败: %v", err)
+	}
+	calculatorTool := &CalculatorTool{} // 数学计算工具
+	// 文本翻译工具: 演示使用离线的模拟模型，实际项目中传入 ark、openai 等 ChatModel 即可
+	glossary, err := translator.LoadGlossary([]byte(projectGlossary))
+	if err != nil {
+		log.Fatalf("加载术语表失败: %v", err)
+	}
+	translatorTool, err := NewTranslatorTool(&mockTranslationModel{}, glossary)
+	if err != nil {
+		log.Fatalf("创建翻译工具失败: %v", err)
+	}
+
+	// 文件管理工具的根目录，演示结束后删除
+	root, err := os.MkdirTemp("", "toolsnode_files_")
+	if err != nil {
+		log.Fatalf("创建演示目录失败: %v", err)
+	}
+	defer os.RemoveAll(root)
+	if err := os.WriteFile(filepath.Join(root, "example.txt"), []byte("第一行\n第二行: Eino ToolsNode\n第三行\n"), 0o644); err != nil {
+		log.Fatalf("创建演示文件失败: %v", err)
+	}
+	fileManagerTool, err := NewFileManagerTool(root) // 文件管理工具
+	if err != nil {
+		log.Fatalf("创建文件管理工具失败: %v", err)
+	}
+	defer fileManagerTool.Close()
 
 	// 2. 将所有工具组织成工具列表
 	// ToolsNode 将基于这个列表来管理和调用工具
@@ -378,7 +338,7 @@ func demonstrateToolsNode() {
 				Type: "function",
 				Function: schema.FunctionCall{
 					Name:      "file_manager",
-					Arguments: `{"action": "info", "path": "/tmp/example.txt"}`, // 获取文件信息
+					Arguments: `{"action": "info", "path": "example.txt"}`, // 获取文件信息 (相对于根目录的路径)
 				},
 			},
 		},
@@ -398,12 +358,28 @@ func demonstrateToolsNode() {
 		fmt.Println()
 	}
 
-	// 6. 演示在 Chain 中使用 ToolsNode
+	// 6. 演示调用选项
+	fmt.Println("--- 演示调用选项 ---")
+	demonstrateToolOptions(ctx, toolsNode)
+
+	// 7. 演示天气工具的日期范围、城市名称解析和缓存
+	fmt.Println("--- 演示天气工具 ---")
+	demonstrateWeather(ctx, toolsNode, weatherServer, weatherCache)
+
+	// 8. 演示翻译工具的术语表和长文本分块
+	fmt.Println("--- 演示翻译工具 ---")
+	demonstrateTranslator(ctx, toolsNode)
+
+	// 9. 演示文件管理工具的更多操作
+	fmt.Println("--- 演示文件管理工具 ---")
+	demonstrateFileManager(ctx, toolsNode)
+
+	// 10. 演示在 Chain 中使用 ToolsNode
 	fmt.Println("--- 演示在 Chain 中使用 ToolsNode ---")
 	// 调用专门的函数来演示 ToolsNode 在工作流链中的使用
 	demonstrateToolsNodeInChain(toolsNode)
 
-	// 7. 演示错误处理
+	// 11. 演示错误处理
 	fmt.Println("--- 演示错误处理 ---")
 
 	// 创建一个调用不存在工具的消息，用于测试错误处理机制
@@ -429,6 +405,201 @@ func demonstrateToolsNode() {
 	} else if len(errorResults) == 0 {
 		fmt.Println("未找到对应工具，跳过执行")
 	}
+
+	// 参数不符合工具定义: translator 缺少必填的 to_lang，calculator 的 expression 类型错误。
+	// 工具不会被执行，模型会收到逐项列出问题的校验结果
+	invalidMessage := &schema.Message{
+		Role: "assistant",
+		ToolCalls: []schema.ToolCall{
+			{
+				ID:   "call_invalid_001",
+				Type: "function",
+				Function: schema.FunctionCall{
+					Name:      "translator",
+					Arguments: `{"text": "Hello World"}`, // 缺少 to_lang
+				},
+			},
+			{
+				ID:   "call_invalid_002",
+				Type: "function",
+				Function: schema.FunctionCall{
+					Name:      "calculator",
+					Arguments: `{"expression": 42}`, // expression 应为字符串
+				},
+			},
+		},
+	}
+	invalidResults, err := toolsNode.Invoke(ctx, invalidMessage)
+	if err != nil {
+		log.Printf("参数校验演示失败: %v", err)
+		return
+	}
+	for _, result := range invalidResults {
+		fmt.Printf("参数校验结果 - %s: %s\n", result.Name, result.Content)
+	}
+}
+
+// demonstrateToolOptions 演示通过 tool.Option 在每次调用时传入工具专属的设置。
+// 同一组选项传给所有工具，天气工具只读取 weather.Options，翻译工具只读取 TranslatorOptions
+func demonstrateToolOptions(ctx context.Context, toolsNode *MockToolsNode) {
+	msg := &schema.Message{
+		Role: "assistant",
+		ToolCalls: []schema.ToolCall{
+			{
+				ID:       "call_weather_opt",
+				Type:     "function",
+				Function: schema.FunctionCall{Name: "get_weather", Arguments: `{"city": "纽约", "date": "2024-08-19"}`},
+			},
+			{
+				ID:       "call_translate_opt",
+				Type:     "function",
+				Function: schema.FunctionCall{Name: "translator", Arguments: `{"text": "Hello World", "to_lang": "zh"}`}, // 没有指定 from_lang
+			},
+		},
+	}
+
+	for _, c := range []struct {
+		desc string
+		opts []tool.Option
+	}{
+		{"默认选项", nil},
+		{"华氏度 + 默认源语言 en", []tool.Option{WithUnits("imperial"), WithDefaultFromLang("en")}},
+	} {
+		results, err := toolsNode.Invoke(ctx, msg, c.opts...)
+		if err != nil {
+			log.Printf("调用选项演示失败: %v", err)
+			return
+		}
+		fmt.Printf("%s:\n", c.desc)
+		for _, result := range results {
+			fmt.Printf("  - %s: %s\n", result.Name, result.Content)
+		}
+	}
+	fmt.Println()
+}
+
+// demonstrateWeather 演示天气工具: 英文城市名和别名解析为同一个城市，日期范围查询，
+// 与已缓存日期重叠的查询只向天气服务请求缺失的日期，以及日期和城市的校验错误
+func demonstrateWeather(ctx context.Context, toolsNode *MockToolsNode, server *weather.FakeServer, cache *weather.CachedProvider) {
+	start := time.Now().Format(weather.DateLayout)
+	end := time.Now().AddDate(0, 0, 2).Format(weather.DateLayout)
+	for _, c := range []struct {
+		desc string
+		args string
+	}{
+		{"英文城市名 + 日期范围", fmt.Sprintf(`{"city": "Shanghai", "start_date": "%s", "end_date": "%s"}`, start, end)},
+		{"别名 + 相对日期 (命中缓存)", `{"city": "沪", "date": "明天"}`},
+		{"日期格式错误", `{"city": "上海", "date": "2024/08/19"}`},
+		{"日期范围超过 7 天", `{"city": "上海", "start_date": "2024-08-19", "end_date": "2024-08-31"}`},
+		{"超出预报范围", `{"city": "上海", "date": "2099-01-01"}`},
+		{"未知城市", `{"city": "亚特兰蒂斯"}`},
+	} {
+		before := server.Requests()
+		results, err := toolsNode.Invoke(ctx, &schema.Message{
+			Role: "assistant",
+			ToolCalls: []schema.ToolCall{
+				{ID: "call_weather_demo", Type: "function", Function: schema.FunctionCall{Name: "get_weather", Arguments: c.args}},
+			},
+		})
+		if err != nil {
+			fmt.Printf("%s: 调用失败: %v\n", c.desc, err)
+			continue
+		}
+		var result weather.Result
+		if err := json.Unmarshal([]byte(results[0].Content), &result); err != nil || result.City == "" {
+			fmt.Printf("%s: %s\n", c.desc, results[0].Content)
+			continue
+		}
+		fmt.Printf("%s: %s (天气服务请求 %d 次)\n", c.desc, result.Description, server.Requests()-before)
+	}
+	stats := cache.Stats()
+	fmt.Printf("缓存统计: 命中 %d 天，未命中 %d 天\n\n", stats.Hits, stats.Misses)
+}
+
+// demonstrateTranslator 演示翻译工具: 术语表中的术语必须使用规定译法 (模拟模型第一次没有使用，
+// 工具检查后带着反馈重新翻译)，多段落的长文本按段落分块翻译后保持原有的段落结构
+func demonstrateTranslator(ctx context.Context, toolsNode *MockToolsNode) {
+	longText := strings.Repeat("Eino makes it easy to build LLM applications. ", 30) +
+		"\n\nGood morning\n\n" + strings.Repeat("Every tool call is executed by the ToolsNode. ", 30)
+	calls := []struct {
+		desc string
+		text string
+	}{
+		{"术语表", "Eino's ToolsNode runs every tool call in parallel."},
+		{"长文本分块", longText},
+	}
+
+	for i, call := range calls {
+		args, _ := json.Marshal(map[string]string{"text": call.text, "to_lang": "zh"})
+		msg := &schema.Message{
+			Role: "assistant",
+			ToolCalls: []schema.ToolCall{{
+				ID:       fmt.Sprintf("call_translate_%03d", i+1),
+				Type:     "function",
+				Function: schema.FunctionCall{Name: "translator", Arguments: string(args)},
+			}},
+		}
+		results, err := toolsNode.Invoke(ctx, msg)
+		if err != nil {
+			fmt.Printf("  %s: 失败: %v\n", call.desc, err)
+			continue
+		}
+
+		var result translator.Result
+		if err := json.Unmarshal([]byte(results[0].Content), &result); err != nil {
+			fmt.Printf("  %s: %s\n", call.desc, results[0].Content)
+			continue
+		}
+		fmt.Printf("  %s: %d 个字符，%d 块，源语言 %s，置信度 %.2f，术语 %d 个，未遵守 %d 个\n",
+			call.desc, len([]rune(call.text)), result.Chunks, result.FromLanguage, result.Confidence,
+			len(result.GlossaryTerms), len(result.GlossaryViolations))
+		fmt.Printf("    译文: %s\n", truncateText(result.TranslatedText, 120))
+	}
+	fmt.Println()
+}
+
+// truncateText 截断过长的文本用于展示
+func truncateText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// demonstrateFileManager 依次调用文件管理工具的各种操作，包括被安全策略拒绝的调用
+func demonstrateFileManager(ctx context.Context, toolsNode *MockToolsNode) {
+	calls := []struct {
+		desc string
+		args string
+	}{
+		{"按行范围读取", `{"action": "read", "path": "example.txt", "start_line": 2, "end_line": 3}`},
+		{"创建文件 (自动创建目录)", `{"action": "create", "path": "notes/todo.md", "content": "- 学习 ToolsNode\n"}`},
+		{"追加内容", `{"action": "append", "path": "notes/todo.md", "content": "- 学习 Chain\n"}`},
+		{"递归列出目录", `{"action": "list", "recursive": true}`},
+		{"搜索内容", `{"action": "search", "query": "chain"}`},
+		{"删除文件", `{"action": "delete", "path": "notes/todo.md"}`},
+		{"路径穿越 (被拒绝)", `{"action": "read", "path": "../../etc/passwd"}`},
+		{"不允许的扩展名 (被拒绝)", `{"action": "create", "path": "run.sh", "content": "echo hi"}`},
+	}
+
+	for i, call := range calls {
+		msg := &schema.Message{
+			Role: "assistant",
+			ToolCalls: []schema.ToolCall{{
+				ID:       fmt.Sprintf("call_fm_%03d", i+1),
+				Type:     "function",
+				Function: schema.FunctionCall{Name: "file_manager", Arguments: call.args},
+			}},
+		}
+		results, err := toolsNode.Invoke(ctx, msg)
+		if err != nil {
+			fmt.Printf("  %s: 失败: %v\n", call.desc, err)
+			continue
+		}
+		fmt.Printf("  %s: %s\n", call.desc, results[0].Content)
+	}
+	fmt.Println()
 }
 
 // --- Chain 中使用 ToolsNode 的演示 ---
@@ -520,7 +691,9 @@ func NewMockToolsNode(tools []InvokableTool) *MockToolsNode {
 
 // Invoke 执行工具调用的核心方法
 // 接收包含工具调用指令的消息，返回工具执行结果
-func (n *MockToolsNode) Invoke(ctx context.Context, msg *schema.Message) ([]*schema.Message, error) {
+// opts 会原样传给每个工具，工具只读取自己的选项类型，其余选项被忽略
+// (对应官方 ToolsNode 的 compose.WithToolOption)
+func (n *MockToolsNode) Invoke(ctx context.Context, msg *schema.Message, opts ...tool.Option) ([]*schema.Message, error) {
 	// 只处理来自助手且包含工具调用的消息
 	if msg.Role != "assistant" || len(msg.ToolCalls) == 0 {
 		return nil, nil
@@ -531,14 +704,30 @@ func (n *MockToolsNode) Invoke(ctx context.Context, msg *schema.Message) ([]*sch
 	// 遍历所有工具调用请求
 	for _, call := range msg.ToolCalls {
 		// 根据工具名称查找对应的工具实例
-		tool, exists := n.tools[call.Function.Name]
+		t, exists := n.tools[call.Function.Name]
 		if !exists {
 			log.Printf("工具 '%s' 不存在", call.Function.Name)
 			continue // 跳过不存在的工具
 		}
 
+		// 按照工具的参数定义校验参数 (必填、类型、枚举等)，
+		// 校验失败时把问题作为工具结果返回，让模型修正参数后重试
+		info, err := t.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取工具 %s 的信息失败: %w", call.Function.Name, err)
+		}
+		if output, ok := schemacheck.Check(info, call.Function.Arguments); !ok {
+			results = append(results, &schema.Message{
+				Role:       "tool",
+				Content:    output,
+				Name:       call.Function.Name,
+				ToolCallID: call.ID,
+			})
+			continue
+		}
+
 		// 执行工具调用
-		output, err := tool.InvokableRun(ctx, call.Function.Arguments)
+		output, err := t.InvokableRun(ctx, call.Function.Arguments, opts...)
 		if err != nil {
 			// 如果工具执行失败，记录错误并返回错误信息
 			log.Printf("工具 '%s' 执行失败: %v", call.Function.Name, err)
@@ -590,10 +779,51 @@ func parseFloat(s string) float64 {
 	return result
 }
 
-// simulateTranslation 模拟文本翻译功能
-// 使用预定义的翻译映射表来模拟翻译过程
-// 实际应用中应该调用真实的翻译 API，如 Google Translate、百度翻译等
-func simulateTranslation(text, fromLang, toLang string) string {
+// projectGlossary 项目术语表: 组件名保持原文，专业术语使用统一译法
+const projectGlossary = `{
+	"terms": [
+		{"source": "ToolsNode", "targets": {"*": "ToolsNode"}, "note": "组件名，不翻译"},
+		{"source": "tool call", "targets": {"zh": "工具调用", "ja": "ツール呼び出し"}},
+		{"source": "Eino", "targets": {"*": "Eino"}, "case_sensitive": true}
+	]
+}`
+
+// mockTranslationModel 模拟的翻译模型，实现 model.BaseChatModel，使演示不依赖外部服务。
+// 按照 tools/translator 的提示词格式读取目标语言、术语表和待翻译文本，返回 JSON 格式的结果:
+// 常用短语查表翻译 (置信度高)，其他文本返回带标记的模拟译文 (置信度低)。
+// 第一次翻译时故意不使用术语表，收到术语反馈后才按规定译法修正，用于演示术语校验和重试。
+type mockTranslationModel struct{}
+
+var (
+	mockTargetLang = regexp.MustCompile(`目标语言: .* \(([\w-]+)\)`)
+	mockTermLine   = regexp.MustCompile(`(?m)^- (.+?) => ([^(\n]+?)(?: \(.*\))?$`)
+)
+
+// Generate 返回一次翻译结果
+func (m *mockTranslationModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	// 第一条用户消息是翻译请求，之后的用户消息是术语反馈
+	var request string
+	var retried bool
+	for _, msg := range input {
+		if msg.Role == schema.User {
+			if request != "" {
+				retried = true
+			}
+			if request == "" {
+				request = msg.Content
+			}
+		}
+	}
+	start, end := strings.Index(request, "<<<\n"), strings.LastIndex(request, "\n>>>")
+	if start < 0 || end < start {
+		return nil, errors.New("无法识别的翻译请求")
+	}
+	text := request[start+4 : end]
+	toLang := "zh"
+	if match := mockTargetLang.FindStringSubmatch(request); match != nil {
+		toLang = match[1]
+	}
+
 	// 预定义的翻译映射表
 	// 键为原文，值为目标语言代码到翻译文本的映射
 	translations := map[string]map[string]string{
@@ -608,57 +838,31 @@ func simulateTranslation(text, fromLang, toLang string) string {
 			"fr": "Bonjour",
 		},
 	}
-
-	// 查找原文是否存在于翻译表中
-	if langMap, exists := translations[text]; exists {
-		// 查找目标语言的翻译
-		if translation, exists := langMap[toLang]; exists {
-			return translation
+	translation, confidence := fmt.Sprintf("[模拟翻译 -> %s] %s", toLang, text), 0.6
+	if known, ok := translations[text][toLang]; ok {
+		translation, confidence = known, 0.97
+	}
+	if retried {
+		for _, term := range mockTermLine.FindAllStringSubmatch(request, -1) {
+			translation = strings.ReplaceAll(translation, term[1], term[2])
 		}
 	}
 
-	// 如果没有找到对应的翻译，返回模拟的翻译格式
-	return fmt.Sprintf("[模拟翻译: %s -> %s] %s", fromLang, toLang, text)
+	detected := translator.DetectLanguage(text)
+	if detected == "" {
+		detected = "en"
+	}
+	reply, _ := json.Marshal(map[string]any{"detected_language": detected, "translation": translation, "confidence": confidence})
+	return schema.AssistantMessage(string(reply), nil), nil
 }
 
-// simulateFileOperation 模拟文件系统操作
-// 根据不同的操作类型返回相应的模拟结果
-// 实际应用中应该执行真实的文件系统操作，并需要添加安全检查
-func simulateFileOperation(action, path, content string) map[string]interface{} {
-	switch action {
-	case "list":
-		// 模拟目录列表操作
-		return map[string]interface{}{
-			"action": "list",
-			"path":   path,
-			"files":  []string{"file1.txt", "file2.txt", "dir1/"}, // 模拟的文件列表
-		}
-	case "info":
-		// 模拟文件信息查询
-		return map[string]interface{}{
-			"action":      "info",
-			"path":        path,
-			"exists":      true,                            // 模拟文件存在
-			"size":        1024,                            // 模拟文件大小（字节）
-			"modified":    time.Now().Format(time.RFC3339), // 模拟修改时间
-			"permissions": "rw-r--r--",                     // 模拟文件权限
-		}
-	case "create":
-		// 模拟文件创建操作
-		return map[string]interface{}{
-			"action":  "create",
-			"path":    path,
-			"success": true, // 模拟创建成功
-			"message": fmt.Sprintf("文件 %s 创建成功", path),
-		}
-	default:
-		// 不支持的操作类型
-		return map[string]interface{}{
-			"action": action,
-			"path":   path,
-			"error":  "不支持的操作",
-		}
+// Stream 翻译工具只使用 Generate
+func (m *mockTranslationModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
 }
 
 // main 程序入口点，启动 ToolsNode 完整演示
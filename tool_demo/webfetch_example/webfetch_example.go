@@ -0,0 +1,145 @@
+// package main 表明这是一个可执行程序
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"Eini/tools/webfetch"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: webfetch_example.go
+//  功能: 演示 tools/webfetch 提供的 http_fetch 工具: 读取网页、转换为 Markdown / 纯文本、
+//        安全策略 (域名白名单、SSRF 防护、重定向限制) 和把网页索引到知识库。
+//  说明: 使用 webfetch.FakeSite 提供的本地文档站点和域名解析，不依赖外部网络。
+//        本地站点监听在 127.0.0.1，默认属于禁止连接的地址，所以通过 AllowedNetworks 单独放行。
+//
+// =============================================================================
+
+// memoryDocTool 把文档保存在内存中的文档处理工具，参数与 comprehensive_demo 的 document_processor 相同
+type memoryDocTool struct {
+	docs map[string]string
+}
+
+func (m *memoryDocTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "document_processor", Desc: "处理和索引新文档到知识库"}, nil
+}
+
+func (m *memoryDocTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var args struct {
+		Content  string         `json:"content"`
+		DocID    string         `json:"doc_id"`
+		MetaData map[string]any `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+	m.docs[args.DocID] = args.Content
+	fmt.Printf("  [document_processor] 保存文档 %s (%d 字符)，来源: %v，标题: %v\n",
+		args.DocID, len([]rune(args.Content)), args.MetaData["source_url"], args.MetaData["title"])
+	return fmt.Sprintf(`{"original_doc_id": %q, "status": "success"}`, args.DocID), nil
+}
+
+// demonstrateWebFetch 依次演示读取、安全策略和索引
+func demonstrateWebFetch() error {
+	ctx := context.Background()
+	site := webfetch.NewFakeSite()
+	defer site.Close()
+
+	docs := &memoryDocTool{docs: map[string]string{}}
+	fetcher, err := webfetch.New(&webfetch.Config{
+		AllowedDomains:  []string{"*.example.test"},
+		AllowedNetworks: []string{site.Network()},
+		LookupIP:        site.LookupIP,
+		MaxContentChars: 2000,
+		DocumentTool:    docs,
+	})
+	if err != nil {
+		return err
+	}
+	info, _ := fetcher.Info(ctx)
+	fmt.Printf("工具: %s\n描述: %s\n", info.Name, info.Desc)
+
+	fetch := func(args string) (*webfetch.Result, error) {
+		output, err := fetcher.InvokableRun(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		var result webfetch.Result
+		return &result, json.Unmarshal([]byte(output), &result)
+	}
+
+	// 1. 网页转换为 Markdown: 去掉导航、脚本和侧栏，保留标题、列表、代码和表格，链接解析为绝对地址
+	fmt.Println("\n=== 1. 网页转换为 Markdown ===")
+	result, err := fetch(fmt.Sprintf(`{"url": %q}`, site.URL("/guide")))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("标题: %s\n描述: %s\n\n%s\n", result.Title, result.Description, result.Content)
+
+	// 2. 纯文本、其他编码、JSON、重定向和截断
+	fmt.Println("\n=== 2. 其他格式 ===")
+	for _, args := range []string{
+		fmt.Sprintf(`{"url": %q, "format": "text", "max_chars": 60}`, site.URL("/guide")),
+		fmt.Sprintf(`{"url": %q}`, site.URL("/gbk")),
+		fmt.Sprintf(`{"url": %q}`, site.URL("/api/status")),
+		fmt.Sprintf(`{"url": %q, "max_chars": 30}`, site.URL("/old-guide")),
+		fmt.Sprintf(`{"url": %q}`, site.URL("/logo.png")),
+		fmt.Sprintf(`{"url": %q}`, site.URL("/missing")),
+	} {
+		result, err := fetch(args)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n  -> HTTP %d, %s, 截断: %v, 最终地址: %s\n  %q\n",
+			args, result.Status, result.ContentType, result.Truncated, result.FinalURL, result.Content)
+	}
+
+	// 3. 安全策略: 直接调用 Fetch 时拒绝的请求返回 error，可以用 errors.Is(err, webfetch.ErrBlocked) 判断
+	fmt.Println("\n=== 3. 安全策略 ===")
+	for _, args := range []string{
+		`{"url": "https://example.com/"}`,                                              // 不在白名单
+		`{"url": "file:///etc/passwd"}`,                                                // 不支持的协议
+		fmt.Sprintf(`{"url": %q, "method": "POST", "body": "{}"}`, site.URL("/guide")), // 不允许的方法
+		fmt.Sprintf(`{"url": %q}`, site.HostURL("intranet.example.test", "/")),         // 解析到内网地址
+		fmt.Sprintf(`{"url": %q}`, site.URL("/metadata")),                              // 重定向到元数据地址
+		fmt.Sprintf(`{"url": %q}`, site.URL("/loop")),                                  // 重定向次数超限
+	} {
+		var req webfetch.Request
+		if err := json.Unmarshal([]byte(args), &req); err != nil {
+			return err
+		}
+		_, err := fetcher.Fetch(ctx, &req)
+		fmt.Printf("%s\n  -> 被拒绝: %v, 错误: %v\n", args, errors.Is(err, webfetch.ErrBlocked), err)
+	}
+	// 作为工具调用时，拒绝的原因作为结果返回给模型，而不是中断 ToolsNode
+	output, err := fetcher.InvokableRun(ctx, `{"url": "https://example.com/"}`)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("工具调用 https://example.com/\n  -> %s\n", output)
+
+	// 4. 获取网页后交给文档处理工具索引，索引的内容始终是 Markdown
+	fmt.Println("\n=== 4. 索引到知识库 ===")
+	result, err = fetch(fmt.Sprintf(`{"url": %q, "format": "text", "max_chars": 40, "index": true}`, site.URL("/guide")))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("返回给模型的内容: %q\n索引结果: %s\n已保存 %d 个文档\n", result.Content, result.Indexed, len(docs.docs))
+	return nil
+}
+
+// main 是程序的入口点。
+func main() {
+	if err := demonstrateWebFetch(); err != nil {
+		log.Fatalf("http_fetch 示例失败: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package codeeval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/dop251/goja"
+)
+
+// =============================================================================
+//
+//  文件: tools/codeeval/codeeval.go
+//  功能: 在沙箱中执行 JavaScript 的 code_eval 工具，实现 tool.InvokableTool。
+//  说明: 用于模型难以可靠完成的计算和多步数据处理 (统计、排序、分组、日期计算等)。
+//        解释器是纯 Go 实现的 goja，每次执行创建新的解释器，执行之间不共享状态；
+//        console.log 的输出作为 stdout 返回，脚本最后一个表达式的值转换为 JSON 作为结果返回。
+//        脚本出错、超时或超出内存时不返回 error，而是把错误信息作为结果返回给模型修正。
+//  安全: 见 sandbox.go。
+//
+// =============================================================================
+
+// 默认限制
+const (
+	DefaultTimeout          = 2 * time.Second
+	DefaultMaxMemoryBytes   = 64 << 20
+	DefaultMaxCodeBytes     = 32 << 10
+	DefaultMaxOutputBytes   = 16 << 10
+	DefaultMaxResultBytes   = 64 << 10
+	DefaultMaxArrayLength   = 1_000_000
+	DefaultMaxStringLength  = 8 << 20
+	DefaultMaxCallStackSize = 1000
+)
+
+// hardTimeoutGrace 超时后等待解释器停止的时间。脚本停在一个耗时的内置函数中时，
+// 中断要等该函数返回后才生效，超过这个时间不再等待，直接返回超时。
+const hardTimeoutGrace = time.Second
+
+// evalSlot 同一时间只运行一个脚本: 内存按整个进程估算，并发执行会互相影响。
+// 这只能避免脚本之间的影响，进程中其他代码分配的内存仍然会计入正在运行的脚本。
+var evalSlot = make(chan struct{}, 1)
+
+// Config code_eval 工具的配置
+type Config struct {
+	// Name 工具名称，默认 code_eval
+	Name string
+
+	Timeout          time.Duration // 单次执行的时间限制，默认 DefaultTimeout
+	MaxMemoryBytes   int64         // 执行期间进程堆内存最多增长的字节数 (近似值)，默认 DefaultMaxMemoryBytes
+	MaxCodeBytes     int           // 代码最大字节数，默认 DefaultMaxCodeBytes
+	MaxOutputBytes   int           // stdout 最多保留的字节数，超出部分丢弃，默认 DefaultMaxOutputBytes
+	MaxResultBytes   int           // 结果 JSON 的最大字节数，默认 DefaultMaxResultBytes
+	MaxArrayLength   int           // 数组方法可以处理的最大长度，默认 DefaultMaxArrayLength
+	MaxStringLength  int           // repeat、replace、concat、join 和模板字符串等生成的字符串的最大长度，默认 DefaultMaxStringLength
+	MaxCallStackSize int           // 最大调用深度，默认 DefaultMaxCallStackSize
+}
+
+// Tool code_eval 工具
+type Tool struct {
+	config Config
+}
+
+// New 创建 code_eval 工具
+func New(config *Config) *Tool {
+	cfg := Config{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Name == "" {
+		cfg.Name = "code_eval"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxMemoryBytes <= 0 {
+		cfg.MaxMemoryBytes = DefaultMaxMemoryBytes
+	}
+	if cfg.MaxCodeBytes <= 0 {
+		cfg.MaxCodeBytes = DefaultMaxCodeBytes
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	if cfg.MaxResultBytes <= 0 {
+		cfg.MaxResultBytes = DefaultMaxResultBytes
+	}
+	if cfg.MaxArrayLength <= 0 {
+		cfg.MaxArrayLength = DefaultMaxArrayLength
+	}
+	if cfg.MaxStringLength <= 0 {
+		cfg.MaxStringLength = DefaultMaxStringLength
+	}
+	if cfg.MaxCallStackSize <= 0 {
+		cfg.MaxCallStackSize = DefaultMaxCallStackSize
+	}
+	return &Tool{config: cfg}
+}
+
+// Info 返回工具的元信息和参数定义
+func (t *Tool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: t.config.Name,
+		Desc: fmt.Sprintf("在沙箱中执行一段 JavaScript (ES2020) 代码，适合多步计算和数据处理，如统计、排序、分组、日期和精确的数值计算。"+
+			"没有文件系统和网络，不能使用 require / import / setTimeout。"+
+			"console.log 的输出作为 stdout 返回；最后一个表达式的值 (或顶层 return 的值) 转换为 JSON 作为 result 返回。"+
+			"每次执行互相独立，最多执行 %s。", t.config.Timeout),
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"code": {
+				Type:     schema.String,
+				Desc:     "要执行的 JavaScript 代码",
+				Required: true,
+			},
+			"input": {
+				Type: schema.Object,
+				Desc: "可选的输入数据，在代码中通过全局变量 input 读取",
+			},
+		}),
+	}, nil
+}
+
+// Result 执行结果
+type Result struct {
+	Result          json.RawMessage `json:"result,omitempty"` // 最后一个表达式的值
+	Stdout          string          `json:"stdout,omitempty"`
+	StdoutTruncated bool            `json:"stdout_truncated,omitempty"` // stdout 超过 MaxOutputBytes，后面的输出被丢弃
+	Error           string          `json:"error,omitempty"`
+	ElapsedMS       int64           `json:"elapsed_ms"`
+}
+
+// InvokableRun 执行代码，返回 Result 的 JSON
+func (t *Tool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var req struct {
+		Code  string          `json:"code"`
+		Input json.RawMessage `json:"input"`
+	}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	result, err := t.Eval(ctx, req.Code, req.Input)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("结果序列化失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// Eval 执行代码，input 为空或 null 时全局变量 input 为 undefined。
+// 脚本本身的错误通过 Result.Error 返回，只有调用被取消时返回 error。
+func (t *Tool) Eval(ctx context.Context, code string, input json.RawMessage) (*Result, error) {
+	if strings.TrimSpace(code) == "" {
+		return &Result{Error: "代码为空"}, nil
+	}
+	if len(code) > t.config.MaxCodeBytes {
+		return &Result{Error: fmt.Sprintf("代码有 %d 字节，超过限制 %d 字节", len(code), t.config.MaxCodeBytes)}, nil
+	}
+	if string(input) == "null" {
+		input = nil
+	}
+
+	// 等待上一个脚本结束，最多等待一个执行时间
+	wait := time.NewTimer(t.config.Timeout)
+	defer wait.Stop()
+	select {
+	case evalSlot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("执行代码失败: %w", ctx.Err())
+	case <-wait.C:
+		return &Result{Error: "上一段代码仍在运行，请稍后重试"}, nil
+	}
+
+	sb, err := newSandbox(&t.config, input)
+	if err != nil {
+		<-evalSlot
+		return &Result{Error: err.Error()}, nil
+	}
+
+	start := time.Now()
+	log.Printf("[CodeEval] 执行 %d 字节的代码", len(code))
+	type outcome struct {
+		value json.RawMessage
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() { <-evalSlot }()
+		value, err := sb.run(ctx, code)
+		done <- outcome{value, err}
+	}()
+
+	result := &Result{}
+	hard := time.NewTimer(t.config.Timeout + hardTimeoutGrace)
+	defer hard.Stop()
+	select {
+	case out := <-done:
+		if out.err != nil {
+			result.Error = t.describe(out.err)
+		} else {
+			result.Result = out.value
+		}
+		result.Stdout, result.StdoutTruncated = sb.stdout.buf.String(), sb.stdout.truncated
+	case <-hard.C:
+		// 解释器停在耗时的内置函数中，后台继续等待它响应中断，这里直接返回 (此时解释器可能仍在写入 stdout，不读取)
+		sb.vm.Interrupt(errTimeout)
+		result.Error = t.describe(errTimeout)
+		log.Printf("[CodeEval] 超时后 %s 内没有停止，放弃等待", hardTimeoutGrace)
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("执行代码失败: %w", ctx.Err())
+	}
+
+	result.ElapsedMS = time.Since(start).Milliseconds()
+	log.Printf("[CodeEval] 完成, 耗时 %dms, stdout %d 字节, 错误: %q", result.ElapsedMS, len(result.Stdout), result.Error)
+	return result, nil
+}
+
+// describe 把执行错误整理为给模型阅读的说明
+func (t *Tool) describe(err error) string {
+	var reason any = err
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		reason = interrupted.Value()
+	}
+	switch reason {
+	case errTimeout:
+		return fmt.Sprintf("执行超过 %s 未完成，已中断，请检查是否有死循环或减少计算量", t.config.Timeout)
+	case errMemory:
+		return fmt.Sprintf("内存使用超过 %d MiB，已中断，请减少数据量或中间结果", t.config.MaxMemoryBytes>>20)
+	case errCanceled:
+		return "调用已取消"
+	}
+	var overflow *goja.StackOverflowError
+	if errors.As(err, &overflow) {
+		return fmt.Sprintf("RangeError: 调用层级超过 %d 层，可能是无限递归", t.config.MaxCallStackSize)
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return describeException(exception)
+	}
+	return err.Error()
+}
@@ -0,0 +1,639 @@
+package codeeval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dop251/goja"
+	"github.com/dop251/goja/ast"
+)
+
+// =============================================================================
+//
+//  文件: tools/codeeval/sandbox.go
+//  功能: 创建和运行 JavaScript 沙箱。
+//  安全:
+//    - goja 只实现 ECMAScript 标准库，没有文件系统、网络、require/import 和定时器；
+//      这里再删除 ArrayBuffer、类型化数组等可以一次分配大块内存的二进制数据类型
+//    - 执行时间: 超时或调用被取消时中断解释器 (Interrupt 在两条指令之间生效)
+//    - 内存: 后台定期读取进程的堆内存，比执行前增长超过限制时中断
+//    - 单个内置函数 (如 Array.prototype.fill、String.prototype.repeat) 在 Go 中一次执行完，
+//      无法在中途中断，所以对按长度工作的内置函数在调用前检查数组长度和结果长度；
+//      模板字符串在编译时加上检查结果长度的标签函数。+ 运算符每次只拼接两个字符串，由内存检查兜底
+//  说明: 内存是按整个进程的堆估算的，同一时间只运行一个脚本 (见 codeeval.go) 以免互相影响；
+//        同一进程中的其他请求同时分配的内存也会算在脚本上，所以限制只是近似值。
+//
+// =============================================================================
+
+// 中断解释器的原因，作为 Interrupt 的参数
+var (
+	errTimeout  = errors.New("执行超时")
+	errMemory   = errors.New("内存超限")
+	errCanceled = errors.New("调用已取消")
+)
+
+// 内存检查的间隔
+const (
+	memoryCheckInterval = 5 * time.Millisecond   // 读取堆内存的间隔
+	minGCBackoff        = 20 * time.Millisecond  // 强制回收后没有超限时，到下一次强制回收的最短间隔
+	maxGCBackoff        = 500 * time.Millisecond // 强制回收的最长间隔
+)
+
+// scriptName 脚本的文件名，用于在异常的调用栈中找到脚本中的位置
+const scriptName = "code.js"
+
+// templateTag 编译时给没有标签的模板字符串加上的标签函数，在拼接前检查结果长度。
+// 定义为只读的全局变量，脚本只能在自己的作用域中用同名变量遮蔽它。
+const templateTag = "__codeEvalTemplate"
+
+// removedGlobals 从全局对象删除的二进制数据类型，构造时按参数一次分配内存
+var removedGlobals = []string{
+	"ArrayBuffer", "SharedArrayBuffer", "DataView",
+	"Int8Array", "Uint8Array", "Uint8ClampedArray", "Int16Array", "Uint16Array",
+	"Int32Array", "Uint32Array", "Float32Array", "Float64Array", "BigInt64Array", "BigUint64Array",
+}
+
+// sandbox 一次执行使用的解释器，不在多次执行之间复用
+type sandbox struct {
+	config     *Config
+	vm         *goja.Runtime
+	stdout     *output
+	stringify  goja.Callable // 执行前保存的 JSON.stringify，脚本修改全局对象不影响结果的转换
+	concat     goja.Callable // 执行前保存的 String.prototype.concat，用于拼接模板字符串
+	rangeError goja.Value    // 执行前保存的 RangeError 构造函数
+}
+
+// newSandbox 创建解释器: 删除二进制数据类型，安装 console 和 input，给内置函数加上长度检查
+func newSandbox(config *Config, input json.RawMessage) (*sandbox, error) {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(config.MaxCallStackSize)
+	s := &sandbox{config: config, vm: vm, stdout: &output{limit: config.MaxOutputBytes}}
+
+	jsonObject := vm.Get("JSON").ToObject(vm)
+	s.stringify, _ = goja.AssertFunction(jsonObject.Get("stringify"))
+	s.rangeError = vm.Get("RangeError")
+	s.concat, _ = goja.AssertFunction(vm.Get("String").ToObject(vm).Get("prototype").ToObject(vm).Get("concat"))
+
+	for _, name := range removedGlobals {
+		if err := vm.GlobalObject().Delete(name); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.guardBuiltins(); err != nil {
+		return nil, fmt.Errorf("设置内置函数失败: %w", err)
+	}
+
+	if err := vm.GlobalObject().DefineDataProperty(templateTag, vm.ToValue(s.template), goja.FLAG_FALSE, goja.FLAG_FALSE, goja.FLAG_FALSE); err != nil {
+		return nil, err
+	}
+
+	console := vm.NewObject()
+	for name, prefix := range map[string]string{"log": "", "info": "", "debug": "", "warn": "[warn] ", "error": "[error] "} {
+		prefix := prefix
+		if err := console.Set(name, func(call goja.FunctionCall) goja.Value {
+			s.print(prefix, call.Arguments)
+			return goja.Undefined()
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := vm.Set("console", console); err != nil {
+		return nil, err
+	}
+
+	// input: 调用方传入的数据，通过 JSON.parse 转换为脚本中的普通对象
+	value := goja.Undefined()
+	if len(input) > 0 {
+		parse, _ := goja.AssertFunction(jsonObject.Get("parse"))
+		v, err := parse(jsonObject, vm.ToValue(string(input)))
+		if err != nil {
+			return nil, fmt.Errorf("input 不是合法的 JSON: %w", err)
+		}
+		value = v
+	}
+	if err := vm.Set("input", value); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// guardBuiltins 给按长度工作的内置函数加上检查: 数组方法检查 this 和参数的长度，
+// join、concat、flat 和字符串方法检查结果的长度。原函数保存在 Go 的闭包中，脚本无法取回。
+func (s *sandbox) guardBuiltins() error {
+	vm := s.vm
+	arrayProto := vm.Get("Array").ToObject(vm).Get("prototype").ToObject(vm)
+	arrayChecks := map[string]func(call goja.FunctionCall){
+		"join":    s.checkJoin,
+		"concat":  s.checkArrayConcat,
+		"flat":    s.checkFlat,
+		"flatMap": s.checkFlatMap,
+	}
+	for _, name := range arrayProto.GetOwnPropertyNames() {
+		if name == "constructor" {
+			continue
+		}
+		if err := s.wrap(arrayProto, name, arrayChecks[name]); err != nil {
+			return err
+		}
+	}
+	if iterator, ok := goja.AssertFunction(arrayProto.GetSymbol(goja.SymIterator)); ok {
+		guarded := s.guarded(iterator, nil)
+		if err := arrayProto.DefineDataPropertySymbol(goja.SymIterator, guarded, goja.FLAG_TRUE, goja.FLAG_FALSE, goja.FLAG_TRUE); err != nil {
+			return err
+		}
+	}
+
+	for object, names := range map[string][]string{
+		"Array":   {"from", "of"},
+		"Reflect": {"apply", "construct"},
+		"JSON":    {"stringify"},
+	} {
+		obj := vm.Get(object).ToObject(vm)
+		for _, name := range names {
+			if err := s.wrap(obj, name, nil); err != nil {
+				return err
+			}
+		}
+	}
+	funcProto := vm.Get("Function").ToObject(vm).Get("prototype").ToObject(vm)
+	if err := s.wrap(funcProto, "apply", nil); err != nil {
+		return err
+	}
+
+	stringProto := vm.Get("String").ToObject(vm).Get("prototype").ToObject(vm)
+	if err := s.wrap(stringProto, "repeat", s.checkRepeat); err != nil {
+		return err
+	}
+	for _, name := range []string{"padStart", "padEnd"} {
+		if err := s.wrap(stringProto, name, s.checkPad); err != nil {
+			return err
+		}
+	}
+	if err := s.wrap(stringProto, "concat", s.checkStringConcat); err != nil {
+		return err
+	}
+	// 统计正则表达式的匹配次数时使用原来的 replace
+	replace, ok := goja.AssertFunction(stringProto.Get("replace"))
+	if !ok {
+		return nil
+	}
+	for _, name := range []string{"replace", "replaceAll"} {
+		if err := s.wrap(stringProto, name, s.checkReplace(replace, name == "replaceAll")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrap 用带检查的函数替换 obj[name]；不存在的方法 (解释器版本不同) 跳过。
+// check 为空时检查 this 和所有参数的长度。
+func (s *sandbox) wrap(obj *goja.Object, name string, check func(call goja.FunctionCall)) error {
+	fn, ok := goja.AssertFunction(obj.Get(name))
+	if !ok {
+		return nil
+	}
+	return obj.DefineDataProperty(name, s.guarded(fn, check), goja.FLAG_TRUE, goja.FLAG_FALSE, goja.FLAG_TRUE)
+}
+
+// guarded 返回先检查、再调用原函数的函数
+func (s *sandbox) guarded(fn goja.Callable, check func(call goja.FunctionCall)) goja.Value {
+	return s.vm.ToValue(func(call goja.FunctionCall) goja.Value {
+		if check != nil {
+			check(call)
+		} else {
+			s.checkLength(call.This)
+			for _, arg := range call.Arguments {
+				s.checkLength(arg)
+			}
+		}
+		v, err := fn(call.This, call.Arguments...)
+		if err != nil {
+			panic(err) // 脚本异常和中断原样抛出
+		}
+		return v
+	})
+}
+
+// checkLength 对象的 length 超过 MaxArrayLength 时抛出 RangeError
+func (s *sandbox) checkLength(v goja.Value) {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return
+	}
+	if _, isFunc := goja.AssertFunction(obj); isFunc {
+		return
+	}
+	if length := obj.Get("length"); length != nil && length.ToFloat() > float64(s.config.MaxArrayLength) {
+		s.throwRange(fmt.Sprintf("数组长度 %v 超过限制 %d", length, s.config.MaxArrayLength))
+	}
+}
+
+// checkJoin 检查数组长度，并估算拼接结果的长度 (元素的长度和分隔符)
+func (s *sandbox) checkJoin(call goja.FunctionCall) {
+	s.checkLength(call.This)
+	obj, ok := call.This.(*goja.Object)
+	if !ok {
+		return
+	}
+	sep := ","
+	if arg := call.Argument(0); !goja.IsUndefined(arg) {
+		sep = arg.String()
+	}
+	if s.joinLength(obj, utf8.RuneCountInString(sep), 0) > s.config.MaxStringLength {
+		s.throwRange(fmt.Sprintf("拼接结果超过 %d 个字符", s.config.MaxStringLength))
+	}
+}
+
+// joinLength 估算数组拼接后的长度: 字符串和数字等原始值按转换后的长度计算，
+// 嵌套的数组按 toString (用逗号拼接) 递归计算，其他对象不计入。超过 MaxStringLength 后不再继续计算。
+func (s *sandbox) joinLength(obj *goja.Object, sep, depth int) int {
+	n := int(obj.Get("length").ToInteger())
+	total := sep * max(n-1, 0)
+	for i := 0; i < n && total <= s.config.MaxStringLength; i++ {
+		switch v := obj.Get(fmt.Sprint(i)).(type) {
+		case nil:
+		case *goja.Object:
+			if v.ClassName() == "Array" && depth < s.config.MaxCallStackSize {
+				total += s.joinLength(v, 1, depth+1)
+			}
+		default:
+			if !goja.IsUndefined(v) && !goja.IsNull(v) {
+				total += len(v.String())
+			}
+		}
+	}
+	return total
+}
+
+// checkArrayConcat 检查 Array.prototype.concat 的结果长度 (this 和数组参数的长度之和)
+func (s *sandbox) checkArrayConcat(call goja.FunctionCall) {
+	total := 0
+	for _, v := range append([]goja.Value{call.This}, call.Arguments...) {
+		s.checkLength(v)
+		if obj, ok := v.(*goja.Object); ok && obj.ClassName() == "Array" {
+			total += int(obj.Get("length").ToInteger())
+		} else {
+			total++
+		}
+	}
+	if total > s.config.MaxArrayLength {
+		s.throwRange(fmt.Sprintf("合并后的数组长度超过限制 %d", s.config.MaxArrayLength))
+	}
+}
+
+// checkFlat 检查 Array.prototype.flat 展开后的长度。
+// 展开层数不超过 MaxCallStackSize，以免包含自身的数组无限递归。
+func (s *sandbox) checkFlat(call goja.FunctionCall) {
+	s.checkLength(call.This)
+	obj, ok := call.This.(*goja.Object)
+	if !ok {
+		return
+	}
+	depth := 1
+	if arg := call.Argument(0); !goja.IsUndefined(arg) {
+		depth = int(min(max(arg.ToFloat(), 0), float64(s.config.MaxCallStackSize)+1))
+	}
+	if s.flatLength(obj, depth, 0, 0) > s.config.MaxArrayLength {
+		s.throwRange(fmt.Sprintf("展开后的数组长度超过限制 %d", s.config.MaxArrayLength))
+	}
+}
+
+// flatLength 计算数组展开 depth 层后的长度，level 为已经展开的层数。超过 MaxArrayLength 后不再继续计算
+func (s *sandbox) flatLength(obj *goja.Object, depth, level, total int) int {
+	if level > s.config.MaxCallStackSize {
+		s.throwRange(fmt.Sprintf("展开的层数超过 %d", s.config.MaxCallStackSize))
+	}
+	n := int(obj.Get("length").ToInteger())
+	for i := 0; i < n && total <= s.config.MaxArrayLength; i++ {
+		if v, ok := obj.Get(fmt.Sprint(i)).(*goja.Object); ok && depth > 0 && v.ClassName() == "Array" {
+			total = s.flatLength(v, depth-1, level+1, total)
+		} else {
+			total++
+		}
+	}
+	return total
+}
+
+// checkFlatMap 检查 Array.prototype.flatMap 的结果长度: 回调函数每返回一个数组累加它的长度
+func (s *sandbox) checkFlatMap(call goja.FunctionCall) {
+	s.checkLength(call.This)
+	fn, ok := goja.AssertFunction(call.Argument(0))
+	if !ok {
+		return // 原函数抛出 TypeError
+	}
+	total := 0
+	call.Arguments[0] = s.vm.ToValue(func(c goja.FunctionCall) goja.Value {
+		v, err := fn(c.This, c.Arguments...)
+		if err != nil {
+			panic(err)
+		}
+		if obj, ok := v.(*goja.Object); ok && obj.ClassName() == "Array" {
+			total += int(obj.Get("length").ToInteger())
+		} else {
+			total++
+		}
+		if total > s.config.MaxArrayLength {
+			s.throwRange(fmt.Sprintf("展开后的数组长度超过限制 %d", s.config.MaxArrayLength))
+		}
+		return v
+	})
+}
+
+// checkStringConcat 检查 String.prototype.concat 的结果长度。
+// 参数在这里转换为字符串后传给原函数，toString 只调用一次。
+func (s *sandbox) checkStringConcat(call goja.FunctionCall) {
+	total := len(call.This.String())
+	for i, arg := range call.Arguments {
+		str := arg.String()
+		call.Arguments[i] = s.vm.ToValue(str)
+		if total += len(str); total > s.config.MaxStringLength {
+			s.throwRange(fmt.Sprintf("concat 的结果超过 %d 个字符", s.config.MaxStringLength))
+		}
+	}
+}
+
+// checkReplace 返回 String.prototype.replace / replaceAll 的检查，按匹配次数估算结果长度:
+//   - 替换为函数时，累加每次返回的字符串的长度
+//   - 替换为字符串时，每次匹配最多插入替换串本身，加上每个 $ 引用 ($&、$`、$' 等) 最多插入整个原字符串
+//
+// 正则表达式的匹配次数由原来的 replace 配合计数的回调函数得到，这次调用的结果不会比原字符串长。
+func (s *sandbox) checkReplace(replace goja.Callable, all bool) func(call goja.FunctionCall) {
+	return func(call goja.FunctionCall) {
+		text := call.This.String()
+		limit := s.config.MaxStringLength
+		if fn, ok := goja.AssertFunction(call.Argument(1)); ok {
+			total := len(text)
+			call.Arguments[1] = s.vm.ToValue(func(c goja.FunctionCall) goja.Value {
+				v, err := fn(c.This, c.Arguments...)
+				if err != nil {
+					panic(err)
+				}
+				str := v.String()
+				if total += len(str); total > limit {
+					s.throwRange(fmt.Sprintf("替换的结果超过 %d 个字符", limit))
+				}
+				return s.vm.ToValue(str)
+			})
+			return
+		}
+
+		pattern := call.Argument(0)
+		matches := 0
+		if obj, ok := pattern.(*goja.Object); ok && obj.ClassName() == "RegExp" {
+			counter := s.vm.ToValue(func(goja.FunctionCall) goja.Value {
+				matches++
+				return s.vm.ToValue("")
+			})
+			if _, err := replace(s.vm.ToValue(text), pattern, counter); err != nil {
+				panic(err)
+			}
+		} else if str := pattern.String(); all {
+			matches = strings.Count(text, str)
+		} else if strings.Contains(text, str) {
+			matches = 1
+		}
+
+		replacement := call.Argument(1).String()
+		perMatch := len(replacement) + strings.Count(replacement, "$")*len(text)
+		if float64(len(text))+float64(matches)*float64(perMatch) > float64(limit) {
+			s.throwRange(fmt.Sprintf("替换的结果超过 %d 个字符", limit))
+		}
+	}
+}
+
+// template 实现模板字符串的拼接: 按顺序交替拼接字符串片段和转换为字符串的值，结果超过 MaxStringLength 时抛出 RangeError
+func (s *sandbox) template(call goja.FunctionCall) goja.Value {
+	strs := call.Argument(0).ToObject(s.vm)
+	parts := []goja.Value{strs.Get("0")}
+	for i, v := range call.Arguments[1:] {
+		parts = append(parts, v.ToString(), strs.Get(fmt.Sprint(i+1))) // Symbol 原样保留，由 concat 抛出 TypeError
+	}
+	total := 0
+	for _, part := range parts {
+		if _, isSymbol := part.(*goja.Symbol); !isSymbol {
+			total += len(part.String())
+		}
+	}
+	if total > s.config.MaxStringLength {
+		s.throwRange(fmt.Sprintf("模板字符串的结果超过 %d 个字符", s.config.MaxStringLength))
+	}
+	v, err := s.concat(s.vm.ToValue(""), parts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// checkRepeat 检查 String.prototype.repeat 的结果长度
+func (s *sandbox) checkRepeat(call goja.FunctionCall) {
+	size := float64(len(call.This.String())) * call.Argument(0).ToFloat()
+	if size > float64(s.config.MaxStringLength) {
+		s.throwRange(fmt.Sprintf("repeat 的结果超过 %d 个字符", s.config.MaxStringLength))
+	}
+}
+
+// checkPad 检查 padStart / padEnd 的目标长度
+func (s *sandbox) checkPad(call goja.FunctionCall) {
+	if call.Argument(0).ToFloat() > float64(s.config.MaxStringLength) {
+		s.throwRange(fmt.Sprintf("填充后的长度超过 %d 个字符", s.config.MaxStringLength))
+	}
+}
+
+// throwRange 在脚本中抛出 RangeError
+func (s *sandbox) throwRange(message string) {
+	ctor, _ := goja.AssertConstructor(s.rangeError)
+	obj, err := ctor(nil, s.vm.ToValue(message))
+	if err != nil {
+		panic(err)
+	}
+	panic(obj)
+}
+
+// print 实现 console.log 等方法: 字符串原样输出，其他值转换为 JSON，参数之间用空格分隔
+func (s *sandbox) print(prefix string, args []goja.Value) {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = s.format(arg)
+	}
+	s.stdout.write(prefix + strings.Join(parts, " ") + "\n")
+}
+
+// format 把值转换为输出的文本
+func (s *sandbox) format(v goja.Value) string {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return v.String()
+	}
+	if _, isFunc := goja.AssertFunction(obj); !isFunc {
+		if out, err := s.stringify(goja.Undefined(), obj); err == nil && !goja.IsUndefined(out) {
+			return out.String()
+		}
+	}
+	return obj.String()
+}
+
+// run 编译并执行脚本，返回最后一个表达式的值 (JSON) 或错误
+func (s *sandbox) run(ctx context.Context, code string) (result json.RawMessage, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("解释器内部错误: %v", x)
+		}
+	}()
+
+	program, err := compile(code)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.AfterFunc(s.config.Timeout, func() { s.vm.Interrupt(errTimeout) })
+	defer timer.Stop()
+	stop := context.AfterFunc(ctx, func() { s.vm.Interrupt(errCanceled) })
+	defer stop()
+	done := make(chan struct{})
+	defer close(done)
+	go s.watchMemory(done)
+
+	value, err := s.vm.RunProgram(program)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || goja.IsUndefined(value) {
+		return nil, nil
+	}
+	out, err := s.stringify(goja.Undefined(), value)
+	if err != nil {
+		return nil, fmt.Errorf("结果无法转换为 JSON: %v", err)
+	}
+	if goja.IsUndefined(out) {
+		return nil, nil
+	}
+	text := out.String()
+	if len(text) > s.config.MaxResultBytes {
+		return nil, fmt.Errorf("结果有 %d 字节，超过限制 %d 字节，请只返回需要的部分或用 console.log 输出摘要", len(text), s.config.MaxResultBytes)
+	}
+	return json.RawMessage(text), nil
+}
+
+// compile 编译脚本。顶层出现 return 时把脚本包装为函数再编译，return 的值作为结果；
+// 包装时不增加行，错误信息中的行号仍然对应原来的代码。
+func compile(code string) (*goja.Program, error) {
+	program, err := goja.Parse(scriptName, code)
+	if err != nil && strings.Contains(err.Error(), "Illegal return") {
+		program, err = goja.Parse(scriptName, "(function () {"+code+"\n})()")
+	}
+	if err != nil {
+		return nil, err
+	}
+	tagTemplates(reflect.ValueOf(program))
+	return goja.CompileAST(program, false)
+}
+
+// astPackage 语法树节点所在的包，tagTemplates 只遍历这个包中的类型
+var astPackage = reflect.TypeOf(ast.Program{}).PkgPath()
+
+// tagTemplates 遍历语法树，给没有标签且包含表达式的模板字符串加上 templateTag 标签。
+// 标签函数得到的字符串片段和原来的拼接结果相同，节点的位置不变，异常中的行列号不受影响。
+func tagTemplates(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if literal, ok := v.Interface().(*ast.TemplateLiteral); ok && literal.Tag == nil && len(literal.Expressions) > 0 {
+			literal.Tag = &ast.Identifier{Name: templateTag, Idx: literal.OpenQuote}
+		}
+		tagTemplates(v.Elem())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			tagTemplates(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type().PkgPath() != astPackage {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				tagTemplates(v.Field(i))
+			}
+		}
+	}
+}
+
+// describeException 异常的说明: 异常的值和在脚本中的位置 (跳过内置函数的调用栈)
+func describeException(exception *goja.Exception) string {
+	message := "未知错误"
+	if v := exception.Value(); v != nil {
+		message = v.String()
+	}
+	for _, frame := range exception.Stack() {
+		if frame.SrcName() == scriptName {
+			position := frame.Position()
+			return fmt.Sprintf("%s (第 %d 行第 %d 列)", message, position.Line, position.Column)
+		}
+	}
+	return message
+}
+
+// watchMemory 定期读取堆内存，比执行前增长超过 MaxMemoryBytes 时中断脚本。
+// 超过时先强制回收一次，只按回收后仍然存活的对象判断，避免把尚未回收的垃圾算进去。
+// 强制回收会暂停整个进程，回收后没有超限 (脚本在不断产生垃圾) 时按指数退避拉长到下一次强制回收的间隔，
+// 间隔内超出的部分不会立即中断，这也是限制只是近似值的原因之一。
+func (s *sandbox) watchMemory(done <-chan struct{}) {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	read := func() int64 {
+		metrics.Read(sample)
+		return int64(sample[0].Value.Uint64())
+	}
+	base := read()
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	var backoff time.Duration
+	var nextGC time.Time
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			if read()-base <= s.config.MaxMemoryBytes || now.Before(nextGC) {
+				continue
+			}
+			runtime.GC()
+			if read()-base > s.config.MaxMemoryBytes {
+				s.vm.Interrupt(errMemory)
+				return
+			}
+			backoff = min(max(backoff*2, minGCBackoff), maxGCBackoff)
+			nextGC = time.Now().Add(backoff)
+		}
+	}
+}
+
+// output 有长度限制的输出缓冲，超出部分丢弃
+type output struct {
+	buf       strings.Builder
+	limit     int
+	truncated bool
+}
+
+// write 写入文本，超过限制时在字符边界截断
+func (o *output) write(text string) {
+	if o.truncated {
+		return
+	}
+	if remain := o.limit - o.buf.Len(); len(text) > remain {
+		for remain > 0 && !utf8.RuneStart(text[remain]) {
+			remain--
+		}
+		text = text[:remain]
+		o.truncated = true
+	}
+	o.buf.WriteString(text)
+}
@@ -0,0 +1,61 @@
+package codeeval
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSandboxLengthChecks(t *testing.T) {
+	evaluator := New(&Config{MaxStringLength: 1000, MaxArrayLength: 1000})
+
+	tests := []struct {
+		name    string
+		code    string
+		want    string // 期望的 result (JSON)，wantErr 为空时检查
+		wantErr string // 期望错误信息包含的文本
+	}{
+		{name: "repeat 超限", code: "'x'.repeat(2000)", wantErr: "repeat 的结果超过"},
+		{name: "padStart 超限", code: "'x'.padStart(2000)", wantErr: "填充后的长度超过"},
+		{name: "replaceAll 替换串放大", code: "'x'.repeat(100).replaceAll('x', 'y'.repeat(100))", wantErr: "替换的结果超过"},
+		{name: "replace 全局正则", code: "'x'.repeat(100).replace(/x/g, 'y'.repeat(20))", wantErr: "替换的结果超过"},
+		{name: "replace 的 $ 引用", code: "'x'.repeat(100).replace(/x/g, \"$'\")", wantErr: "替换的结果超过"},
+		{name: "replace 替换函数", code: "'x'.repeat(100).replace(/x/g, () => 'y'.repeat(20))", wantErr: "替换的结果超过"},
+		{name: "replace 正常使用", code: "'a-b-c'.replace(/-/g, '+')", want: `"a+b+c"`},
+		{name: "replace 非全局正则只替换一次", code: "'x'.repeat(100).replace(/x/, 'y'.repeat(500)).length", want: "599"},
+		{name: "replaceAll 的 $& 引用", code: "'abc'.replaceAll('b', '[$&]')", want: `"a[b]c"`},
+		{name: "replace 替换函数正常使用", code: "'abc'.replace('b', m => m.toUpperCase())", want: `"aBc"`},
+		{name: "String.prototype.concat 超限", code: "const s = 'x'.repeat(400); ''.concat(s, s, s)", wantErr: "concat 的结果超过"},
+		{name: "String.prototype.concat 正常使用", code: "'a'.concat(1, null)", want: `"a1null"`},
+		{name: "Array.prototype.concat 超限", code: "const a = Array(400).fill(0); a.concat(a, a).length", wantErr: "合并后的数组长度超过"},
+		{name: "flat 超限", code: "Array(400).fill([1, 2, 3]).flat().length", wantErr: "展开后的数组长度超过"},
+		{name: "flat 包含自身的数组", code: "const a = []; a.push(a); a.flat(Infinity)", wantErr: "展开的层数超过"},
+		{name: "flat 正常使用", code: "[1, [2, [3]]].flat(Infinity)", want: "[1,2,3]"},
+		{name: "flatMap 超限", code: "Array(400).fill(0).flatMap(() => [1, 2, 3]).length", wantErr: "展开后的数组长度超过"},
+		{name: "join 嵌套数组超限", code: "const s = 'x'.repeat(400); [[s, s], [s]].join()", wantErr: "拼接结果超过"},
+		{name: "join 数字元素超限", code: "Array(300).fill(123456).join('')", wantErr: "拼接结果超过"},
+		{name: "join 正常使用", code: "[1, 'a', [2, 3]].join('-')", want: `"1-a-2,3"`},
+		{name: "模板字符串超限", code: "const s = 'x'.repeat(400); `${s}${s}${s}`", wantErr: "模板字符串的结果超过"},
+		{name: "模板字符串正常使用", code: "const n = 2, o = {toString() { return '个' }}; `${n} ${o}苹果`", want: `"2 个苹果"`},
+		{name: "模板字符串中的 Symbol", code: "`${Symbol()}`", wantErr: "TypeError"},
+		{name: "带标签的模板字符串不变", code: "String.raw`a${1}\\n`", want: `"a1\\n"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := evaluator.Eval(context.Background(), tt.code, nil)
+			if err != nil {
+				t.Fatalf("Eval 返回错误: %v", err)
+			}
+			if tt.wantErr != "" {
+				if !strings.Contains(result.Error, tt.wantErr) {
+					t.Fatalf("错误 = %q，期望包含 %q", result.Error, tt.wantErr)
+				}
+				return
+			}
+			if result.Error != "" || string(result.Result) != tt.want {
+				t.Fatalf("结果 = %s，错误 = %q，期望 %s", result.Result, result.Error, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package filemanager
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/filemanager/audit.go
+//  功能: 修改类操作 (create / write / append / delete) 的审计日志。
+//  说明: 每次操作写入一行 JSON，被拒绝的操作 (只读模式、路径越界、超出大小限制等)
+//        同样会被记录，success 为 false 并附带原因。
+//
+// =============================================================================
+
+// AuditRecord 一条审计记录
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Path    string    `json:"path"`
+	Bytes   int       `json:"bytes,omitempty"` // 写入的字节数
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// auditor 串行写入审计记录，保证并发调用时每条记录完整占一行
+type auditor struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newAuditor(w io.Writer) *auditor {
+	return &auditor{w: w}
+}
+
+// record 写入审计记录。审计日志写入失败不影响操作结果，只记录到日志中。
+func (a *auditor) record(rec *AuditRecord, opErr error) {
+	rec.Time = time.Now()
+	rec.Success = opErr == nil
+	if opErr != nil {
+		rec.Error = opErr.Error()
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		log.Printf("[FileManager] 审计记录序列化失败: %v", err)
+		return
+	}
+
+	if a.w == nil {
+		log.Printf("[FileManager][审计] %s", line)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(line, '\n')); err != nil {
+		log.Printf("[FileManager] 写入审计日志失败: %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+package filemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/filemanager/filemanager.go
+//  功能: 限定在根目录内的文件管理工具，实现 tool.InvokableTool。
+//  操作: list / read / create / write / append / delete / info / search
+//  安全:
+//    - 所有路径都相对于配置的根目录，通过 os.Root 访问文件系统，
+//      ".." 路径穿越和指向根目录之外的符号链接都会被拒绝
+//    - 读写大小限制、只读模式、扩展名白名单 (配置了白名单时不通过符号链接读写文件)
+//    - create / write / append / delete 无论成功与否都会写入审计日志
+//
+// =============================================================================
+
+// 默认限制
+const (
+	DefaultMaxReadBytes     = 1 << 20  // 单次读取最多返回 1MB
+	DefaultMaxWriteBytes    = 1 << 20  // 单次写入最多 1MB
+	DefaultMaxFileSize      = 10 << 20 // 写入 / 追加后文件最大 10MB
+	DefaultMaxListEntries   = 1000     // list 最多返回的条目数
+	DefaultMaxSearchResults = 100      // search 最多返回的匹配数
+)
+
+// 支持的操作
+const (
+	ActionList   = "list"
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionWrite  = "write"
+	ActionAppend = "append"
+	ActionDelete = "delete"
+	ActionInfo   = "info"
+	ActionSearch = "search"
+)
+
+// Config 文件管理工具的配置
+type Config struct {
+	// Root 根目录，所有操作都限定在该目录内 (必填)
+	Root string
+	// ReadOnly 只读模式，拒绝 create / write / append / delete
+	ReadOnly bool
+	// AllowedExtensions 允许读写的文件扩展名，如 ".txt"、".md"，不区分大小写；为空时不限制。
+	// list 和 info 只返回元数据，不受此限制。
+	AllowedExtensions []string
+
+	MaxReadBytes     int64 // 单次读取最多返回的字节数，默认 DefaultMaxReadBytes
+	MaxWriteBytes    int64 // 单次写入的最大字节数，默认 DefaultMaxWriteBytes
+	MaxFileSize      int64 // 写入 / 追加后文件的最大字节数，默认 DefaultMaxFileSize
+	MaxListEntries   int   // list 最多返回的条目数，默认 DefaultMaxListEntries
+	MaxSearchResults int   // search 最多返回的匹配数，默认 DefaultMaxSearchResults
+
+	// AuditLog 审计日志的输出位置，每条记录为一行 JSON；为空时通过 log 包输出
+	AuditLog io.Writer
+}
+
+// FileManager 文件管理工具
+type FileManager struct {
+	config     Config
+	root       *os.Root
+	extensions map[string]struct{}
+	audit      *auditor
+}
+
+// New 创建文件管理工具，根目录必须已经存在
+func New(config *Config) (*FileManager, error) {
+	if config == nil || config.Root == "" {
+		return nil, errors.New("必须配置根目录")
+	}
+	cfg := *config
+	if cfg.MaxReadBytes <= 0 {
+		cfg.MaxReadBytes = DefaultMaxReadBytes
+	}
+	if cfg.MaxWriteBytes <= 0 {
+		cfg.MaxWriteBytes = DefaultMaxWriteBytes
+	}
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = DefaultMaxFileSize
+	}
+	if cfg.MaxListEntries <= 0 {
+		cfg.MaxListEntries = DefaultMaxListEntries
+	}
+	if cfg.MaxSearchResults <= 0 {
+		cfg.MaxSearchResults = DefaultMaxSearchResults
+	}
+
+	root, err := os.OpenRoot(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("打开根目录失败: %w", err)
+	}
+
+	extensions := make(map[string]struct{}, len(cfg.AllowedExtensions))
+	for _, ext := range cfg.AllowedExtensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		extensions[ext] = struct{}{}
+	}
+
+	return &FileManager{
+		config:     cfg,
+		root:       root,
+		extensions: extensions,
+		audit:      newAuditor(cfg.AuditLog),
+	}, nil
+}
+
+// Close 关闭根目录
+func (f *FileManager) Close() error {
+	return f.root.Close()
+}
+
+// Info 返回工具的元信息和参数定义
+func (f *FileManager) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	actions := []string{ActionList, ActionRead, ActionInfo, ActionSearch}
+	desc := "管理工作目录中的文件: 列出目录、读取文件 (支持字节和行范围)、查看信息、按名称或内容搜索"
+	if !f.config.ReadOnly {
+		actions = append(actions, ActionCreate, ActionWrite, ActionAppend, ActionDelete)
+		desc += "，以及创建、覆盖写入、追加和删除文件"
+	}
+	desc += "。路径是相对于工作目录的路径，如 docs/readme.md"
+	if len(f.config.AllowedExtensions) > 0 {
+		desc += fmt.Sprintf("。只能读写以下类型的文件: %s", strings.Join(f.config.AllowedExtensions, ", "))
+	}
+
+	return &schema.ToolInfo{
+		Name: "file_manager",
+		Desc: desc,
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"action": {
+				Type:     schema.String,
+				Desc:     "操作类型",
+				Enum:     actions,
+				Required: true,
+			},
+			"path": {
+				Type: schema.String,
+				Desc: "相对于工作目录的路径，为空时表示工作目录本身",
+			},
+			"content": {
+				Type: schema.String,
+				Desc: "写入的内容 (create / write / append 时使用)",
+			},
+			"offset": {
+				Type: schema.Integer,
+				Desc: "read: 从第几个字节开始读取，从 0 开始",
+			},
+			"limit": {
+				Type: schema.Integer,
+				Desc: "read: 最多读取的字节数",
+			},
+			"start_line": {
+				Type: schema.Integer,
+				Desc: "read: 起始行号，从 1 开始 (包含)；指定行范围时忽略 offset 和 limit",
+			},
+			"end_line": {
+				Type: schema.Integer,
+				Desc: "read: 结束行号 (包含)，为空时读到文件末尾",
+			},
+			"recursive": {
+				Type: schema.Boolean,
+				Desc: "list: 是否递归列出子目录",
+			},
+			"pattern": {
+				Type: schema.String,
+				Desc: "search: 文件名匹配模式，如 *.md",
+			},
+			"query": {
+				Type: schema.String,
+				Desc: "search: 在文件内容中搜索的文本，返回匹配的行",
+			},
+		}),
+	}, nil
+}
+
+// request 工具参数
+type request struct {
+	Action    string `json:"action"`
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Offset    int64  `json:"offset"`
+	Limit     int64  `json:"limit"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Recursive bool   `json:"recursive"`
+	Pattern   string `json:"pattern"`
+	Query     string `json:"query"`
+}
+
+// InvokableRun 执行文件操作，返回 JSON 格式的结果
+func (f *FileManager) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var req request
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	log.Printf("[FileManager] 执行 %s 操作，路径: %s", req.Action, req.Path)
+
+	var (
+		result any
+		err    error
+	)
+	switch req.Action {
+	case ActionList:
+		result, err = f.list(ctx, &req)
+	case ActionRead:
+		result, err = f.read(&req)
+	case ActionInfo:
+		result, err = f.info(&req)
+	case ActionSearch:
+		result, err = f.search(ctx, &req)
+	case ActionCreate, ActionWrite, ActionAppend, ActionDelete:
+		result, err = f.mutate(&req)
+	default:
+		return "", fmt.Errorf("不支持的操作: %s", req.Action)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("结果序列化失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// resolve 把用户传入的路径规范化为根目录内的相对路径。
+// 绝对路径和包含 ".." 穿越的路径直接拒绝；符号链接逃逸由 os.Root 在访问时拒绝。
+func resolve(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" || p == "." || p == "/" {
+		return ".", nil
+	}
+	if filepath.IsAbs(p) || strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("路径 %s 必须是相对于工作目录的路径", p)
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(p))
+	if !filepath.IsLocal(cleaned) {
+		return "", fmt.Errorf("路径 %s 超出了工作目录", p)
+	}
+	return cleaned, nil
+}
+
+// checkExtension 检查文件扩展名是否在白名单内
+func (f *FileManager) checkExtension(name string) error {
+	if len(f.extensions) == 0 {
+		return nil
+	}
+	if _, ok := f.extensions[strings.ToLower(filepath.Ext(name))]; !ok {
+		return fmt.Errorf("不允许访问 %s 类型的文件", filepath.Ext(name))
+	}
+	return nil
+}
+
+// checkFile 检查要读写的文件: 扩展名必须在白名单内。
+// 配置了白名单时不允许通过符号链接读写，否则 notes.txt -> secrets.env 这样的链接可以绕过白名单；
+// 文件不存在时 (create) 只检查扩展名。
+func (f *FileManager) checkFile(name string) error {
+	if err := f.checkExtension(name); err != nil {
+		return err
+	}
+	if len(f.extensions) == 0 {
+		return nil
+	}
+	lstat, err := f.root.Lstat(name)
+	if err == nil && lstat.Mode()&fs.ModeSymlink != 0 {
+		return fmt.Errorf("%s 是符号链接，配置了扩展名白名单时不允许通过符号链接访问文件", displayPath(name))
+	}
+	return nil
+}
+
+// extensionAllowed 判断文件扩展名是否在白名单内
+func (f *FileManager) extensionAllowed(name string) bool {
+	return f.checkExtension(name) == nil
+}
+
+// displayPath 把内部路径转换为返回给调用方的路径 (使用 / 分隔)
+func displayPath(p string) string {
+	return filepath.ToSlash(p)
+}
@@ -0,0 +1,510 @@
+package filemanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/filemanager/operations.go
+//  功能: 文件管理工具各操作的实现，所有文件系统访问都通过 os.Root 进行。
+//
+// =============================================================================
+
+// maxSearchLineLen search 结果中每行最多返回的字符数
+const maxSearchLineLen = 200
+
+// entry 目录条目
+type entry struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"` // file / dir / symlink / other
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+}
+
+// listResult list 操作的结果
+type listResult struct {
+	Path      string   `json:"path"`
+	Entries   []*entry `json:"entries"`
+	Truncated bool     `json:"truncated,omitempty"` // 条目数超过上限，只返回了一部分
+}
+
+// readResult read 操作的结果
+type readResult struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Content   string `json:"content"`
+	Offset    int64  `json:"offset,omitempty"`     // 字节范围读取时的起始位置
+	Bytes     int    `json:"bytes"`                // 返回的字节数
+	StartLine int    `json:"start_line,omitempty"` // 行范围读取时实际返回的首行
+	EndLine   int    `json:"end_line,omitempty"`   // 行范围读取时实际返回的末行
+	Truncated bool   `json:"truncated"`            // 是否还有未返回的内容
+}
+
+// infoResult info 操作的结果
+type infoResult struct {
+	Path     string `json:"path"`
+	Exists   bool   `json:"exists"`
+	Type     string `json:"type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+	Modified string `json:"modified,omitempty"`
+	Target   string `json:"target,omitempty"` // 符号链接指向的文件类型
+}
+
+// searchMatch search 操作的一个匹配
+type searchMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// searchResult search 操作的结果
+type searchResult struct {
+	Matches   []*searchMatch `json:"matches"`
+	Truncated bool           `json:"truncated,omitempty"`
+}
+
+// mutateResult create / write / append / delete 操作的结果
+type mutateResult struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	Bytes  int    `json:"bytes,omitempty"` // 写入的字节数
+	Size   int64  `json:"size,omitempty"`  // 操作后的文件大小
+}
+
+// ================================
+// 只读操作
+// ================================
+
+// list 列出目录内容，recursive 时递归列出子目录
+func (f *FileManager) list(ctx context.Context, req *request) (*listResult, error) {
+	dir, err := resolve(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	result := &listResult{Path: displayPath(dir), Entries: []*entry{}}
+
+	if !req.Recursive {
+		d, err := f.root.Open(dir)
+		if err != nil {
+			return nil, fmt.Errorf("打开目录失败: %w", err)
+		}
+		defer d.Close()
+		dirEntries, err := d.ReadDir(-1)
+		if err != nil {
+			return nil, fmt.Errorf("读取目录失败: %w", err)
+		}
+		for _, de := range dirEntries {
+			if len(result.Entries) >= f.config.MaxListEntries {
+				result.Truncated = true
+				break
+			}
+			result.Entries = append(result.Entries, newEntry(path.Join(displayPath(dir), de.Name()), de))
+		}
+		return result, nil
+	}
+
+	err = fs.WalkDir(f.root.FS(), displayPath(dir), func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if p == displayPath(dir) {
+			return nil
+		}
+		if len(result.Entries) >= f.config.MaxListEntries {
+			result.Truncated = true
+			return fs.SkipAll
+		}
+		result.Entries = append(result.Entries, newEntry(p, de))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+	return result, nil
+}
+
+// read 读取文件内容，支持字节范围 (offset / limit) 和行范围 (start_line / end_line)
+func (f *FileManager) read(req *request) (*readResult, error) {
+	name, err := resolve(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkFile(name); err != nil {
+		return nil, err
+	}
+
+	file, err := f.root.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %w", err)
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s 不是普通文件", req.Path)
+	}
+
+	result := &readResult{Path: displayPath(name), Size: stat.Size()}
+	if req.StartLine > 0 || req.EndLine > 0 {
+		err = f.readLines(file, req, result)
+	} else {
+		err = f.readBytes(file, req, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// readBytes 按字节范围读取，单次最多读取 MaxReadBytes
+func (f *FileManager) readBytes(file *os.File, req *request, result *readResult) error {
+	if req.Offset < 0 || req.Limit < 0 {
+		return errors.New("offset 和 limit 不能为负数")
+	}
+	limit := req.Limit
+	if limit == 0 || limit > f.config.MaxReadBytes {
+		limit = f.config.MaxReadBytes
+	}
+	if req.Offset >= result.Size {
+		result.Offset = req.Offset
+		return nil
+	}
+
+	buf := make([]byte, min(limit, result.Size-req.Offset))
+	n, err := file.ReadAt(buf, req.Offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	result.Offset = req.Offset
+	result.Content = string(buf[:n])
+	result.Bytes = n
+	result.Truncated = req.Offset+int64(n) < result.Size
+	return nil
+}
+
+// readLines 按行范围读取 (行号从 1 开始，包含首尾)，返回内容超过 MaxReadBytes 时截断
+func (f *FileManager) readLines(file *os.File, req *request, result *readResult) error {
+	start, end := req.StartLine, req.EndLine
+	if start <= 0 {
+		start = 1
+	}
+	if end > 0 && end < start {
+		return fmt.Errorf("end_line (%d) 不能小于 start_line (%d)", end, start)
+	}
+
+	var sb strings.Builder
+	reader := bufio.NewReader(file)
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("读取文件失败: %w", err)
+		}
+		if line == "" {
+			break
+		}
+		if lineNo >= start {
+			// 超出行范围或大小上限时停止，说明还有未返回的内容
+			if (end > 0 && lineNo > end) || int64(sb.Len()+len(line)) > f.config.MaxReadBytes {
+				result.Truncated = true
+				break
+			}
+			sb.WriteString(line)
+			if result.StartLine == 0 {
+				result.StartLine = lineNo
+			}
+			result.EndLine = lineNo
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	result.Content = sb.String()
+	result.Bytes = sb.Len()
+	return nil
+}
+
+// info 返回文件或目录的信息，不存在时 exists 为 false
+func (f *FileManager) info(req *request) (*infoResult, error) {
+	name, err := resolve(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	result := &infoResult{Path: displayPath(name)}
+
+	lstat, err := f.root.Lstat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %w", err)
+	}
+
+	result.Exists = true
+	result.Type = fileType(lstat.Mode())
+	result.Size = lstat.Size()
+	result.Mode = lstat.Mode().String()
+	result.Modified = lstat.ModTime().Format(time.RFC3339)
+
+	if lstat.Mode()&fs.ModeSymlink != 0 {
+		// 只返回链接目标的类型；指向工作目录之外的链接会被 os.Root 拒绝
+		stat, err := f.root.Stat(name)
+		if err != nil {
+			result.Target = "inaccessible"
+		} else {
+			result.Target = fileType(stat.Mode())
+			result.Size = stat.Size()
+		}
+	}
+	return result, nil
+}
+
+// search 按文件名模式和 / 或内容搜索，内容匹配不区分大小写
+func (f *FileManager) search(ctx context.Context, req *request) (*searchResult, error) {
+	if req.Pattern == "" && req.Query == "" {
+		return nil, errors.New("search 操作需要 pattern 或 query 参数")
+	}
+	if req.Pattern != "" {
+		if _, err := path.Match(req.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("无效的文件名模式 %s: %w", req.Pattern, err)
+		}
+	}
+	dir, err := resolve(req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(req.Query)
+	result := &searchResult{Matches: []*searchMatch{}}
+	fsys := f.root.FS()
+
+	err = fs.WalkDir(fsys, displayPath(dir), func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !de.Type().IsRegular() {
+			return nil
+		}
+		if req.Pattern != "" {
+			if ok, _ := path.Match(req.Pattern, de.Name()); !ok {
+				return nil
+			}
+		}
+		if query == "" {
+			result.Matches = append(result.Matches, &searchMatch{Path: p})
+		} else if f.extensionAllowed(p) {
+			if err := f.searchFile(fsys, p, query, result); err != nil {
+				return err
+			}
+		}
+		if len(result.Matches) >= f.config.MaxSearchResults {
+			result.Matches = result.Matches[:f.config.MaxSearchResults]
+			result.Truncated = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("搜索失败: %w", err)
+	}
+	return result, nil
+}
+
+// searchFile 在单个文件中搜索内容，跳过二进制文件和超过 MaxFileSize 的文件
+func (f *FileManager) searchFile(fsys fs.FS, p, query string, result *searchResult) error {
+	file, err := fsys.Open(p)
+	if err != nil {
+		return nil // 无法打开的文件 (如权限不足) 直接跳过
+	}
+	defer file.Close()
+
+	if stat, err := file.Stat(); err != nil || stat.Size() > f.config.MaxFileSize {
+		return nil
+	}
+
+	reader := bufio.NewReader(file)
+	if head, _ := reader.Peek(512); bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), int(f.config.MaxReadBytes))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if !strings.Contains(strings.ToLower(line), query) {
+			continue
+		}
+		result.Matches = append(result.Matches, &searchMatch{Path: p, Line: lineNo, Text: truncateLine(line)})
+		if len(result.Matches) >= f.config.MaxSearchResults {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ================================
+// 修改操作
+// ================================
+
+// mutate 执行修改操作并写入审计日志
+func (f *FileManager) mutate(req *request) (*mutateResult, error) {
+	rec := &AuditRecord{Action: req.Action, Path: req.Path}
+	result, err := f.doMutate(req, rec)
+	f.audit.record(rec, err)
+	return result, err
+}
+
+func (f *FileManager) doMutate(req *request, rec *AuditRecord) (*mutateResult, error) {
+	if f.config.ReadOnly {
+		return nil, fmt.Errorf("只读模式下不允许 %s 操作", req.Action)
+	}
+	name, err := resolve(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "." {
+		return nil, fmt.Errorf("不能对工作目录本身执行 %s 操作", req.Action)
+	}
+	rec.Path = displayPath(name)
+
+	if req.Action == ActionDelete {
+		return f.remove(name)
+	}
+
+	if err := f.checkFile(name); err != nil {
+		return nil, err
+	}
+	size := int64(len(req.Content))
+	if size > f.config.MaxWriteBytes {
+		return nil, fmt.Errorf("写入内容 %d 字节，超过单次写入上限 %d 字节", size, f.config.MaxWriteBytes)
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE
+	switch req.Action {
+	case ActionCreate:
+		flag |= os.O_EXCL
+	case ActionWrite:
+		flag |= os.O_TRUNC
+	case ActionAppend:
+		flag |= os.O_APPEND
+		if stat, err := f.root.Stat(name); err == nil {
+			size += stat.Size()
+		}
+	}
+	if size > f.config.MaxFileSize {
+		return nil, fmt.Errorf("写入后文件大小 %d 字节，超过上限 %d 字节", size, f.config.MaxFileSize)
+	}
+
+	if err := f.mkdirAll(filepath.Dir(name)); err != nil {
+		return nil, err
+	}
+	file, err := f.root.OpenFile(name, flag, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("文件 %s 已存在，覆盖请使用 write 操作", req.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+
+	n, err := file.WriteString(req.Content)
+	rec.Bytes = n
+	if err != nil {
+		return nil, fmt.Errorf("写入文件失败: %w", err)
+	}
+	return &mutateResult{Action: req.Action, Path: displayPath(name), Bytes: n, Size: size}, nil
+}
+
+// remove 删除文件或空目录。不支持递归删除，避免一次调用误删整个目录树。
+func (f *FileManager) remove(name string) (*mutateResult, error) {
+	lstat, err := f.root.Lstat(name)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %w", err)
+	}
+	if !lstat.IsDir() {
+		if err := f.checkExtension(name); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.root.Remove(name); err != nil {
+		if lstat.IsDir() {
+			return nil, fmt.Errorf("删除目录失败 (只能删除空目录): %w", err)
+		}
+		return nil, fmt.Errorf("删除文件失败: %w", err)
+	}
+	return &mutateResult{Action: ActionDelete, Path: displayPath(name)}, nil
+}
+
+// mkdirAll 在根目录内逐级创建目录
+func (f *FileManager) mkdirAll(dir string) error {
+	if dir == "." {
+		return nil
+	}
+	current := ""
+	for _, part := range strings.Split(dir, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		if err := f.root.Mkdir(current, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("创建目录 %s 失败: %w", displayPath(current), err)
+		}
+	}
+	return nil
+}
+
+// ================================
+// 辅助函数
+// ================================
+
+// newEntry 根据目录条目创建返回结果
+func newEntry(p string, de fs.DirEntry) *entry {
+	e := &entry{Path: p, Type: fileType(de.Type())}
+	if info, err := de.Info(); err == nil {
+		e.Size = info.Size()
+		e.Modified = info.ModTime().Format(time.RFC3339)
+	}
+	return e
+}
+
+// fileType 返回文件类型的名称
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "other"
+	}
+}
+
+// truncateLine 截断过长的行，按字符截断避免破坏多字节字符
+func truncateLine(line string) string {
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) <= maxSearchLineLen {
+		return string(runes)
+	}
+	return string(runes[:maxSearchLineLen]) + "..."
+}
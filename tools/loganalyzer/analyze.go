@@ -0,0 +1,425 @@
+package loganalyzer
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/loganalyzer/analyze.go
+//  功能: 日志统计: 各级别数量、按消息模板聚类的错误、按时间分桶的速率、
+//        异常尖峰检测以及按签名分组的堆栈。
+//  说明: Analyzer 逐条累加 Entry，内存只与模板数量、堆栈分组数量和日志时间跨度 (秒) 有关，
+//        与日志行数无关。模板和堆栈分组数量超过上限后，新出现的归入 "<other>"。
+//
+// =============================================================================
+
+const (
+	defaultTopN        = 10
+	defaultMaxClusters = 1000
+	maxBuckets         = 1440 // 速率序列的最大桶数
+	autoBuckets        = 60   // 自动选择桶大小时的最大目标桶数
+	maxLineBytes       = 1 << 20
+	maxStackFrames     = 6 // 堆栈签名使用的帧数
+
+	spikeThreshold = 3.5 // 稳健 z 分数阈值
+	spikeMinCount  = 3   // 尖峰桶的最小计数
+	spikeMinBucket = 5   // 检测尖峰所需的最少桶数
+)
+
+// 自动选择桶大小时的候选值
+var bucketSizes = []time.Duration{
+	time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 6 * time.Hour, 24 * time.Hour,
+}
+
+// Options 统计选项
+type Options struct {
+	Bucket      time.Duration // 速率统计的桶大小，0 表示按时间跨度自动选择
+	TopN        int           // 输出的错误模板 / 堆栈分组数量，默认 10
+	MaxClusters int           // 模板和堆栈分组的数量上限，默认 1000
+}
+
+// Analyzer 日志统计器
+type Analyzer struct {
+	opts Options
+
+	entries  int
+	levels   map[string]int
+	formats  map[string]int
+	clusters map[string]*Cluster    // 错误消息模板 -> 聚类
+	stacks   map[string]*StackGroup // 堆栈签名 -> 分组
+	seconds  map[int64]*counts      // Unix 秒 -> 计数
+	loc      *time.Location
+}
+
+// counts 一个时间段内的计数
+type counts struct {
+	total, errors, warnings int
+}
+
+// Cluster 同一模板的错误消息
+type Cluster struct {
+	Template  string         `json:"template"`
+	Count     int            `json:"count"`
+	Levels    map[string]int `json:"levels"`
+	Example   string         `json:"example"`
+	FirstLine int            `json:"first_line"`
+	FirstSeen string         `json:"first_seen,omitempty"`
+	LastSeen  string         `json:"last_seen,omitempty"`
+}
+
+// StackGroup 签名相同的堆栈
+type StackGroup struct {
+	Signature string   `json:"signature"`
+	Count     int      `json:"count"`
+	Message   string   `json:"message"`
+	Frames    []string `json:"frames"`
+	Example   []string `json:"example"`
+	FirstLine int      `json:"first_line"`
+	FirstSeen string   `json:"first_seen,omitempty"`
+	LastSeen  string   `json:"last_seen,omitempty"`
+}
+
+// Bucket 速率序列中的一个桶
+type Bucket struct {
+	Start    string `json:"start"`
+	Total    int    `json:"total"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+}
+
+// Spike 异常尖峰
+type Spike struct {
+	Start    string  `json:"start"`
+	Metric   string  `json:"metric"` // total 或 errors
+	Count    int     `json:"count"`
+	Baseline float64 `json:"baseline"` // 该指标各桶的中位数
+	Score    float64 `json:"score"`    // 稳健 z 分数: (count - 中位数) / (1.4826 * MAD)
+}
+
+// NewAnalyzer 创建统计器
+func NewAnalyzer(opts Options) *Analyzer {
+	opts.TopN = cmp.Or(opts.TopN, defaultTopN)
+	opts.MaxClusters = cmp.Or(opts.MaxClusters, defaultMaxClusters)
+	return &Analyzer{
+		opts:     opts,
+		levels:   make(map[string]int),
+		formats:  make(map[string]int),
+		clusters: make(map[string]*Cluster),
+		stacks:   make(map[string]*StackGroup),
+		seconds:  make(map[int64]*counts),
+	}
+}
+
+// Scan 逐行读取日志并解析为 Entry，续行追加到上一条日志的 Stack 中，
+// 每条日志 (连同它的续行) 读完后调用 fn，返回读取的行数。
+// 无法按指定格式解析的行按普通文本处理，Entry.Format 为 FormatPlain。
+func Scan(r io.Reader, format string, fn func(*Entry) error) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	var pending *Entry
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if pending != nil && isContinuation(line) {
+			pending.Stack = append(pending.Stack, line)
+			continue
+		}
+
+		e, ok := parseLine(line, format)
+		if !ok {
+			e = parsePlain(line)
+		}
+		e.Line = n
+		if pending != nil {
+			if err := fn(pending); err != nil {
+				return n, err
+			}
+		}
+		pending = e
+	}
+	if err := scanner.Err(); err != nil {
+		return n, fmt.Errorf("读取第 %d 行失败: %w", n+1, err)
+	}
+	if pending != nil {
+		return n, fn(pending)
+	}
+	return n, nil
+}
+
+// Add 累加一条日志
+func (a *Analyzer) Add(e *Entry) {
+	a.entries++
+	a.levels[e.Level]++
+	a.formats[e.Format]++
+
+	seen := ""
+	if !e.Time.IsZero() {
+		if a.loc == nil {
+			a.loc = e.Time.Location()
+		}
+		sec := e.Time.Unix()
+		c := a.seconds[sec]
+		if c == nil {
+			c = &counts{}
+			a.seconds[sec] = c
+		}
+		c.total++
+		switch {
+		case e.isError():
+			c.errors++
+		case e.Level == LevelWarn:
+			c.warnings++
+		}
+		seen = e.Time.Format(time.RFC3339)
+	}
+
+	if e.isError() {
+		tmpl := Template(e.Message)
+		c := a.clusters[tmpl]
+		if c == nil {
+			if len(a.clusters) >= a.opts.MaxClusters {
+				tmpl = "<other>"
+				c = a.clusters[tmpl]
+			}
+			if c == nil {
+				c = &Cluster{Template: tmpl, Levels: make(map[string]int), Example: e.Message, FirstLine: e.Line, FirstSeen: seen}
+				a.clusters[tmpl] = c
+			}
+		}
+		c.Count++
+		c.Levels[e.Level]++
+		c.LastSeen = cmp.Or(seen, c.LastSeen)
+	}
+
+	if len(e.Stack) > 0 {
+		frames := stackFrames(e.Stack)
+		sig := Template(e.Message) + "\n" + strings.Join(frames, "\n")
+		g := a.stacks[sig]
+		if g == nil {
+			if len(a.stacks) >= a.opts.MaxClusters {
+				sig = "<other>"
+				g = a.stacks[sig]
+			}
+			if g == nil {
+				g = &StackGroup{
+					Signature: fmt.Sprintf("%08x", fnv32(sig)),
+					Message:   e.Message,
+					Frames:    frames,
+					Example:   e.Stack,
+					FirstLine: e.Line,
+					FirstSeen: seen,
+				}
+				a.stacks[sig] = g
+			}
+		}
+		g.Count++
+		g.LastSeen = cmp.Or(seen, g.LastSeen)
+	}
+}
+
+// Levels 各级别的日志数量
+func (a *Analyzer) Levels() map[string]int {
+	return a.levels
+}
+
+// Formats 各格式的日志数量
+func (a *Analyzer) Formats() map[string]int {
+	return a.formats
+}
+
+// Entries 已累加的日志条数
+func (a *Analyzer) Entries() int {
+	return a.entries
+}
+
+// TopErrors 数量最多的 TopN 个错误模板，数量相同时先出现的在前
+func (a *Analyzer) TopErrors() []*Cluster {
+	clusters := make([]*Cluster, 0, len(a.clusters))
+	for _, c := range a.clusters {
+		clusters = append(clusters, c)
+	}
+	slices.SortFunc(clusters, func(x, y *Cluster) int {
+		return cmp.Or(cmp.Compare(y.Count, x.Count), cmp.Compare(x.FirstLine, y.FirstLine))
+	})
+	return clusters[:min(len(clusters), a.opts.TopN)]
+}
+
+// StackTraces 数量最多的 TopN 个堆栈分组
+func (a *Analyzer) StackTraces() []*StackGroup {
+	groups := make([]*StackGroup, 0, len(a.stacks))
+	for _, g := range a.stacks {
+		groups = append(groups, g)
+	}
+	slices.SortFunc(groups, func(x, y *StackGroup) int {
+		return cmp.Or(cmp.Compare(y.Count, x.Count), cmp.Compare(x.FirstLine, y.FirstLine))
+	})
+	return groups[:min(len(groups), a.opts.TopN)]
+}
+
+// Rates 按时间分桶的日志数量，包含中间没有日志的桶。没有带时间的日志时返回空序列。
+// 未指定桶大小或指定的桶大小会产生超过 1440 个桶时，按时间跨度和日志数量自动选择
+// (最多 60 个桶)，返回实际使用的桶大小。
+func (a *Analyzer) Rates() (time.Duration, []Bucket) {
+	if len(a.seconds) == 0 {
+		return 0, nil
+	}
+	first, last := int64(math.MaxInt64), int64(math.MinInt64)
+	timed := 0
+	for sec, c := range a.seconds {
+		first, last = min(first, sec), max(last, sec)
+		timed += c.total
+	}
+
+	span := time.Duration(last-first) * time.Second
+	bucket := a.opts.Bucket
+	if bucket <= 0 || span/bucket >= maxBuckets {
+		// 日志较少时减少桶数，使每个桶平均有几条日志，避免大量空桶让尖峰检测过于敏感
+		target := time.Duration(min(max(timed/3, 2*spikeMinBucket), autoBuckets))
+		bucket = bucketSizes[len(bucketSizes)-1]
+		for _, size := range bucketSizes {
+			if span/size < target {
+				bucket = size
+				break
+			}
+		}
+	}
+
+	start := time.Unix(first, 0).In(a.loc).Truncate(bucket)
+	n := int(time.Unix(last, 0).Sub(start)/bucket) + 1
+	buckets := make([]Bucket, n)
+	for i := range buckets {
+		buckets[i].Start = start.Add(time.Duration(i) * bucket).Format(time.RFC3339)
+	}
+	for sec, c := range a.seconds {
+		i := int(time.Unix(sec, 0).Sub(start) / bucket)
+		buckets[i].Total += c.total
+		buckets[i].Errors += c.errors
+		buckets[i].Warnings += c.warnings
+	}
+	return bucket, buckets
+}
+
+// Spikes 在速率序列中检测日志总量和错误数量的异常尖峰。
+// 使用中位数和 MAD (中位数绝对偏差) 计算稳健 z 分数，不受尖峰本身拉高均值的影响；
+// 桶数少于 5 个时不检测。
+func Spikes(buckets []Bucket) []Spike {
+	if len(buckets) < spikeMinBucket {
+		return nil
+	}
+	var spikes []Spike
+	for _, metric := range []string{"total", "errors"} {
+		values := make([]float64, len(buckets))
+		for i, b := range buckets {
+			values[i] = float64(b.Total)
+			if metric == "errors" {
+				values[i] = float64(b.Errors)
+			}
+		}
+		med := median(values)
+		deviations := make([]float64, len(values))
+		for i, v := range values {
+			deviations[i] = math.Abs(v - med)
+		}
+		// MAD 为 0 (大部分桶数量相同) 时以 1 作为下限，避免任何波动都被判为尖峰
+		scale := max(1.4826*median(deviations), 1)
+
+		for i, v := range values {
+			score := (v - med) / scale
+			if score >= spikeThreshold && v >= spikeMinCount && v >= 2*med {
+				spikes = append(spikes, Spike{
+					Start:    buckets[i].Start,
+					Metric:   metric,
+					Count:    int(v),
+					Baseline: med,
+					Score:    math.Round(score*10) / 10,
+				})
+			}
+		}
+	}
+	return spikes
+}
+
+// median 中位数
+func median(values []float64) float64 {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+var (
+	uuidPattern   = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	emailPattern  = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`)
+	ipPattern     = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	hexPattern    = regexp.MustCompile(`\b(?:0x[0-9a-fA-F]+|[0-9a-fA-F]{8,})\b`)
+	quotedPattern = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	numberPattern = regexp.MustCompile(`\b\d+(?:\.\d+)?`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+
+	framePosPattern  = regexp.MustCompile(`(:\d+)+|\+0x[0-9a-fA-F]+|, line \d+`)
+	frameArgsPattern = regexp.MustCompile(`\(0x[^)]*\)$`)
+	stackSkipPattern = regexp.MustCompile(`^(goroutine \d+ \[|Traceback \(most recent call last\)|\.\.\. \d+ more)`)
+)
+
+// Template 把消息中的变量部分替换为占位符，得到用于聚类的模板:
+// UUID -> <uuid>，邮箱 -> <email>，IP -> <ip>，十六进制 -> <hex>，引号中的字符串 -> <str>，数字 -> <num>
+func Template(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = emailPattern.ReplaceAllString(msg, "<email>")
+	msg = ipPattern.ReplaceAllString(msg, "<ip>")
+	msg = hexPattern.ReplaceAllStringFunc(msg, func(s string) string {
+		// 纯字母的单词 (如 deadline) 不是十六进制
+		if strings.HasPrefix(s, "0x") || strings.ContainsAny(s, "0123456789") {
+			return "<hex>"
+		}
+		return s
+	})
+	msg = quotedPattern.ReplaceAllString(msg, "<str>")
+	msg = numberPattern.ReplaceAllString(msg, "<num>")
+	return strings.TrimSpace(spacePattern.ReplaceAllString(msg, " "))
+}
+
+// stackFrames 提取堆栈签名使用的帧: 去掉行号、偏移和参数地址，跳过 goroutine 头等与位置无关的行
+func stackFrames(stack []string) []string {
+	var frames []string
+	for _, line := range stack {
+		line = strings.TrimSpace(line)
+		if stackSkipPattern.MatchString(line) {
+			continue
+		}
+		line = frameArgsPattern.ReplaceAllString(line, "(...)")
+		line = framePosPattern.ReplaceAllString(line, "")
+		frames = append(frames, Template(line))
+		if len(frames) == maxStackFrames {
+			break
+		}
+	}
+	return frames
+}
+
+// fnv32 FNV-1a 哈希，用作堆栈分组的短标识
+func fnv32(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
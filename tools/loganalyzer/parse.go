@@ -0,0 +1,351 @@
+package loganalyzer
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/loganalyzer/parse.go
+//  功能: 把一行日志解析为 Entry，支持 JSON lines、logfmt、nginx/common log、
+//        Go log 前缀 (2006/01/02 15:04:05) 以及 "时间 级别 消息" 形式的普通文本日志。
+//  说明: auto 模式下逐行识别格式，混合格式的日志也能解析。
+//        堆栈、缩进行等续行不单独成为 Entry，而是追加到上一条日志的 Stack 中。
+//
+// =============================================================================
+
+// 支持的日志格式
+const (
+	FormatAuto   = "auto"   // 逐行自动识别
+	FormatJSON   = "json"   // JSON lines，如 {"time": "...", "level": "error", "msg": "..."}
+	FormatLogfmt = "logfmt" // 如 time=... level=error msg="..."
+	FormatNginx  = "nginx"  // nginx combined / common log，级别由状态码推断
+	FormatGoLog  = "golog"  // Go 标准库 log 的默认前缀，如 2006/01/02 15:04:05 message
+	FormatPlain  = "plain"  // 普通文本，如 2006-01-02 15:04:05 ERROR message
+)
+
+// 归一化后的日志级别
+const (
+	LevelDebug   = "debug"
+	LevelInfo    = "info"
+	LevelWarn    = "warn"
+	LevelError   = "error"
+	LevelFatal   = "fatal"
+	LevelUnknown = "unknown"
+)
+
+// Entry 一条解析后的日志
+type Entry struct {
+	Line    int               // 第一行的行号，从 1 开始
+	Format  string            // 实际识别出的格式
+	Time    time.Time         // 日志时间，无法识别时为零值
+	Level   string            // 归一化后的级别
+	Message string            // 日志消息
+	Fields  map[string]string // 其他字段 (JSON / logfmt 的其余键、nginx 的状态码等)
+	Stack   []string          // 续行 (堆栈等)
+}
+
+// isError 判断日志是否为错误级别 (error / fatal)
+func (e *Entry) isError() bool {
+	return e.Level == LevelError || e.Level == LevelFatal
+}
+
+var (
+	// nginx combined / common log: $remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent ["$http_referer" "$http_user_agent"]
+	nginxPattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\d+|-)(?: "([^"]*)" "([^"]*)")?`)
+	// Go 标准库 log 的默认前缀，可选微秒和 file:line
+	goLogPattern = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?) (?:([\w./-]+\.go:\d+): )?(.*)$`)
+	// 以时间戳开头的普通文本日志
+	plainTimePattern = regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\]?\s+(.*)$`)
+	// 消息开头的级别，如 ERROR、[WARN]、level=info 以外的 "error:"
+	levelPrefixPattern = regexp.MustCompile(`(?i)^\[?(trace|debug|info|information|notice|warn|warning|error|err|fatal|critical|crit|panic|severe)\]?:?\s+`)
+	// logfmt 键值对
+	logfmtPattern = regexp.MustCompile(`([\w.@-]+)=("(?:[^"\\]|\\.)*"|\S*)`)
+	// 续行: 缩进行、Java / Python 堆栈、Go goroutine 堆栈
+	continuationPattern = regexp.MustCompile(`^(\s+\S|at \S|Caused by:|\.\.\. \d+ more|goroutine \d+ \[|Traceback \(most recent call last\)|File "|[\w.]+(Error|Exception)(: |$)|[A-Za-z_][\w./*()-]*\(.*\)$)`)
+)
+
+// 时间字段的常见格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999",
+	"2006/01/02 15:04:05.999999",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// 常见的时间、级别、消息和堆栈字段名
+var (
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t", "datetime"}
+	levelKeys   = []string{"level", "lvl", "severity", "loglevel", "log.level"}
+	messageKeys = []string{"msg", "message", "event", "log"}
+	stackKeys   = []string{"stack", "stacktrace", "stack_trace", "exception"}
+)
+
+// isContinuation 判断一行是否为上一条日志的续行
+func isContinuation(line string) bool {
+	return continuationPattern.MatchString(line)
+}
+
+// parseLine 按指定格式解析一行日志，格式为 FormatAuto 时依次尝试各种格式。
+// 无法解析时返回 false，调用方按普通文本处理。
+func parseLine(line string, format string) (*Entry, bool) {
+	switch format {
+	case FormatJSON:
+		return parseJSON(line)
+	case FormatLogfmt:
+		return parseLogfmt(line)
+	case FormatNginx:
+		return parseNginx(line)
+	case FormatGoLog:
+		return parseGoLog(line)
+	case FormatPlain:
+		return parsePlain(line), true
+	}
+
+	for _, parse := range []func(string) (*Entry, bool){parseJSON, parseNginx, parseGoLog, parseLogfmt} {
+		if e, ok := parse(line); ok {
+			return e, true
+		}
+	}
+	return parsePlain(line), true
+}
+
+// parseJSON 解析 JSON lines
+func parseJSON(line string) (*Entry, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return nil, false
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return nil, false
+	}
+
+	fields := make(map[string]string, len(obj))
+	for k, v := range obj {
+		switch v := v.(type) {
+		case string:
+			fields[k] = v
+		case nil:
+		default:
+			data, _ := json.Marshal(v)
+			fields[k] = string(data)
+		}
+	}
+	e := fromFields(fields, FormatJSON)
+	// 数字形式的时间戳 (秒或毫秒)
+	if e.Time.IsZero() {
+		for _, k := range timeKeys {
+			if n, ok := obj[k].(float64); ok {
+				e.Time = unixTime(n)
+				delete(e.Fields, k)
+				break
+			}
+		}
+	}
+	return e, true
+}
+
+// parseLogfmt 解析 logfmt，至少需要两个键值对且以键值对开头
+func parseLogfmt(line string) (*Entry, bool) {
+	matches := logfmtPattern.FindAllStringSubmatchIndex(line, -1)
+	if len(matches) < 2 || matches[0][0] != 0 {
+		return nil, false
+	}
+	fields := make(map[string]string, len(matches))
+	for _, m := range matches {
+		key, value := line[m[2]:m[3]], line[m[4]:m[5]]
+		if strings.HasPrefix(value, `"`) {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+		}
+		fields[key] = value
+	}
+	return fromFields(fields, FormatLogfmt), true
+}
+
+// fromFields 从键值对中提取时间、级别、消息和堆栈，其余字段保留在 Fields 中
+func fromFields(fields map[string]string, format string) *Entry {
+	e := &Entry{Format: format, Level: LevelUnknown, Fields: fields}
+	if k, v, ok := takeField(fields, timeKeys); ok {
+		if t, ok := parseTime(v); ok {
+			e.Time = t
+		} else {
+			fields[k] = v
+		}
+	}
+	if _, v, ok := takeField(fields, levelKeys); ok {
+		e.Level = normalizeLevel(v)
+	}
+	if _, v, ok := takeField(fields, messageKeys); ok {
+		e.Message = v
+	}
+	if _, v, ok := takeField(fields, stackKeys); ok {
+		for _, frame := range strings.Split(strings.TrimSpace(v), "\n") {
+			if frame = strings.TrimRight(frame, "\r"); frame != "" {
+				e.Stack = append(e.Stack, frame)
+			}
+		}
+	}
+	if e.Message == "" {
+		e.Message = fields["error"]
+	}
+	if e.Level == LevelUnknown {
+		e.Level, e.Message = detectLevel(e.Message)
+	}
+	return e
+}
+
+// takeField 取出并删除第一个存在的字段
+func takeField(fields map[string]string, keys []string) (string, string, bool) {
+	for _, k := range keys {
+		if v, ok := fields[k]; ok {
+			delete(fields, k)
+			return k, v, true
+		}
+	}
+	return "", "", false
+}
+
+// parseNginx 解析 nginx combined / common log，5xx 视为 error，4xx 视为 warn
+func parseNginx(line string) (*Entry, bool) {
+	m := nginxPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	t, _ := time.Parse("02/Jan/2006:15:04:05 -0700", m[3])
+	status, _ := strconv.Atoi(m[5])
+
+	level := LevelInfo
+	switch {
+	case status >= 500:
+		level = LevelError
+	case status >= 400:
+		level = LevelWarn
+	}
+
+	// 消息只保留方法和路径 (去掉查询参数)，便于按模板聚类
+	request := m[4]
+	if parts := strings.Fields(request); len(parts) >= 2 {
+		path, _, _ := strings.Cut(parts[1], "?")
+		request = parts[0] + " " + path
+	}
+
+	fields := map[string]string{"remote_addr": m[1], "status": m[5], "bytes": m[6], "request": m[4]}
+	if m[2] != "-" {
+		fields["remote_user"] = m[2]
+	}
+	if m[7] != "" {
+		fields["referer"] = m[7]
+	}
+	if m[8] != "" {
+		fields["user_agent"] = m[8]
+	}
+	return &Entry{
+		Format:  FormatNginx,
+		Time:    t,
+		Level:   level,
+		Message: request + " " + m[5],
+		Fields:  fields,
+	}, true
+}
+
+// parseGoLog 解析 Go 标准库 log 的默认格式 (可选 Lmicroseconds 和 Lshortfile)
+func parseGoLog(line string) (*Entry, bool) {
+	m := goLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	t, _ := time.ParseInLocation("2006/01/02 15:04:05.999999", m[1], time.Local)
+	e := &Entry{Format: FormatGoLog, Time: t}
+	if m[2] != "" {
+		e.Fields = map[string]string{"caller": m[2]}
+	}
+	e.Level, e.Message = detectLevel(m[3])
+	return e, true
+}
+
+// parsePlain 解析普通文本日志: 可选的时间戳 + 可选的级别 + 消息，任何一行都能解析
+func parsePlain(line string) *Entry {
+	e := &Entry{Format: FormatPlain}
+	rest := line
+	if m := plainTimePattern.FindStringSubmatch(line); m != nil {
+		if t, ok := parseTime(strings.Replace(m[1], ",", ".", 1)); ok {
+			e.Time, rest = t, m[2]
+		}
+	}
+	e.Level, e.Message = detectLevel(rest)
+	return e
+}
+
+// detectLevel 识别消息开头的级别 (如 ERROR、[warn]、panic:)，返回级别和去掉级别后的消息
+func detectLevel(msg string) (string, string) {
+	if m := levelPrefixPattern.FindStringSubmatch(msg); m != nil {
+		return normalizeLevel(m[1]), msg[len(m[0]):]
+	}
+	return LevelUnknown, msg
+}
+
+// normalizeLevel 把各种写法的级别归一化
+func normalizeLevel(level string) string {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "trace", "debug", "dbg", "verbose":
+		return LevelDebug
+	case "info", "information", "informational", "notice":
+		return LevelInfo
+	case "warn", "warning":
+		return LevelWarn
+	case "error", "err", "severe":
+		return LevelError
+	case "fatal", "critical", "crit", "panic", "emerg", "emergency", "alert", "dpanic":
+		return LevelFatal
+	}
+	// 数字级别: syslog (0-7) 和 pino / bunyan (10-60)
+	if n, err := strconv.Atoi(level); err == nil {
+		switch {
+		case n >= 60 || (n >= 0 && n <= 2):
+			return LevelFatal
+		case n >= 50 || n == 3:
+			return LevelError
+		case n >= 40 || n == 4:
+			return LevelWarn
+		case n >= 30 || n == 5 || n == 6:
+			return LevelInfo
+		default:
+			return LevelDebug
+		}
+	}
+	return LevelUnknown
+}
+
+// parseTime 按常见格式解析时间字符串
+func parseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return unixTime(n), true
+	}
+	return time.Time{}, false
+}
+
+// unixTime 把 Unix 时间戳转换为时间，大于 1e12 时视为毫秒
+func unixTime(n float64) time.Time {
+	if n > 1e12 {
+		return time.UnixMilli(int64(n))
+	}
+	sec := int64(n)
+	return time.Unix(sec, int64((n-float64(sec))*1e9))
+}
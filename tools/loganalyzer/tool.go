@@ -0,0 +1,394 @@
+package loganalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"Eini/tools/streamutil"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/loganalyzer/tool.go
+//  功能: 日志分析工具，同时实现 tool.InvokableTool 和 tool.StreamableTool。
+//  输入: 内联的日志内容 (content) 或根目录内的日志文件 (path)
+//  输出: JSON lines，每行一个对象，type 字段区分:
+//    - progress:     读取过程中定期输出的进度和各级别数量
+//    - levels:       各级别、各格式的日志数量
+//    - top_errors:   按消息模板聚类的错误
+//    - rates:        按时间分桶的日志总量、错误和警告数量
+//    - anomalies:    日志总量和错误数量的异常尖峰
+//    - stack_traces: 按签名分组的堆栈
+//
+// =============================================================================
+
+// 默认限制
+const (
+	DefaultMaxBytes      = 64 << 20 // 最多读取 64MB 日志
+	DefaultProgressLines = 10000    // 每读取 1 万行输出一次进度
+)
+
+// 支持的分析类型
+const (
+	AnalysisLevels      = "levels"
+	AnalysisTopErrors   = "top_errors"
+	AnalysisRates       = "rates"
+	AnalysisAnomalies   = "anomalies"
+	AnalysisStackTraces = "stack_traces"
+)
+
+var (
+	allFormats  = []string{FormatAuto, FormatJSON, FormatLogfmt, FormatNginx, FormatGoLog, FormatPlain}
+	allAnalyses = []string{AnalysisLevels, AnalysisTopErrors, AnalysisRates, AnalysisAnomalies, AnalysisStackTraces}
+)
+
+// Config 日志分析工具的配置
+type Config struct {
+	// Root 日志文件所在的根目录，path 参数限定在该目录内；为空时只能分析内联内容
+	Root string
+
+	MaxBytes      int64 // 最多读取的字节数 (content 和文件相同)，默认 DefaultMaxBytes
+	ProgressLines int   // 每读取多少行输出一次进度，默认 DefaultProgressLines
+	MaxClusters   int   // 错误模板和堆栈分组的数量上限，默认 1000
+}
+
+// Tool 日志分析工具
+type Tool struct {
+	config Config
+	root   *os.Root
+}
+
+// New 创建日志分析工具，配置了 Root 时根目录必须已经存在
+func New(config *Config) (*Tool, error) {
+	var cfg Config
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.ProgressLines <= 0 {
+		cfg.ProgressLines = DefaultProgressLines
+	}
+
+	t := &Tool{config: cfg}
+	if cfg.Root != "" {
+		root, err := os.OpenRoot(cfg.Root)
+		if err != nil {
+			return nil, fmt.Errorf("打开日志根目录失败: %w", err)
+		}
+		t.root = root
+	}
+	return t, nil
+}
+
+// Close 关闭根目录
+func (t *Tool) Close() error {
+	if t.root == nil {
+		return nil
+	}
+	return t.root.Close()
+}
+
+// Info 返回工具的元信息和参数定义
+func (t *Tool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	desc := "分析日志: 统计各级别数量，按消息模板聚类错误，按时间分桶统计速率并检测异常尖峰，按签名分组堆栈。" +
+		"支持 JSON lines、logfmt、nginx 访问日志、Go log 和普通文本日志，默认自动识别。结果以 JSON lines 流式返回"
+	params := map[string]*schema.ParameterInfo{
+		"content": {
+			Type: schema.String,
+			Desc: "要分析的日志内容，与 path 二选一",
+		},
+		"format": {
+			Type: schema.String,
+			Desc: "日志格式，默认 auto (逐行自动识别)",
+			Enum: allFormats,
+		},
+		"analyses": {
+			Type:     schema.Array,
+			Desc:     "要执行的分析，默认全部",
+			ElemInfo: &schema.ParameterInfo{Type: schema.String, Enum: allAnalyses},
+		},
+		"bucket": {
+			Type: schema.String,
+			Desc: "速率统计的时间桶大小，如 30s、1m、1h，默认按日志时间跨度自动选择",
+		},
+		"top_n": {
+			Type: schema.Integer,
+			Desc: "返回的错误模板和堆栈分组数量，默认 10",
+		},
+	}
+	if t.root != nil {
+		desc += "。可以直接传入日志内容，也可以指定日志目录中的文件"
+		params["path"] = &schema.ParameterInfo{
+			Type: schema.String,
+			Desc: "相对于日志目录的文件路径，如 app/error.log，与 content 二选一",
+		}
+	}
+
+	return &schema.ToolInfo{
+		Name:        "log_analyzer",
+		Desc:        desc,
+		ParamsOneOf: schema.NewParamsOneOfByParams(params),
+	}, nil
+}
+
+// request 工具参数
+type request struct {
+	Content  string   `json:"content"`
+	Path     string   `json:"path"`
+	Format   string   `json:"format"`
+	Analyses []string `json:"analyses"`
+	Bucket   string   `json:"bucket"`
+	TopN     int      `json:"top_n"`
+}
+
+// 输出的各类 JSON 对象
+type (
+	progressSection struct {
+		Type    string         `json:"type"`
+		Lines   int            `json:"lines"`
+		Entries int            `json:"entries"`
+		Levels  map[string]int `json:"levels"`
+	}
+	levelsSection struct {
+		Type      string         `json:"type"`
+		Lines     int            `json:"lines"`
+		Entries   int            `json:"entries"`
+		Levels    map[string]int `json:"levels"`
+		Formats   map[string]int `json:"formats"`
+		Truncated bool           `json:"truncated,omitempty"`
+	}
+	topErrorsSection struct {
+		Type     string     `json:"type"`
+		Errors   int        `json:"errors"`
+		Clusters []*Cluster `json:"clusters"`
+	}
+	ratesSection struct {
+		Type    string   `json:"type"`
+		Bucket  string   `json:"bucket,omitempty"`
+		Buckets []Bucket `json:"buckets"`
+		Note    string   `json:"note,omitempty"`
+	}
+	anomaliesSection struct {
+		Type   string  `json:"type"`
+		Bucket string  `json:"bucket,omitempty"`
+		Spikes []Spike `json:"spikes"`
+		Note   string  `json:"note,omitempty"`
+	}
+	stackTracesSection struct {
+		Type   string        `json:"type"`
+		Groups []*StackGroup `json:"groups"`
+	}
+)
+
+// StreamableRun 流式分析日志。参数错误和文件打开失败直接返回错误，
+// 读取过程中的错误在已输出的结果之后通过流返回。
+func (t *Tool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	var req request
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return nil, fmt.Errorf("参数解析失败: %w", err)
+	}
+	analyses, options, err := t.validate(&req)
+	if err != nil {
+		return nil, err
+	}
+	src, err := t.open(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[LogAnalyzer] 分析 %s，格式: %s，分析: %s", src.name, req.Format, strings.Join(analyses, ", "))
+
+	return streamutil.Produce(ctx, 1, func(ctx context.Context, emit func(string) error) error {
+		defer src.Close()
+
+		send := func(v any) error {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("结果序列化失败: %w", err)
+			}
+			return emit(string(data) + "\n")
+		}
+
+		a := NewAnalyzer(options)
+		nextProgress := t.config.ProgressLines
+		lines, err := Scan(src, req.Format, func(e *Entry) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			a.Add(e)
+			if e.Line < nextProgress {
+				return nil
+			}
+			nextProgress = e.Line + t.config.ProgressLines
+			return send(progressSection{Type: "progress", Lines: e.Line, Entries: a.Entries(), Levels: a.Levels()})
+		})
+		if err != nil {
+			return err
+		}
+
+		bucket, buckets := a.Rates()
+		for _, analysis := range analyses {
+			var section any
+			switch analysis {
+			case AnalysisLevels:
+				section = levelsSection{
+					Type: analysis, Lines: lines, Entries: a.Entries(),
+					Levels: a.Levels(), Formats: a.Formats(), Truncated: src.truncated(),
+				}
+			case AnalysisTopErrors:
+				section = topErrorsSection{
+					Type: analysis, Errors: a.Levels()[LevelError] + a.Levels()[LevelFatal],
+					Clusters: a.TopErrors(),
+				}
+			case AnalysisRates:
+				s := ratesSection{Type: analysis, Buckets: buckets}
+				if len(buckets) == 0 {
+					s.Buckets, s.Note = []Bucket{}, "日志中没有可识别的时间"
+				} else {
+					s.Bucket = bucket.String()
+				}
+				section = s
+			case AnalysisAnomalies:
+				s := anomaliesSection{Type: analysis, Spikes: Spikes(buckets)}
+				if len(buckets) < spikeMinBucket {
+					s.Note = fmt.Sprintf("时间桶少于 %d 个，无法检测尖峰", spikeMinBucket)
+				} else {
+					s.Bucket = bucket.String()
+				}
+				if s.Spikes == nil {
+					s.Spikes = []Spike{}
+				}
+				section = s
+			case AnalysisStackTraces:
+				section = stackTracesSection{Type: analysis, Groups: a.StackTraces()}
+			}
+			if err := send(section); err != nil {
+				return err
+			}
+		}
+		return nil
+	}), nil
+}
+
+// InvokableRun 分析日志，返回完整的 JSON lines 结果
+func (t *Tool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	sr, err := t.StreamableRun(ctx, argumentsInJSON, opts...)
+	if err != nil {
+		return "", err
+	}
+	return streamutil.Join(sr)
+}
+
+// validate 检查参数并填充默认值，返回要执行的分析 (按固定顺序) 和统计选项
+func (t *Tool) validate(req *request) ([]string, Options, error) {
+	var options Options
+	switch {
+	case req.Content == "" && req.Path == "":
+		return nil, options, errors.New("必须提供 content 或 path")
+	case req.Content != "" && req.Path != "":
+		return nil, options, errors.New("content 和 path 只能提供一个")
+	case req.Path != "" && t.root == nil:
+		return nil, options, errors.New("未配置日志目录，只能通过 content 传入日志内容")
+	case int64(len(req.Content)) > t.config.MaxBytes:
+		return nil, options, fmt.Errorf("日志内容超过 %d 字节的限制", t.config.MaxBytes)
+	case req.TopN < 0:
+		return nil, options, fmt.Errorf("top_n 不能为负数: %d", req.TopN)
+	}
+
+	if req.Format == "" {
+		req.Format = FormatAuto
+	}
+	if !slices.Contains(allFormats, req.Format) {
+		return nil, options, fmt.Errorf("不支持的日志格式: %s", req.Format)
+	}
+
+	for _, analysis := range req.Analyses {
+		if !slices.Contains(allAnalyses, analysis) {
+			return nil, options, fmt.Errorf("不支持的分析类型: %s", analysis)
+		}
+	}
+	analyses := allAnalyses
+	if len(req.Analyses) > 0 {
+		analyses = slices.DeleteFunc(slices.Clone(allAnalyses), func(a string) bool {
+			return !slices.Contains(req.Analyses, a)
+		})
+	}
+
+	if req.Bucket != "" {
+		bucket, err := time.ParseDuration(req.Bucket)
+		if err != nil || bucket < time.Second {
+			return nil, options, fmt.Errorf("无效的时间桶大小: %s (至少 1s)", req.Bucket)
+		}
+		options.Bucket = bucket
+	}
+	options.TopN = req.TopN
+	options.MaxClusters = t.config.MaxClusters
+	return analyses, options, nil
+}
+
+// source 日志来源，读取量限制在 MaxBytes 以内
+type source struct {
+	io.Reader
+	name   string
+	file   *os.File // 内联内容时为 nil
+	remain *io.LimitedReader
+}
+
+// open 打开日志来源，文件路径通过 os.Root 访问，不能超出根目录
+func (t *Tool) open(req *request) (*source, error) {
+	if req.Content != "" {
+		return &source{Reader: strings.NewReader(req.Content), name: "内联日志"}, nil
+	}
+
+	p := filepath.Clean(filepath.FromSlash(strings.TrimSpace(req.Path)))
+	if filepath.IsAbs(p) || !filepath.IsLocal(p) {
+		return nil, fmt.Errorf("路径 %s 必须是日志目录内的相对路径", req.Path)
+	}
+	f, err := t.root.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("读取日志文件信息失败: %w", err)
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("%s 是目录", req.Path)
+	}
+
+	remain := &io.LimitedReader{R: f, N: t.config.MaxBytes}
+	return &source{Reader: remain, name: filepath.ToSlash(p), file: f, remain: remain}, nil
+}
+
+// truncated 文件是否超过了 MaxBytes，只读取了前面的部分
+func (s *source) truncated() bool {
+	if s.file == nil || s.remain.N > 0 {
+		return false
+	}
+	n, _ := s.file.Read(make([]byte, 1))
+	return n > 0
+}
+
+// Close 关闭日志文件
+func (s *source) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"Eini/tools/schemacheck"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/middleware/builtin.go
+//  功能: 内置中间件: 超时、重试、参数校验、日志。
+//  说明: 缓存、限流和指标统计分别见 cache.go、ratelimit.go 和 metrics.go。
+//
+// =============================================================================
+
+// ErrTimeout 工具执行超时
+var ErrTimeout = errors.New("工具执行超时")
+
+// WithTimeout 限制单次调用的执行时间。
+// 流式调用的超时覆盖整个流: 超时后下游收到 ErrTimeout，原工具的 ctx 被取消。
+func WithTimeout(d time.Duration) Middleware {
+	return Middleware{
+		Name: "timeout",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				ctx, cancel := context.WithTimeout(ctx, d)
+				defer cancel()
+
+				// 在独立的 goroutine 中执行，不响应 ctx 的工具也能按时返回
+				type result struct {
+					output string
+					err    error
+				}
+				done := make(chan result, 1)
+				go func() {
+					output, err := next(ctx, argumentsInJSON, opts...)
+					done <- result{output, err}
+				}()
+
+				select {
+				case r := <-done:
+					return r.output, r.err
+				case <-ctx.Done():
+					return "", timeoutError(ctx, info.Name, d)
+				}
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				ctx, cancel := context.WithTimeout(ctx, d)
+				sr, err := next(ctx, argumentsInJSON, opts...)
+				if err != nil {
+					cancel()
+					if ctx.Err() != nil {
+						return nil, timeoutError(ctx, info.Name, d)
+					}
+					return nil, err
+				}
+				return relay(sr, ctx.Done(), func() error { return timeoutError(ctx, info.Name, d) },
+					func(string) {}, func(error) { cancel() }), nil
+			}
+		},
+	}
+}
+
+// timeoutError 区分超时和调用方取消
+func timeoutError(ctx context.Context, name string, d time.Duration) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s 超过 %s", ErrTimeout, name, d)
+	}
+	return ctx.Err()
+}
+
+// RetryConfig 重试配置
+type RetryConfig struct {
+	MaxAttempts int                  // 最多尝试次数 (含第一次)，默认 3
+	Backoff     time.Duration        // 第一次重试前的等待时间，之后每次翻倍，默认 200ms
+	MaxBackoff  time.Duration        // 最长等待时间，默认 5s
+	Retryable   func(err error) bool // 判断错误是否可以重试，默认除调用方取消外都重试
+}
+
+// WithRetry 调用失败时按指数退避 (带随机抖动) 重试。
+// 流式调用只重试建立流的过程，已经开始输出的流出错时不会重试。
+// 注意: 只应用于幂等的工具，或者配合 Retryable 只重试确定没有产生副作用的错误。
+func WithRetry(config RetryConfig) Middleware {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 200 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Second
+	}
+	if config.Retryable == nil {
+		config.Retryable = func(err error) bool { return !errors.Is(err, context.Canceled) }
+	}
+
+	return Middleware{
+		Name: "retry",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				var output string
+				err := retry(ctx, info.Name, config, func() error {
+					var err error
+					output, err = next(ctx, argumentsInJSON, opts...)
+					return err
+				})
+				return output, err
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				var sr *schema.StreamReader[string]
+				err := retry(ctx, info.Name, config, func() error {
+					var err error
+					sr, err = next(ctx, argumentsInJSON, opts...)
+					return err
+				})
+				return sr, err
+			}
+		},
+	}
+}
+
+// retry 执行 fn，失败时按配置重试
+func retry(ctx context.Context, name string, config RetryConfig, fn func() error) error {
+	backoff := config.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= config.MaxAttempts || !config.Retryable(err) || ctx.Err() != nil {
+			if err != nil && attempt > 1 {
+				return fmt.Errorf("重试 %d 次后仍然失败: %w", attempt-1, err)
+			}
+			return err
+		}
+
+		// 在 [backoff/2, backoff) 之间随机等待，避免多个调用同时重试
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Printf("[ToolMiddleware] %s 第 %d 次调用失败，%s 后重试: %v", name, attempt, wait.Round(time.Millisecond), err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, config.MaxBackoff)
+	}
+}
+
+// WithValidation 执行前按工具的参数定义校验参数 (见 schemacheck.Check)。
+// 校验失败时不调用原工具，把校验结果作为工具结果返回给模型。
+func WithValidation() Middleware {
+	return Middleware{
+		Name: "validation",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				if result, ok := schemacheck.Check(info, argumentsInJSON); !ok {
+					return result, nil
+				}
+				return next(ctx, argumentsInJSON, opts...)
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				if result, ok := schemacheck.Check(info, argumentsInJSON); !ok {
+					return schema.StreamReaderFromArray([]string{result}), nil
+				}
+				return next(ctx, argumentsInJSON, opts...)
+			}
+		},
+	}
+}
+
+// WithLogging 记录每次调用的参数、耗时和结果，替代各个工具内部分散的日志
+func WithLogging() Middleware {
+	return Middleware{
+		Name: "logging",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				start := time.Now()
+				log.Printf("[Tool] %s 开始执行，参数: %s", info.Name, truncate(argumentsInJSON, 200))
+				output, err := next(ctx, argumentsInJSON, opts...)
+				if err != nil {
+					log.Printf("[Tool] %s 执行失败 (耗时 %s): %v", info.Name, time.Since(start).Round(time.Millisecond), err)
+					return output, err
+				}
+				log.Printf("[Tool] %s 执行完成 (耗时 %s)，结果 %d 字节", info.Name, time.Since(start).Round(time.Millisecond), len(output))
+				return output, nil
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				start := time.Now()
+				log.Printf("[Tool] %s 开始流式执行，参数: %s", info.Name, truncate(argumentsInJSON, 200))
+				sr, err := next(ctx, argumentsInJSON, opts...)
+				if err != nil {
+					log.Printf("[Tool] %s 执行失败 (耗时 %s): %v", info.Name, time.Since(start).Round(time.Millisecond), err)
+					return nil, err
+				}
+				chunks := 0
+				return relay(sr, nil, nil, func(string) { chunks++ }, func(err error) {
+					elapsed := time.Since(start).Round(time.Millisecond)
+					if err != nil {
+						log.Printf("[Tool] %s 流式输出中断 (耗时 %s，%d 个数据块): %v", info.Name, elapsed, chunks, err)
+						return
+					}
+					log.Printf("[Tool] %s 流式执行完成 (耗时 %s，%d 个数据块)", info.Name, elapsed, chunks)
+				}), nil
+			}
+		},
+	}
+}
+
+// truncate 截断过长的文本，用于日志输出
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/middleware/cache.go
+//  功能: 工具结果缓存 (LRU + TTL)。
+//  说明: 缓存键由工具名称和规范化后的参数组成，参数中字段的顺序和空白不影响命中。
+//        只缓存成功的结果；流式调用在流完整读取后缓存拼接的结果，命中时以单个数据块返回。
+//        带 tool.Option 的调用无法判断选项是否影响结果，不使用缓存。
+//
+// =============================================================================
+
+// CacheConfig 结果缓存配置
+type CacheConfig struct {
+	TTL        time.Duration // 缓存有效期，默认 5 分钟
+	MaxEntries int           // 每个工具最多缓存的结果数，默认 256
+}
+
+// WithResultCache 缓存工具的执行结果，只应用于结果只取决于参数的工具 (如查询、计算)
+func WithResultCache(config CacheConfig) Middleware {
+	if config.TTL <= 0 {
+		config.TTL = 5 * time.Minute
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 256
+	}
+
+	return Middleware{
+		Name: "cache",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			cache := newResultCache(config)
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				if len(opts) > 0 {
+					return next(ctx, argumentsInJSON, opts...)
+				}
+				key := cacheKey(argumentsInJSON)
+				if output, ok := cache.get(key); ok {
+					return output, nil
+				}
+				output, err := next(ctx, argumentsInJSON, opts...)
+				if err == nil {
+					cache.put(key, output)
+				}
+				return output, err
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			cache := newResultCache(config)
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				if len(opts) > 0 {
+					return next(ctx, argumentsInJSON, opts...)
+				}
+				key := cacheKey(argumentsInJSON)
+				if output, ok := cache.get(key); ok {
+					return schema.StreamReaderFromArray([]string{output}), nil
+				}
+				sr, err := next(ctx, argumentsInJSON, opts...)
+				if err != nil {
+					return nil, err
+				}
+
+				var sb strings.Builder
+				return relay(sr, nil, nil, func(chunk string) { sb.WriteString(chunk) }, func(err error) {
+					if err == nil {
+						cache.put(key, sb.String())
+					}
+				}), nil
+			}
+		},
+	}
+}
+
+// cacheKey 规范化参数: 合法的 JSON 重新序列化 (对象字段按名称排序)，否则直接使用原文
+func cacheKey(argumentsInJSON string) string {
+	var v any
+	if err := json.Unmarshal([]byte(argumentsInJSON), &v); err != nil {
+		return argumentsInJSON
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return argumentsInJSON
+	}
+	return string(data)
+}
+
+// resultCache 带过期时间的 LRU 缓存
+type resultCache struct {
+	config CacheConfig
+
+	mu      sync.Mutex
+	order   *list.List               // 最近使用的在前
+	entries map[string]*list.Element // 键到链表元素的映射
+}
+
+type cacheEntry struct {
+	key     string
+	output  string
+	expires time.Time
+}
+
+func newResultCache(config CacheConfig) *resultCache {
+	return &resultCache{config: config, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *resultCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return entry.output, true
+}
+
+func (c *resultCache) put(key, output string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(c.config.TTL)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.output, entry.expires = output, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, output: output, expires: expires})
+	for c.order.Len() > c.config.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/middleware/metrics.go
+//  功能: 工具调用的指标统计: 调用次数、失败次数、超时次数、耗时。
+//  说明: 一个 Metrics 可以在多个工具之间共享，按工具名称分别统计，
+//        通过 Snapshot 读取 (例如通过 HTTP 接口对外暴露)。
+//        流式调用的耗时从建立流开始计算到流结束。
+//
+// =============================================================================
+
+// ToolStats 单个工具的统计数据
+type ToolStats struct {
+	Name         string        `json:"name"`
+	Calls        int64         `json:"calls"`         // 调用次数
+	Errors       int64         `json:"errors"`        // 失败次数 (含超时)
+	Timeouts     int64         `json:"timeouts"`      // 超时次数
+	InFlight     int64         `json:"in_flight"`     // 正在执行的调用数
+	TotalLatency time.Duration `json:"total_latency"` // 总耗时
+	MaxLatency   time.Duration `json:"max_latency"`   // 最长耗时
+	LastError    string        `json:"last_error,omitempty"`
+	LastCalledAt time.Time     `json:"last_called_at"`
+}
+
+// AvgLatency 平均耗时
+func (s ToolStats) AvgLatency() time.Duration {
+	if s.Calls == s.InFlight {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls-s.InFlight)
+}
+
+// Metrics 工具调用指标
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*ToolStats
+}
+
+// NewMetrics 创建指标统计
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*ToolStats)}
+}
+
+// Snapshot 返回所有工具的统计数据，按工具名称排序
+func (m *Metrics) Snapshot() []ToolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ret := make([]ToolStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		ret = append(ret, *s)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// start 记录一次调用开始，返回调用结束时的回调
+func (m *Metrics) start(name string) func(err error) {
+	begin := time.Now()
+
+	m.mu.Lock()
+	s, ok := m.stats[name]
+	if !ok {
+		s = &ToolStats{Name: name}
+		m.stats[name] = s
+	}
+	s.Calls++
+	s.InFlight++
+	s.LastCalledAt = begin
+	m.mu.Unlock()
+
+	return func(err error) {
+		latency := time.Since(begin)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		s.InFlight--
+		s.TotalLatency += latency
+		s.MaxLatency = max(s.MaxLatency, latency)
+		if err != nil {
+			s.Errors++
+			s.LastError = err.Error()
+			if errors.Is(err, ErrTimeout) {
+				s.Timeouts++
+			}
+		}
+	}
+}
+
+// WithMetrics 把调用指标记录到 m 中。
+// 放在 WithTimeout / WithRetry 之前 (外层) 时统计的是包含重试的整体耗时，放在之后时统计每次尝试。
+func WithMetrics(m *Metrics) Middleware {
+	return Middleware{
+		Name: "metrics",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				done := m.start(info.Name)
+				output, err := next(ctx, argumentsInJSON, opts...)
+				done(err)
+				return output, err
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				done := m.start(info.Name)
+				sr, err := next(ctx, argumentsInJSON, opts...)
+				if err != nil {
+					done(err)
+					return nil, err
+				}
+				return relay(sr, nil, nil, func(string) {}, func(err error) {
+					if errors.Is(err, errClosedByReader) {
+						err = nil // 读取方主动关闭不算失败
+					}
+					done(err)
+				}), nil
+			}
+		},
+	}
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/middleware/middleware.go
+//  功能: 可组合的工具中间件，为 InvokableTool 和 StreamableTool 加上通用能力。
+//  用法: middleware.Wrap(ctx, t, WithTimeout(...), WithRetry(...), WithResultCache(...),
+//        WithRateLimit(...), WithMetrics(...))
+//  说明: 包装后的工具 Info 保持不变，InferTool 生成的工具和手写工具都可以包装；
+//        原工具同时实现两种接口时，包装后仍然同时实现两者。
+//        中间件按传入顺序从外到内执行，第一个中间件最先拿到调用、最后拿到结果。
+//
+// =============================================================================
+
+// InvokeFunc 同步调用
+type InvokeFunc func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error)
+
+// StreamFunc 流式调用
+type StreamFunc func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error)
+
+// Middleware 工具中间件。Invoke 和 Stream 分别包装同步调用和流式调用，为 nil 时不处理对应的调用。
+// 两个函数在每次 Wrap 时各调用一次，可以在其中创建每个工具独立的状态 (如限流器)。
+type Middleware struct {
+	Name   string
+	Invoke func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc
+	Stream func(info *schema.ToolInfo, next StreamFunc) StreamFunc
+}
+
+// Wrap 按顺序为工具加上中间件
+func Wrap(ctx context.Context, t tool.BaseTool, mws ...Middleware) (tool.BaseTool, error) {
+	info, err := t.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取工具信息失败: %w", err)
+	}
+
+	invokable, isInvokable := t.(tool.InvokableTool)
+	streamable, isStreamable := t.(tool.StreamableTool)
+	if !isInvokable && !isStreamable {
+		return nil, fmt.Errorf("工具 %s 既不是 InvokableTool 也不是 StreamableTool", info.Name)
+	}
+
+	w := &wrappedTool{BaseTool: t}
+	// 从最内层开始包装，保证第一个中间件在最外层
+	if isInvokable {
+		w.invoke = invokable.InvokableRun
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i].Invoke != nil {
+				w.invoke = mws[i].Invoke(info, w.invoke)
+			}
+		}
+	}
+	if isStreamable {
+		w.stream = streamable.StreamableRun
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i].Stream != nil {
+				w.stream = mws[i].Stream(info, w.stream)
+			}
+		}
+	}
+
+	switch {
+	case isInvokable && isStreamable:
+		return &wrappedBothTool{w}, nil
+	case isInvokable:
+		return &wrappedInvokableTool{w}, nil
+	default:
+		return &wrappedStreamableTool{w}, nil
+	}
+}
+
+// WrapAll 为一组工具加上相同的中间件。有状态的中间件 (限流、缓存) 对每个工具分别生效。
+func WrapAll(ctx context.Context, tools []tool.BaseTool, mws ...Middleware) ([]tool.BaseTool, error) {
+	wrapped := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		w, err := Wrap(ctx, t, mws...)
+		if err != nil {
+			return nil, err
+		}
+		wrapped = append(wrapped, w)
+	}
+	return wrapped, nil
+}
+
+// wrappedTool 保存原工具和包装后的调用链，Info 直接使用原工具的实现
+type wrappedTool struct {
+	tool.BaseTool
+	invoke InvokeFunc
+	stream StreamFunc
+}
+
+type wrappedInvokableTool struct{ *wrappedTool }
+
+// InvokableRun 经过中间件调用原工具
+func (w *wrappedInvokableTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	return w.invoke(ctx, argumentsInJSON, opts...)
+}
+
+type wrappedStreamableTool struct{ *wrappedTool }
+
+// StreamableRun 经过中间件调用原工具
+func (w *wrappedStreamableTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	return w.stream(ctx, argumentsInJSON, opts...)
+}
+
+type wrappedBothTool struct{ *wrappedTool }
+
+// InvokableRun 经过中间件调用原工具
+func (w *wrappedBothTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	return w.invoke(ctx, argumentsInJSON, opts...)
+}
+
+// StreamableRun 经过中间件调用原工具
+func (w *wrappedBothTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	return w.stream(ctx, argumentsInJSON, opts...)
+}
+
+// ================================
+// 流式辅助函数
+// ================================
+
+// relay 把 src 中的数据转发到新的流中，每个数据块调用 onChunk，结束时调用 onEnd
+// (正常结束时 err 为 nil，下游提前关闭时为 errClosedByReader)。
+// done 关闭时停止转发并向下游发送 doneErr()。
+func relay(src *schema.StreamReader[string], done <-chan struct{}, doneErr func() error,
+	onChunk func(string), onEnd func(error)) *schema.StreamReader[string] {
+
+	out, w := schema.Pipe[string](1)
+	go func() {
+		defer w.Close()
+		defer src.Close()
+
+		type item struct {
+			chunk string
+			err   error
+		}
+		items := make(chan item)
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			defer close(items)
+			for {
+				chunk, err := src.Recv()
+				select {
+				case items <- item{chunk, err}:
+				case <-stop:
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				err := doneErr()
+				w.Send("", err)
+				onEnd(err)
+				return
+			case it, ok := <-items:
+				if !ok {
+					return
+				}
+				if errors.Is(it.err, io.EOF) {
+					onEnd(nil)
+					return
+				}
+				if it.err != nil {
+					w.Send("", it.err)
+					onEnd(it.err)
+					return
+				}
+				onChunk(it.chunk)
+				if closed := w.Send(it.chunk, nil); closed {
+					onEnd(errClosedByReader)
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// errClosedByReader 下游在流结束前关闭了流
+var errClosedByReader = errors.New("流被读取方提前关闭")
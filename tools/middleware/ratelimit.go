@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/middleware/ratelimit.go
+//  功能: 基于令牌桶的调用限流，保护有调用频率限制的后端服务。
+//  说明: 每个工具有独立的令牌桶。令牌不足时等待，直到拿到令牌、ctx 结束
+//        或需要等待的时间超过 MaxWait。
+//
+// =============================================================================
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Rate    float64       // 每秒补充的令牌数 (即平均每秒允许的调用次数)，必须大于 0
+	Burst   int           // 令牌桶容量 (允许的突发调用次数)，默认 1
+	MaxWait time.Duration // 最长等待时间，为 0 时一直等待到 ctx 结束
+}
+
+// WithRateLimit 限制工具的调用频率。流式调用只在建立流时消耗令牌。
+func WithRateLimit(config RateLimitConfig) Middleware {
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+
+	return Middleware{
+		Name: "ratelimit",
+		Invoke: func(info *schema.ToolInfo, next InvokeFunc) InvokeFunc {
+			limiter := newTokenBucket(config)
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+				if err := limiter.wait(ctx, info.Name); err != nil {
+					return "", err
+				}
+				return next(ctx, argumentsInJSON, opts...)
+			}
+		},
+		Stream: func(info *schema.ToolInfo, next StreamFunc) StreamFunc {
+			limiter := newTokenBucket(config)
+			return func(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+				if err := limiter.wait(ctx, info.Name); err != nil {
+					return nil, err
+				}
+				return next(ctx, argumentsInJSON, opts...)
+			}
+		},
+	}
+}
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	config RateLimitConfig
+
+	mu     sync.Mutex
+	tokens float64   // 当前令牌数，可以为负数 (表示已经被等待中的调用预订)
+	last   time.Time // 上次补充令牌的时间
+}
+
+func newTokenBucket(config RateLimitConfig) *tokenBucket {
+	return &tokenBucket{config: config, tokens: float64(config.Burst), last: time.Now()}
+}
+
+// wait 取得一个令牌，令牌不足时等待
+func (b *tokenBucket) wait(ctx context.Context, name string) error {
+	if b.config.Rate <= 0 {
+		return fmt.Errorf("工具 %s 的限流配置无效: Rate 必须大于 0", name)
+	}
+
+	delay, ok := b.reserve()
+	if !ok {
+		return fmt.Errorf("工具 %s 调用过于频繁，需要等待超过 %s", name, b.config.MaxWait)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve 预订一个令牌，返回需要等待的时间。等待时间超过 MaxWait 时不预订，返回 false。
+func (b *tokenBucket) reserve() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(float64(b.config.Burst), b.tokens+now.Sub(b.last).Seconds()*b.config.Rate)
+	b.last = now
+
+	var delay time.Duration
+	if b.tokens < 1 {
+		delay = time.Duration((1 - b.tokens) / b.config.Rate * float64(time.Second))
+	}
+	if b.config.MaxWait > 0 && delay > b.config.MaxWait {
+		return 0, false
+	}
+	b.tokens--
+	return delay, true
+}
+
+// cancel 归还取消等待的调用预订的令牌
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(float64(b.config.Burst), b.tokens+1)
+}
@@ -0,0 +1,456 @@
+package openapitool
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+//
+//  文件: tools/openapitool/fake.go
+//  功能: 基于 httptest 的本地商品服务，同时提供 OpenAPI 文档 (/openapi.yaml) 和文档中描述的接口，
+//        用于离线演示和测试: 读取文档 -> 生成工具 -> 调用工具，整个流程不依赖外部服务。
+//  接口: 商品列表 (查询参数、数组参数、分页)、商品详情 (路径参数)、创建订单 (JSON 请求体、请求头参数)、
+//        健康检查 (不需要认证) 和只接受 multipart 的导入接口 (生成工具时会被跳过)。
+//  认证: 除健康检查外需要 X-API-Key 请求头；创建订单还需要 Bearer Token。
+//
+// =============================================================================
+
+// fakeSpec 假服务的 OpenAPI 文档，servers 为相对地址，读取时按文档地址解析
+const fakeSpec = `openapi: 3.0.3
+info:
+  title: 商品服务
+  version: 1.0.0
+servers:
+  - url: /api
+security:
+  - apiKey: []
+paths:
+  /health:
+    get:
+      operationId: getHealth
+      summary: 检查服务状态
+      security: []
+      responses:
+        "200":
+          description: 服务正常
+  /products:
+    get:
+      operationId: listProducts
+      summary: 查询商品列表
+      description: 按类目、关键词、价格和标签筛选商品，结果按价格从低到高排序。
+      parameters:
+        - name: category
+          in: query
+          description: 商品类目
+          schema:
+            type: string
+            enum: [electronics, books, home, food]
+        - name: keyword
+          in: query
+          description: 商品名称中包含的关键词
+          schema:
+            type: string
+        - name: max_price
+          in: query
+          description: 最高价格 (元)
+          schema:
+            type: number
+            minimum: 0
+        - name: tags
+          in: query
+          description: 商品标签，商品需要包含全部标签
+          schema:
+            type: array
+            items:
+              type: string
+        - $ref: "#/components/parameters/Limit"
+      responses:
+        "200":
+          description: 商品列表
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  total:
+                    type: integer
+                  items:
+                    type: array
+                    items:
+                      $ref: "#/components/schemas/Product"
+  /products/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        description: 商品 ID
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      operationId: getProduct
+      summary: 查询单个商品的详情和库存
+      responses:
+        "200":
+          description: 商品详情
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Product"
+        "404":
+          description: 商品不存在
+  /orders:
+    post:
+      operationId: createOrder
+      summary: 创建订单
+      description: 下单后扣减库存，库存不足时返回 409。
+      security:
+        - apiKey: []
+          bearerAuth: []
+      parameters:
+        - name: Idempotency-Key
+          in: header
+          description: 幂等键，相同的键只会创建一个订单
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Order"
+      responses:
+        "201":
+          description: 创建的订单
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Order"
+  /catalog/import:
+    post:
+      operationId: importCatalog
+      summary: 上传商品目录文件
+      requestBody:
+        required: true
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                file:
+                  type: string
+                  format: binary
+      responses:
+        "202":
+          description: 已接受
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    bearerAuth:
+      type: http
+      scheme: bearer
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      description: 最多返回的商品数
+      schema:
+        type: integer
+        minimum: 1
+        maximum: 200
+        default: 20
+  schemas:
+    Product:
+      type: object
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        category:
+          type: string
+        price:
+          type: number
+        stock:
+          type: integer
+        tags:
+          type: array
+          items:
+            type: string
+    OrderItem:
+      type: object
+      required: [product_id, quantity]
+      properties:
+        product_id:
+          type: integer
+          description: 商品 ID
+        quantity:
+          type: integer
+          minimum: 1
+          description: 购买数量
+    Order:
+      type: object
+      required: [items]
+      properties:
+        id:
+          type: string
+          readOnly: true
+        status:
+          type: string
+          readOnly: true
+        total:
+          type: number
+          readOnly: true
+        items:
+          type: array
+          minItems: 1
+          items:
+            $ref: "#/components/schemas/OrderItem"
+        note:
+          type: string
+          maxLength: 200
+          description: 订单备注
+`
+
+// FakeServer 本地商品服务
+type FakeServer struct {
+	*httptest.Server
+
+	apiKey string
+	token  string
+
+	mu       sync.Mutex
+	products []*fakeProduct
+	orders   map[string]map[string]any // 幂等键 -> 订单
+	nextID   int
+}
+
+type fakeProduct struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Price    float64  `json:"price"`
+	Stock    int      `json:"stock"`
+	Tags     []string `json:"tags"`
+}
+
+// NewFakeServer 启动商品服务。apiKey 和 token 分别是要求的 X-API-Key 和 Bearer Token。
+// 使用完毕后调用 Close 关闭。
+func NewFakeServer(apiKey, token string) *FakeServer {
+	f := &FakeServer{apiKey: apiKey, token: token, products: fakeProducts(), orders: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(fakeSpec))
+	})
+	mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /api/products", f.authorized(false, f.handleList))
+	mux.HandleFunc("GET /api/products/{id}", f.authorized(false, f.handleGet))
+	mux.HandleFunc("POST /api/orders", f.authorized(true, f.handleCreateOrder))
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+// SpecURL 返回 OpenAPI 文档的地址
+func (f *FakeServer) SpecURL() string {
+	return f.URL + "/openapi.yaml"
+}
+
+// Credentials 返回与文档中安全方案名称对应的凭证，可以直接用作 Config.Credentials
+func (f *FakeServer) Credentials() map[string]string {
+	return map[string]string{"apiKey": f.apiKey, "bearerAuth": f.token}
+}
+
+// authorized 检查 X-API-Key，needToken 为 true 时同时检查 Bearer Token
+func (f *FakeServer) authorized(needToken bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != f.apiKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid api key"})
+			return
+		}
+		if needToken && r.Header.Get("Authorization") != "Bearer "+f.token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "bearer token required"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (f *FakeServer) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 20
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > 200 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+	maxPrice := -1.0
+	if s := query.Get("max_price"); s != "" {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid max_price"})
+			return
+		}
+		maxPrice = n
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := []*fakeProduct{}
+	for _, p := range f.products {
+		if c := query.Get("category"); c != "" && p.Category != c {
+			continue
+		}
+		if k := query.Get("keyword"); k != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(k)) {
+			continue
+		}
+		if maxPrice >= 0 && p.Price > maxPrice {
+			continue
+		}
+		if !hasAllTags(p.Tags, query["tags"]) {
+			continue
+		}
+		items = append(items, p)
+	}
+	slices.SortStableFunc(items, func(a, b *fakeProduct) int {
+		switch {
+		case a.Price < b.Price:
+			return -1
+		case a.Price > b.Price:
+			return 1
+		}
+		return 0
+	})
+	total := len(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total": total, "items": items})
+}
+
+func (f *FakeServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err != nil || id < 1 || id > len(f.products) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "product not found: " + r.PathValue("id")})
+		return
+	}
+	writeJSON(w, http.StatusOK, f.products[id-1])
+}
+
+func (f *FakeServer) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Items []struct {
+			ProductID int `json:"product_id"`
+			Quantity  int `json:"quantity"`
+		} `json:"items"`
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Items) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := r.Header.Get("Idempotency-Key")
+	if order, ok := f.orders[key]; ok && key != "" {
+		writeJSON(w, http.StatusOK, order)
+		return
+	}
+
+	total := 0.0
+	for _, item := range req.Items {
+		if item.ProductID < 1 || item.ProductID > len(f.products) || item.Quantity < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid item: product %d x %d", item.ProductID, item.Quantity)})
+			return
+		}
+		p := f.products[item.ProductID-1]
+		if p.Stock < item.Quantity {
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "insufficient stock", "product_id": p.ID, "stock": p.Stock})
+			return
+		}
+		total += p.Price * float64(item.Quantity)
+	}
+	for _, item := range req.Items {
+		f.products[item.ProductID-1].Stock -= item.Quantity
+	}
+
+	f.nextID++
+	order := map[string]any{
+		"id":     fmt.Sprintf("ORD-%04d", f.nextID),
+		"status": "created",
+		"total":  float64(int(total*100+0.5)) / 100,
+		"items":  req.Items,
+		"note":   req.Note,
+	}
+	if key != "" {
+		f.orders[key] = order
+	}
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// hasAllTags 商品是否包含全部标签
+func hasAllTags(tags, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(tags, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// fakeProducts 生成 120 个商品，价格和库存由编号决定，每次启动都相同
+func fakeProducts() []*fakeProduct {
+	categories := []struct {
+		name  string
+		items []string
+		tags  []string
+	}{
+		{"electronics", []string{"无线耳机", "机械键盘", "显示器", "移动电源", "USB-C 扩展坞"}, []string{"数码", "办公"}},
+		{"books", []string{"Go 语言编程", "数据结构", "机器学习导论", "设计模式", "算法图解"}, []string{"技术", "畅销"}},
+		{"home", []string{"台灯", "保温杯", "收纳盒", "靠枕", "香薰机"}, []string{"家居", "办公"}},
+		{"food", []string{"咖啡豆", "乌龙茶", "坚果礼盒", "黑巧克力", "燕麦片"}, []string{"零食", "畅销"}},
+	}
+	var products []*fakeProduct
+	for i := 0; i < 120; i++ {
+		c := categories[i%len(categories)]
+		n := i / len(categories)
+		p := &fakeProduct{
+			ID:       i + 1,
+			Name:     fmt.Sprintf("%s %d 号", c.items[n%len(c.items)], n/len(c.items)+1),
+			Category: c.name,
+			Price:    float64((i*37)%500+19) + 0.9,
+			Stock:    (i * 7) % 40,
+			Tags:     []string{c.tags[n%2]},
+		}
+		if i%3 == 0 {
+			p.Tags = append(p.Tags, "新品")
+		}
+		products = append(products, p)
+	}
+	return products
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
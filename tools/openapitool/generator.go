@@ -0,0 +1,285 @@
+package openapitool
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// =============================================================================
+//
+//  文件: tools/openapitool/generator.go
+//  功能: 为 OpenAPI 3 文档中的每个操作生成一个 Eino 工具 (tool.InvokableTool)。
+//  说明: 工具名称取自 operationId (没有时由 方法 + 路径 生成)，描述取自 summary / description，
+//        参数定义由操作的参数和请求体生成 (见 schema.go)，调用时发送对应的 HTTP 请求 (见 tool.go)。
+//        认证按文档的 security 要求注入: 凭证按安全方案名称配置，不出现在工具参数中，模型无法读取或修改。
+//
+// =============================================================================
+
+const (
+	// DefaultTimeout 单次请求的默认超时时间
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxResponseBytes 返回给模型的响应体默认最大字节数
+	DefaultMaxResponseBytes = 16 << 10
+	// maxToolNameLen 工具名称的最大长度 (多数模型接口的限制)
+	maxToolNameLen = 64
+)
+
+// Config 工具生成配置
+type Config struct {
+	// Spec OpenAPI 3 文档 (必填)，通过 LoadSpec 或 ParseSpec 读取
+	Spec *openapi3.T
+	// BaseURL 服务地址，如 https://api.example.com/v1，默认使用文档 servers 中的第一个地址
+	BaseURL string
+	// Credentials 按安全方案名称 (components.securitySchemes 中的键) 配置的凭证:
+	//   http bearer、oauth2、openIdConnect 为令牌；http basic 为 "用户名:密码"；apiKey 为密钥
+	Credentials map[string]string
+	// Headers 每个请求都携带的请求头
+	Headers map[string]string
+	// Client 发送请求使用的 HTTP 客户端，默认为超时 Timeout 的 http.Client
+	Client *http.Client
+	// Timeout 单次请求的超时时间，默认 DefaultTimeout；配置了 Client 时忽略
+	Timeout time.Duration
+	// MaxResponseBytes 返回给模型的响应体最大字节数，默认 DefaultMaxResponseBytes
+	MaxResponseBytes int
+	// Operations 只为这些操作生成工具，每项为 operationId 或 "GET /path" 形式，为空时生成全部
+	Operations []string
+	// NamePrefix 工具名称前缀，如 "shop_"，同时注册多个服务的工具时避免冲突
+	NamePrefix string
+}
+
+// New 为文档中的操作生成工具，按路径和方法排序。
+// 无法生成的操作 (如请求体只支持表单格式) 会被跳过并记录日志；Operations 中指定的操作不存在时返回错误。
+func New(cfg *Config) ([]*Tool, error) {
+	if cfg == nil || cfg.Spec == nil {
+		return nil, fmt.Errorf("OpenAPI 文档不能为空")
+	}
+	baseURL, err := serverURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := cfg.Client
+	if client == nil {
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	maxBytes := cfg.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxResponseBytes
+	}
+
+	wanted := make(map[string]bool, len(cfg.Operations))
+	for _, op := range cfg.Operations {
+		wanted[op] = false
+	}
+
+	var tools []*Tool
+	names := map[string]bool{}
+	for _, path := range sortedKeys(cfg.Spec.Paths) {
+		item := cfg.Spec.Paths[path]
+		for _, method := range methodOrder {
+			op := item.GetOperation(method)
+			if op == nil {
+				continue
+			}
+			if len(wanted) > 0 {
+				key := method + " " + path
+				_, byID := wanted[op.OperationID]
+				_, byRoute := wanted[key]
+				if !byID && !byRoute {
+					continue
+				}
+				wanted[op.OperationID], wanted[key] = true, true
+			}
+
+			t, err := newTool(cfg, method, path, item, op)
+			if errors.Is(err, errUnsupportedBody) {
+				log.Printf("[OpenAPITool] 跳过操作 %s %s: %v", method, path, err)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("生成操作 %s %s 的工具失败: %w", method, path, err)
+			}
+			t.baseURL, t.client, t.maxBytes = baseURL, client, maxBytes
+			t.info.Name = uniqueName(names, t.info.Name)
+			tools = append(tools, t)
+		}
+	}
+
+	for _, op := range cfg.Operations {
+		if !wanted[op] {
+			return nil, fmt.Errorf("文档中没有操作 %s", op)
+		}
+	}
+	return tools, nil
+}
+
+// NewTools 与 New 相同，返回可以直接注册到 ToolsNode 的 tool.BaseTool 列表
+func NewTools(cfg *Config) ([]tool.BaseTool, error) {
+	tools, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	baseTools := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		baseTools = append(baseTools, t)
+	}
+	return baseTools, nil
+}
+
+// methodOrder 同一路径下操作的生成顺序
+var methodOrder = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodHead, http.MethodOptions, http.MethodTrace,
+}
+
+// newTool 生成单个操作的工具 (不含服务地址、客户端等公共配置)
+func newTool(cfg *Config, method, path string, item *openapi3.PathItem, op *openapi3.Operation) (*Tool, error) {
+	params, bindings, body, err := buildParams(item.Parameters, op)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := resolveAuth(cfg, op)
+	if err != nil {
+		return nil, err
+	}
+	if auth == nil && len(securityOf(cfg.Spec, op)) > 0 {
+		log.Printf("[OpenAPITool] 操作 %s %s 需要认证，但没有配置对应的凭证", method, path)
+	}
+
+	return &Tool{
+		info: &schema.ToolInfo{
+			Name:        toolName(cfg.NamePrefix, method, path, op.OperationID),
+			Desc:        toolDesc(method, path, op),
+			ParamsOneOf: schema.NewParamsOneOfByOpenAPIV3(params),
+		},
+		method:  method,
+		path:    path,
+		params:  bindings,
+		body:    body,
+		auth:    auth,
+		headers: cfg.Headers,
+	}, nil
+}
+
+// serverURL 确定服务地址: 配置的 BaseURL，或文档 servers 中的第一个地址 (变量取默认值)
+func serverURL(cfg *Config) (*url.URL, error) {
+	raw := cfg.BaseURL
+	if raw == "" {
+		if len(cfg.Spec.Servers) == 0 {
+			return nil, fmt.Errorf("文档中没有 servers，需要配置 BaseURL")
+		}
+		server := cfg.Spec.Servers[0]
+		raw = server.URL
+		for name, variable := range server.Variables {
+			raw = strings.ReplaceAll(raw, "{"+name+"}", variable.Default)
+		}
+	}
+	u, err := url.Parse(strings.TrimSuffix(raw, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("服务地址无效: %w", err)
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("服务地址 %s 不是绝对地址，需要配置 BaseURL 或通过 LoadSpec 按文档地址读取", raw)
+	}
+	return u, nil
+}
+
+// credential 一个需要注入的凭证
+type credential struct {
+	scheme *openapi3.SecurityScheme
+	value  string
+}
+
+// securityOf 操作的安全要求: 操作上声明的优先 (空列表表示不需要认证)，否则使用文档级别的
+func securityOf(doc *openapi3.T, op *openapi3.Operation) openapi3.SecurityRequirements {
+	if op.Security != nil {
+		return *op.Security
+	}
+	return doc.Security
+}
+
+// resolveAuth 在操作的安全要求中选择第一个凭证齐全的方案组合。
+// 没有安全要求、包含空要求 (可匿名访问) 或没有凭证齐全的组合时返回 nil。
+func resolveAuth(cfg *Config, op *openapi3.Operation) ([]*credential, error) {
+	for _, requirement := range securityOf(cfg.Spec, op) {
+		if len(requirement) == 0 {
+			return nil, nil
+		}
+		var creds []*credential
+		for _, name := range sortedKeys(requirement) {
+			value, ok := cfg.Credentials[name]
+			if !ok {
+				creds = nil
+				break
+			}
+			var ref *openapi3.SecuritySchemeRef
+			if cfg.Spec.Components != nil {
+				ref = cfg.Spec.Components.SecuritySchemes[name]
+			}
+			if ref == nil || ref.Value == nil {
+				return nil, fmt.Errorf("文档中没有安全方案 %s", name)
+			}
+			creds = append(creds, &credential{scheme: ref.Value, value: value})
+		}
+		if creds != nil {
+			return creds, nil
+		}
+	}
+	return nil, nil
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// toolName 由 operationId 生成工具名称，没有 operationId 时使用 方法_路径 (如 get_products_id)
+func toolName(prefix, method, path, operationID string) string {
+	name := operationID
+	if name == "" {
+		name = strings.ToLower(method) + path // 路径开头的 / 会被替换为 _
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+	name = prefix + name
+	if len(name) > maxToolNameLen {
+		name = name[:maxToolNameLen]
+	}
+	return name
+}
+
+// uniqueName 名称重复时追加 _2、_3 ...
+func uniqueName(names map[string]bool, name string) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		unique = name[:min(len(name), maxToolNameLen-len(suffix))] + suffix
+	}
+	names[unique] = true
+	return unique
+}
+
+// toolDesc 工具描述: summary 和 description，末尾附上对应的 HTTP 接口
+func toolDesc(method, path string, op *openapi3.Operation) string {
+	var parts []string
+	if op.Deprecated {
+		parts = append(parts, "(已废弃)")
+	}
+	if op.Summary != "" {
+		parts = append(parts, strings.TrimSpace(op.Summary))
+	}
+	if op.Description != "" && op.Description != op.Summary {
+		parts = append(parts, strings.TrimSpace(op.Description))
+	}
+	parts = append(parts, fmt.Sprintf("[%s %s]", method, path))
+	return strings.Join(parts, " ")
+}
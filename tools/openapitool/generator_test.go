@@ -0,0 +1,152 @@
+package openapitool
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// newFakeTools 启动假服务并为文档中的操作生成工具，返回 工具名称 -> 工具
+func newFakeTools(t *testing.T, credentials map[string]string) (*FakeServer, map[string]*Tool) {
+	t.Helper()
+	server := NewFakeServer("demo-key", "demo-token")
+	t.Cleanup(server.Close)
+
+	doc, err := LoadSpec(context.Background(), server.SpecURL(), server.Client())
+	if err != nil {
+		t.Fatalf("LoadSpec 返回错误: %v", err)
+	}
+	if credentials == nil {
+		credentials = server.Credentials()
+	}
+	tools, err := New(&Config{Spec: doc, Credentials: credentials, Client: server.Client()})
+	if err != nil {
+		t.Fatalf("New 返回错误: %v", err)
+	}
+	byName := make(map[string]*Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.info.Name] = tool
+	}
+	return server, byName
+}
+
+func TestNewDerivesToolInfo(t *testing.T) {
+	_, tools := newFakeTools(t, nil)
+
+	tests := []struct {
+		name         string
+		route        string
+		descContains string
+		required     []string
+		properties   []string
+		check        func(t *testing.T, params *openapi3.Schema)
+	}{
+		{
+			name:         "getHealth",
+			route:        "GET /health",
+			descContains: "检查服务状态 [GET /health]",
+		},
+		{
+			name:         "listProducts",
+			route:        "GET /products",
+			descContains: "按类目、关键词、价格和标签筛选商品",
+			properties:   []string{"category", "keyword", "limit", "max_price", "tags"},
+			check: func(t *testing.T, params *openapi3.Schema) {
+				// $ref 引用的参数展开为内联定义，保留范围约束
+				limit := params.Properties["limit"].Value
+				if limit.Max == nil || *limit.Max != 200 {
+					t.Errorf("limit 的最大值 = %v，期望 200", limit.Max)
+				}
+			},
+		},
+		{
+			name:         "getProduct",
+			route:        "GET /products/{id}",
+			descContains: "查询单个商品的详情和库存",
+			required:     []string{"id"},
+			properties:   []string{"id"},
+		},
+		{
+			name:         "createOrder",
+			route:        "POST /orders",
+			descContains: "库存不足时返回 409",
+			required:     []string{"body"},
+			properties:   []string{"Idempotency-Key", "body"},
+			check: func(t *testing.T, params *openapi3.Schema) {
+				// 请求体中 readOnly 的字段由服务端生成，不应该出现在参数中
+				body := params.Properties["body"].Value
+				if got := slices.Sorted(maps.Keys(body.Properties)); !slices.Equal(got, []string{"items", "note"}) {
+					t.Errorf("body 的字段 = %v，期望 [items note]", got)
+				}
+			},
+		},
+	}
+
+	if _, ok := tools["importCatalog"]; ok {
+		t.Error("只接受 multipart 请求体的操作应该被跳过")
+	}
+	if len(tools) != len(tests) {
+		t.Errorf("生成了 %d 个工具，期望 %d 个", len(tools), len(tests))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, ok := tools[tt.name]
+			if !ok {
+				t.Fatalf("没有生成工具 %s", tt.name)
+			}
+			if got := tool.Route(); got != tt.route {
+				t.Errorf("Route() = %s，期望 %s", got, tt.route)
+			}
+			if !strings.Contains(tool.info.Desc, tt.descContains) {
+				t.Errorf("描述 %q 中没有 %q", tool.info.Desc, tt.descContains)
+			}
+
+			params, err := tool.info.ParamsOneOf.ToOpenAPIV3()
+			if err != nil {
+				t.Fatalf("ToOpenAPIV3 返回错误: %v", err)
+			}
+			if got := slices.Sorted(maps.Keys(params.Properties)); !slices.Equal(got, tt.properties) {
+				t.Errorf("参数 = %v，期望 %v", got, tt.properties)
+			}
+			if got := slices.Sorted(slices.Values(params.Required)); !slices.Equal(got, tt.required) {
+				t.Errorf("必填参数 = %v，期望 %v", got, tt.required)
+			}
+			if tt.check != nil {
+				tt.check(t, params)
+			}
+		})
+	}
+}
+
+func TestNewUnknownOperation(t *testing.T) {
+	doc, err := ParseSpec(context.Background(), []byte(fakeSpec), nil)
+	if err != nil {
+		t.Fatalf("ParseSpec 返回错误: %v", err)
+	}
+	if _, err := New(&Config{Spec: doc, BaseURL: "http://localhost", Operations: []string{"getProduct", "DELETE /products/{id}"}}); err == nil {
+		t.Fatal("Operations 中的操作不存在时应该返回错误")
+	}
+}
+
+func TestToolName(t *testing.T) {
+	tests := []struct {
+		prefix, method, path, operationID string
+		want                              string
+	}{
+		{method: "GET", path: "/products/{id}", operationID: "getProduct", want: "getProduct"},
+		{method: "GET", path: "/products/{id}", want: "get_products_id"},
+		{method: "POST", path: "/v1/orders", operationID: "orders.create", want: "orders_create"},
+		{prefix: "shop_", method: "GET", path: "/health", operationID: "getHealth", want: "shop_getHealth"},
+		{method: "GET", path: "/x", operationID: strings.Repeat("a", 70), want: strings.Repeat("a", maxToolNameLen)},
+	}
+	for _, tt := range tests {
+		if got := toolName(tt.prefix, tt.method, tt.path, tt.operationID); got != tt.want {
+			t.Errorf("toolName(%q, %q, %q, %q) = %q，期望 %q", tt.prefix, tt.method, tt.path, tt.operationID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package openapitool
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/openapitool/response.go
+//  功能: 把 HTTP 响应整理为返回给模型的结果，并把响应体裁剪到大小限制以内。
+//  裁剪: JSON 响应优先截短其中最大的数组 (从末尾去掉元素)，保持 JSON 结构完整，
+//        并在 omitted 中记录每个数组保留 / 原有的元素数；截短数组仍然超限时，
+//        或者响应不是 JSON 时，按字节截断为字符串 (不会切断多字节字符)。
+//
+// =============================================================================
+
+// maxReadBytes 读取响应体的上限，超出部分直接丢弃 (裁剪只需要前面的内容)
+const maxReadBytes = 4 << 20
+
+// maxTrimRounds 裁剪 JSON 时最多截短数组的次数
+const maxTrimRounds = 32
+
+// newResponse 整理响应
+func newResponse(resp *http.Response, data []byte, limit int) *Response {
+	result := &Response{
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Bytes:       len(data),
+	}
+	if len(data) > maxReadBytes {
+		data = data[:maxReadBytes]
+		result.Truncated = true
+	}
+	if len(data) == 0 {
+		return result
+	}
+
+	if !utf8.Valid(data) && !isJSON(result.ContentType, data) {
+		result.Body = quote(fmt.Sprintf("[二进制内容: %d 字节]", len(data)))
+		return result
+	}
+	if isJSON(result.ContentType, data) {
+		if body, omitted, ok := trimJSON(data, limit); ok {
+			result.Body = body
+			result.Omitted = omitted
+			result.Truncated = result.Truncated || len(omitted) > 0
+			return result
+		}
+	}
+
+	text, cut := truncateText(string(data), limit)
+	result.Body = quote(text)
+	result.Truncated = result.Truncated || cut
+	return result
+}
+
+// isJSON 按 Content-Type 判断响应是否为 JSON，没有 Content-Type 时检查内容本身
+func isJSON(contentType string, data []byte) bool {
+	if contentType == "" {
+		return json.Valid(data)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// trimJSON 把 JSON 压缩并裁剪到 limit 字节以内。无法解析或截短数组后仍然超限时返回 false。
+func trimJSON(data []byte, limit int) (json.RawMessage, []*Omission, bool) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, data); err != nil {
+		return nil, nil, false
+	}
+	if compacted.Len() <= limit {
+		return compacted.Bytes(), nil, true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var root any
+	if err := dec.Decode(&root); err != nil {
+		return nil, nil, false
+	}
+
+	var omitted []*Omission
+	byPath := map[string]*Omission{}
+	for range maxTrimRounds {
+		encoded := marshal(root)
+		excess := len(encoded) - limit
+		if excess <= 0 {
+			return encoded, omitted, true
+		}
+
+		arr := largestArray(root, func(v any) { root = v })
+		if arr == nil {
+			break
+		}
+		// 从末尾去掉元素，直到去掉的字节数超过超出的部分
+		keep, removed := len(arr.items), 0
+		for keep > 0 && removed < excess {
+			keep--
+			removed += len(marshal(arr.items[keep])) + 1
+		}
+		arr.set(arr.items[:keep])
+
+		if o, ok := byPath[arr.path]; ok {
+			o.Kept = keep
+		} else {
+			o = &Omission{Path: arr.path, Kept: keep, Total: len(arr.items)}
+			byPath[arr.path] = o
+			omitted = append(omitted, o)
+		}
+	}
+	return nil, nil, false
+}
+
+// arrayRef JSON 中的一个数组及其替换方法
+type arrayRef struct {
+	path  string
+	items []any
+	size  int
+	set   func([]any)
+}
+
+// largestArray 找到序列化后最大的非空数组，大小相同时取路径较小的，保证结果稳定
+func largestArray(root any, setRoot func(any)) *arrayRef {
+	var best *arrayRef
+	var walk func(v any, path string, set func(any))
+	walk = func(v any, path string, set func(any)) {
+		switch x := v.(type) {
+		case []any:
+			if len(x) > 0 {
+				size := len(marshal(x))
+				if best == nil || size > best.size || (size == best.size && path < best.path) {
+					best = &arrayRef{path: path, items: x, size: size, set: func(items []any) { set(items) }}
+				}
+			}
+			for i, item := range x {
+				walk(item, fmt.Sprintf("%s[%d]", path, i), func(n any) { x[i] = n })
+			}
+		case map[string]any:
+			for _, k := range sortedKeys(x) {
+				walk(x[k], path+"."+k, func(n any) { x[k] = n })
+			}
+		}
+	}
+	walk(root, "$", setRoot)
+	return best
+}
+
+// marshal 序列化为紧凑的 JSON，不转义 HTML 字符
+func marshal(v any) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// quote 把字符串编码为 JSON 字符串
+func quote(s string) json.RawMessage {
+	return marshal(s)
+}
+
+// truncateText 把文本截断到 limit 字节以内，不切断多字节字符
+func truncateText(s string, limit int) (string, bool) {
+	if len(s) <= limit {
+		return s, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…", true
+}
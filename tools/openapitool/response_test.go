@@ -0,0 +1,65 @@
+package openapitool
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewResponseTrimming(t *testing.T) {
+	// 20 个元素的数组，每个元素约 30 字节
+	var items []string
+	for i := range 20 {
+		items = append(items, fmt.Sprintf(`{"id": %d, "name": "商品 %02d"}`, i, i))
+	}
+	list := `{"total": 20, "items": [` + strings.Join(items, ", ") + `]}`
+
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		limit         int
+		wantBody      string // 为空时不检查
+		wantTruncated bool
+		wantOmitted   string // path kept/total
+	}{
+		{name: "未超限的 JSON 被压缩", contentType: "application/json", body: "{\n  \"ok\": true\n}", limit: 100, wantBody: `{"ok":true}`},
+		{name: "截短最大的数组", contentType: "application/json; charset=utf-8", body: list, limit: 200, wantTruncated: true, wantOmitted: "$.items 6/20"},
+		{name: "+json 也按 JSON 处理", contentType: "application/problem+json", body: list, limit: 200, wantTruncated: true, wantOmitted: "$.items 6/20"},
+		{name: "没有 Content-Type 时检查内容", body: `[1, 2, 3]`, limit: 100, wantBody: `[1,2,3]`},
+		{name: "文本按字节截断，不切断多字节字符", contentType: "text/plain", body: "你好世界", limit: 7, wantBody: `"你好…"`, wantTruncated: true},
+		{name: "不是合法 JSON 时按文本处理", contentType: "application/json", body: `{"a": `, limit: 100, wantBody: `"{\"a\": "`},
+		{name: "二进制内容", contentType: "application/octet-stream", body: "\xff\xfe\x00\x01", limit: 100, wantBody: `"[二进制内容: 4 字节]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+			if tt.contentType != "" {
+				resp.Header.Set("Content-Type", tt.contentType)
+			}
+			result := newResponse(resp, []byte(tt.body), tt.limit)
+
+			if result.Bytes != len(tt.body) {
+				t.Errorf("Bytes = %d，期望 %d", result.Bytes, len(tt.body))
+			}
+			if tt.wantBody != "" && string(result.Body) != tt.wantBody {
+				t.Errorf("Body = %s，期望 %s", result.Body, tt.wantBody)
+			}
+			if result.Truncated != tt.wantTruncated {
+				t.Errorf("Truncated = %v，期望 %v", result.Truncated, tt.wantTruncated)
+			}
+			var omitted []string
+			for _, o := range result.Omitted {
+				omitted = append(omitted, fmt.Sprintf("%s %d/%d", o.Path, o.Kept, o.Total))
+			}
+			if got := strings.Join(omitted, ", "); got != tt.wantOmitted {
+				t.Errorf("Omitted = %q，期望 %q", got, tt.wantOmitted)
+			}
+			if tt.wantOmitted != "" && len(result.Body) > tt.limit {
+				t.Errorf("裁剪后的 Body 有 %d 字节，超过限制 %d", len(result.Body), tt.limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package openapitool
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// =============================================================================
+//
+//  文件: tools/openapitool/schema.go
+//  功能: 由操作的参数和请求体生成工具的参数定义。
+//  规则:
+//    - path / query / header / cookie 参数各为一个顶层参数，名称相同的参数按 "<位置>_<名称>" 区分
+//    - JSON 请求体作为 body 参数 (名称被占用时为 request_body)，请求体必填时 body 必填
+//    - $ref 展开为内联定义 (模型看不到 components)，allOf 合并为一个对象，
+//      递归定义最多展开 maxSchemaDepth 层；请求体中的 readOnly 字段 (如服务端生成的 id) 被去掉
+//
+// =============================================================================
+
+// maxSchemaDepth 展开嵌套 / 递归定义的最大深度
+const maxSchemaDepth = 8
+
+// errUnsupportedBody 请求体必填但没有 JSON 格式
+var errUnsupportedBody = fmt.Errorf("请求体不支持 JSON 格式")
+
+// paramBinding 工具参数与 HTTP 参数的对应关系
+type paramBinding struct {
+	key   string // 工具参数中的名称
+	param *openapi3.Parameter
+}
+
+// bodyBinding 工具参数与请求体的对应关系
+type bodyBinding struct {
+	key         string // 工具参数中的名称
+	contentType string
+}
+
+// buildParams 生成操作的参数定义。pathParams 为路径级别的公共参数，会被操作中同位置同名的参数覆盖。
+func buildParams(pathParams openapi3.Parameters, op *openapi3.Operation) (*openapi3.Schema, []*paramBinding, *bodyBinding, error) {
+	params := mergeParameters(pathParams, op.Parameters)
+
+	root := &openapi3.Schema{Type: openapi3.TypeObject, Properties: openapi3.Schemas{}}
+	var bindings []*paramBinding
+	count := map[string]int{}
+	for _, p := range params {
+		count[p.Name]++
+	}
+	for _, p := range params {
+		key := p.Name
+		if count[p.Name] > 1 {
+			key = p.In + "_" + p.Name
+		}
+		s := inlineSchema(parameterSchema(p), 0, false)
+		if p.Description != "" {
+			s.Description = p.Description
+		}
+		if p.Deprecated {
+			s.Deprecated = true
+		}
+		root.Properties[key] = &openapi3.SchemaRef{Value: s}
+		if p.Required || p.In == openapi3.ParameterInPath {
+			root.Required = append(root.Required, key)
+		}
+		bindings = append(bindings, &paramBinding{key: key, param: p})
+	}
+
+	var body *bodyBinding
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		rb := op.RequestBody.Value
+		contentType, media := jsonMediaType(rb.Content)
+		switch {
+		case media != nil:
+			key := "body"
+			if _, taken := root.Properties[key]; taken {
+				key = "request_body"
+			}
+			s := inlineSchema(media.Schema, 0, true)
+			if rb.Description != "" {
+				s.Description = rb.Description
+			} else if s.Description == "" {
+				s.Description = "请求体 (JSON)"
+			}
+			root.Properties[key] = &openapi3.SchemaRef{Value: s}
+			if rb.Required {
+				root.Required = append(root.Required, key)
+			}
+			body = &bodyBinding{key: key, contentType: contentType}
+		case rb.Required:
+			return nil, nil, nil, errUnsupportedBody
+		}
+	}
+	sort.Strings(root.Required)
+	return root, bindings, body, nil
+}
+
+// mergeParameters 合并路径级别和操作级别的参数，按 位置 + 名称 去重
+func mergeParameters(pathParams, opParams openapi3.Parameters) []*openapi3.Parameter {
+	var merged []*openapi3.Parameter
+	index := map[string]int{}
+	for _, refs := range []openapi3.Parameters{pathParams, opParams} {
+		for _, ref := range refs {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			p := ref.Value
+			id := p.In + ":" + p.Name
+			if i, ok := index[id]; ok {
+				merged[i] = p
+				continue
+			}
+			index[id] = len(merged)
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// parameterSchema 参数的定义，使用 content 描述的参数取第一个媒体类型的定义
+func parameterSchema(p *openapi3.Parameter) *openapi3.SchemaRef {
+	if p.Schema != nil {
+		return p.Schema
+	}
+	for _, mediaType := range sortedKeys(p.Content) {
+		if media := p.Content[mediaType]; media != nil && media.Schema != nil {
+			return media.Schema
+		}
+	}
+	return &openapi3.SchemaRef{Value: openapi3.NewStringSchema()}
+}
+
+// jsonMediaType 选择请求体的 JSON 媒体类型: 优先 application/json，其次任意 */*+json
+func jsonMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if media := content.Get("application/json"); media != nil {
+		return "application/json", media
+	}
+	for _, mediaType := range sortedKeys(content) {
+		if strings.Contains(mediaType, "json") {
+			return mediaType, content[mediaType]
+		}
+	}
+	return "", nil
+}
+
+// inlineSchema 复制定义并展开其中的 $ref。request 为 true 时去掉 readOnly 属性 (请求中不应出现)。
+func inlineSchema(ref *openapi3.SchemaRef, depth int, request bool) *openapi3.Schema {
+	if ref == nil || ref.Value == nil {
+		return &openapi3.Schema{}
+	}
+	src := ref.Value
+	if depth >= maxSchemaDepth {
+		// 递归定义在此截断，只保留类型和说明
+		return &openapi3.Schema{Type: src.Type, Description: src.Description}
+	}
+
+	s := *src
+	s.Extensions = nil
+	s.Discriminator = nil // mapping 中的 $ref 在展开后没有意义
+	s.OneOf = inlineRefs(src.OneOf, depth, request)
+	s.AnyOf = inlineRefs(src.AnyOf, depth, request)
+	s.AllOf = nil
+	s.Not = inlineRef(src.Not, depth, request)
+	s.Items = inlineRef(src.Items, depth, request)
+	s.AdditionalProperties.Schema = inlineRef(src.AdditionalProperties.Schema, depth, request)
+
+	s.Properties, s.Required = nil, nil
+	if len(src.Properties) > 0 {
+		s.Properties = make(openapi3.Schemas, len(src.Properties))
+	}
+	for name, prop := range src.Properties {
+		if request && prop != nil && prop.Value != nil && prop.Value.ReadOnly {
+			continue
+		}
+		s.Properties[name] = &openapi3.SchemaRef{Value: inlineSchema(prop, depth+1, request)}
+	}
+	for _, name := range src.Required {
+		if _, ok := s.Properties[name]; ok {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	for _, part := range src.AllOf {
+		mergeSchema(&s, inlineSchema(part, depth+1, request))
+	}
+	return &s
+}
+
+// inlineRef 展开单个定义
+func inlineRef(ref *openapi3.SchemaRef, depth int, request bool) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	return &openapi3.SchemaRef{Value: inlineSchema(ref, depth+1, request)}
+}
+
+// inlineRefs 展开一组定义
+func inlineRefs(refs openapi3.SchemaRefs, depth int, request bool) openapi3.SchemaRefs {
+	if len(refs) == 0 {
+		return nil
+	}
+	out := make(openapi3.SchemaRefs, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, inlineRef(ref, depth, request))
+	}
+	return out
+}
+
+// mergeSchema 把 allOf 的一部分合并到 s: 合并属性和必填列表，类型和说明只在 s 没有时使用
+func mergeSchema(s, part *openapi3.Schema) {
+	if s.Type == "" {
+		s.Type = part.Type
+	}
+	if s.Description == "" {
+		s.Description = part.Description
+	}
+	if len(part.Properties) > 0 && s.Properties == nil {
+		s.Properties = make(openapi3.Schemas, len(part.Properties))
+	}
+	for name, prop := range part.Properties {
+		s.Properties[name] = prop
+	}
+	for _, name := range part.Required {
+		if !slices.Contains(s.Required, name) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	if len(part.Enum) > 0 && len(s.Enum) == 0 {
+		s.Enum = part.Enum
+	}
+}
+
+// sortedKeys 按字母顺序返回 map 的键，保证生成结果稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
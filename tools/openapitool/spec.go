@@ -0,0 +1,98 @@
+package openapitool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// =============================================================================
+//
+//  文件: tools/openapitool/spec.go
+//  功能: 读取并校验 OpenAPI 3 文档。
+//  说明: 文档可以来自 http(s) 地址或本地文件，JSON 和 YAML 均可。
+//        文档内的 $ref 会被解析；不允许引用其他文件，避免文档内容决定读取哪些本地文件或地址。
+//        servers 中的相对地址 (如 "/api/v1") 按文档地址解析为绝对地址。
+//
+// =============================================================================
+
+// MaxSpecBytes 文档的最大字节数
+const MaxSpecBytes = 10 << 20
+
+// LoadSpec 从 http(s) 地址或本地文件读取 OpenAPI 3 文档，client 为 nil 时使用 http.DefaultClient
+func LoadSpec(ctx context.Context, location string, client *http.Client) (*openapi3.T, error) {
+	var (
+		data []byte
+		base *url.URL
+		err  error
+	)
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		if base, err = url.Parse(location); err != nil {
+			return nil, fmt.Errorf("文档地址无效: %w", err)
+		}
+		data, err = fetchSpec(ctx, location, client)
+	} else {
+		data, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 OpenAPI 文档失败: %w", err)
+	}
+	return ParseSpec(ctx, data, base)
+}
+
+// ParseSpec 解析并校验 OpenAPI 3 文档。base 为文档地址，用于解析 servers 中的相对地址，可以为 nil。
+func ParseSpec(ctx context.Context, data []byte, base *url.URL) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.Context = ctx
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析 OpenAPI 文档失败: %w", err)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("OpenAPI 文档无效: %w", err)
+	}
+
+	if base != nil {
+		for _, server := range doc.Servers {
+			if ref, err := url.Parse(server.URL); err == nil && !ref.IsAbs() && !strings.Contains(server.URL, "{") {
+				server.URL = base.ResolveReference(ref).String()
+			}
+		}
+	}
+	return doc, nil
+}
+
+// fetchSpec 下载文档
+func fetchSpec(ctx context.Context, location string, client *http.Client) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.1")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSpecBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSpecBytes {
+		return nil, fmt.Errorf("文档超过 %d 字节", MaxSpecBytes)
+	}
+	return data, nil
+}
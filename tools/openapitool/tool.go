@@ -0,0 +1,342 @@
+package openapitool
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// =============================================================================
+//
+//  文件: tools/openapitool/tool.go
+//  功能: 由 OpenAPI 操作生成的工具，调用时把参数组装为 HTTP 请求并返回响应。
+//  说明: 参数按 OpenAPI 的 style / explode 规则序列化 (query 默认 form + explode，path / header 默认 simple)。
+//        HTTP 状态码不是 2xx 时不返回 error (ToolsNode 遇到 error 会中断整个调用)，
+//        而是把状态码和响应体作为结果返回给模型；只有请求无法发出或参数有误时才返回 error。
+//
+// =============================================================================
+
+// Tool 一个 OpenAPI 操作对应的工具
+type Tool struct {
+	info    *schema.ToolInfo
+	method  string
+	path    string
+	params  []*paramBinding
+	body    *bodyBinding
+	auth    []*credential
+	headers map[string]string
+
+	baseURL  *url.URL
+	client   *http.Client
+	maxBytes int
+}
+
+// Response 返回给模型的调用结果
+type Response struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`      // JSON 响应为原始 JSON，其他响应为字符串
+	Bytes       int             `json:"bytes"`               // 响应体的原始字节数
+	Truncated   bool            `json:"truncated,omitempty"` // 响应体超过大小限制，已被裁剪
+	Omitted     []*Omission     `json:"omitted,omitempty"`   // 裁剪 JSON 时被截短的数组
+}
+
+// Omission 裁剪 JSON 响应时被截短的数组
+type Omission struct {
+	Path  string `json:"path"`  // 数组位置，如 $.items
+	Kept  int    `json:"kept"`  // 保留的元素数
+	Total int    `json:"total"` // 原始元素数
+}
+
+// Info 返回工具信息
+func (t *Tool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.info, nil
+}
+
+// Route 返回工具对应的 HTTP 接口，如 "GET /products/{id}"
+func (t *Tool) Route() string {
+	return t.method + " " + t.path
+}
+
+// InvokableRun 发送 HTTP 请求，返回 Response 的 JSON
+func (t *Tool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	args, err := decodeArgs(argumentsInJSON)
+	if err != nil {
+		return "", fmt.Errorf("解析参数失败: %w", err)
+	}
+
+	req, err := t.buildRequest(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[OpenAPITool] %s: %s %s", t.info.Name, req.Method, req.URL.Redacted())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求 %s 失败: %w", t.Route(), err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReadBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 的响应失败: %w", t.Route(), err)
+	}
+	result := newResponse(resp, data, t.maxBytes)
+	log.Printf("[OpenAPITool] %s: HTTP %d, %d 字节, 裁剪: %v", t.info.Name, result.Status, result.Bytes, result.Truncated)
+
+	output, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("序列化结果失败: %w", err)
+	}
+	return string(output), nil
+}
+
+// decodeArgs 解析参数，数字保留为 json.Number，避免大整数 ID 被转换为浮点数
+func decodeArgs(argumentsInJSON string) (map[string]any, error) {
+	args := map[string]any{}
+	if strings.TrimSpace(argumentsInJSON) == "" {
+		return args, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(argumentsInJSON))
+	dec.UseNumber()
+	if err := dec.Decode(&args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// buildRequest 把参数组装为 HTTP 请求
+func (t *Tool) buildRequest(ctx context.Context, args map[string]any) (*http.Request, error) {
+	path := t.path
+	query := t.baseURL.Query()
+	header := http.Header{}
+	var cookies []*http.Cookie
+
+	for _, b := range t.params {
+		value, ok := args[b.key]
+		if !ok || value == nil {
+			if b.param.In == openapi3.ParameterInPath {
+				return nil, fmt.Errorf("缺少路径参数 %s", b.key)
+			}
+			continue
+		}
+		method, err := b.param.SerializationMethod()
+		if err != nil {
+			return nil, err
+		}
+		switch b.param.In {
+		case openapi3.ParameterInPath:
+			segment := pathValue(b.param.Name, value, method)
+			if segment == "." || segment == ".." {
+				// 转义不处理 . 和 ..，它们会被当作相对路径，使请求落到其他接口上
+				return nil, fmt.Errorf("路径参数 %s 的值 %q 无效", b.key, scalar(value))
+			}
+			path = strings.ReplaceAll(path, "{"+b.param.Name+"}", segment)
+		case openapi3.ParameterInQuery:
+			addQuery(query, b.param.Name, value, method)
+		case openapi3.ParameterInHeader:
+			header.Set(b.param.Name, simpleValue(value, method.Explode, noEscape))
+		case openapi3.ParameterInCookie:
+			cookies = append(cookies, &http.Cookie{Name: b.param.Name, Value: simpleValue(value, false, noEscape)})
+		}
+	}
+
+	var body io.Reader
+	if t.body != nil {
+		if value, ok := args[t.body.key]; ok {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("序列化请求体失败: %w", err)
+			}
+			body = bytes.NewReader(data)
+		}
+	}
+
+	base := *t.baseURL
+	base.RawQuery, base.Fragment = "", ""
+	u, err := url.Parse(base.String() + path)
+	if err != nil {
+		return nil, fmt.Errorf("请求地址无效: %w", err)
+	}
+	for _, cred := range t.auth {
+		applyCredential(header, query, &cookies, cred)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, t.method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", t.body.contentType)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, */*;q=0.5")
+	}
+	return req, nil
+}
+
+// applyCredential 按安全方案注入凭证
+func applyCredential(header http.Header, query url.Values, cookies *[]*http.Cookie, cred *credential) {
+	s := cred.scheme
+	switch s.Type {
+	case "apiKey":
+		switch s.In {
+		case openapi3.ParameterInQuery:
+			query.Set(s.Name, cred.value)
+		case openapi3.ParameterInCookie:
+			*cookies = append(*cookies, &http.Cookie{Name: s.Name, Value: cred.value})
+		default:
+			header.Set(s.Name, cred.value)
+		}
+	case "http":
+		switch strings.ToLower(s.Scheme) {
+		case "basic":
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cred.value)))
+		case "bearer":
+			header.Set("Authorization", "Bearer "+cred.value)
+		default:
+			header.Set("Authorization", s.Scheme+" "+cred.value)
+		}
+	default: // oauth2、openIdConnect: 使用已获取的访问令牌
+		header.Set("Authorization", "Bearer "+cred.value)
+	}
+}
+
+// ================================
+// 参数序列化
+// ================================
+
+// scalar 把基本类型的值转换为字符串，其他类型使用 JSON
+func scalar(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(x)
+		return string(data)
+	}
+}
+
+// simpleValue simple 风格: 数组为 a,b,c；对象为 k1,v1,k2,v2 (explode 时为 k1=v1,k2=v2)。
+// escape 用于转义每个值，分隔符本身不转义。
+func simpleValue(v any, explode bool, escape func(string) string) string {
+	switch x := v.(type) {
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, escape(scalar(item)))
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		var parts []string
+		for _, k := range sortedKeys(x) {
+			if explode {
+				parts = append(parts, escape(k)+"="+escape(scalar(x[k])))
+			} else {
+				parts = append(parts, escape(k), escape(scalar(x[k])))
+			}
+		}
+		return strings.Join(parts, ",")
+	default:
+		return escape(scalar(v))
+	}
+}
+
+// noEscape 请求头和 Cookie 中的值不需要转义
+func noEscape(s string) string { return s }
+
+// pathValue 路径参数，支持 simple (默认)、label (.a.b) 和 matrix (;name=a,b) 风格，值经过转义
+func pathValue(name string, v any, method *openapi3.SerializationMethod) string {
+	escape := url.PathEscape
+	switch method.Style {
+	case openapi3.SerializationLabel:
+		if items, ok := v.([]any); ok && method.Explode {
+			var sb strings.Builder
+			for _, item := range items {
+				sb.WriteString("." + escape(scalar(item)))
+			}
+			return sb.String()
+		}
+		return "." + simpleValue(v, method.Explode, escape)
+	case openapi3.SerializationMatrix:
+		if items, ok := v.([]any); ok && method.Explode {
+			var sb strings.Builder
+			for _, item := range items {
+				sb.WriteString(";" + name + "=" + escape(scalar(item)))
+			}
+			return sb.String()
+		}
+		return ";" + name + "=" + simpleValue(v, method.Explode, escape)
+	default:
+		return simpleValue(v, method.Explode, escape)
+	}
+}
+
+// addQuery 查询参数，支持 form (默认)、spaceDelimited、pipeDelimited 和 deepObject 风格
+func addQuery(query url.Values, name string, v any, method *openapi3.SerializationMethod) {
+	switch x := v.(type) {
+	case []any:
+		if method.Style == openapi3.SerializationForm && method.Explode {
+			for _, item := range x {
+				query.Add(name, scalar(item))
+			}
+			return
+		}
+		sep := ","
+		switch method.Style {
+		case openapi3.SerializationSpaceDelimited:
+			sep = " "
+		case openapi3.SerializationPipeDelimited:
+			sep = "|"
+		}
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, scalar(item))
+		}
+		query.Add(name, strings.Join(parts, sep))
+	case map[string]any:
+		keys := sortedKeys(x)
+		switch {
+		case method.Style == openapi3.SerializationDeepObject:
+			for _, k := range keys {
+				query.Add(name+"["+k+"]", scalar(x[k]))
+			}
+		case method.Explode:
+			for _, k := range keys {
+				query.Add(k, scalar(x[k]))
+			}
+		default:
+			query.Add(name, simpleValue(x, false, noEscape))
+		}
+	default:
+		query.Add(name, scalar(v))
+	}
+}
@@ -0,0 +1,94 @@
+package openapitool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestToolInvokableRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials map[string]string // 为 nil 时使用 FakeServer 的凭证
+		tool        string
+		args        string
+		wantStatus  int
+		wantErr     bool
+	}{
+		{name: "查询商品", tool: "getProduct", args: `{"id": 3}`, wantStatus: http.StatusOK},
+		{name: "商品不存在时返回 404 结果而不是 error", tool: "getProduct", args: `{"id": 999}`, wantStatus: http.StatusNotFound},
+		{name: "不需要认证的接口", credentials: map[string]string{}, tool: "getHealth", wantStatus: http.StatusOK},
+		{name: "注入 API Key 和 Bearer Token", tool: "createOrder", args: `{"body": {"items": [{"product_id": 2, "quantity": 1}]}}`, wantStatus: http.StatusCreated},
+		{name: "凭证错误时返回 401 结果", credentials: map[string]string{"apiKey": "wrong"}, tool: "getProduct", args: `{"id": 3}`, wantStatus: http.StatusUnauthorized},
+		{name: "缺少 Bearer Token 时照常发送请求", credentials: map[string]string{"apiKey": "demo-key"}, tool: "createOrder", args: `{"body": {"items": [{"product_id": 2, "quantity": 1}]}}`, wantStatus: http.StatusUnauthorized},
+		{name: "缺少路径参数", tool: "getProduct", args: `{}`, wantErr: true},
+		{name: "路径参数为 ..", tool: "getProduct", args: `{"id": ".."}`, wantErr: true},
+		{name: "路径参数为 .", tool: "getProduct", args: `{"id": "."}`, wantErr: true},
+		{name: "参数不是 JSON", tool: "getProduct", args: `{"id": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, tools := newFakeTools(t, tt.credentials)
+			output, err := tools[tt.tool].InvokableRun(context.Background(), tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回 error，实际返回 %s", output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InvokableRun 返回错误: %v", err)
+			}
+			var resp Response
+			if err := json.Unmarshal([]byte(output), &resp); err != nil {
+				t.Fatalf("结果不是 Response 的 JSON: %v\n%s", err, output)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("状态码 = %d，期望 %d，响应: %s", resp.Status, tt.wantStatus, resp.Body)
+			}
+		})
+	}
+}
+
+func TestApplyCredential(t *testing.T) {
+	tests := []struct {
+		name       string
+		scheme     openapi3.SecurityScheme
+		value      string
+		wantHeader string // Name: Value 形式
+		wantQuery  string
+		wantCookie string
+	}{
+		{name: "apiKey 请求头", scheme: openapi3.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}, value: "k1", wantHeader: "X-Api-Key: k1"},
+		{name: "apiKey 查询参数", scheme: openapi3.SecurityScheme{Type: "apiKey", In: "query", Name: "api_key"}, value: "k2", wantQuery: "api_key=k2"},
+		{name: "apiKey Cookie", scheme: openapi3.SecurityScheme{Type: "apiKey", In: "cookie", Name: "session"}, value: "k3", wantCookie: "session=k3"},
+		{name: "http bearer", scheme: openapi3.SecurityScheme{Type: "http", Scheme: "bearer"}, value: "t1", wantHeader: "Authorization: Bearer t1"},
+		{name: "http basic", scheme: openapi3.SecurityScheme{Type: "http", Scheme: "basic"}, value: "user:pass", wantHeader: "Authorization: Basic dXNlcjpwYXNz"},
+		{name: "oauth2 使用访问令牌", scheme: openapi3.SecurityScheme{Type: "oauth2"}, value: "t2", wantHeader: "Authorization: Bearer t2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, query := http.Header{}, url.Values{}
+			var cookies []*http.Cookie
+			applyCredential(header, query, &cookies, &credential{scheme: &tt.scheme, value: tt.value})
+
+			var gotHeader, gotCookie string
+			for name, values := range header {
+				gotHeader = name + ": " + values[0]
+			}
+			for _, c := range cookies {
+				gotCookie = c.String()
+			}
+			if gotHeader != tt.wantHeader || query.Encode() != tt.wantQuery || gotCookie != tt.wantCookie {
+				t.Errorf("请求头 %q、查询参数 %q、Cookie %q，期望 %q、%q、%q",
+					gotHeader, query.Encode(), gotCookie, tt.wantHeader, tt.wantQuery, tt.wantCookie)
+			}
+		})
+	}
+}
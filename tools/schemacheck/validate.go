@@ -0,0 +1,482 @@
+package schemacheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// =============================================================================
+//
+//  文件: tools/schemacheck/validate.go
+//  功能: 按照工具的参数定义 (schema.ToolInfo) 校验模型生成的 JSON 参数。
+//  支持: type / required / enum / nullable、数值的 minimum / maximum (含 exclusive) / multipleOf、
+//        字符串的 minLength / maxLength / pattern、数组的 minItems / maxItems / uniqueItems / items、
+//        对象的 properties / additionalProperties / minProperties / maxProperties，以及嵌套结构。
+//  说明: 通过 NewParamsOneOfByParams 或 utils.InferTool (jsonschema 标签) 定义的参数
+//        只有类型、必填和枚举信息；范围、长度、正则等约束需要通过
+//        NewParamsOneOfByOpenAPIV3 定义。
+//        一次校验收集所有问题，方便模型一次性修正。
+//        在工具执行前统一校验请使用 tools/middleware 的 WithValidation，它通过 Check 校验参数。
+//
+// =============================================================================
+
+// Issue 参数中的一个问题
+type Issue struct {
+	Path    string `json:"path"`    // 参数路径，如 to_lang、items[0].price，根对象为 $
+	Message string `json:"message"` // 问题描述
+}
+
+// ValidationError 参数校验失败
+type ValidationError struct {
+	Tool   string   `json:"tool"`
+	Issues []*Issue `json:"issues"`
+}
+
+// Error 返回所有问题拼接后的描述
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		parts = append(parts, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+	}
+	return fmt.Sprintf("工具 %s 的参数校验失败: %s", e.Tool, strings.Join(parts, "; "))
+}
+
+// ToolResult 返回给模型的校验结果 (JSON)，包含每个问题的位置和修正提示
+func (e *ValidationError) ToolResult() string {
+	data, _ := json.Marshal(map[string]any{
+		"error":  "参数校验失败",
+		"tool":   e.Tool,
+		"issues": e.Issues,
+		"hint":   "请根据 issues 修正参数后重新调用该工具",
+	})
+	return string(data)
+}
+
+// Validate 按照工具的参数定义校验 JSON 参数。
+// 校验不通过时返回 *ValidationError；参数定义本身无法转换时返回普通错误。
+func Validate(info *schema.ToolInfo, argumentsInJSON string) error {
+	s, err := toolSchema(info)
+	if err != nil {
+		return err
+	}
+	return ValidateSchema(info.Name, s, argumentsInJSON)
+}
+
+// Check 校验参数，返回校验是否通过以及不通过时返回给模型的工具结果 (JSON)。
+// 校验失败时工具不应执行，也不应返回 error (ToolsNode 遇到 error 会中断整个调用)，
+// 而是把结果交给模型，让模型修正参数后重试。
+func Check(info *schema.ToolInfo, argumentsInJSON string) (string, bool) {
+	return checkResult(Validate(info, argumentsInJSON))
+}
+
+// checkResult 把校验错误转换为返回给模型的工具结果
+func checkResult(err error) (string, bool) {
+	if err == nil {
+		return "", true
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return fmt.Sprintf(`{"error": %q}`, err.Error()), false
+	}
+	log.Printf("[SchemaCheck] %v", verr)
+	return verr.ToolResult(), false
+}
+
+// ValidateSchema 按照 OpenAPI V3 Schema 校验 JSON 参数，s 为 nil 时只检查参数是否为合法的 JSON
+func ValidateSchema(toolName string, s *openapi3.Schema, argumentsInJSON string) error {
+	v := &validator{}
+
+	value, err := decodeArguments(argumentsInJSON)
+	if err != nil {
+		v.add("$", "参数不是合法的 JSON: %v", err)
+	} else if s != nil {
+		v.check("$", s, value)
+	}
+
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Tool: toolName, Issues: v.issues}
+}
+
+// toolSchema 把工具的参数定义转换为 OpenAPI V3 Schema
+func toolSchema(info *schema.ToolInfo) (*openapi3.Schema, error) {
+	if info == nil {
+		return nil, fmt.Errorf("工具信息为空")
+	}
+	if info.ParamsOneOf == nil {
+		return nil, nil
+	}
+	s, err := info.ParamsOneOf.ToOpenAPIV3()
+	if err != nil {
+		return nil, fmt.Errorf("转换工具 %s 的参数定义失败: %w", info.Name, err)
+	}
+	return s, nil
+}
+
+// decodeArguments 解析 JSON 参数，数字保留为 json.Number 以区分整数和小数。
+// 空字符串视为空对象 (部分模型对无参数的工具返回空参数)。
+func decodeArguments(argumentsInJSON string) (any, error) {
+	if strings.TrimSpace(argumentsInJSON) == "" {
+		return map[string]any{}, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(argumentsInJSON))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("JSON 之后还有多余的内容")
+	}
+	return value, nil
+}
+
+// ================================
+// 校验逻辑
+// ================================
+
+// validator 递归校验参数，收集所有问题
+type validator struct {
+	issues []*Issue
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	v.issues = append(v.issues, &Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check 校验 value 是否符合 s
+func (v *validator) check(path string, s *openapi3.Schema, value any) {
+	if value == nil {
+		// 未声明类型的参数允许任意值
+		if !s.Nullable && s.Type != "" && s.Type != "null" {
+			v.add(path, "不能为 null，应为 %s", typeName(s.Type))
+		}
+		return
+	}
+
+	if !v.checkType(path, s.Type, value) {
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		v.add(path, "取值 %s 不在允许的范围内，可选值: %s", formatValue(value), formatEnum(s.Enum))
+	}
+
+	switch val := value.(type) {
+	case json.Number:
+		v.checkNumber(path, s, val)
+	case string:
+		v.checkString(path, s, val)
+	case []any:
+		v.checkArray(path, s, val)
+	case map[string]any:
+		v.checkObject(path, s, val)
+	}
+}
+
+// checkType 检查 JSON 类型，不匹配时记录问题并返回 false
+func (v *validator) checkType(path, typ string, value any) bool {
+	ok := true
+	switch typ {
+	case "":
+		return true
+	case openapi3.TypeObject:
+		_, ok = value.(map[string]any)
+	case openapi3.TypeArray:
+		_, ok = value.([]any)
+	case openapi3.TypeString:
+		_, ok = value.(string)
+	case openapi3.TypeBoolean:
+		_, ok = value.(bool)
+	case openapi3.TypeNumber:
+		_, ok = value.(json.Number)
+	case openapi3.TypeInteger:
+		var n json.Number
+		if n, ok = value.(json.Number); ok {
+			ok = isInteger(n)
+		}
+	case "null":
+		ok = false // value 不为 nil
+	}
+	if !ok {
+		v.add(path, "类型错误，应为 %s，实际为 %s", typeName(typ), jsonTypeName(value))
+	}
+	return ok
+}
+
+func (v *validator) checkNumber(path string, s *openapi3.Schema, n json.Number) {
+	f, err := n.Float64()
+	if err != nil {
+		v.add(path, "无法解析数字 %s", n)
+		return
+	}
+	if s.Min != nil {
+		if s.ExclusiveMin && f <= *s.Min {
+			v.add(path, "应大于 %s，实际为 %s", formatFloat(*s.Min), n)
+		} else if !s.ExclusiveMin && f < *s.Min {
+			v.add(path, "应大于等于 %s，实际为 %s", formatFloat(*s.Min), n)
+		}
+	}
+	if s.Max != nil {
+		if s.ExclusiveMax && f >= *s.Max {
+			v.add(path, "应小于 %s，实际为 %s", formatFloat(*s.Max), n)
+		} else if !s.ExclusiveMax && f > *s.Max {
+			v.add(path, "应小于等于 %s，实际为 %s", formatFloat(*s.Max), n)
+		}
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		if q := f / *s.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.add(path, "应为 %s 的整数倍，实际为 %s", formatFloat(*s.MultipleOf), n)
+		}
+	}
+}
+
+func (v *validator) checkString(path string, s *openapi3.Schema, str string) {
+	length := uint64(utf8.RuneCountInString(str))
+	if s.MinLength > 0 && length < s.MinLength {
+		v.add(path, "长度至少为 %d 个字符，实际为 %d", s.MinLength, length)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.add(path, "长度最多为 %d 个字符，实际为 %d", *s.MaxLength, length)
+	}
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			v.add(path, "参数定义中的正则表达式 %q 无效: %v", s.Pattern, err)
+		} else if !re.MatchString(str) {
+			v.add(path, "格式不正确，应匹配正则表达式 %s", s.Pattern)
+		}
+	}
+}
+
+func (v *validator) checkArray(path string, s *openapi3.Schema, items []any) {
+	count := uint64(len(items))
+	if s.MinItems > 0 && count < s.MinItems {
+		v.add(path, "至少需要 %d 个元素，实际为 %d", s.MinItems, count)
+	}
+	if s.MaxItems != nil && count > *s.MaxItems {
+		v.add(path, "最多允许 %d 个元素，实际为 %d", *s.MaxItems, count)
+	}
+	if s.UniqueItems {
+		for i := 1; i < len(items); i++ {
+			for j := 0; j < i; j++ {
+				if equalValue(items[i], items[j]) {
+					v.add(fmt.Sprintf("%s[%d]", path, i), "与第 %d 个元素重复，数组元素不能重复", j)
+				}
+			}
+		}
+	}
+	if s.Items != nil && s.Items.Value != nil {
+		for i, item := range items {
+			v.check(fmt.Sprintf("%s[%d]", path, i), s.Items.Value, item)
+		}
+	}
+}
+
+func (v *validator) checkObject(path string, s *openapi3.Schema, obj map[string]any) {
+	required := append([]string(nil), s.Required...)
+	sort.Strings(required)
+	for _, name := range required {
+		if _, ok := obj[name]; !ok {
+			desc := ""
+			if prop := s.Properties[name]; prop != nil && prop.Value != nil && prop.Value.Description != "" {
+				desc = fmt.Sprintf(" (%s)", prop.Value.Description)
+			}
+			v.add(joinPath(path, name), "缺少必填参数%s", desc)
+		}
+	}
+
+	count := uint64(len(obj))
+	if s.MinProps > 0 && count < s.MinProps {
+		v.add(path, "至少需要 %d 个字段，实际为 %d", s.MinProps, count)
+	}
+	if s.MaxProps != nil && count > *s.MaxProps {
+		v.add(path, "最多允许 %d 个字段，实际为 %d", *s.MaxProps, count)
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := obj[name]
+		if prop := s.Properties[name]; prop != nil {
+			if prop.Value != nil {
+				v.check(joinPath(path, name), prop.Value, value)
+			}
+			continue
+		}
+
+		// 未声明的字段: 默认允许，additionalProperties 为 false 时拒绝，为 Schema 时按其校验
+		switch additional := s.AdditionalProperties; {
+		case additional.Has != nil && !*additional.Has:
+			v.add(joinPath(path, name), "未知参数，允许的参数: %s", strings.Join(propertyNames(s), ", "))
+		case additional.Schema != nil && additional.Schema.Value != nil:
+			v.check(joinPath(path, name), additional.Schema.Value, value)
+		}
+	}
+}
+
+// ================================
+// 辅助函数
+// ================================
+
+// patternCache 缓存编译后的正则表达式，同一个工具的参数定义会被反复使用
+var patternCache sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+// joinPath 拼接参数路径，根对象的字段不带 $ 前缀
+func joinPath(parent, name string) string {
+	if parent == "$" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// isInteger 判断数字是否为整数 (1.0 也视为整数)
+func isInteger(n json.Number) bool {
+	if _, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+		return true
+	}
+	f, err := n.Float64()
+	return err == nil && f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+// inEnum 判断值是否在枚举中。数字按数值比较，因为枚举值可能是 float64 或 int。
+func inEnum(enum []any, value any) bool {
+	for _, e := range enum {
+		if equalValue(normalize(e), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize 把参数定义中的值转换为与解析结果相同的表示 (数字为 json.Number)
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	out, err := decodeArguments(string(data))
+	if err != nil {
+		return v
+	}
+	return out
+}
+
+// equalValue 比较两个解析后的 JSON 值，数字按数值比较
+func equalValue(a, b any) bool {
+	if na, ok := a.(json.Number); ok {
+		nb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// propertyNames 返回对象声明的字段名称 (排序后)
+func propertyNames(s *openapi3.Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// typeName 返回类型的中文名称
+func typeName(typ string) string {
+	switch typ {
+	case openapi3.TypeObject:
+		return "对象 (object)"
+	case openapi3.TypeArray:
+		return "数组 (array)"
+	case openapi3.TypeString:
+		return "字符串 (string)"
+	case openapi3.TypeBoolean:
+		return "布尔值 (boolean)"
+	case openapi3.TypeNumber:
+		return "数字 (number)"
+	case openapi3.TypeInteger:
+		return "整数 (integer)"
+	default:
+		return typ
+	}
+}
+
+// jsonTypeName 返回解析后 JSON 值的类型名称
+func jsonTypeName(value any) string {
+	switch val := value.(type) {
+	case map[string]any:
+		return typeName(openapi3.TypeObject)
+	case []any:
+		return typeName(openapi3.TypeArray)
+	case string:
+		return typeName(openapi3.TypeString)
+	case bool:
+		return typeName(openapi3.TypeBoolean)
+	case json.Number:
+		if isInteger(val) {
+			return typeName(openapi3.TypeInteger)
+		}
+		return typeName(openapi3.TypeNumber)
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// formatValue 把值格式化为 JSON，用于错误描述
+func formatValue(value any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func formatEnum(enum []any) string {
+	parts := make([]string, 0, len(enum))
+	for _, e := range enum {
+		parts = append(parts, formatValue(e))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,138 @@
+package sqlquery
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/sqlquery/demo.go
+//  功能: 生成演示用的商品数据库，用于离线演示和测试。
+//  数据: categories (类目)、products (商品)、customers (客户)、orders (订单)、order_items (订单明细)
+//        和视图 product_sales (每个商品的销量和销售额)。数据由固定的规则生成，每次生成的内容相同。
+//
+// =============================================================================
+
+// demoSchema 演示数据库的表结构
+const demoSchema = `
+CREATE TABLE categories (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE products (
+	id          INTEGER PRIMARY KEY,
+	name        TEXT NOT NULL,
+	category_id INTEGER NOT NULL REFERENCES categories(id),
+	price       REAL NOT NULL,
+	stock       INTEGER NOT NULL DEFAULT 0,
+	launched_on TEXT NOT NULL
+);
+CREATE TABLE customers (
+	id    INTEGER PRIMARY KEY,
+	name  TEXT NOT NULL,
+	city  TEXT NOT NULL
+);
+CREATE TABLE orders (
+	id          INTEGER PRIMARY KEY,
+	customer_id INTEGER NOT NULL REFERENCES customers(id),
+	status      TEXT NOT NULL CHECK (status IN ('paid', 'shipped', 'cancelled')),
+	created_at  TEXT NOT NULL
+);
+CREATE TABLE order_items (
+	order_id   INTEGER NOT NULL REFERENCES orders(id),
+	product_id INTEGER NOT NULL REFERENCES products(id),
+	quantity   INTEGER NOT NULL,
+	unit_price REAL NOT NULL,
+	PRIMARY KEY (order_id, product_id)
+);
+CREATE VIEW product_sales AS
+	SELECT p.id AS product_id, p.name, sum(i.quantity) AS units, round(sum(i.quantity * i.unit_price), 2) AS revenue
+	FROM products p JOIN order_items i ON i.product_id = p.id JOIN orders o ON o.id = i.order_id
+	WHERE o.status != 'cancelled'
+	GROUP BY p.id;
+`
+
+// demoCategories 类目及其商品名称
+var demoCategories = []struct {
+	name     string
+	products []string
+}{
+	{"手机", []string{"星辰 X1", "星辰 X1 Pro", "青竹 S5", "青竹 S5 青春版", "极光 Mini"}},
+	{"电脑", []string{"轻薄本 Air 13", "轻薄本 Air 15", "游戏本 Storm", "工作站 Titan"}},
+	{"家电", []string{"扫地机器人 R3", "空气净化器 P2", "电饭煲 C1", "咖啡机 Brew", "加湿器 H1", "台灯 L2"}},
+	{"图书", []string{"Go 语言实战", "深入理解大模型", "向量数据库入门", "RAG 应用开发", "算法图解"}},
+}
+
+// demoCities 客户所在城市
+var demoCities = []string{"北京", "上海", "广州", "深圳", "杭州", "成都"}
+
+// CreateDemoDB 在 path 创建演示用的商品数据库，文件已经存在时返回错误
+func CreateDemoDB(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("数据库文件 %s 已经存在", path)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("创建数据库失败: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("创建数据库失败: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(demoSchema); err != nil {
+		return fmt.Errorf("创建表失败: %w", err)
+	}
+
+	// 商品: 价格和库存由序号决定
+	var prices []float64
+	productID := 0
+	launched := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	for c, category := range demoCategories {
+		if _, err := tx.Exec("INSERT INTO categories (id, name) VALUES (?, ?)", c+1, category.name); err != nil {
+			return fmt.Errorf("写入类目失败: %w", err)
+		}
+		for _, name := range category.products {
+			productID++
+			price := float64((productID*37)%90+10) * []float64{30, 80, 15, 1}[c]
+			stock := (productID * 53) % 120
+			if _, err := tx.Exec("INSERT INTO products (id, name, category_id, price, stock, launched_on) VALUES (?, ?, ?, ?, ?, ?)",
+				productID, name, c+1, price, stock, launched.AddDate(0, productID*2, 0).Format(time.DateOnly)); err != nil {
+				return fmt.Errorf("写入商品失败: %w", err)
+			}
+			prices = append(prices, price)
+		}
+	}
+
+	// 客户
+	names := []string{"张伟", "王芳", "李娜", "刘洋", "陈静", "杨磊", "赵敏", "黄强", "周婷", "吴昊", "徐丽", "孙鹏"}
+	for i, name := range names {
+		if _, err := tx.Exec("INSERT INTO customers (id, name, city) VALUES (?, ?, ?)", i+1, name, demoCities[i%len(demoCities)]); err != nil {
+			return fmt.Errorf("写入客户失败: %w", err)
+		}
+	}
+
+	// 订单: 2024 年每隔几天一笔，每笔 1-3 种商品
+	statuses := []string{"paid", "shipped", "shipped", "shipped", "cancelled"}
+	created := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	for id := 1; id <= 80; id++ {
+		customer := (id*7)%len(names) + 1
+		if _, err := tx.Exec("INSERT INTO orders (id, customer_id, status, created_at) VALUES (?, ?, ?, ?)",
+			id, customer, statuses[id%len(statuses)], created.Add(time.Duration(id*109)*time.Hour).Format(time.DateTime)); err != nil {
+			return fmt.Errorf("写入订单失败: %w", err)
+		}
+		for k := 0; k < id%3+1; k++ {
+			product := (id*11+k*5)%len(prices) + 1
+			if _, err := tx.Exec("INSERT INTO order_items (order_id, product_id, quantity, unit_price) VALUES (?, ?, ?, ?)",
+				id, product, (id+k)%4+1, prices[product-1]); err != nil {
+				return fmt.Errorf("写入订单明细失败: %w", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,367 @@
+package sqlquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/sqlquery/parse.go
+//  功能: 检查 SQL 是否为一条只读查询。
+//  说明: 先按 SQLite 的词法把 SQL 切分为记号 (字符串、带引号的标识符和注释中的内容不会被误认为关键字)，
+//        再按语法检查语句结构: 只能有一条语句；语句以 SELECT 或 VALUES 开头，或者是 WITH 子句加查询，
+//        每个公用表表达式 (CTE) 和括号中的子查询同样必须是查询；不允许参数占位符和危险函数。
+//        这里只检查语句类型，表名、列名是否存在由 SQLite 在执行时检查；数据库连接本身也是只读的，见 sqlquery.go。
+//
+// =============================================================================
+
+// ErrNotReadOnly SQL 不是一条只读查询
+var ErrNotReadOnly = errors.New("只允许执行一条 SELECT 查询")
+
+// deniedFunctions 查询中不允许调用的函数
+var deniedFunctions = map[string]bool{
+	"load_extension": true, // 加载动态库
+	"fts3_tokenizer": true, // 可以注册任意函数指针
+	"readfile":       true, // sqlite3 命令行提供的文件读写函数
+	"writefile":      true,
+	"edit":           true,
+	"randomblob":     true, // 按参数一次分配任意大小的内存
+	"zeroblob":       true,
+}
+
+// tokenKind 记号类型
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // 关键字或标识符
+	tokenQuoted                  // 带引号的标识符: "name"、`name`、[name]
+	tokenString                  // 字符串或 BLOB 字面量
+	tokenNumber                  // 数字
+	tokenSymbol                  // 运算符和标点
+)
+
+// token SQL 记号
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 在 SQL 中的字节偏移
+}
+
+// is 判断记号是否为指定的关键字 (不区分大小写) 或符号
+func (t token) is(text string) bool {
+	switch t.kind {
+	case tokenWord:
+		return strings.EqualFold(t.text, text)
+	case tokenSymbol:
+		return t.text == text
+	}
+	return false
+}
+
+// name 关键字或标识符的名称，带引号的标识符去掉引号 (SQLite 同样按去掉引号后的名称查找函数)
+func (t token) name() string {
+	switch t.kind {
+	case tokenWord:
+		return t.text
+	case tokenQuoted:
+		inner := t.text[1 : len(t.text)-1]
+		if t.text[0] == '[' {
+			return inner
+		}
+		quote := t.text[:1]
+		return strings.ReplaceAll(inner, quote+quote, quote)
+	}
+	return ""
+}
+
+// symbols 多字符运算符，较长的在前
+var symbols = []string{"->>", "->", "||", "<<", ">>", "<=", ">=", "==", "!=", "<>"}
+
+// tokenize 把 SQL 切分为记号，跳过空白和注释
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("位置 %d 的注释没有结束", i)
+			}
+			i += end + 4
+		case c == '\'':
+			end, err := quotedEnd(sql, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sql[i:end], pos: i})
+			i = end
+		case (c == 'x' || c == 'X') && i+1 < len(sql) && sql[i+1] == '\'':
+			end, err := quotedEnd(sql, i+1, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sql[i:end], pos: i})
+			i = end
+		case c == '"' || c == '`':
+			end, err := quotedEnd(sql, i, c)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: sql[i:end], pos: i})
+			i = end
+		case c == '[':
+			end := strings.IndexByte(sql[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("位置 %d 的标识符没有结束", i)
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: sql[i : i+end+1], pos: i})
+			i += end + 1
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			end := numberEnd(sql, i)
+			tokens = append(tokens, token{kind: tokenNumber, text: sql[i:end], pos: i})
+			i = end
+		case c == '?' || c == ':' || c == '@' || c == '$':
+			return nil, fmt.Errorf("位置 %d: 不支持参数占位符，请直接写入值", i)
+		default:
+			r, size := utf8.DecodeRuneInString(sql[i:])
+			if r == '_' || unicode.IsLetter(r) {
+				end := i + size
+				for end < len(sql) {
+					r, size := utf8.DecodeRuneInString(sql[end:])
+					if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+						break
+					}
+					end += size
+				}
+				tokens = append(tokens, token{kind: tokenWord, text: sql[i:end], pos: i})
+				i = end
+				continue
+			}
+			symbol := string(c)
+			for _, s := range symbols {
+				if strings.HasPrefix(sql[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if len(symbol) == 1 && strings.IndexByte("(),;.+-*/%<>=&|~", c) < 0 {
+				return nil, fmt.Errorf("位置 %d: 无法识别的字符 %q", i, r)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, pos: i})
+			i += len(symbol)
+		}
+	}
+	return tokens, nil
+}
+
+// quotedEnd 返回从 start 开始、以 quote 包围的内容的结束位置，两个连续的引号表示引号本身
+func quotedEnd(sql string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] != quote {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, fmt.Errorf("位置 %d 的引号没有结束", start)
+}
+
+// numberEnd 返回数字字面量的结束位置: 整数、小数、科学计数法和 0x 十六进制
+func numberEnd(sql string, start int) int {
+	i := start
+	if strings.HasPrefix(sql[i:], "0x") || strings.HasPrefix(sql[i:], "0X") {
+		i += 2
+		for i < len(sql) && strings.IndexByte("0123456789abcdefABCDEF_", sql[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+	for i < len(sql) && (sql[i] >= '0' && sql[i] <= '9' || sql[i] == '.' || sql[i] == '_') {
+		i++
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+			for i = j; i < len(sql) && sql[i] >= '0' && sql[i] <= '9'; i++ {
+			}
+		}
+	}
+	return i
+}
+
+// parser 按语法检查记号序列
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// checkReadOnly 检查 SQL 是否为一条只读查询，返回去掉末尾分号的语句
+func checkReadOnly(sql string) (string, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotReadOnly, err)
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].is(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("%w: SQL 为空", ErrNotReadOnly)
+	}
+
+	p := &parser{tokens: tokens}
+	if err := p.query(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotReadOnly, err)
+	}
+	if p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		if t.is(";") {
+			return "", fmt.Errorf("%w: 只能包含一条语句", ErrNotReadOnly)
+		}
+		return "", fmt.Errorf("%w: 位置 %d 多余的 %s", ErrNotReadOnly, t.pos, t.text)
+	}
+	end := tokens[len(tokens)-1]
+	return strings.TrimSpace(sql[:end.pos+len(end.text)]), nil
+}
+
+// peek 返回当前记号，已经结束时返回空记号
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{kind: tokenSymbol, pos: -1}
+}
+
+// expect 当前记号必须是 text
+func (p *parser) expect(text string) error {
+	t := p.peek()
+	if !t.is(text) {
+		return fmt.Errorf("%s 处应为 %s", describe(t), text)
+	}
+	p.pos++
+	return nil
+}
+
+// query 查询: [WITH [RECURSIVE] cte, ...] (SELECT | VALUES) ...，读到查询所在层级的右括号或分号为止
+func (p *parser) query() error {
+	if p.peek().is("WITH") {
+		p.pos++
+		if p.peek().is("RECURSIVE") {
+			p.pos++
+		}
+		for {
+			if err := p.cte(); err != nil {
+				return err
+			}
+			if !p.peek().is(",") {
+				break
+			}
+			p.pos++
+		}
+	}
+
+	t := p.peek()
+	if !t.is("SELECT") && !t.is("VALUES") {
+		if t.kind == tokenWord {
+			return fmt.Errorf("不允许 %s 语句", strings.ToUpper(t.text))
+		}
+		return fmt.Errorf("%s 处应为 SELECT", describe(t))
+	}
+	p.pos++
+	return p.body()
+}
+
+// cte 公用表表达式: name [(列, ...)] AS [[NOT] MATERIALIZED] (查询)
+func (p *parser) cte() error {
+	if t := p.peek(); t.kind != tokenWord && t.kind != tokenQuoted {
+		return fmt.Errorf("%s 处应为公用表表达式的名称", describe(t))
+	}
+	p.pos++
+	if p.peek().is("(") {
+		if err := p.group(false); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("AS"); err != nil {
+		return err
+	}
+	if p.peek().is("NOT") {
+		p.pos++
+	}
+	if p.peek().is("MATERIALIZED") {
+		p.pos++
+	}
+	if !p.peek().is("(") {
+		return fmt.Errorf("%s 处应为 (", describe(p.peek()))
+	}
+	return p.group(true)
+}
+
+// body 查询的其余部分，检查其中的函数调用和括号
+func (p *parser) body() error {
+	for p.pos < len(p.tokens) {
+		t := p.peek()
+		switch {
+		case t.is(")") || t.is(";"):
+			return nil
+		case t.is("("):
+			if err := p.group(false); err != nil {
+				return err
+			}
+		case (t.kind == tokenWord || t.kind == tokenQuoted) && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].is("(") &&
+			deniedFunctions[strings.ToLower(t.name())]:
+			return fmt.Errorf("不允许调用函数 %s", t.name())
+		default:
+			p.pos++
+		}
+	}
+	return nil
+}
+
+// group 括号中的内容。以 SELECT / VALUES / WITH 开头时按子查询检查，
+// 否则按表达式检查 (其中仍可能包含子查询)；mustQuery 为 true 时内容必须是查询。
+func (p *parser) group(mustQuery bool) error {
+	open := p.peek()
+	p.pos++
+	t := p.peek()
+	var err error
+	if mustQuery || t.is("SELECT") || t.is("VALUES") || t.is("WITH") {
+		err = p.query()
+	} else {
+		err = p.body()
+	}
+	if err != nil {
+		return err
+	}
+	if !p.peek().is(")") {
+		return fmt.Errorf("位置 %d 的括号没有闭合", open.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// describe 描述记号，用于错误信息
+func describe(t token) string {
+	if t.pos < 0 {
+		return "语句末尾"
+	}
+	return fmt.Sprintf("位置 %d 的 %s", t.pos, t.text)
+}
@@ -0,0 +1,50 @@
+package sqlquery
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		wantErr string // 为空时期望通过检查
+	}{
+		{name: "简单查询", sql: "SELECT * FROM products"},
+		{name: "末尾的分号", sql: "SELECT 1;"},
+		{name: "VALUES", sql: "VALUES (1, 2)"},
+		{name: "WITH 子句", sql: "WITH t AS (SELECT 1 AS x) SELECT x FROM t"},
+		{name: "字符串中的分号和关键字", sql: "SELECT name FROM products WHERE name = '; DELETE FROM products'"},
+		{name: "注释中的关键字", sql: "SELECT 1 -- DROP TABLE products"},
+		{name: "带引号的列名", sql: `SELECT "load_extension", [edit] FROM t`},
+		{name: "普通函数", sql: "SELECT upper(name), count(*) FROM products"},
+		{name: "DELETE", sql: "DELETE FROM orders", wantErr: "不允许 DELETE 语句"},
+		{name: "多条语句", sql: "SELECT 1; DROP TABLE products", wantErr: "只能包含一条语句"},
+		{name: "WITH 后接 UPDATE", sql: "WITH t AS (SELECT 1) UPDATE products SET price = 0", wantErr: "不允许 UPDATE 语句"},
+		{name: "参数占位符", sql: "SELECT * FROM products WHERE id = ?", wantErr: "不支持参数占位符"},
+		{name: "危险函数", sql: "SELECT load_extension('/tmp/evil.so')", wantErr: "不允许调用函数 load_extension"},
+		{name: "危险函数不区分大小写", sql: "SELECT LOAD_EXTENSION('/tmp/evil.so')", wantErr: "不允许调用函数 LOAD_EXTENSION"},
+		{name: "双引号函数名", sql: `SELECT "load_extension"('x')`, wantErr: "不允许调用函数 load_extension"},
+		{name: "反引号函数名", sql: "SELECT `randomblob`(1e9)", wantErr: "不允许调用函数 randomblob"},
+		{name: "方括号函数名", sql: "SELECT [zeroblob](1e9)", wantErr: "不允许调用函数 zeroblob"},
+		{name: "函数名与括号之间有注释", sql: `SELECT "readfile" /* x */ ('/etc/passwd')`, wantErr: "不允许调用函数 readfile"},
+		{name: "子查询中的危险函数", sql: "SELECT * FROM (SELECT `load_extension`('x'))", wantErr: "不允许调用函数 load_extension"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := checkReadOnly(tt.sql)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkReadOnly(%q) 返回错误: %v", tt.sql, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrNotReadOnly) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkReadOnly(%q) 错误 = %v，期望包含 %q 的 ErrNotReadOnly", tt.sql, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package sqlquery
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+//
+//  文件: tools/sqlquery/schema.go
+//  功能: 读取数据库结构 (表、视图、列、主键、外键和行数)，整理为给模型阅读的说明。
+//  说明: 结构在创建工具时读取一次，写入 ToolInfo 的描述和 text-to-SQL 的提示词；
+//        数据库结构变化后需要重新创建工具。
+//
+// =============================================================================
+
+// Table 表或视图的结构
+type Table struct {
+	Name    string    `json:"name"`
+	View    bool      `json:"view,omitempty"`
+	Rows    int64     `json:"rows"` // 行数，视图为 -1
+	Columns []*Column `json:"columns"`
+}
+
+// Column 列的结构
+type Column struct {
+	Name       string `json:"name"`
+	Type       string `json:"type,omitempty"`
+	NotNull    bool   `json:"not_null,omitempty"`
+	PrimaryKey bool   `json:"primary_key,omitempty"`
+	References string `json:"references,omitempty"` // 外键指向的 表.列
+}
+
+// loadSchema 读取数据库中所有的表和视图 (不含 sqlite_ 开头的内部表)
+func loadSchema(ctx context.Context, db *sql.DB) ([]*Table, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT name, type FROM sqlite_schema WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY type, name`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*Table
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, &Table{Name: name, View: typ == "view", Rows: -1})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, t := range tables {
+		if t.Columns, err = loadColumns(ctx, db, t.Name); err != nil {
+			return nil, fmt.Errorf("读取 %s 的列失败: %w", t.Name, err)
+		}
+		if t.View {
+			continue
+		}
+		if err := db.QueryRowContext(ctx, "SELECT count(*) FROM "+quoteIdent(t.Name)).Scan(&t.Rows); err != nil {
+			return nil, fmt.Errorf("统计 %s 的行数失败: %w", t.Name, err)
+		}
+	}
+	return tables, nil
+}
+
+// loadColumns 读取列和外键
+func loadColumns(ctx context.Context, db *sql.DB, table string) ([]*Column, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, type, \"notnull\", pk FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	var columns []*Column
+	byName := map[string]*Column{}
+	for rows.Next() {
+		c := &Column{}
+		var pk int
+		if err := rows.Scan(&c.Name, &c.Type, &c.NotNull, &pk); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		c.PrimaryKey = pk > 0
+		columns = append(columns, c)
+		byName[c.Name] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT "from", "table", coalesce("to", '') FROM pragma_foreign_key_list(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var from, target, to string
+		if err := rows.Scan(&from, &target, &to); err != nil {
+			return nil, err
+		}
+		if c, ok := byName[from]; ok {
+			if to == "" {
+				to = "主键"
+			}
+			c.References = target + "." + to
+		}
+	}
+	return columns, rows.Err()
+}
+
+// describeSchema 把结构整理为文本，每个表一行，如:
+// - products (120 行): id INTEGER 主键, name TEXT 非空, category_id INTEGER -> categories.id
+func describeSchema(tables []*Table) string {
+	var sb strings.Builder
+	for _, t := range tables {
+		if t.View {
+			fmt.Fprintf(&sb, "- 视图 %s: ", t.Name)
+		} else {
+			fmt.Fprintf(&sb, "- %s (%d 行): ", t.Name, t.Rows)
+		}
+		for i, c := range t.Columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(c.Name)
+			if c.Type != "" {
+				sb.WriteString(" " + c.Type)
+			}
+			if c.PrimaryKey {
+				sb.WriteString(" 主键")
+			} else if c.NotNull {
+				sb.WriteString(" 非空")
+			}
+			if c.References != "" {
+				sb.WriteString(" -> " + c.References)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// quoteIdent 给标识符加上双引号
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,276 @@
+package sqlquery
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	_ "modernc.org/sqlite" // 纯 Go 实现的 SQLite 驱动，不依赖 cgo
+)
+
+// =============================================================================
+//
+//  文件: tools/sqlquery/sqlquery.go
+//  功能: 查询本地 SQLite 数据库的 sql_query 工具，实现 tool.InvokableTool。
+//  安全:
+//    - 执行前解析 SQL，只允许一条只读查询 (见 parse.go)
+//    - 数据库以只读模式打开，并开启 query_only，即使检查有遗漏也无法写入
+//    - 限制返回的行数、单元格长度和执行时间 (超时后中断查询)
+//  说明: ToolInfo 的描述中包含数据库结构，模型不需要先查询表结构。
+//        SQL 有误 (语法错误、列不存在等) 或不是只读查询时不返回 error，而是把错误信息作为结果返回给模型修正；
+//        直接调用 Query 时，不是只读查询返回 ErrNotReadOnly。
+//
+// =============================================================================
+
+// 默认限制
+const (
+	DefaultMaxRows      = 200
+	DefaultMaxCellChars = 500
+	DefaultTimeout      = 5 * time.Second
+)
+
+// Config sql_query 工具的配置
+type Config struct {
+	// Path SQLite 数据库文件的路径 (必填)，文件必须已经存在
+	Path string
+	// Name 工具名称，默认 sql_query
+	Name string
+	// Description 附加在工具描述中的说明，如字段含义、金额单位
+	Description string
+
+	MaxRows      int           // 最多返回的行数，默认 DefaultMaxRows
+	MaxCellChars int           // 单元格最多的字符数，超出部分截断，默认 DefaultMaxCellChars
+	Timeout      time.Duration // 单次查询的超时时间，默认 DefaultTimeout
+}
+
+// Tool sql_query 工具
+type Tool struct {
+	config Config
+	db     *sql.DB
+	tables []*Table
+	schema string
+}
+
+// New 以只读模式打开数据库并读取结构
+func New(config *Config) (*Tool, error) {
+	if config == nil || config.Path == "" {
+		return nil, errors.New("必须配置数据库文件路径")
+	}
+	cfg := *config
+	if cfg.Name == "" {
+		cfg.Name = "sql_query"
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultMaxRows
+	}
+	if cfg.MaxCellChars <= 0 {
+		cfg.MaxCellChars = DefaultMaxCellChars
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if _, err := os.Stat(cfg.Path); err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+
+	dsn := (&url.URL{
+		Scheme:   "file",
+		OmitHost: true,
+		Path:     cfg.Path,
+		RawQuery: "mode=ro&_pragma=query_only(1)&_pragma=busy_timeout(2000)",
+	}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	tables, err := loadSchema(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("读取数据库结构失败: %w", err)
+	}
+	log.Printf("[SQLQuery] 打开数据库 %s: %d 个表和视图", cfg.Path, len(tables))
+
+	return &Tool{config: cfg, db: db, tables: tables, schema: describeSchema(tables)}, nil
+}
+
+// Close 关闭数据库
+func (t *Tool) Close() error {
+	return t.db.Close()
+}
+
+// Tables 返回数据库中的表和视图
+func (t *Tool) Tables() []*Table {
+	return t.tables
+}
+
+// Schema 返回数据库结构的文本说明，每个表一行
+func (t *Tool) Schema() string {
+	return t.schema
+}
+
+// Info 返回工具的元信息和参数定义，描述中包含数据库结构
+func (t *Tool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	desc := fmt.Sprintf("在本地 SQLite 数据库上执行一条只读的 SELECT 查询 (可以使用 WITH 子句)，适合统计数量、列出和筛选数据。"+
+		"结果最多返回 %d 行，需要更多时请使用聚合或分页 (LIMIT / OFFSET)。", t.config.MaxRows)
+	if t.config.Description != "" {
+		desc += t.config.Description
+	}
+	desc += "\n数据库结构:\n" + t.schema
+
+	return &schema.ToolInfo{
+		Name: t.config.Name,
+		Desc: desc,
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"sql": {
+				Type:     schema.String,
+				Desc:     "SQLite 方言的 SELECT 语句，不支持参数占位符",
+				Required: true,
+			},
+		}),
+	}, nil
+}
+
+// Result 查询结果，rows 中每一行的值与 columns 一一对应
+type Result struct {
+	SQL       string   `json:"sql"`
+	Columns   []string `json:"columns"`
+	Rows      [][]any  `json:"rows"`
+	RowCount  int      `json:"row_count"`
+	Truncated bool     `json:"truncated,omitempty"` // 结果超过行数限制，只返回了前 MaxRows 行
+	ElapsedMS int64    `json:"elapsed_ms"`
+}
+
+// queryFailure SQL 执行失败时返回给模型的结果
+type queryFailure struct {
+	SQL   string `json:"sql"`
+	Error string `json:"error"`
+	Hint  string `json:"hint"`
+}
+
+// InvokableRun 执行查询，返回 Result 的 JSON
+func (t *Tool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var req struct {
+		SQL string `json:"sql"`
+	}
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	var output any
+	result, err := t.Query(ctx, req.SQL)
+	switch {
+	case errors.Is(err, ErrNotReadOnly):
+		output = &queryFailure{SQL: req.SQL, Error: err.Error(), Hint: "只能执行一条只读的 SELECT 查询 (可以使用 WITH 子句)，请改写为查询；需要修改数据时告诉用户无法执行"}
+	case err != nil:
+		output = &queryFailure{SQL: req.SQL, Error: err.Error(), Hint: "请根据错误信息和数据库结构修改 SQL 后重试"}
+	default:
+		output = result
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		return "", fmt.Errorf("结果序列化失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// Query 检查并执行查询。不是只读查询时返回 ErrNotReadOnly，超时时返回 context.DeadlineExceeded。
+func (t *Tool) Query(ctx context.Context, query string) (*Result, error) {
+	stmt, err := checkReadOnly(query)
+	if err != nil {
+		log.Printf("[SQLQuery] 拒绝: %v", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.config.Timeout)
+	defer cancel()
+	start := time.Now()
+	log.Printf("[SQLQuery] 执行: %s", stmt)
+
+	rows, err := t.db.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, t.queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, t.queryError(ctx, err)
+	}
+	result := &Result{SQL: stmt, Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		if len(result.Rows) == t.config.MaxRows {
+			result.Truncated = true
+			break
+		}
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, t.queryError(ctx, err)
+		}
+		for i, v := range values {
+			values[i] = t.cell(v)
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, t.queryError(ctx, err)
+	}
+
+	result.RowCount = len(result.Rows)
+	result.ElapsedMS = time.Since(start).Milliseconds()
+	log.Printf("[SQLQuery] 返回 %d 行, 截断: %v, 耗时 %dms", result.RowCount, result.Truncated, result.ElapsedMS)
+	return result, nil
+}
+
+// queryError 整理执行错误，超时时给出明确的说明
+func (t *Tool) queryError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("查询超过 %s 未完成，请缩小查询范围: %w", t.config.Timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// cell 把单元格的值转换为 JSON 友好的类型，并截断过长的文本
+func (t *Tool) cell(v any) any {
+	switch x := v.(type) {
+	case []byte:
+		if !utf8.Valid(x) {
+			return fmt.Sprintf("[BLOB: %d 字节]", len(x))
+		}
+		return t.truncate(string(x))
+	case string:
+		return t.truncate(x)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			// JSON 不能表示无穷大和 NaN (如 1e999 的结果)，转换为 +Inf、-Inf、NaN 字符串
+			return strconv.FormatFloat(x, 'g', -1, 64)
+		}
+		return x
+	default:
+		return v
+	}
+}
+
+// truncate 截断超过 MaxCellChars 个字符的文本
+func (t *Tool) truncate(s string) string {
+	if utf8.RuneCountInString(s) <= t.config.MaxCellChars {
+		return s
+	}
+	return string([]rune(s)[:t.config.MaxCellChars]) + "…"
+}
@@ -0,0 +1,215 @@
+package sqlquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/sqlquery/text2sql.go
+//  功能: text-to-SQL 链，把自然语言问题转换为 SQL 并执行，出错时让模型修正。
+//  流程: prompt (问题 + 数据库结构) -> model (起草 SQL) -> execute (检查并执行)
+//        -> 成功或达到最大尝试次数时结束，否则 -> repair (把错误信息反馈给模型) -> model ...
+//  说明: 使用 compose.Graph 的分支和环实现修正循环，对话历史保存在图的本地状态中，
+//        每次修正时模型都能看到之前写过的 SQL 和对应的错误。
+//
+// =============================================================================
+
+// DefaultMaxAttempts 默认的最大尝试次数 (第一次起草 + 修正)
+const DefaultMaxAttempts = 3
+
+// 图中节点的名称
+const (
+	nodePrompt  = "prompt"
+	nodeModel   = "model"
+	nodeExecute = "execute"
+	nodeRepair  = "repair"
+)
+
+// Text2SQLConfig text-to-SQL 链的配置
+type Text2SQLConfig struct {
+	Model       model.BaseChatModel // 起草和修正 SQL 的模型 (必填)
+	Query       *Tool               // 执行 SQL 的工具 (必填)，提示词中的数据库结构也来自这里
+	MaxAttempts int                 // 最多执行几次 SQL，默认 DefaultMaxAttempts
+}
+
+// Attempt 一次执行 SQL 的记录
+type Attempt struct {
+	SQL   string `json:"sql"`
+	Error string `json:"error,omitempty"`
+}
+
+// Answer 问题的查询结果。所有尝试都失败时 Result 为空，Error 为最后一次的错误。
+type Answer struct {
+	Question string     `json:"question"`
+	SQL      string     `json:"sql,omitempty"` // 成功执行的 SQL
+	Result   *Result    `json:"result,omitempty"`
+	Attempts []*Attempt `json:"attempts"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// text2sqlState 图的本地状态，保存对话历史和每次尝试的结果
+type text2sqlState struct {
+	messages []*schema.Message
+	answer   *Answer
+}
+
+// Text2SQL text-to-SQL 链
+type Text2SQL struct {
+	config   Text2SQLConfig
+	runnable compose.Runnable[string, *Answer]
+}
+
+// sqlFence 模型回复中的 SQL 代码块
+var sqlFence = regexp.MustCompile("(?is)```(?:sql|sqlite)?[ \t]*\n(.*?)```")
+
+// NewText2SQL 创建并编译 text-to-SQL 链
+func NewText2SQL(ctx context.Context, config *Text2SQLConfig) (*Text2SQL, error) {
+	if config == nil || config.Model == nil || config.Query == nil {
+		return nil, errors.New("必须配置模型和 sql_query 工具")
+	}
+	cfg := *config
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	t := &Text2SQL{config: cfg}
+
+	g := compose.NewGraph[string, *Answer](compose.WithGenLocalState(func(ctx context.Context) *text2sqlState {
+		return &text2sqlState{}
+	}))
+	_ = g.AddLambdaNode(nodePrompt, compose.InvokableLambda(t.prompt))
+	_ = g.AddChatModelNode(nodeModel, cfg.Model)
+	_ = g.AddLambdaNode(nodeExecute, compose.InvokableLambda(t.execute))
+	_ = g.AddLambdaNode(nodeRepair, compose.InvokableLambda(t.repair))
+
+	_ = g.AddEdge(compose.START, nodePrompt)
+	_ = g.AddEdge(nodePrompt, nodeModel)
+	_ = g.AddEdge(nodeModel, nodeExecute)
+	_ = g.AddBranch(nodeExecute, compose.NewGraphBranch(t.next, map[string]bool{compose.END: true, nodeRepair: true}))
+	_ = g.AddEdge(nodeRepair, nodeModel)
+
+	// 每次尝试经过 model、execute、repair 三个节点
+	runnable, err := g.Compile(ctx,
+		compose.WithGraphName("text2sql"),
+		compose.WithNodeTriggerMode(compose.AnyPredecessor),
+		compose.WithMaxRunSteps(cfg.MaxAttempts*3+2))
+	if err != nil {
+		return nil, fmt.Errorf("编译 text-to-SQL 链失败: %w", err)
+	}
+	t.runnable = runnable
+	return t, nil
+}
+
+// Ask 回答问题。只有模型调用失败等无法继续的情况返回 error，SQL 始终无法执行时通过 Answer.Error 说明。
+func (t *Text2SQL) Ask(ctx context.Context, question string) (*Answer, error) {
+	log.Printf("[Text2SQL] 问题: %s", question)
+	answer, err := t.runnable.Invoke(ctx, question)
+	if err != nil {
+		return nil, fmt.Errorf("text-to-SQL 执行失败: %w", err)
+	}
+	if answer.Error != "" {
+		log.Printf("[Text2SQL] %d 次尝试后仍然失败: %s", len(answer.Attempts), answer.Error)
+	} else {
+		log.Printf("[Text2SQL] 第 %d 次尝试成功，返回 %d 行", len(answer.Attempts), answer.Result.RowCount)
+	}
+	return answer, nil
+}
+
+// prompt 生成包含数据库结构的提示词
+func (t *Text2SQL) prompt(ctx context.Context, question string) ([]*schema.Message, error) {
+	system := "你是 SQLite 专家，根据数据库结构把用户的问题转换为一条 SQL 查询。\n" +
+		"要求:\n" +
+		"- 只能使用 SELECT (可以使用 WITH 子句)，不能修改数据\n" +
+		"- 只使用下面列出的表和列，不要使用参数占位符\n" +
+		fmt.Sprintf("- 结果最多返回 %d 行，列出数据时加上合适的 ORDER BY 和 LIMIT\n", t.config.Query.config.MaxRows) +
+		"- 只输出 SQL，放在 ```sql 代码块中\n"
+	if t.config.Query.config.Description != "" {
+		system += "说明: " + t.config.Query.config.Description + "\n"
+	}
+	system += "数据库结构:\n" + t.config.Query.Schema()
+
+	messages := []*schema.Message{schema.SystemMessage(system), schema.UserMessage(question)}
+	err := compose.ProcessState(ctx, func(ctx context.Context, state *text2sqlState) error {
+		state.messages = messages
+		state.answer = &Answer{Question: question, Attempts: []*Attempt{}}
+		return nil
+	})
+	return messages, err
+}
+
+// execute 从模型回复中取出 SQL 并执行，记录本次尝试
+func (t *Text2SQL) execute(ctx context.Context, reply *schema.Message) (*Answer, error) {
+	query := extractSQL(reply.Content)
+	attempt := &Attempt{SQL: query}
+
+	var result *Result
+	var err error
+	if query == "" {
+		err = errors.New("回复中没有找到 SQL")
+	} else {
+		result, err = t.config.Query.Query(ctx, query)
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	var answer *Answer
+	stateErr := compose.ProcessState(ctx, func(ctx context.Context, state *text2sqlState) error {
+		state.messages = append(state.messages, schema.AssistantMessage(reply.Content, nil))
+		answer = state.answer
+		answer.Attempts = append(answer.Attempts, attempt)
+		if err != nil {
+			answer.Error = attempt.Error
+			return nil
+		}
+		answer.SQL, answer.Result, answer.Error = result.SQL, result, ""
+		return nil
+	})
+	log.Printf("[Text2SQL] 第 %d 次尝试: %s", len(answer.Attempts), oneLine(query))
+	return answer, stateErr
+}
+
+// next 执行成功或达到最大尝试次数时结束，否则修正
+func (t *Text2SQL) next(ctx context.Context, answer *Answer) (string, error) {
+	if answer.Result != nil || len(answer.Attempts) >= t.config.MaxAttempts {
+		return compose.END, nil
+	}
+	return nodeRepair, nil
+}
+
+// repair 把错误信息加入对话，让模型修正 SQL
+func (t *Text2SQL) repair(ctx context.Context, answer *Answer) ([]*schema.Message, error) {
+	last := answer.Attempts[len(answer.Attempts)-1]
+	feedback := fmt.Sprintf("执行上面的 SQL 出错:\n%s\n请根据错误信息和数据库结构修正，仍然只输出一条 SQL，放在 ```sql 代码块中。", last.Error)
+	log.Printf("[Text2SQL] 执行出错，请模型修正: %s", last.Error)
+
+	var messages []*schema.Message
+	err := compose.ProcessState(ctx, func(ctx context.Context, state *text2sqlState) error {
+		state.messages = append(state.messages, schema.UserMessage(feedback))
+		messages = state.messages
+		return nil
+	})
+	return messages, err
+}
+
+// extractSQL 取出回复中的 SQL: 优先取 ```sql 代码块，否则取整个回复
+func extractSQL(content string) string {
+	if m := sqlFence.FindStringSubmatch(content); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(content), "`"))
+}
+
+// oneLine 把 SQL 压缩为一行，用于日志
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
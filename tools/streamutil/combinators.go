@@ -0,0 +1,169 @@
+package streamutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/streamutil/combinators.go
+//  功能: schema.StreamReader 的组合函数: Map、Filter、Batch、Merge、Tee、Timeout。
+//  说明: 所有函数都接管传入的流: 返回的流读完或被关闭时，传入的流也会被关闭，
+//        调用方只需要关闭返回的流。上游的错误原样传给下游。
+//
+// =============================================================================
+
+// Map 对流中的每个数据块调用 fn。fn 返回错误时，该错误作为这一次 Recv 的错误返回。
+func Map[T, D any](sr *schema.StreamReader[T], fn func(T) (D, error)) *schema.StreamReader[D] {
+	return schema.StreamReaderWithConvert(sr, fn)
+}
+
+// Filter 只保留 keep 返回 true 的数据块
+func Filter[T any](sr *schema.StreamReader[T], keep func(T) bool) *schema.StreamReader[T] {
+	return schema.StreamReaderWithConvert(sr, func(chunk T) (T, error) {
+		if !keep(chunk) {
+			return chunk, schema.ErrNoValue
+		}
+		return chunk, nil
+	})
+}
+
+// Merge 合并多个流，数据块按到达顺序输出，所有流都结束后返回 EOF
+func Merge[T any](srs ...*schema.StreamReader[T]) *schema.StreamReader[T] {
+	if len(srs) == 0 {
+		return schema.StreamReaderFromArray[T](nil)
+	}
+	return schema.MergeStreamReaders(srs)
+}
+
+// Tee 把一个流复制为 n 个流，每个流都能读到完整的数据。
+// 每个副本都必须被读完或关闭；读得快的副本不会等待读得慢的副本，未读取的数据会保留在内存中。
+func Tee[T any](sr *schema.StreamReader[T], n int) []*schema.StreamReader[T] {
+	return sr.Copy(n)
+}
+
+// Batch 把数据块按 size 个一组合并输出。maxWait > 0 时，一组中的第一个数据块
+// 等待超过 maxWait 后即使不满 size 个也立即输出，避免上游较慢时下游长时间收不到数据。
+// 上游出错时先输出已经收到的数据，再返回错误。
+func Batch[T any](sr *schema.StreamReader[T], size int, maxWait time.Duration) *schema.StreamReader[[]T] {
+	size = max(size, 1)
+	return Produce(context.Background(), 0, func(ctx context.Context, emit func([]T) error) error {
+		items := pump(ctx, sr)
+
+		var batch []T
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		flush := func() error {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+			b := batch
+			batch = nil
+			return emit(b)
+		}
+
+		for {
+			select {
+			case it := <-items:
+				if errors.Is(it.err, io.EOF) {
+					return flush()
+				}
+				if it.err != nil {
+					if err := flush(); err != nil {
+						return err
+					}
+					return it.err
+				}
+				batch = append(batch, it.chunk)
+				if len(batch) == 1 && maxWait > 0 {
+					timer = time.NewTimer(maxWait)
+					timeout = timer.C
+				}
+				if len(batch) >= size {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			case <-timeout:
+				if err := flush(); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+}
+
+// Timeout 限制相邻两个数据块之间的等待时间 (含第一个数据块)，超过 d 没有收到数据时
+// 返回包装了 ErrTimeout 的错误并关闭上游。等待下游读取的时间不计入。
+func Timeout[T any](sr *schema.StreamReader[T], d time.Duration) *schema.StreamReader[T] {
+	return Produce(context.Background(), 0, func(ctx context.Context, emit func(T) error) error {
+		items := pump(ctx, sr)
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		for {
+			select {
+			case it := <-items:
+				if errors.Is(it.err, io.EOF) {
+					return nil
+				}
+				if it.err != nil {
+					return it.err
+				}
+				if err := emit(it.chunk); err != nil {
+					return err
+				}
+				timer.Reset(d)
+			case <-timer.C:
+				return fmt.Errorf("%w: %s 内没有收到数据", ErrTimeout, d)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+}
+
+// item 上游的一次 Recv 结果
+type item[T any] struct {
+	chunk T
+	err   error
+}
+
+// pump 在独立的 goroutine 中读取 sr，把每次 Recv 的结果发送到返回的通道，
+// 读到 EOF 或错误后停止。ctx 结束时关闭 sr，使阻塞中的 Recv 尽快返回。
+func pump[T any](ctx context.Context, sr *schema.StreamReader[T]) <-chan item[T] {
+	var once sync.Once
+	closeSrc := func() { once.Do(sr.Close) }
+
+	stop := context.AfterFunc(ctx, closeSrc)
+	items := make(chan item[T])
+	go func() {
+		defer stop()
+		defer closeSrc()
+		for {
+			chunk, err := sr.Recv()
+			select {
+			case items <- item[T]{chunk, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return items
+}
@@ -0,0 +1,137 @@
+package streamutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/streamutil/producer.go
+//  功能: 把"在 goroutine 中逐个产生数据"的代码转换为 schema.StreamReader。
+//  说明: Produce 统一处理流式工具中反复出现的问题:
+//        1. 取消: ctx 结束或读取方关闭流后，emit 返回错误，生产函数据此退出
+//        2. 背压: 缓冲区满时 emit 阻塞，直到读取方取走数据 (或 ctx 结束)
+//        3. 错误: 生产函数返回的错误在所有已发送的数据之后送达读取方，不会丢失
+//        4. 关闭顺序: 数据、错误、EOF 依次送达，写入端只关闭一次；生产函数 panic 时转换为错误
+//
+// =============================================================================
+
+var (
+	// ErrClosed 读取方已经关闭了流，生产函数应该停止产生数据
+	ErrClosed = errors.New("流已被读取方关闭")
+	// ErrTimeout 等待上游数据超时 (见 Timeout)
+	ErrTimeout = errors.New("等待流数据超时")
+)
+
+// ProducerFunc 生产函数，通过 emit 逐个发送数据。
+// emit 返回错误 (ErrClosed 或 ctx 的错误) 时应该尽快返回；返回 nil 表示正常结束，
+// 返回其他错误时该错误会作为最后一个数据块发送给读取方。
+type ProducerFunc[T any] func(ctx context.Context, emit func(T) error) error
+
+// Produce 在独立的 goroutine 中运行 fn，返回读取 fn 所发送数据的流。
+// bufSize 是缓冲的数据块数量，缓冲区满时 emit 阻塞，直到读取方取走数据 (背压)。
+// 读取方关闭流后，fn 在下一次 emit 时得到 ErrClosed，同时传给 fn 的 ctx 被取消。
+// (Pipe 不会主动通知读取方关闭，长时间不 emit 的 fn 应该同时监听调用方的 ctx。)
+func Produce[T any](ctx context.Context, bufSize int, fn ProducerFunc[T]) *schema.StreamReader[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	sr, sw := schema.Pipe[T](0)
+
+	items := make(chan T, max(bufSize, 0))
+	gone := make(chan struct{}) // 读取方关闭了流
+	var result error            // fn 的返回值，在 items 关闭前写入
+
+	emit := func(chunk T) error {
+		select {
+		case <-gone:
+			return ErrClosed
+		default:
+		}
+		select {
+		case items <- chunk:
+			return nil
+		case <-gone:
+			return ErrClosed
+		case <-ctx.Done():
+			select {
+			case <-gone:
+				return ErrClosed
+			default:
+				return ctx.Err()
+			}
+		}
+	}
+
+	// 生产者
+	go func() {
+		defer close(items)
+		defer func() {
+			if r := recover(); r != nil {
+				result = fmt.Errorf("流生产函数 panic: %v", r)
+			}
+		}()
+		result = fn(ctx, emit)
+	}()
+
+	// 转发者: 把缓冲区中的数据依次写入流，最后写入错误并关闭写入端
+	go func() {
+		defer cancel()
+		defer sw.Close()
+		for chunk := range items {
+			if closed := sw.Send(chunk, nil); closed {
+				close(gone)
+				return
+			}
+		}
+		if result != nil && !errors.Is(result, ErrClosed) {
+			var zero T
+			sw.Send(zero, result)
+		}
+	}()
+
+	return sr
+}
+
+// Sleep 等待 d 或 ctx 结束，ctx 结束时返回 ctx 的错误。用于生产函数中模拟耗时或限速。
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Collect 读取流中的所有数据并关闭流，遇到错误时返回已读取的数据和该错误
+func Collect[T any](sr *schema.StreamReader[T]) ([]T, error) {
+	defer sr.Close()
+
+	var chunks []T
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+// Join 读取字符串流中的所有数据并拼接，用于在 InvokableRun 中复用 StreamableRun 的实现
+func Join(sr *schema.StreamReader[string]) (string, error) {
+	chunks, err := Collect(sr)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(chunks, ""), nil
+}
@@ -0,0 +1,221 @@
+package textutil
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/longbridgeapp/opencc"
+	"github.com/mozillazg/go-pinyin"
+	"golang.org/x/text/unicode/norm"
+)
+
+// =============================================================================
+//
+//  文件: tools/textutil/convert.go
+//  功能: 全角 / 半角转换、简繁转换、汉字转拼音和 slug 生成。
+//  说明: 简繁转换使用 OpenCC 的词典 (按词组转换，如 "头发" -> "頭髮"、"发展" -> "發展")，
+//        转换器在第一次使用时加载。拼音按单字注音，多音字取最常用的读音，并在结果中列出全部读音。
+//
+// =============================================================================
+
+// ToFullWidth 把 ASCII 字母、数字、标点和空格转换为全角 (Ａ、１、！、全角空格)，其他字符不变
+func ToFullWidth(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '\u3000'
+		case r > ' ' && r < unicode.MaxASCII:
+			return r - '!' + '！'
+		}
+		return r
+	}, text)
+}
+
+// ToHalfWidth 把全角字母、数字、标点和全角空格转换为 ASCII，其他字符不变。
+// 中文特有的标点 (。、「」等) 没有对应的 ASCII 字符，保持不变。
+func ToHalfWidth(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u3000':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - '！' + '!'
+		}
+		return r
+	}, text)
+}
+
+// 简繁转换方向，与 OpenCC 的配置名称一致
+const (
+	S2T  = "s2t"  // 简体 -> 繁体
+	T2S  = "t2s"  // 繁体 -> 简体
+	S2TW = "s2tw" // 简体 -> 台湾正体
+	TW2S = "tw2s" // 台湾正体 -> 简体
+	S2HK = "s2hk" // 简体 -> 香港繁体
+	HK2S = "hk2s" // 香港繁体 -> 简体
+
+	S2TWP = "s2twp" // 简体 -> 台湾正体，并转换台湾常用词汇 (软件 -> 軟體、鼠标 -> 滑鼠)
+	TW2SP = "tw2sp" // 台湾正体 -> 简体，并转换大陆常用词汇
+)
+
+var (
+	convertersMu sync.Mutex
+	converters   = make(map[string]*opencc.OpenCC)
+)
+
+// ConvertChinese 按 conversion (S2T、T2S 等) 进行简繁转换
+func ConvertChinese(text, conversion string) (string, error) {
+	switch conversion {
+	case S2T, T2S, S2TW, TW2S, S2HK, HK2S, S2TWP, TW2SP:
+	default:
+		return "", fmt.Errorf("不支持的简繁转换: %s", conversion)
+	}
+
+	convertersMu.Lock()
+	cc, ok := converters[conversion]
+	if !ok {
+		var err error
+		if cc, err = opencc.New(conversion); err != nil {
+			convertersMu.Unlock()
+			return "", fmt.Errorf("加载简繁转换词典失败: %w", err)
+		}
+		converters[conversion] = cc
+	}
+	convertersMu.Unlock()
+
+	result, err := cc.Convert(text)
+	if err != nil {
+		return "", fmt.Errorf("简繁转换失败: %w", err)
+	}
+	return result, nil
+}
+
+// ToTraditional 简体转繁体，使用台湾正体字形 (OpenCC 的 s2t 使用 "喫"、"麪" 等传统字形，日常较少见)
+func ToTraditional(text string) (string, error) {
+	return ConvertChinese(text, S2TW)
+}
+
+// ToSimplified 繁体转简体
+func ToSimplified(text string) (string, error) {
+	return ConvertChinese(text, T2S)
+}
+
+// 拼音风格
+const (
+	PinyinTone   = "tone"   // 声调符号: zhōng guó
+	PinyinNumber = "number" // 数字声调: zhong1 guo2
+	PinyinPlain  = "plain"  // 不带声调: zhong guo
+)
+
+// PinyinResult 汉字转拼音的结果
+type PinyinResult struct {
+	Pinyin     string      `json:"pinyin"`               // 汉字替换为拼音，音节之间用空格分隔，其他字符原样保留
+	Initials   string      `json:"initials"`             // 每个音节的首字母，其他字母和数字原样保留 (小写)
+	Polyphones []Polyphone `json:"polyphones,omitempty"` // 文本中的多音字
+}
+
+// Polyphone 多音字及其全部读音，第一个为结果中使用的读音
+type Polyphone struct {
+	Char     string   `json:"char"`
+	Readings []string `json:"readings"`
+}
+
+// Pinyin 把文本中的汉字转换为拼音，style 为空时使用 PinyinTone
+func Pinyin(text, style string) (*PinyinResult, error) {
+	args := pinyin.NewArgs()
+	switch style {
+	case PinyinTone, "":
+		args.Style = pinyin.Tone
+	case PinyinNumber:
+		args.Style = pinyin.Tone3
+	case PinyinPlain:
+		args.Style = pinyin.Normal
+	default:
+		return nil, fmt.Errorf("不支持的拼音风格: %s (可选 %s、%s、%s)", style, PinyinTone, PinyinNumber, PinyinPlain)
+	}
+	args.Heteronym = true
+
+	var (
+		result     PinyinResult
+		out        strings.Builder
+		initials   strings.Builder
+		afterSyl   bool // 上一个写入的是拼音音节
+		seenPoly   = make(map[rune]bool)
+		plainStyle = pinyin.NewArgs()
+	)
+	for _, r := range text {
+		readings := pinyin.SinglePinyin(r, args)
+		if len(readings) == 0 {
+			// 非汉字: 音节和紧跟的字母、数字之间补一个空格，标点和空白直接拼接
+			if afterSyl && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				out.WriteByte(' ')
+			}
+			out.WriteRune(r)
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				initials.WriteRune(unicode.ToLower(r))
+			}
+			afterSyl = false
+			continue
+		}
+
+		if out.Len() > 0 && !endsWithSpaceOrOpen(out.String()) {
+			out.WriteByte(' ')
+		}
+		out.WriteString(readings[0])
+		if plain := pinyin.SinglePinyin(r, plainStyle); len(plain) > 0 && plain[0] != "" {
+			initials.WriteByte(plain[0][0])
+		}
+		afterSyl = true
+
+		if len(readings) > 1 && !seenPoly[r] {
+			seenPoly[r] = true
+			result.Polyphones = append(result.Polyphones, Polyphone{Char: string(r), Readings: readings})
+		}
+	}
+	result.Pinyin = out.String()
+	result.Initials = initials.String()
+	return &result, nil
+}
+
+// endsWithSpaceOrOpen 判断文本是否以空白或左括号、左引号结尾 (其后的音节不需要补空格)
+func endsWithSpaceOrOpen(s string) bool {
+	last, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(last) || unicode.Is(unicode.Ps, last) || unicode.Is(unicode.Pi, last)
+}
+
+// Slugify 生成 URL 友好的 slug: 汉字转为不带声调的拼音，去掉字母上的附加符号 (é -> e)，
+// 全角字符转为半角，只保留小写 ASCII 字母和数字，其余部分用 separator 连接 (默认 "-")。
+// 没有拼音的其他文字 (如假名、西里尔字母) 会被去掉。
+func Slugify(text, separator string) string {
+	if separator == "" {
+		separator = "-"
+	}
+	var words []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			words = append(words, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range norm.NFKD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// 附加符号
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			cur.WriteRune(unicode.ToLower(r))
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, pinyin.NewArgs()); len(py) > 0 {
+				words = append(words, py[0])
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return strings.Join(words, separator)
+}
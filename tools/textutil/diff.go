@@ -0,0 +1,352 @@
+package textutil
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// =============================================================================
+//
+//  文件: tools/textutil/diff.go
+//  功能: 比较两段文本的差异，粒度可以是行、词或字符。
+//  算法: Myers 差分算法的线性空间版本 (每次找到编辑路径的 "中间蛇" 后递归处理两侧)，
+//        比较前先去掉公共前缀和后缀。词粒度下每个汉字 / 假名是一个词，连续的空白是一个词。
+//  输出: 合并后的 equal / delete / insert 片段、增删数量、相似度，行粒度时另外输出 unified diff。
+//
+// =============================================================================
+
+// 比较粒度
+const (
+	DiffLine = "line"
+	DiffWord = "word"
+	DiffChar = "char"
+)
+
+// 差异片段类型
+const (
+	OpEqual  = "equal"
+	OpDelete = "delete"
+	OpInsert = "insert"
+)
+
+// DiffOp 一个差异片段
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffResult 差异比较结果
+type DiffResult struct {
+	Mode       string   `json:"mode"`
+	Ops        []DiffOp `json:"ops"`
+	Insertions int      `json:"insertions"`        // 插入的行 / 词 / 字符数
+	Deletions  int      `json:"deletions"`         // 删除的行 / 词 / 字符数
+	Similarity float64  `json:"similarity"`        // 相同部分占比: 2 * 相同数 / (原文数 + 新文数)
+	Unified    string   `json:"unified,omitempty"` // unified diff，仅行粒度
+}
+
+// DiffContextLines unified diff 中每处修改前后保留的上下文行数
+const DiffContextLines = 3
+
+// Diff 比较 a (原文) 和 b (新文)，mode 为空时按行比较
+func Diff(a, b, mode string) (*DiffResult, error) {
+	if mode == "" {
+		mode = DiffLine
+	}
+	var ta, tb []string
+	switch mode {
+	case DiffLine:
+		ta, tb = splitLines(a), splitLines(b)
+	case DiffWord:
+		ta, tb = splitWords(a), splitWords(b)
+	case DiffChar:
+		ta, tb = splitChars(a), splitChars(b)
+	default:
+		return nil, fmt.Errorf("不支持的比较粒度: %s (可选 %s、%s、%s)", mode, DiffLine, DiffWord, DiffChar)
+	}
+
+	edits := groupChanges(diffTokens(ta, tb))
+	result := &DiffResult{Mode: mode, Ops: []DiffOp{}, Similarity: 1}
+	equal := 0
+	for _, e := range edits {
+		switch e.op {
+		case OpEqual:
+			equal++
+		case OpDelete:
+			result.Deletions++
+		case OpInsert:
+			result.Insertions++
+		}
+		if n := len(result.Ops); n > 0 && result.Ops[n-1].Op == e.op {
+			result.Ops[n-1].Text += e.text
+		} else {
+			result.Ops = append(result.Ops, DiffOp{Op: e.op, Text: e.text})
+		}
+	}
+	if total := len(ta) + len(tb); total > 0 {
+		result.Similarity = math.Round(float64(2*equal)/float64(total)*10000) / 10000
+	}
+	if mode == DiffLine && (result.Insertions > 0 || result.Deletions > 0) {
+		result.Unified = unifiedDiff(edits)
+	}
+	return result, nil
+}
+
+// edit 单个 token 的编辑
+type edit struct {
+	op   string
+	text string
+}
+
+// diffTokens 计算把 a 变为 b 的最短编辑序列
+func diffTokens(a, b []string) []edit {
+	// 公共前缀和后缀
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []edit
+	for _, t := range a[:prefix] {
+		edits = append(edits, edit{OpEqual, t})
+	}
+	edits = append(edits, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, t := range a[len(a)-suffix:] {
+		edits = append(edits, edit{OpEqual, t})
+	}
+	return edits
+}
+
+// diffMiddle 比较去掉公共前后缀后的部分
+func diffMiddle(a, b []string) []edit {
+	if len(a) > 0 && len(b) > 0 {
+		if x, y, ok := middleSnake(a, b); ok {
+			return append(diffTokens(a[:x], b[:y]), diffTokens(a[x:], b[y:])...)
+		}
+	}
+	// 一侧为空 (或没有找到中间蛇): 删除全部原文，插入全部新文
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, t := range a {
+		edits = append(edits, edit{OpDelete, t})
+	}
+	for _, t := range b {
+		edits = append(edits, edit{OpInsert, t})
+	}
+	return edits
+}
+
+// middleSnake 同时从两端搜索编辑路径，返回两条路径相遇处的分割点
+func middleSnake(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	size := 2*maxD + 3
+	v1, v2 := make([]int, size), make([]int, size)
+	for i := range v1 {
+		v1[i], v2[i] = -1, -1
+	}
+	v1[offset+1], v2[offset+1] = 0, 0
+	delta := n - m
+	front := delta%2 != 0 // delta 为奇数时在正向搜索中检查相遇
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+
+	for d := 0; d <= maxD; d++ {
+		// 正向
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			i := offset + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[i-1] < v1[i+1]) {
+				x1 = v1[i+1]
+			} else {
+				x1 = v1[i-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[i] = x1
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				j := offset + delta - k1
+				if j >= 0 && j < size && v2[j] != -1 && x1 >= n-v2[j] {
+					return x1, y1, true
+				}
+			}
+		}
+		// 反向
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			i := offset + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[i-1] < v2[i+1]) {
+				x2 = v2[i+1]
+			} else {
+				x2 = v2[i-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[i] = x2
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				j := offset + delta - k2
+				if j >= 0 && j < size && v1[j] != -1 {
+					x1 := v1[j]
+					y1 := x1 - (j - offset)
+					if x1 >= n-x2 {
+						return x1, y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// groupChanges 在每段连续的修改中把删除排在插入之前 ("-旧 +新")，便于阅读
+func groupChanges(edits []edit) []edit {
+	grouped := make([]edit, 0, len(edits))
+	var inserts []edit
+	for _, e := range edits {
+		switch e.op {
+		case OpInsert:
+			inserts = append(inserts, e)
+			continue
+		case OpEqual:
+			grouped = append(grouped, inserts...)
+			inserts = inserts[:0]
+		}
+		grouped = append(grouped, e)
+	}
+	return append(grouped, inserts...)
+}
+
+// splitLines 按行切分，每行保留行尾的换行符
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitWords 按词切分: 连续的字母数字、连续的空白各为一个词，汉字、假名和标点各自为一个词
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch r := runes[i]; {
+		case isWordRune(r):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		case unicode.IsSpace(r):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		words = append(words, string(runes[i:j]))
+		i = j
+	}
+	return words
+}
+
+// splitChars 按字符切分
+func splitChars(s string) []string {
+	chars := make([]string, 0, len(s))
+	for _, r := range s {
+		chars = append(chars, string(r))
+	}
+	return chars
+}
+
+// unifiedDiff 生成行粒度的 unified diff，每处修改前后保留 DiffContextLines 行上下文
+func unifiedDiff(edits []edit) string {
+	var sb strings.Builder
+	sb.WriteString("--- a\n+++ b\n")
+
+	// 每个编辑对应的原文 / 新文行号 (从 0 开始)
+	aLine, bLine := make([]int, len(edits)), make([]int, len(edits))
+	na, nb := 0, 0
+	for i, e := range edits {
+		aLine[i], bLine[i] = na, nb
+		if e.op != OpInsert {
+			na++
+		}
+		if e.op != OpDelete {
+			nb++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == OpEqual {
+			i++
+			continue
+		}
+		// 从第一处修改向前取上下文，向后合并间隔不超过 2 * DiffContextLines 的修改
+		start := max(0, i-DiffContextLines)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != OpEqual {
+				end = j
+			} else if j-end > 2*DiffContextLines {
+				break
+			}
+		}
+		end = min(len(edits), end+1+DiffContextLines)
+
+		var aCount, bCount int
+		for _, e := range edits[start:end] {
+			if e.op != OpInsert {
+				aCount++
+			}
+			if e.op != OpDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aLine[start], aCount), hunkRange(bLine[start], bCount))
+		for _, e := range edits[start:end] {
+			prefix := " "
+			switch e.op {
+			case OpDelete:
+				prefix = "-"
+			case OpInsert:
+				prefix = "+"
+			}
+			sb.WriteString(prefix + strings.TrimSuffix(e.text, "\n") + "\n")
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange unified diff 中的行范围，行号从 1 开始；空范围的行号为前一行
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
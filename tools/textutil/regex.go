@@ -0,0 +1,143 @@
+package textutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/textutil/regex.go
+//  功能: 正则提取和替换。
+//  说明: 使用 Go 的 RE2 语法 (线性时间匹配，不支持反向引用和环视)。
+//        匹配位置按字符 (Unicode 码点) 计算而不是字节，方便调用方对中文文本截取。
+//
+// =============================================================================
+
+// DefaultMaxMatches Extract 默认最多返回的匹配数
+const DefaultMaxMatches = 100
+
+// Match 一个正则匹配
+type Match struct {
+	Text   string            `json:"text"`
+	Start  int               `json:"start"`            // 起始字符位置 (从 0 开始)
+	End    int               `json:"end"`              // 结束字符位置 (不含)
+	Groups []string          `json:"groups,omitempty"` // 捕获组，按编号排列
+	Named  map[string]string `json:"named,omitempty"`  // 命名捕获组
+}
+
+// ExtractResult 正则提取结果
+type ExtractResult struct {
+	Pattern   string   `json:"pattern"`
+	Count     int      `json:"count"`     // 匹配总数
+	Truncated bool     `json:"truncated"` // 匹配数超过上限，只返回了前 maxMatches 个
+	Matches   []*Match `json:"matches"`
+}
+
+// ReplaceResult 正则替换结果
+type ReplaceResult struct {
+	Pattern string `json:"pattern"`
+	Result  string `json:"result"`
+	Count   int    `json:"count"` // 替换次数
+}
+
+// compilePattern 编译正则，错误信息中包含原始的正则
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("正则表达式不能为空")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("正则表达式 %s 无效: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Extract 提取所有匹配，maxMatches <= 0 时使用 DefaultMaxMatches
+func Extract(text, pattern string, maxMatches int) (*ExtractResult, error) {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if maxMatches <= 0 {
+		maxMatches = DefaultMaxMatches
+	}
+
+	locs := re.FindAllStringSubmatchIndex(text, -1)
+	result := &ExtractResult{Pattern: pattern, Count: len(locs), Matches: []*Match{}}
+	if len(locs) > maxMatches {
+		locs, result.Truncated = locs[:maxMatches], true
+	}
+
+	names := re.SubexpNames()
+	// 字节位置 -> 字符位置，匹配按顺序出现，从上一个位置继续计数
+	bytePos, runePos := 0, 0
+	toRune := func(b int) int {
+		runePos += utf8.RuneCountInString(text[bytePos:b])
+		bytePos = b
+		return runePos
+	}
+	for _, loc := range locs {
+		m := &Match{Text: text[loc[0]:loc[1]], Start: toRune(loc[0]), End: toRune(loc[1])}
+		for g := 1; g < len(names); g++ {
+			var s string
+			if loc[2*g] >= 0 {
+				s = text[loc[2*g]:loc[2*g+1]]
+			}
+			m.Groups = append(m.Groups, s)
+			if names[g] != "" {
+				if m.Named == nil {
+					m.Named = make(map[string]string)
+				}
+				m.Named[names[g]] = s
+			}
+		}
+		result.Matches = append(result.Matches, m)
+	}
+	return result, nil
+}
+
+// Replace 替换所有匹配。replacement 中可以使用 $1、${name} 引用捕获组，$$ 表示 $ 本身。
+func Replace(text, pattern, replacement string) (*ReplaceResult, error) {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	count := len(re.FindAllStringIndex(text, -1))
+	return &ReplaceResult{
+		Pattern: pattern,
+		Result:  re.ReplaceAllString(text, bracedGroupRefs(replacement)),
+		Count:   count,
+	}, nil
+}
+
+// bracedGroupRefs 把 $1 改写为 ${1}。Go 会把 "$1年" 解释为名为 "1年" 的捕获组 (结果为空)，
+// 紧跟中文或字母的编号引用在中文替换模板中很常见。
+func bracedGroupRefs(replacement string) string {
+	var sb strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c != '$' || i+1 == len(replacement) {
+			sb.WriteByte(c)
+			continue
+		}
+		if replacement[i+1] == '$' {
+			sb.WriteString("$$")
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(replacement) && replacement[j] >= '0' && replacement[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteString("${" + replacement[i+1:j] + "}")
+		i = j - 1
+	}
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package textutil
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/textutil/stats.go
+//  功能: 按 Unicode 字符 (而不是字节) 统计文本的字符、单词、句子、行和段落数，以及按字符反转文本。
+//  规则:
+//    - 汉字、平假名、片假名没有词间空格，每个字计为一个词 (与常见字处理软件的中文字数一致)
+//    - 拉丁字母、数字、谚文等连续书写的部分计为一个词，词内的 ' ’ - 和数字中的 . , 不断词 (don't、e-mail、3.14)
+//    - 句子以 。！？!?… 或后面不是字母、数字的 . 结束，连续的结束标点 (?!、……) 只算一次，
+//      没有结束标点的最后一段文字也算一句；3.14 之类的小数点不断句
+//
+// =============================================================================
+
+// Stats 文本统计结果
+type Stats struct {
+	Characters         int `json:"characters"`           // 字符数 (Unicode 码点)
+	CharactersNoSpaces int `json:"characters_no_spaces"` // 不含空白的字符数
+	Bytes              int `json:"bytes"`                // UTF-8 字节数
+	Words              int `json:"words"`                // 词数 = 中日文字数 + 其他单词数
+	CJKCharacters      int `json:"cjk_characters"`       // 汉字、假名数
+	OtherWords         int `json:"other_words"`          // 拉丁字母、数字、谚文等单词数
+	Punctuation        int `json:"punctuation"`          // 标点符号数
+	Sentences          int `json:"sentences"`            // 句子数
+	Lines              int `json:"lines"`                // 行数 (空文本为 0)
+	Paragraphs         int `json:"paragraphs"`           // 段落数，以空行分隔
+}
+
+var paragraphSeparator = regexp.MustCompile(`\n[ \t\r]*\n`)
+
+// Count 统计文本
+func Count(text string) *Stats {
+	s := &Stats{
+		Characters: utf8.RuneCountInString(text),
+		Bytes:      len(text),
+	}
+	if text == "" {
+		return s
+	}
+	s.Lines = strings.Count(text, "\n") + 1
+	if strings.HasSuffix(text, "\n") {
+		s.Lines--
+	}
+
+	runes := []rune(text)
+	inWord := false       // 是否在拉丁单词中
+	sentenceOpen := false // 当前句子是否已有内容
+	for i, r := range runes {
+		if !unicode.IsSpace(r) {
+			s.CharactersNoSpaces++
+		}
+		switch {
+		case IsCJK(r):
+			s.CJKCharacters++
+			inWord = false
+			sentenceOpen = true
+		case isWordRune(r):
+			if !inWord {
+				s.OtherWords++
+				inWord = true
+			}
+			sentenceOpen = true
+		case inWord && strings.ContainsRune("'’-", r) && i+1 < len(runes) && isWordRune(runes[i+1]):
+			// 词内的撇号和连字符，不断词
+		case inWord && strings.ContainsRune(".,", r) && unicode.IsDigit(runes[i-1]) && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			// 数字中的小数点和千位分隔符 (3.14、1,000)
+		default:
+			inWord = false
+		}
+
+		if unicode.IsPunct(r) {
+			s.Punctuation++
+		}
+		if sentenceOpen && isSentenceEnd(runes, i) {
+			s.Sentences++
+			sentenceOpen = false
+		}
+	}
+	if sentenceOpen {
+		s.Sentences++
+	}
+	s.Words = s.CJKCharacters + s.OtherWords
+
+	for _, para := range paragraphSeparator.Split(text, -1) {
+		if strings.TrimSpace(para) != "" {
+			s.Paragraphs++
+		}
+	}
+	return s
+}
+
+// Reverse 按字符反转文本，组合附加符号 (如 é 分解形式中的重音符号) 和变体选择符跟随前面的基本字符一起移动
+func Reverse(text string) string {
+	var clusters []string
+	for _, r := range text {
+		if len(clusters) > 0 && unicode.In(r, unicode.Mn, unicode.Me) {
+			clusters[len(clusters)-1] += string(r)
+			continue
+		}
+		clusters = append(clusters, string(r))
+	}
+	var sb strings.Builder
+	sb.Grow(len(text))
+	for i := len(clusters) - 1; i >= 0; i-- {
+		sb.WriteString(clusters[i])
+	}
+	return sb.String()
+}
+
+// IsCJK 判断字符是否为没有词间空格的中日文字: 汉字 (含扩展区)、平假名和片假名
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// isWordRune 组成单词的字符: 除中日文字外的字母、数字、组合附加符号
+func isWordRune(r rune) bool {
+	return !IsCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r))
+}
+
+// isSentenceEnd 判断 runes[i] 是否结束一个句子。连续的结束标点只在最后一个处结束。
+func isSentenceEnd(runes []rune, i int) bool {
+	r := runes[i]
+	var end bool
+	switch {
+	case strings.ContainsRune("。！？!?…", r):
+		end = true
+	case r == '.':
+		// 小数点和缩写中间的句点 (3.14、a.b) 不断句
+		end = i+1 == len(runes) || !isWordRune(runes[i+1])
+	}
+	if !end {
+		return false
+	}
+	// 后面紧跟结束标点时 (?!、……)，由最后一个结束
+	if i+1 < len(runes) && strings.ContainsRune("。！？!?….", runes[i+1]) {
+		return false
+	}
+	return true
+}
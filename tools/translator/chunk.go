@@ -0,0 +1,184 @@
+package translator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/translator/chunk.go
+//  功能: 长文本分块和基于文字系统的语言检测。
+//  分块: 优先在段落 (空行) 处切分，段落过长时在句末标点处切分，单句仍然过长时按字符数硬切分；
+//        相邻的小段合并到不超过 ChunkSize 个字符。块之间的空白原样保留，翻译后按原样拼接。
+//
+// =============================================================================
+
+// chunk 一个待翻译的文本块，sep 为该块之后的原始空白 (段落分隔、换行等)
+type chunk struct {
+	text string
+	sep  string
+}
+
+var paragraphSeparator = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// splitChunks 把文本切分为不超过 size 个字符的块 (单个字符过长的情况除外)。
+// 返回文本开头的空白和块列表，拼接 leading + 每块的 text + sep 等于原文。
+func splitChunks(text string, size int) (string, []chunk) {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	leading := text[:len(text)-len(trimmed)]
+
+	var pieces []chunk
+	for _, para := range splitKeep(trimmed, paragraphSeparator) {
+		if utf8.RuneCountInString(para.text) <= size {
+			pieces = append(pieces, para)
+			continue
+		}
+		sentences := splitSentences(para.text)
+		sentences[len(sentences)-1].sep += para.sep
+		for _, s := range sentences {
+			pieces = append(pieces, hardSplit(s, size)...)
+		}
+	}
+
+	// 合并相邻的小块
+	var chunks []chunk
+	var cur strings.Builder
+	curLen := 0
+	var curSep string
+	for _, p := range pieces {
+		n := utf8.RuneCountInString(p.text)
+		if cur.Len() > 0 && curLen+utf8.RuneCountInString(curSep)+n > size {
+			chunks = append(chunks, chunk{text: cur.String(), sep: curSep})
+			cur.Reset()
+			curLen = 0
+		} else if cur.Len() > 0 {
+			cur.WriteString(curSep)
+			curLen += utf8.RuneCountInString(curSep)
+		}
+		cur.WriteString(p.text)
+		curLen += n
+		curSep = p.sep
+	}
+	if cur.Len() > 0 || curSep != "" {
+		chunks = append(chunks, chunk{text: cur.String(), sep: curSep})
+	}
+	return leading, chunks
+}
+
+// splitKeep 按分隔符切分，分隔符保留在前一段的 sep 中
+func splitKeep(text string, sep *regexp.Regexp) []chunk {
+	var parts []chunk
+	start := 0
+	for _, loc := range sep.FindAllStringIndex(text, -1) {
+		parts = append(parts, chunk{text: text[start:loc[0]], sep: text[loc[0]:loc[1]]})
+		start = loc[1]
+	}
+	body := strings.TrimRightFunc(text[start:], unicode.IsSpace)
+	return append(parts, chunk{text: body, sep: text[start+len(body):]})
+}
+
+// splitSentences 在句末标点 (。！？!?；; 以及后面跟空白的 .) 和换行处切分句子
+func splitSentences(text string) []chunk {
+	var sentences []chunk
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		end := strings.ContainsRune("。！？!?；;\n", r) ||
+			(r == '.' && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])))
+		if !end {
+			continue
+		}
+		// 连续的标点和后面的空白属于同一个句子
+		j := i + 1
+		for j < len(runes) && strings.ContainsRune("。！？!?；;.”’\")」", runes[j]) {
+			j++
+		}
+		k := j
+		for k < len(runes) && unicode.IsSpace(runes[k]) {
+			k++
+		}
+		sentences = append(sentences, chunk{text: string(runes[start:j]), sep: string(runes[j:k])})
+		start, i = k, k-1
+	}
+	if start < len(runes) {
+		sentences = append(sentences, chunk{text: string(runes[start:])})
+	}
+	if len(sentences) == 0 {
+		sentences = append(sentences, chunk{})
+	}
+	return sentences
+}
+
+// hardSplit 按字符数切分过长的句子，尽量在空白处断开
+func hardSplit(c chunk, size int) []chunk {
+	runes := []rune(c.text)
+	if len(runes) <= size {
+		return []chunk{c}
+	}
+	var parts []chunk
+	for len(runes) > size {
+		cut := size
+		for i := size; i > size/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+		k := cut
+		for k < len(runes) && unicode.IsSpace(runes[k]) {
+			k++
+		}
+		parts = append(parts, chunk{text: string(runes[:cut]), sep: string(runes[cut:k])})
+		runes = runes[k:]
+	}
+	return append(parts, chunk{text: string(runes), sep: c.sep})
+}
+
+// DetectLanguage 根据文字系统推测语言代码: 含假名为 ja，含谚文为 ko，汉字为 zh，
+// 西里尔字母为 ru，阿拉伯字母为 ar，泰文为 th。拉丁字母无法区分具体语言，返回空字符串。
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.IsLetter(r):
+			counts[""]++
+		default:
+			continue
+		}
+		letters++
+	}
+	if letters == 0 {
+		return ""
+	}
+	// 日文通常夹杂汉字，只要假名占一定比例就认为是日文
+	if counts["ja"]*10 >= letters {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for lang, n := range counts {
+		if n > bestCount || (n == bestCount && lang < best) {
+			best, bestCount = lang, n
+		}
+	}
+	if bestCount*2 < letters {
+		return "" // 没有占多数的文字系统
+	}
+	return best
+}
@@ -0,0 +1,183 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/translator/glossary.go
+//  功能: 项目术语表，保证产品名称和专业术语在所有译文中使用统一的译法。
+//  格式: {"terms": [{"source": "ToolsNode", "targets": {"*": "ToolsNode"}, "note": "组件名，不翻译"},
+//                   {"source": "tool call", "targets": {"zh": "工具调用", "ja": "ツール呼び出し"}}]}
+//  说明: targets 的键为目标语言代码，"*" 表示所有目标语言 (通常用于保持原文不翻译)。
+//        只有原文中出现的术语会放入提示词，翻译完成后检查译文是否使用了规定的译法。
+//
+// =============================================================================
+
+// Term 术语表中的一个术语
+type Term struct {
+	Source        string            `json:"source"`                   // 原文中的术语
+	Targets       map[string]string `json:"targets"`                  // 目标语言 -> 规定译法，"*" 表示所有语言
+	Note          string            `json:"note,omitempty"`           // 给模型的说明，如 "产品名，不翻译"
+	CaseSensitive bool              `json:"case_sensitive,omitempty"` // 匹配原文时是否区分大小写，默认不区分
+}
+
+// Glossary 项目术语表
+type Glossary struct {
+	Terms []*Term `json:"terms"`
+}
+
+// TermUsage 一次翻译中用到的术语
+type TermUsage struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Note   string `json:"note,omitempty"`
+}
+
+// LoadGlossaryFile 从 JSON 文件加载术语表
+func LoadGlossaryFile(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取术语表失败: %w", err)
+	}
+	return LoadGlossary(data)
+}
+
+// LoadGlossary 解析 JSON 格式的术语表，未知字段视为错误
+func LoadGlossary(data []byte) (*Glossary, error) {
+	var g Glossary
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&g); err != nil {
+		return nil, fmt.Errorf("解析术语表失败: %w", err)
+	}
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// validate 检查术语表: 术语和译法不能为空，同一术语不能重复定义
+func (g *Glossary) validate() error {
+	seen := make(map[string]bool, len(g.Terms))
+	for i, t := range g.Terms {
+		if t == nil || strings.TrimSpace(t.Source) == "" {
+			return fmt.Errorf("术语表第 %d 项缺少 source", i+1)
+		}
+		if len(t.Targets) == 0 {
+			return fmt.Errorf("术语 %s 缺少 targets", t.Source)
+		}
+		for lang, target := range t.Targets {
+			if strings.TrimSpace(target) == "" {
+				return fmt.Errorf("术语 %s 的 %s 译法为空", t.Source, lang)
+			}
+		}
+		key := strings.ToLower(t.Source)
+		if seen[key] {
+			return fmt.Errorf("术语 %s 重复定义", t.Source)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Lookup 返回原文中出现的、在目标语言下有规定译法的术语，较长的术语优先 (避免 "tool" 抢先匹配 "tool call")
+func (g *Glossary) Lookup(text, toLang string) []TermUsage {
+	if g == nil {
+		return nil
+	}
+	var usages []TermUsage
+	rest := text
+	for _, t := range g.sortedTerms() {
+		target, ok := t.Targets[toLang]
+		if !ok {
+			target, ok = t.Targets["*"]
+		}
+		if !ok || !containsTerm(rest, t.Source, t.CaseSensitive) {
+			continue
+		}
+		usages = append(usages, TermUsage{Source: t.Source, Target: target, Note: t.Note})
+		// 去掉已匹配的术语，被更长术语包含的短术语只有单独出现时才列出
+		pattern := regexp.QuoteMeta(t.Source)
+		if !t.CaseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		rest = regexp.MustCompile(pattern).ReplaceAllString(rest, "\x00")
+	}
+	return usages
+}
+
+// sortedTerms 按术语长度从长到短排序
+func (g *Glossary) sortedTerms() []*Term {
+	terms := slices.Clone(g.Terms)
+	slices.SortStableFunc(terms, func(a, b *Term) int {
+		return utf8.RuneCountInString(b.Source) - utf8.RuneCountInString(a.Source)
+	})
+	return terms
+}
+
+// missingTerms 返回译文中没有使用规定译法的术语
+func missingTerms(translation string, usages []TermUsage) []TermUsage {
+	var missing []TermUsage
+	for _, u := range usages {
+		if !strings.Contains(strings.ToLower(translation), strings.ToLower(u.Target)) {
+			missing = append(missing, u)
+		}
+	}
+	return missing
+}
+
+// containsTerm 判断文本中是否出现术语。以字母或数字开头 / 结尾的拉丁术语要求完整单词匹配，
+// 避免 "API" 匹配到 "rapid"；中日韩术语直接按子串匹配。
+func containsTerm(text, term string, caseSensitive bool) bool {
+	if !caseSensitive {
+		text, term = strings.ToLower(text), strings.ToLower(term)
+	}
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		if boundary(text, start, term, true) && boundary(text, end, term, false) {
+			return true
+		}
+		offset = start + 1
+		for offset < len(text) && !utf8.RuneStart(text[offset]) {
+			offset++
+		}
+	}
+}
+
+// boundary 检查术语在 pos 处的边界: 术语该端是拉丁字母或数字时，相邻字符不能是拉丁字母或数字
+func boundary(text string, pos int, term string, before bool) bool {
+	var edge, neighbor rune
+	if before {
+		edge, _ = utf8.DecodeRuneInString(term)
+		if pos == 0 {
+			return true
+		}
+		neighbor, _ = utf8.DecodeLastRuneInString(text[:pos])
+	} else {
+		edge, _ = utf8.DecodeLastRuneInString(term)
+		if pos >= len(text) {
+			return true
+		}
+		neighbor, _ = utf8.DecodeRuneInString(text[pos:])
+	}
+	return !isWordRune(edge) || !isWordRune(neighbor)
+}
+
+// isWordRune 拉丁字母、数字和下划线 (中日韩文字没有单词边界)
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsDigit(r) || unicode.Is(unicode.Latin, r)
+}
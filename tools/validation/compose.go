@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+)
+
+// =============================================================================
+//
+//  文件: tools/validation/compose.go
+//  功能: 把校验规则接入 Eino 编排: 作为 Chain / Graph 中的 Lambda 节点，或作为 InferTool 工具。
+//
+// =============================================================================
+
+// Lambda 返回校验输入的 Lambda 节点: 校验通过时原样输出，不通过时返回 *ValidationError 中断执行
+func Lambda[T any](s *Schema, locale string) *compose.Lambda {
+	return compose.InvokableLambda(func(ctx context.Context, input T) (T, error) {
+		if err := s.Validate(input, locale); err != nil {
+			return input, err
+		}
+		return input, nil
+	})
+}
+
+// ToolRequest 校验工具的输入参数
+type ToolRequest struct {
+	Data   map[string]any `json:"data" jsonschema:"required,description=待校验的数据 (JSON 对象)"`
+	Locale string         `json:"locale,omitempty" jsonschema:"enum=zh,enum=en,description=错误消息的语言，默认 zh"`
+}
+
+// ToolResponse 校验工具的输出
+type ToolResponse struct {
+	Valid  bool          `json:"valid"`
+	Errors []*FieldError `json:"errors"`
+}
+
+// NewTool 把校验规则包装为工具 (基于 utils.InferTool)，工具描述中会附上各字段的规则。
+// 校验不通过不是工具错误，结果中 valid 为 false 并列出每个字段的错误。
+func NewTool(name, desc string, s *Schema) (tool.InvokableTool, error) {
+	return utils.InferTool(name, desc+"\n校验规则:\n"+s.Describe(), func(ctx context.Context, req *ToolRequest) (*ToolResponse, error) {
+		log.Printf("[Validation] 工具 %s 正在校验 %d 个字段", name, len(req.Data))
+
+		err := s.Validate(req.Data, req.Locale)
+		var verr *ValidationError
+		switch {
+		case err == nil:
+			return &ToolResponse{Valid: true, Errors: []*FieldError{}}, nil
+		case errors.As(err, &verr):
+			return &ToolResponse{Valid: false, Errors: verr.Errors}, nil
+		default:
+			return nil, err
+		}
+	})
+}
+
+// Describe 每行列出一个字段及其规则，如 "email (邮箱): required, email"
+func (s *Schema) Describe() string {
+	lines := make([]string, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		field := f.Field
+		if label := f.label(DefaultLocale, ""); label != "" {
+			field = fmt.Sprintf("%s (%s)", f.Field, label)
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", field, strings.Join(f.Rules, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
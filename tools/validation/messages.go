@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"maps"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+//
+//  文件: tools/validation/messages.go
+//  功能: 错误消息的多语言模板，内置中文 (zh，默认) 和英文 (en)。
+//  占位符: {field} 字段显示名称，{param} 规则参数 (跨字段规则为另一个字段的显示名称)，
+//         {min} / {max} range 的上下限，{options} enum 的可选值。
+//
+// =============================================================================
+
+// DefaultLocale 未指定语言或语言不支持时使用的语言
+const DefaultLocale = "zh"
+
+// localeMessages 一种语言的消息模板
+type localeMessages struct {
+	locale    string
+	separator string // enum 可选值的分隔符
+	templates map[string]string
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]*localeMessages{
+		"zh": {locale: "zh", separator: "、", templates: map[string]string{
+			RuleRequired:        "{field}不能为空",
+			RuleRequiredWith:    "填写{param}时{field}不能为空",
+			RuleRequiredWithout: "未填写{param}时{field}不能为空",
+			RuleLen:             "{field}的长度必须为 {param}",
+			RuleMinLen:          "{field}的长度不能少于 {param}",
+			RuleMaxLen:          "{field}的长度不能超过 {param}",
+			RuleMin:             "{field}不能小于 {param}",
+			RuleMax:             "{field}不能大于 {param}",
+			RuleRange:           "{field}必须在 {min} 到 {max} 之间",
+			RuleRegex:           "{field}的格式不正确",
+			RuleEmail:           "{field}格式不正确，应为有效的邮箱地址",
+			RuleCNMobile:        "{field}格式不正确，应为 11 位中国大陆手机号",
+			RuleCNIDCard:        "{field}无效，应为出生日期和校验码正确的 18 位居民身份证号",
+			RuleEnum:            "{field}必须是以下值之一: {options}",
+			RuleEqField:         "{field}必须与{param}一致",
+			RuleNeField:         "{field}不能与{param}相同",
+			RuleGtField:         "{field}必须大于{param}",
+			RuleGteField:        "{field}不能小于{param}",
+			RuleLtField:         "{field}必须小于{param}",
+			RuleLteField:        "{field}不能大于{param}",
+		}},
+		"en": {locale: "en", separator: ", ", templates: map[string]string{
+			RuleRequired:        "{field} is required",
+			RuleRequiredWith:    "{field} is required when {param} is present",
+			RuleRequiredWithout: "{field} is required when {param} is absent",
+			RuleLen:             "{field} must have a length of {param}",
+			RuleMinLen:          "{field} must have a length of at least {param}",
+			RuleMaxLen:          "{field} must have a length of at most {param}",
+			RuleMin:             "{field} must be at least {param}",
+			RuleMax:             "{field} must be at most {param}",
+			RuleRange:           "{field} must be between {min} and {max}",
+			RuleRegex:           "{field} has an invalid format",
+			RuleEmail:           "{field} must be a valid email address",
+			RuleCNMobile:        "{field} must be a valid mainland China mobile number",
+			RuleCNIDCard:        "{field} must be a valid Chinese resident ID card number",
+			RuleEnum:            "{field} must be one of: {options}",
+			RuleEqField:         "{field} must match {param}",
+			RuleNeField:         "{field} must differ from {param}",
+			RuleGtField:         "{field} must be greater than {param}",
+			RuleGteField:        "{field} must be greater than or equal to {param}",
+			RuleLtField:         "{field} must be less than {param}",
+			RuleLteField:        "{field} must be less than or equal to {param}",
+		}},
+	}
+)
+
+// RegisterLocale 注册或覆盖一种语言的消息模板，未提供的规则使用默认语言的模板
+func RegisterLocale(locale, separator string, templates map[string]string) {
+	locale = normalizeLocale(locale)
+	localesMu.Lock()
+	defer localesMu.Unlock()
+
+	merged := maps.Clone(locales[DefaultLocale].templates)
+	if existing, ok := locales[locale]; ok {
+		maps.Copy(merged, existing.templates)
+	}
+	maps.Copy(merged, templates)
+	locales[locale] = &localeMessages{locale: locale, separator: separator, templates: merged}
+}
+
+// messagesFor 返回语言的消息模板，不支持的语言使用默认语言
+func messagesFor(locale string) *localeMessages {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	if m, ok := locales[normalizeLocale(locale)]; ok {
+		return m
+	}
+	return locales[DefaultLocale]
+}
+
+// normalizeLocale 把 zh-CN、en_US 等写法归一为 zh、en
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
@@ -0,0 +1,350 @@
+package validation
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// =============================================================================
+//
+//  文件: tools/validation/rules.go
+//  功能: 内置校验规则的解析和检查。
+//  规则写法: 名称或 名称=参数，如 required、email、min_len=3、range=0..150、enum=male|female。
+//  说明: 除 required 系列外，字段缺失或为空时跳过其他规则 (可选字段只在填写时校验)。
+//        数值比较基于 float64，长度按字符 (rune) 计算，数组和对象按元素个数计算。
+//
+// =============================================================================
+
+// 内置规则名称
+const (
+	RuleRequired        = "required"         // 必填: 不能缺失、为 null、空字符串 (仅空白也算空)、空数组或空对象
+	RuleRequiredWith    = "required_with"    // 参数字段填写时必填，如 required_with=password
+	RuleRequiredWithout = "required_without" // 参数字段未填写时必填，如 required_without=email
+	RuleLen             = "len"              // 长度等于参数
+	RuleMinLen          = "min_len"          // 长度不小于参数
+	RuleMaxLen          = "max_len"          // 长度不大于参数
+	RuleMin             = "min"              // 数值不小于参数
+	RuleMax             = "max"              // 数值不大于参数
+	RuleRange           = "range"            // 数值在闭区间内，如 range=0..150
+	RuleRegex           = "regex"            // 匹配正则 (整个值匹配需自行加 ^$)
+	RuleEmail           = "email"            // 邮箱地址
+	RuleCNMobile        = "cn_mobile"        // 中国大陆手机号，允许 +86 / 86 前缀
+	RuleCNIDCard        = "cn_id_card"       // 18 位居民身份证号，检查出生日期和校验码
+	RuleEnum            = "enum"             // 取值在列表中，用 | 分隔，如 enum=male|female
+	RuleEqField         = "eq_field"         // 与同级字段相等，如 eq_field=password
+	RuleNeField         = "ne_field"         // 与同级字段不相等
+	RuleGtField         = "gt_field"         // 大于同级字段 (数值按大小比较，其余按字符串比较，适用于 ISO 日期)
+	RuleGteField        = "gte_field"        // 不小于同级字段
+	RuleLtField         = "lt_field"         // 小于同级字段
+	RuleLteField        = "lte_field"        // 不大于同级字段
+)
+
+// rule 解析后的一条规则
+type rule struct {
+	name  string
+	param string
+
+	// 预解析的参数
+	number  float64
+	low     float64
+	high    float64
+	pattern *regexp.Regexp
+	options []string
+}
+
+// ruleSpec 规则的参数要求
+type ruleSpec struct {
+	param    string // "" 无参数，number 数字，range 区间，regex 正则，list 列表，field 字段名
+	required bool   // 缺失或为空时是否仍然检查
+}
+
+var ruleSpecs = map[string]ruleSpec{
+	RuleRequired:        {required: true},
+	RuleRequiredWith:    {param: "field", required: true},
+	RuleRequiredWithout: {param: "field", required: true},
+	RuleLen:             {param: "number"},
+	RuleMinLen:          {param: "number"},
+	RuleMaxLen:          {param: "number"},
+	RuleMin:             {param: "number"},
+	RuleMax:             {param: "number"},
+	RuleRange:           {param: "range"},
+	RuleRegex:           {param: "regex"},
+	RuleEmail:           {},
+	RuleCNMobile:        {},
+	RuleCNIDCard:        {},
+	RuleEnum:            {param: "list"},
+	RuleEqField:         {param: "field"},
+	RuleNeField:         {param: "field"},
+	RuleGtField:         {param: "field"},
+	RuleGteField:        {param: "field"},
+	RuleLtField:         {param: "field"},
+	RuleLteField:        {param: "field"},
+}
+
+// parseRule 解析 "名称" 或 "名称=参数" 形式的规则
+func parseRule(text string) (*rule, error) {
+	name, param, hasParam := strings.Cut(strings.TrimSpace(text), "=")
+	name = strings.TrimSpace(name)
+	spec, ok := ruleSpecs[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的校验规则: %s", name)
+	}
+	if spec.param == "" {
+		if hasParam {
+			return nil, fmt.Errorf("规则 %s 不需要参数", name)
+		}
+		return &rule{name: name}, nil
+	}
+	if spec.param != "regex" {
+		param = strings.TrimSpace(param)
+	}
+	if param == "" {
+		return nil, fmt.Errorf("规则 %s 缺少参数", name)
+	}
+
+	r := &rule{name: name, param: param}
+	switch spec.param {
+	case "number":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s 的参数不是数字: %s", name, param)
+		}
+		r.number = n
+	case "range":
+		low, high, ok := strings.Cut(param, "..")
+		l, err1 := strconv.ParseFloat(strings.TrimSpace(low), 64)
+		h, err2 := strconv.ParseFloat(strings.TrimSpace(high), 64)
+		if !ok || err1 != nil || err2 != nil || l > h {
+			return nil, fmt.Errorf("规则 %s 的参数应为 最小值..最大值: %s", name, param)
+		}
+		r.low, r.high = l, h
+	case "regex":
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s 的正则无效: %w", name, err)
+		}
+		r.pattern = re
+	case "list":
+		for _, option := range strings.Split(param, "|") {
+			r.options = append(r.options, strings.TrimSpace(option))
+		}
+	}
+	return r, nil
+}
+
+// check 检查一个值，value 为 nil 表示字段缺失或为 null，parent 为字段所在的对象 (用于跨字段规则)
+func (r *rule) check(value any, parent map[string]any) bool {
+	switch r.name {
+	case RuleRequired:
+		return !isEmpty(value)
+	case RuleRequiredWith:
+		return isEmpty(parent[r.param]) || !isEmpty(value)
+	case RuleRequiredWithout:
+		return !isEmpty(parent[r.param]) || !isEmpty(value)
+	case RuleLen, RuleMinLen, RuleMaxLen:
+		n, ok := length(value)
+		if !ok {
+			return false
+		}
+		switch r.name {
+		case RuleLen:
+			return float64(n) == r.number
+		case RuleMinLen:
+			return float64(n) >= r.number
+		default:
+			return float64(n) <= r.number
+		}
+	case RuleMin, RuleMax, RuleRange:
+		n, ok := number(value)
+		if !ok {
+			return false
+		}
+		switch r.name {
+		case RuleMin:
+			return n >= r.number
+		case RuleMax:
+			return n <= r.number
+		default:
+			return n >= r.low && n <= r.high
+		}
+	case RuleRegex:
+		s, ok := value.(string)
+		return ok && r.pattern.MatchString(s)
+	case RuleEmail:
+		s, ok := value.(string)
+		return ok && isEmail(s)
+	case RuleCNMobile:
+		s, ok := value.(string)
+		return ok && cnMobilePattern.MatchString(strings.ReplaceAll(s, " ", ""))
+	case RuleCNIDCard:
+		s, ok := value.(string)
+		return ok && isCNIDCard(s)
+	case RuleEnum:
+		s := scalarString(value)
+		for _, option := range r.options {
+			if s == option {
+				return true
+			}
+		}
+		return false
+	default:
+		return r.compareField(value, parent[r.param])
+	}
+}
+
+// compareField 跨字段比较，另一个字段为空或无法比较时只有 ne_field 通过
+func (r *rule) compareField(value, other any) bool {
+	if isEmpty(other) {
+		return r.name == RuleNeField
+	}
+	c, ok := compare(value, other)
+	if !ok {
+		return r.name == RuleNeField
+	}
+	switch r.name {
+	case RuleEqField:
+		return c == 0
+	case RuleNeField:
+		return c != 0
+	case RuleGtField:
+		return c > 0
+	case RuleGteField:
+		return c >= 0
+	case RuleLtField:
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// checksEmpty 字段缺失或为空时是否仍然需要检查该规则
+func (r *rule) checksEmpty() bool {
+	return ruleSpecs[r.name].required
+}
+
+// isEmpty 判断字段是否缺失或为空。数值 0 和 false 视为已填写。
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// length 字符串的字符数，数组和对象的元素个数
+func length(value any) (int, bool) {
+	switch v := value.(type) {
+	case string:
+		return utf8.RuneCountInString(v), true
+	case []any:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	}
+	return 0, false
+}
+
+// number 把 JSON 数值 (或数字字符串) 转换为 float64
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// scalarString 把字符串、数值和布尔值转换为字符串，用于枚举比较
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// compare 比较两个值: 两个字符串按字符串比较 (适用于 ISO 日期)，其余能转换为数值时按大小比较
+func compare(a, b any) (int, bool) {
+	_, aString := a.(string)
+	_, bString := b.(string)
+	x, ok1 := number(a)
+	y, ok2 := number(b)
+	if ok1 && ok2 && !(aString && bString) {
+		return cmp.Compare(x, y), true
+	}
+	if isComposite(a) || isComposite(b) {
+		return 0, false
+	}
+	return strings.Compare(scalarString(a), scalarString(b)), true
+}
+
+// isComposite 判断是否为数组或对象
+func isComposite(value any) bool {
+	switch value.(type) {
+	case []any, map[string]any:
+		return true
+	}
+	return false
+}
+
+var (
+	cnMobilePattern = regexp.MustCompile(`^(?:\+?86)?1[3-9]\d{9}$`)
+	emailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// isEmail 检查邮箱地址: 不带显示名称的 RFC 5322 地址，且域名包含点
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && emailPattern.MatchString(s)
+}
+
+// 身份证校验码的加权因子和校验码 (GB 11643-1999)
+var (
+	idCardWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardCodes   = "10X98765432"
+)
+
+// isCNIDCard 检查 18 位居民身份证号: 前 17 位为数字、出生日期有效且不晚于今天、校验码正确
+func isCNIDCard(s string) bool {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) != 18 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		sum += int(s[i]-'0') * idCardWeights[i]
+	}
+	if s[17] != idCardCodes[sum%11] {
+		return false
+	}
+
+	birth, err := time.ParseInLocation("20060102", s[6:14], time.Local)
+	return err == nil && birth.Year() >= 1900 && !birth.After(time.Now())
+}
@@ -0,0 +1,406 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+//
+//  文件: tools/validation/schema.go
+//  功能: 校验规则集 (Schema) 的定义、加载和执行。
+//  规则来源:
+//    - 结构体标签: `validate:"required,email" label:"邮箱"`，通过 For[T] 或 Struct 使用
+//    - JSON 规则文件: {"fields": [{"field": "email", "rules": ["required", "email"]}]}，通过 LoadSchema 加载
+//  字段路径: 使用 JSON 字段名，嵌套对象用点号 (address.city)，数组元素用 [*] (items[*].price)；
+//           错误中的路径为具体位置 (items[0].price)。跨字段规则的参数是同一对象中的字段名。
+//  说明: 待校验的数据先转换为 JSON 形式再校验，因此结构体、map 和 JSON 字符串使用同一套规则。
+//        每个具体位置只报告第一条不通过的规则，所有字段的错误一次性返回。
+//
+// =============================================================================
+
+// FieldRules 一个字段的校验规则
+type FieldRules struct {
+	Field    string                       `json:"field"`              // 字段路径，如 email、items[*].price
+	Rules    []string                     `json:"rules"`              // 规则列表，如 ["required", "min_len=3"]
+	Labels   map[string]string            `json:"labels,omitempty"`   // 字段的显示名称，键为语言 (zh、en)，空字符串表示所有语言
+	Messages map[string]map[string]string `json:"messages,omitempty"` // 自定义错误消息: 语言 -> 规则名称 -> 模板，语言为空字符串表示所有语言
+
+	segments []segment
+	rules    []*rule
+}
+
+// segment 字段路径中的一段，each 为 true 表示对数组的每个元素校验
+type segment struct {
+	name string
+	each bool
+}
+
+// Schema 一组字段校验规则，编译后可以被多个 goroutine 同时使用
+type Schema struct {
+	Fields []*FieldRules `json:"fields"`
+
+	byPath map[string]*FieldRules
+}
+
+// FieldError 一个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`           // 具体位置，如 items[0].price
+	Rule    string `json:"rule"`            // 不通过的规则名称
+	Param   string `json:"param,omitempty"` // 规则参数
+	Message string `json:"message"`         // 按语言生成的错误消息
+}
+
+// ValidationError 数据校验失败，包含所有字段的错误
+type ValidationError struct {
+	Errors []*FieldError `json:"errors"`
+}
+
+// Error 返回所有错误拼接后的描述
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		parts = append(parts, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return fmt.Sprintf("数据校验失败: %s", strings.Join(parts, "; "))
+}
+
+// NewSchema 编译字段规则，规则或路径无效时返回错误
+func NewSchema(fields ...*FieldRules) (*Schema, error) {
+	s := &Schema{Fields: fields}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSchemaFile 从 JSON 规则文件加载校验规则
+func LoadSchemaFile(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取校验规则失败: %w", err)
+	}
+	return LoadSchema(data)
+}
+
+// LoadSchema 解析 JSON 格式的校验规则，未知字段视为错误
+func LoadSchema(data []byte) (*Schema, error) {
+	var s Schema
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("解析校验规则失败: %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// compile 解析字段路径和规则
+func (s *Schema) compile() error {
+	s.byPath = make(map[string]*FieldRules, len(s.Fields))
+	for i, f := range s.Fields {
+		if f == nil || f.Field == "" {
+			return fmt.Errorf("第 %d 个字段缺少 field", i+1)
+		}
+		if _, ok := s.byPath[f.Field]; ok {
+			return fmt.Errorf("字段 %s 重复定义", f.Field)
+		}
+		segments, err := parsePath(f.Field)
+		if err != nil {
+			return err
+		}
+		f.segments = segments
+		f.rules = f.rules[:0]
+		for _, text := range f.Rules {
+			r, err := parseRule(text)
+			if err != nil {
+				return fmt.Errorf("字段 %s: %w", f.Field, err)
+			}
+			f.rules = append(f.rules, r)
+		}
+		s.byPath[f.Field] = f
+	}
+	return nil
+}
+
+// parsePath 解析 a.b[*].c 形式的字段路径
+func parsePath(path string) ([]segment, error) {
+	var segments []segment
+	for _, part := range strings.Split(path, ".") {
+		name, each := strings.CutSuffix(part, "[*]")
+		if name == "" || strings.ContainsAny(name, "[]*") {
+			return nil, fmt.Errorf("字段路径无效: %s", path)
+		}
+		segments = append(segments, segment{name: name, each: each})
+	}
+	return segments, nil
+}
+
+// Validate 校验数据，v 可以是结构体 (或其指针)、map 或其他可以序列化为 JSON 对象的值。
+// 校验不通过时返回 *ValidationError，locale 为错误消息的语言 (zh、en，默认 zh)。
+func (s *Schema) Validate(v any, locale string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化待校验数据失败: %w", err)
+	}
+	return s.ValidateJSON(string(data), locale)
+}
+
+// ValidateJSON 校验 JSON 对象
+func (s *Schema) ValidateJSON(data string, locale string) error {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("解析待校验数据失败: %w", err)
+	}
+	root, ok := value.(map[string]any)
+	if !ok {
+		return errors.New("待校验的数据必须是 JSON 对象")
+	}
+
+	messages := messagesFor(locale)
+	var fieldErrors []*FieldError
+	for _, f := range s.Fields {
+		f.walk(root, func(path string, value any, parent map[string]any) {
+			for _, r := range f.rules {
+				if isEmpty(value) && !r.checksEmpty() {
+					continue
+				}
+				if !r.check(value, parent) {
+					fieldErrors = append(fieldErrors, &FieldError{
+						Field:   path,
+						Rule:    r.name,
+						Param:   r.param,
+						Message: s.message(messages, f, r, path),
+					})
+					return
+				}
+			}
+		})
+	}
+
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: fieldErrors}
+}
+
+// walk 找到路径对应的所有具体位置。中间的对象缺失时按字段缺失处理，数组为空时没有需要校验的位置。
+func (f *FieldRules) walk(root map[string]any, fn func(path string, value any, parent map[string]any)) {
+	var visit func(parent map[string]any, i int, prefix string)
+	visit = func(parent map[string]any, i int, prefix string) {
+		seg := f.segments[i]
+		path := seg.name
+		if prefix != "" {
+			path = prefix + "." + seg.name
+		}
+		value := parent[seg.name]
+
+		targets := []any{value}
+		paths := []string{path}
+		if seg.each {
+			items, _ := value.([]any)
+			targets, paths = items, make([]string, len(items))
+			for j := range items {
+				paths[j] = path + "[" + strconv.Itoa(j) + "]"
+			}
+		}
+
+		for j, target := range targets {
+			if i == len(f.segments)-1 {
+				fn(paths[j], target, parent)
+				continue
+			}
+			child, _ := target.(map[string]any)
+			visit(child, i+1, paths[j])
+		}
+	}
+	visit(root, 0, "")
+}
+
+// label 字段的显示名称: 指定语言的名称、通用名称，都没有时使用具体路径
+func (f *FieldRules) label(locale, path string) string {
+	if label := f.Labels[locale]; label != "" {
+		return label
+	}
+	if label := f.Labels[""]; label != "" {
+		return label
+	}
+	return path
+}
+
+// message 生成错误消息，跨字段规则的参数替换为另一个字段的显示名称
+func (s *Schema) message(messages *localeMessages, f *FieldRules, r *rule, path string) string {
+	template, ok := f.Messages[messages.locale][r.name]
+	if !ok {
+		template, ok = f.Messages[""][r.name]
+	}
+	if !ok {
+		template = messages.templates[r.name]
+	}
+
+	param := r.param
+	if ruleSpecs[r.name].param == "field" {
+		sibling := r.param
+		if i := strings.LastIndex(f.Field, "."); i >= 0 {
+			sibling = f.Field[:i+1] + r.param
+		}
+		if other, ok := s.byPath[sibling]; ok {
+			param = other.label(messages.locale, r.param)
+		}
+	}
+
+	return strings.NewReplacer(
+		"{field}", f.label(messages.locale, path),
+		"{param}", param,
+		"{min}", formatNumber(r.low),
+		"{max}", formatNumber(r.high),
+		"{options}", strings.Join(r.options, messages.separator),
+	).Replace(template)
+}
+
+// formatNumber 格式化规则中的数值参数
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// schemaCache 结构体类型 -> 由标签生成的 Schema
+var schemaCache sync.Map
+
+// For 根据结构体 T 的 validate / label 标签生成校验规则，结果按类型缓存
+func For[T any]() (*Schema, error) {
+	return fromType(reflect.TypeFor[T]())
+}
+
+// Struct 按照 v 的结构体标签校验 v
+func Struct(v any, locale string) error {
+	s, err := fromType(reflect.TypeOf(v))
+	if err != nil {
+		return err
+	}
+	return s.Validate(v, locale)
+}
+
+// fromType 解析结构体标签，支持嵌套结构体、结构体指针和结构体切片 (生成 items[*].price 形式的路径)
+func fromType(t reflect.Type) (*Schema, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("只能从结构体标签生成校验规则: %v", t)
+	}
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*Schema), nil
+	}
+
+	var fields []*FieldRules
+	if err := collectFields(t, "", &fields, map[reflect.Type]bool{}); err != nil {
+		return nil, err
+	}
+	s, err := NewSchema(fields...)
+	if err != nil {
+		return nil, fmt.Errorf("结构体 %v 的校验标签无效: %w", t, err)
+	}
+	cached, _ := schemaCache.LoadOrStore(t, s)
+	return cached.(*Schema), nil
+}
+
+// collectFields 收集结构体字段的规则，prefix 为父级路径
+func collectFields(t reflect.Type, prefix string, fields *[]*FieldRules, visiting map[reflect.Type]bool) error {
+	if visiting[t] {
+		return nil // 递归类型只展开一层
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if err := collectFields(ft, prefix, fields, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		if tag := sf.Tag.Get("validate"); tag != "" {
+			*fields = append(*fields, &FieldRules{Field: path, Rules: splitTag(tag), Labels: parseLabels(sf.Tag.Get("label"))})
+		}
+
+		elem := ft
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			elem = ft.Elem()
+			for elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			path += "[*]"
+		}
+		if elem.Kind() == reflect.Struct && elem.NumField() > 0 && elem.PkgPath() != "time" {
+			if err := collectFields(elem, path, fields, visiting); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitTag 按逗号拆分 validate 标签。regex 规则必须放在最后，其后的内容 (可以包含逗号) 都作为正则。
+func splitTag(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(strings.TrimSpace(tag), RuleRegex+"=") {
+			return append(rules, strings.TrimSpace(tag))
+		}
+		item, rest, _ := strings.Cut(tag, ",")
+		if item = strings.TrimSpace(item); item != "" {
+			rules = append(rules, item)
+		}
+		tag = rest
+	}
+	return rules
+}
+
+// parseLabels 解析 label 标签: "邮箱" 表示所有语言通用，"zh=邮箱,en=Email" 按语言指定
+func parseLabels(tag string) map[string]string {
+	if tag == "" {
+		return nil
+	}
+	if !strings.Contains(tag, "=") {
+		return map[string]string{"": tag}
+	}
+	labels := make(map[string]string)
+	for _, item := range strings.Split(tag, ",") {
+		if locale, label, ok := strings.Cut(item, "="); ok {
+			labels[normalizeLocale(locale)] = strings.TrimSpace(label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,142 @@
+package weather
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/cache.go
+//  功能: 按 城市 + 日期 缓存天气数据的 Provider 包装。
+//  说明: 缓存粒度是单日，日期范围有重叠的查询可以复用已缓存的日期:
+//        先查询 2024-08-19 ~ 2024-08-21，再查询 2024-08-20 ~ 2024-08-23 时只请求 08-22 ~ 08-23。
+//        缺失的日期合并为一次请求 (从最早缺失日到最晚缺失日)。只缓存成功的结果。
+//
+// =============================================================================
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	TTL        time.Duration // 缓存有效期，默认 30 分钟
+	MaxEntries int           // 最多缓存的 城市 + 日期 条目数，默认 1024
+}
+
+// CacheStats 缓存统计，按天计数
+type CacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+// CachedProvider 带缓存的 Provider
+type CachedProvider struct {
+	provider Provider
+	config   CacheConfig
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	day     DailyWeather
+	expires time.Time
+}
+
+// NewCachedProvider 为 provider 增加按 城市 + 日期 的缓存
+func NewCachedProvider(provider Provider, config CacheConfig) *CachedProvider {
+	if config.TTL <= 0 {
+		config.TTL = 30 * time.Minute
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1024
+	}
+	return &CachedProvider{
+		provider: provider,
+		config:   config,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// Forecast 优先使用缓存，只向下层数据源请求缺失的日期
+func (c *CachedProvider) Forecast(ctx context.Context, city *City, start, end time.Time) ([]*DailyWeather, error) {
+	n := daysBetween(start, end) + 1
+	days := make([]*DailyWeather, n)
+	firstMissing, lastMissing := -1, -1
+
+	c.mu.Lock()
+	now := time.Now()
+	for i := range days {
+		key := cacheKey(city, start.AddDate(0, 0, i))
+		if e, ok := c.entries[key]; ok && now.Before(e.expires) {
+			day := e.day
+			days[i] = &day
+			c.stats.Hits++
+			continue
+		}
+		c.stats.Misses++
+		if firstMissing < 0 {
+			firstMissing = i
+		}
+		lastMissing = i
+	}
+	c.mu.Unlock()
+
+	if firstMissing < 0 {
+		return days, nil
+	}
+
+	fetched, err := c.provider.Forecast(ctx, city, start.AddDate(0, 0, firstMissing), start.AddDate(0, 0, lastMissing))
+	if err != nil {
+		return nil, err
+	}
+	if len(fetched) != lastMissing-firstMissing+1 {
+		// 下层数据源没有遵守约定时不缓存，交给调用方处理
+		return fetched, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(c.config.TTL)
+	for i, day := range fetched {
+		copied := *day
+		days[firstMissing+i] = &copied
+		c.put(cacheKey(city, start.AddDate(0, 0, firstMissing+i)), cacheEntry{day: *day, expires: expires})
+	}
+	return days, nil
+}
+
+// Stats 返回缓存命中统计
+func (c *CachedProvider) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// put 写入缓存，超过容量时先清理过期条目，仍然超过时淘汰最早过期的条目。调用方需持有锁。
+func (c *CachedProvider) put(key string, entry cacheEntry) {
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.config.MaxEntries {
+		now := time.Now()
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for len(c.entries) >= c.config.MaxEntries {
+			var oldest string
+			for k, e := range c.entries {
+				if oldest == "" || e.expires.Before(c.entries[oldest].expires) {
+					oldest = k
+				}
+			}
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// cacheKey 缓存键，城市标识不区分大小写
+func cacheKey(city *City, date time.Time) string {
+	return strings.ToLower(city.ID) + "|" + date.Format(DateLayout)
+}
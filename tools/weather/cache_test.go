@@ -0,0 +1,93 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingProvider 记录每次向下层数据源请求的日期范围
+type recordingProvider struct {
+	Provider
+	calls []string
+}
+
+func (p *recordingProvider) Forecast(ctx context.Context, city *City, start, end time.Time) ([]*DailyWeather, error) {
+	p.calls = append(p.calls, start.Format(DateLayout)+"~"+end.Format(DateLayout))
+	return p.Provider.Forecast(ctx, city, start, end)
+}
+
+func date(s string) time.Time {
+	d, err := time.Parse(DateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestCachedProviderReusesCachedDays(t *testing.T) {
+	server := NewFakeServer("")
+	defer server.Close()
+	recorder := &recordingProvider{Provider: server.Provider()}
+	cached := NewCachedProvider(recorder, CacheConfig{})
+
+	// 按顺序执行，后面的查询复用前面缓存的日期
+	steps := []struct {
+		name       string
+		city       string
+		start, end string
+		wantFetch  string // 向下层请求的范围，空字符串表示全部命中缓存
+		wantHits   int    // 累计命中天数
+		wantMisses int    // 累计未命中天数
+	}{
+		{name: "首次查询", city: "北京", start: "2024-08-19", end: "2024-08-21", wantFetch: "2024-08-19~2024-08-21", wantHits: 0, wantMisses: 3},
+		{name: "重叠范围只请求缺失的日期", city: "北京", start: "2024-08-20", end: "2024-08-23", wantFetch: "2024-08-22~2024-08-23", wantHits: 2, wantMisses: 5},
+		{name: "全部命中", city: "Beijing", start: "2024-08-19", end: "2024-08-23", wantHits: 7, wantMisses: 5},
+		{name: "别名使用同一个缓存键", city: "peking", start: "2024-08-21", end: "2024-08-21", wantHits: 8, wantMisses: 5},
+	}
+
+	for _, step := range steps {
+		city := ResolveCity(step.city)
+		calls := len(recorder.calls)
+		days, err := cached.Forecast(context.Background(), city, date(step.start), date(step.end))
+		if err != nil {
+			t.Fatalf("%s: Forecast 返回错误: %v", step.name, err)
+		}
+		if want := daysBetween(date(step.start), date(step.end)) + 1; len(days) != want {
+			t.Fatalf("%s: 返回 %d 天，期望 %d 天", step.name, len(days), want)
+		}
+		for i, d := range days {
+			if want := fakeDay(city, date(step.start).AddDate(0, 0, i)); *d != *want {
+				t.Errorf("%s: 第 %d 天 = %+v，期望 %+v", step.name, i, *d, *want)
+			}
+		}
+
+		var fetched string
+		if len(recorder.calls) > calls {
+			fetched = recorder.calls[len(recorder.calls)-1]
+		}
+		if len(recorder.calls)-calls > 1 || fetched != step.wantFetch {
+			t.Errorf("%s: 请求了 %v，期望 %q", step.name, recorder.calls[calls:], step.wantFetch)
+		}
+		if stats := cached.Stats(); stats.Hits != step.wantHits || stats.Misses != step.wantMisses {
+			t.Errorf("%s: 缓存统计 = %+v，期望命中 %d 次、未命中 %d 次", step.name, stats, step.wantHits, step.wantMisses)
+		}
+	}
+}
+
+func TestCachedProviderDoesNotCacheErrors(t *testing.T) {
+	server := NewFakeServer("")
+	defer server.Close()
+	cached := NewCachedProvider(server.Provider(), CacheConfig{})
+	city := ResolveCity("Atlantis")
+
+	for i := 0; i < 2; i++ {
+		if _, err := cached.Forecast(context.Background(), city, date("2024-08-19"), date("2024-08-19")); !errors.Is(err, ErrCityNotFound) {
+			t.Fatalf("第 %d 次查询: 错误 = %v，期望 ErrCityNotFound", i+1, err)
+		}
+	}
+	if got := server.Requests(); got != 2 {
+		t.Errorf("服务收到 %d 次请求，失败的结果不应该被缓存", got)
+	}
+}
@@ -0,0 +1,138 @@
+package weather
+
+import (
+	"strings"
+	"unicode"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/city.go
+//  功能: 城市名称解析，把中文名、英文名和常见别名统一为同一个城市。
+//  示例: "北京"、"北京市"、"Beijing"、"peking"、"BEIJING" 都解析为 北京 (Beijing)。
+//  说明: 内置表之外的城市原样交给数据源查询，由数据源决定是否认识 (见 City.Known)。
+//
+// =============================================================================
+
+// City 解析后的城市
+type City struct {
+	ID      string `json:"id"`      // 向数据源查询使用的标识，内置城市为英文名
+	Name    string `json:"name"`    // 中文名称
+	English string `json:"english"` // 英文名称
+	Country string `json:"country,omitempty"`
+	Known   bool   `json:"-"` // 是否为内置表中的城市
+
+	latitude float64 // 纬度，南半球为负数
+	meanTemp float64 // 年平均气温 (°C)，供 FakeServer 生成数据
+	swing    float64 // 最热月与年平均气温之差 (°C)
+	wetness  float64 // 降水倾向 (0-1)
+}
+
+// cities 内置城市表
+var cities = []*City{
+	{ID: "Beijing", Name: "北京", English: "Beijing", Country: "CN", latitude: 39.9, meanTemp: 12.9, swing: 14, wetness: 0.3},
+	{ID: "Shanghai", Name: "上海", English: "Shanghai", Country: "CN", latitude: 31.2, meanTemp: 17.1, swing: 11, wetness: 0.5},
+	{ID: "Guangzhou", Name: "广州", English: "Guangzhou", Country: "CN", latitude: 23.1, meanTemp: 22.4, swing: 6.5, wetness: 0.6},
+	{ID: "Shenzhen", Name: "深圳", English: "Shenzhen", Country: "CN", latitude: 22.5, meanTemp: 23.0, swing: 6, wetness: 0.6},
+	{ID: "Hangzhou", Name: "杭州", English: "Hangzhou", Country: "CN", latitude: 30.3, meanTemp: 17.0, swing: 12, wetness: 0.55},
+	{ID: "Nanjing", Name: "南京", English: "Nanjing", Country: "CN", latitude: 32.1, meanTemp: 15.9, swing: 12.5, wetness: 0.5},
+	{ID: "Chengdu", Name: "成都", English: "Chengdu", Country: "CN", latitude: 30.7, meanTemp: 16.5, swing: 9.5, wetness: 0.55},
+	{ID: "Chongqing", Name: "重庆", English: "Chongqing", Country: "CN", latitude: 29.6, meanTemp: 18.3, swing: 10.5, wetness: 0.55},
+	{ID: "Wuhan", Name: "武汉", English: "Wuhan", Country: "CN", latitude: 30.6, meanTemp: 17.1, swing: 12.5, wetness: 0.5},
+	{ID: "Xian", Name: "西安", English: "Xi'an", Country: "CN", latitude: 34.3, meanTemp: 14.1, swing: 13, wetness: 0.35},
+	{ID: "Tianjin", Name: "天津", English: "Tianjin", Country: "CN", latitude: 39.1, meanTemp: 13.0, swing: 14, wetness: 0.3},
+	{ID: "Harbin", Name: "哈尔滨", English: "Harbin", Country: "CN", latitude: 45.8, meanTemp: 4.5, swing: 19, wetness: 0.3},
+	{ID: "Hong Kong", Name: "香港", English: "Hong Kong", Country: "CN", latitude: 22.3, meanTemp: 23.5, swing: 5.5, wetness: 0.6},
+	{ID: "Taipei", Name: "台北", English: "Taipei", Country: "CN", latitude: 25.0, meanTemp: 23.0, swing: 6.5, wetness: 0.65},
+	{ID: "Tokyo", Name: "东京", English: "Tokyo", Country: "JP", latitude: 35.7, meanTemp: 15.8, swing: 10.5, wetness: 0.5},
+	{ID: "Seoul", Name: "首尔", English: "Seoul", Country: "KR", latitude: 37.6, meanTemp: 12.8, swing: 13.5, wetness: 0.4},
+	{ID: "Singapore", Name: "新加坡", English: "Singapore", Country: "SG", latitude: 1.3, meanTemp: 27.6, swing: 1, wetness: 0.7},
+	{ID: "London", Name: "伦敦", English: "London", Country: "GB", latitude: 51.5, meanTemp: 11.3, swing: 7.5, wetness: 0.55},
+	{ID: "Paris", Name: "巴黎", English: "Paris", Country: "FR", latitude: 48.9, meanTemp: 12.4, swing: 8, wetness: 0.45},
+	{ID: "Berlin", Name: "柏林", English: "Berlin", Country: "DE", latitude: 52.5, meanTemp: 10.0, swing: 9.5, wetness: 0.45},
+	{ID: "New York", Name: "纽约", English: "New York", Country: "US", latitude: 40.7, meanTemp: 13.0, swing: 12.5, wetness: 0.4},
+	{ID: "San Francisco", Name: "旧金山", English: "San Francisco", Country: "US", latitude: 37.8, meanTemp: 14.6, swing: 3.5, wetness: 0.3},
+	{ID: "Los Angeles", Name: "洛杉矶", English: "Los Angeles", Country: "US", latitude: 34.1, meanTemp: 18.6, swing: 5, wetness: 0.15},
+	{ID: "Sydney", Name: "悉尼", English: "Sydney", Country: "AU", latitude: -33.9, meanTemp: 18.4, swing: 5, wetness: 0.45},
+}
+
+// cityAliases 英文旧称、缩写和常见别名 (已归一化，见 normalizeCityName)
+var cityAliases = map[string]string{
+	"peking":  "Beijing",
+	"peiping": "Beijing",
+	"京":       "Beijing",
+	"沪":       "Shanghai",
+	"申":       "Shanghai",
+	"canton":  "Guangzhou",
+	"穗":       "Guangzhou",
+	"羊城":      "Guangzhou",
+	"鹏城":      "Shenzhen",
+	"蓉城":      "Chengdu",
+	"山城":      "Chongqing",
+	"长安":      "Xian",
+	"江城":      "Wuhan",
+	"冰城":      "Harbin",
+	"hk":      "Hong Kong",
+	"汉城":      "Seoul",
+	"nyc":     "New York",
+	"sf":      "San Francisco",
+	"三藩市":     "San Francisco",
+	"la":      "Los Angeles",
+	"雪梨":      "Sydney",
+	"星加坡":     "Singapore",
+}
+
+// cityIndex 归一化名称 -> 城市，包含 ID、中文名、英文名和别名
+var cityIndex = buildCityIndex()
+
+func buildCityIndex() map[string]*City {
+	byID := make(map[string]*City, len(cities))
+	index := make(map[string]*City, len(cities)*3+len(cityAliases))
+	for _, c := range cities {
+		c.Known = true
+		byID[c.ID] = c
+		for _, name := range []string{c.ID, c.Name, c.English} {
+			index[normalizeCityName(name)] = c
+		}
+	}
+	for alias, id := range cityAliases {
+		index[normalizeCityName(alias)] = byID[id]
+	}
+	return index
+}
+
+// ResolveCity 解析城市名称。内置城市返回内置表中的信息；其他名称返回 Known 为 false 的 City，
+// ID 为去掉首尾空白后的原始名称，由数据源决定是否认识。名称为空时返回 nil。
+func ResolveCity(name string) *City {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	if c, ok := cityIndex[normalizeCityName(name)]; ok {
+		copied := *c
+		return &copied
+	}
+	return &City{ID: name, Name: name, English: name}
+}
+
+// normalizeCityName 归一化城市名称: 英文不区分大小写，去掉空格、连字符、撇号和句点，
+// 去掉英文的 "city" 和中文的 "市" 后缀 (保留单字城市名)，以及 ", China" 之类的国家后缀。
+func normalizeCityName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(name, ",，"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || strings.ContainsRune("-'’.·_", r) {
+			return -1
+		}
+		return r
+	}, name)
+	if strings.HasSuffix(name, "city") && len(name) > len("city") {
+		name = strings.TrimSuffix(name, "city")
+	}
+	if trimmed := strings.TrimSuffix(name, "市"); trimmed != name && len([]rune(trimmed)) >= 2 {
+		name = trimmed
+	}
+	return name
+}
@@ -0,0 +1,104 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/date.go
+//  功能: 查询日期的解析和校验。
+//  规则:
+//    - 日期格式为 YYYY-MM-DD，另外支持 today / tomorrow / 今天 / 明天 / 后天
+//    - date 与 start_date / end_date 不能同时使用；都不填时查询今天；只填 end_date 时从今天开始
+//    - 结束日期不能早于开始日期，范围不超过 MaxRangeDays 天，最晚只能查询到今天之后 ForecastDays 天
+//
+// =============================================================================
+
+// DateLayout 日期格式
+const DateLayout = "2006-01-02"
+
+// relativeDates 相对日期 -> 相对今天的天数
+var relativeDates = map[string]int{
+	"today":    0,
+	"tomorrow": 1,
+	"今天":       0,
+	"今日":       0,
+	"明天":       1,
+	"明日":       1,
+	"后天":       2,
+}
+
+// ParseDate 解析日期，返回 UTC 零点。today 用于计算相对日期。
+func ParseDate(s string, today time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if offset, ok := relativeDates[strings.ToLower(s)]; ok {
+		return civilDate(today).AddDate(0, 0, offset), nil
+	}
+	d, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期 %s 无效，格式应为 YYYY-MM-DD", s)
+	}
+	return d, nil
+}
+
+// civilDate 取 t 在其所在时区的日期，返回该日期的 UTC 零点
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dateLimits 日期范围限制
+type dateLimits struct {
+	maxRangeDays int // 一次最多查询的天数
+	forecastDays int // 最晚可以查询今天之后多少天
+}
+
+// resolveDates 根据 date / start_date / end_date 参数计算查询的日期范围
+func resolveDates(date, startDate, endDate string, now time.Time, limits dateLimits) (start, end time.Time, err error) {
+	today := civilDate(now)
+	date, startDate, endDate = strings.TrimSpace(date), strings.TrimSpace(startDate), strings.TrimSpace(endDate)
+
+	switch {
+	case date != "" && (startDate != "" || endDate != ""):
+		return start, end, fmt.Errorf("date 不能与 start_date / end_date 同时使用")
+	case date != "":
+		if start, err = ParseDate(date, now); err != nil {
+			return start, end, err
+		}
+		end = start
+	case startDate == "" && endDate == "":
+		start, end = today, today
+	default:
+		start, end = today, today
+		if startDate != "" {
+			if start, err = ParseDate(startDate, now); err != nil {
+				return start, end, err
+			}
+			end = start
+		}
+		if endDate != "" {
+			if end, err = ParseDate(endDate, now); err != nil {
+				return start, end, err
+			}
+		}
+	}
+
+	if end.Before(start) {
+		return start, end, fmt.Errorf("结束日期 %s 早于开始日期 %s", end.Format(DateLayout), start.Format(DateLayout))
+	}
+	if days := daysBetween(start, end) + 1; days > limits.maxRangeDays {
+		return start, end, fmt.Errorf("日期范围为 %d 天，一次最多查询 %d 天", days, limits.maxRangeDays)
+	}
+	if last := today.AddDate(0, 0, limits.forecastDays); end.After(last) {
+		return start, end, fmt.Errorf("只能查询到今天之后 %d 天 (%s) 的天气预报", limits.forecastDays, last.Format(DateLayout))
+	}
+	return start, end, nil
+}
+
+// daysBetween 两个 UTC 零点之间相差的天数
+func daysBetween(start, end time.Time) int {
+	return int(end.Sub(start).Hours() / 24)
+}
@@ -0,0 +1,55 @@
+package weather
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDates(t *testing.T) {
+	// 东八区的晚上，UTC 仍然是前一天的下午，"今天" 应该按所在时区计算
+	now := time.Date(2024, 8, 19, 22, 30, 0, 0, time.FixedZone("CST", 8*3600))
+	limits := dateLimits{maxRangeDays: DefaultMaxRangeDays, forecastDays: DefaultForecastDays}
+
+	tests := []struct {
+		name                     string
+		date, startDate, endDate string
+		wantStart, wantEnd       string
+		wantErr                  bool
+	}{
+		{name: "都不填时查询今天", wantStart: "2024-08-19", wantEnd: "2024-08-19"},
+		{name: "相对日期", date: "明天", wantStart: "2024-08-20", wantEnd: "2024-08-20"},
+		{name: "英文相对日期不区分大小写", date: " Tomorrow ", wantStart: "2024-08-20", wantEnd: "2024-08-20"},
+		{name: "绝对日期", date: "2024-08-25", wantStart: "2024-08-25", wantEnd: "2024-08-25"},
+		{name: "只填开始日期时查询一天", startDate: "2024-08-21", wantStart: "2024-08-21", wantEnd: "2024-08-21"},
+		{name: "只填结束日期时从今天开始", endDate: "2024-08-21", wantStart: "2024-08-19", wantEnd: "2024-08-21"},
+		{name: "日期范围", startDate: "2024-08-20", endDate: "后天", wantStart: "2024-08-20", wantEnd: "2024-08-21"},
+		{name: "最多查询 7 天", startDate: "2024-08-19", endDate: "2024-08-25", wantStart: "2024-08-19", wantEnd: "2024-08-25"},
+		{name: "最晚查询今天之后 14 天", date: "2024-09-02", wantStart: "2024-09-02", wantEnd: "2024-09-02"},
+		{name: "date 与范围同时使用", date: "今天", endDate: "2024-08-21", wantErr: true},
+		{name: "日期格式无效", date: "2024/08/20", wantErr: true},
+		{name: "结束日期早于开始日期", startDate: "2024-08-21", endDate: "2024-08-20", wantErr: true},
+		{name: "超过 7 天", startDate: "2024-08-19", endDate: "2024-08-26", wantErr: true},
+		{name: "超出预报范围", date: "2024-09-03", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := resolveDates(tt.date, tt.startDate, tt.endDate, now, limits)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s ~ %s", start.Format(DateLayout), end.Format(DateLayout))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveDates 返回错误: %v", err)
+			}
+			if got := start.Format(DateLayout); got != tt.wantStart {
+				t.Errorf("开始日期 = %s，期望 %s", got, tt.wantStart)
+			}
+			if got := end.Format(DateLayout); got != tt.wantEnd {
+				t.Errorf("结束日期 = %s，期望 %s", got, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package weather
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/fake.go
+//  功能: 基于 httptest 的本地假天气服务，实现 http.go 中约定的接口，用于离线演示和测试。
+//  数据: 只认识内置城市表中的城市。温度按城市的年平均气温和季节变化 (南半球季节相反) 计算，
+//        再叠加由 城市 + 日期 哈希得到的扰动，同一城市同一天的结果总是相同，可以直接写进断言。
+//
+// =============================================================================
+
+// FakeServer 本地假天气服务
+type FakeServer struct {
+	*httptest.Server
+
+	apiKey   string
+	requests atomic.Int64
+}
+
+// NewFakeServer 启动假天气服务，apiKey 非空时要求请求携带对应的 Bearer Token。
+// 使用完毕后调用 Close 关闭。
+func NewFakeServer(apiKey string) *FakeServer {
+	f := &FakeServer{apiKey: apiKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /forecast", f.handleForecast)
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+// Requests 返回服务收到的 /forecast 请求数，用于观察缓存效果
+func (f *FakeServer) Requests() int {
+	return int(f.requests.Load())
+}
+
+// Provider 返回连接到该服务的 HTTPProvider
+func (f *FakeServer) Provider() *HTTPProvider {
+	p, _ := NewHTTPProvider(&HTTPConfig{BaseURL: f.URL, APIKey: f.apiKey, Client: f.Client()})
+	return p
+}
+
+func (f *FakeServer) handleForecast(w http.ResponseWriter, r *http.Request) {
+	f.requests.Add(1)
+	if f.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+f.apiKey {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid api key"})
+		return
+	}
+
+	query := r.URL.Query()
+	city, ok := cityIndex[normalizeCityName(query.Get("city"))]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "city not found: " + query.Get("city")})
+		return
+	}
+	start, err1 := time.Parse(DateLayout, query.Get("start"))
+	end, err2 := time.Parse(DateLayout, query.Get("end"))
+	if err1 != nil || err2 != nil || end.Before(start) || daysBetween(start, end) >= 31 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid date range"})
+		return
+	}
+
+	resp := forecastResponse{City: city.ID}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		resp.Days = append(resp.Days, fakeDay(city, d))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// fakeDay 生成城市某一天的天气
+func fakeDay(city *City, date time.Time) *DailyWeather {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(city.ID) + "|" + date.Format(DateLayout)))
+	seed := h.Sum64()
+	// noise 从种子中取出第 i 组 8 位，映射到 [0, 1)
+	noise := func(i int) float64 { return float64((seed>>(8*i))&0xff) / 256 }
+
+	// 北半球最热在 7 月下旬 (第 200 天左右)，南半球相差半年
+	phase := float64(date.YearDay()-200) / 365 * 2 * math.Pi
+	if city.latitude < 0 {
+		phase += math.Pi
+	}
+	mean := city.meanTemp + city.swing*math.Cos(phase) + (noise(0)-0.5)*4
+
+	rain := noise(1) < city.wetness*0.6
+	var condition string
+	var precipitation float64
+	switch {
+	case rain && mean <= 0:
+		condition, precipitation = "小雪", 1+noise(2)*4
+	case rain && noise(2) > 0.7:
+		condition, precipitation = "雷阵雨", 8+noise(3)*20
+	case rain:
+		condition, precipitation = "小雨", 1+noise(3)*8
+	case noise(2) < 0.45:
+		condition = "晴"
+	case noise(2) < 0.8:
+		condition = "多云"
+	default:
+		condition = "阴"
+	}
+
+	spread := 8 - city.wetness*4
+	if rain {
+		spread -= 2
+	}
+	humidity := 35 + city.wetness*40 + noise(4)*15
+	if rain {
+		humidity += 15
+	}
+	return &DailyWeather{
+		Date:          date.Format(DateLayout),
+		Condition:     condition,
+		TempMax:       round1(mean + spread/2),
+		TempMin:       round1(mean - spread/2),
+		Humidity:      int(math.Min(humidity, 98)),
+		WindSpeed:     round1(5 + noise(5)*20),
+		Precipitation: round1(precipitation),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// round1 保留一位小数
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
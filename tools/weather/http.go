@@ -0,0 +1,145 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/http.go
+//  功能: 通过 HTTP 接口获取天气数据的 Provider。
+//  接口:
+//    GET {BaseURL}/forecast?city=Beijing&start=2024-08-19&end=2024-08-21
+//    Authorization: Bearer {APIKey}         (配置了 APIKey 时)
+//    200 {"city": "Beijing", "days": [{"date": "2024-08-19", "condition": "晴", "temp_max": 31.2, ...}]}
+//    4xx/5xx {"error": "..."}，其中 404 表示城市不存在
+//  说明: 需要对接其他格式的天气服务时，实现 Provider 接口或在服务端做一层转换即可。
+//
+// =============================================================================
+
+// DefaultHTTPTimeout 单次请求的默认超时时间
+const DefaultHTTPTimeout = 10 * time.Second
+
+// HTTPConfig HTTP 数据源的配置
+type HTTPConfig struct {
+	// BaseURL 天气服务地址 (必填)，如 https://weather.example.com/v1
+	BaseURL string
+	// APIKey 访问凭证，非空时以 Bearer Token 发送
+	APIKey string
+	// Client 发送请求使用的 HTTP 客户端，默认为超时 Timeout 的 http.Client
+	Client *http.Client
+	// Timeout 单次请求的超时时间，默认 DefaultHTTPTimeout；配置了 Client 时忽略
+	Timeout time.Duration
+}
+
+// HTTPProvider 通过 HTTP 接口获取天气数据
+type HTTPProvider struct {
+	baseURL *url.URL
+	apiKey  string
+	client  *http.Client
+}
+
+// forecastResponse 接口的成功响应
+type forecastResponse struct {
+	City string          `json:"city"`
+	Days []*DailyWeather `json:"days"`
+}
+
+// errorResponse 接口的错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHTTPProvider 创建 HTTP 数据源
+func NewHTTPProvider(config *HTTPConfig) (*HTTPProvider, error) {
+	if config == nil || config.BaseURL == "" {
+		return nil, errors.New("必须配置天气服务地址")
+	}
+	base, err := url.Parse(strings.TrimRight(config.BaseURL, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("天气服务地址 %q 无效", config.BaseURL)
+	}
+	client := config.Client
+	if client == nil {
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = DefaultHTTPTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	return &HTTPProvider{baseURL: base, apiKey: config.APIKey, client: client}, nil
+}
+
+// Forecast 请求 [start, end] 内每一天的天气
+func (p *HTTPProvider) Forecast(ctx context.Context, city *City, start, end time.Time) ([]*DailyWeather, error) {
+	endpoint := p.baseURL.JoinPath("forecast")
+	endpoint.RawQuery = url.Values{
+		"city":  {city.ID},
+		"start": {start.Format(DateLayout)},
+		"end":   {end.Format(DateLayout)},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建天气请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求天气服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取天气服务响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &e) == nil && e.Error != "" {
+			message = e.Error
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrCityNotFound, city.ID)
+		}
+		return nil, fmt.Errorf("天气服务返回 %d: %s", resp.StatusCode, message)
+	}
+
+	var result forecastResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析天气服务响应失败: %w", err)
+	}
+	return checkDays(result.Days, start, end)
+}
+
+// checkDays 检查返回的数据是否恰好覆盖 [start, end] 的每一天，并按日期排序
+func checkDays(days []*DailyWeather, start, end time.Time) ([]*DailyWeather, error) {
+	byDate := make(map[string]*DailyWeather, len(days))
+	for _, d := range days {
+		if d != nil {
+			byDate[d.Date] = d
+		}
+	}
+	sorted := make([]*DailyWeather, 0, daysBetween(start, end)+1)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		day, ok := byDate[d.Format(DateLayout)]
+		if !ok {
+			return nil, fmt.Errorf("天气服务缺少 %s 的数据", d.Format(DateLayout))
+		}
+		sorted = append(sorted, day)
+	}
+	return sorted, nil
+}
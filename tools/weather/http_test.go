@@ -0,0 +1,98 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPProviderForecast(t *testing.T) {
+	server := NewFakeServer("secret")
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		city     string
+		wantDays int
+		wantErr  error  // 期望通过 errors.Is 匹配的错误
+		errText  string // 期望错误信息包含的文本
+	}{
+		{name: "查询成功", apiKey: "secret", city: "上海", wantDays: 3},
+		{name: "404 映射为 ErrCityNotFound", apiKey: "secret", city: "Atlantis", wantErr: ErrCityNotFound},
+		{name: "API Key 错误", apiKey: "wrong", city: "上海", errText: "401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewHTTPProvider(&HTTPConfig{BaseURL: server.URL + "/", APIKey: tt.apiKey, Client: server.Client()})
+			if err != nil {
+				t.Fatalf("NewHTTPProvider 返回错误: %v", err)
+			}
+			days, err := provider.Forecast(context.Background(), ResolveCity(tt.city), date("2024-08-19"), date("2024-08-21"))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("错误 = %v，期望 %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("错误 = %v，期望包含 %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Fatalf("Forecast 返回错误: %v", err)
+			case len(days) != tt.wantDays:
+				t.Fatalf("返回 %d 天，期望 %d 天", len(days), tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestToolQuery(t *testing.T) {
+	server := NewFakeServer("")
+	defer server.Close()
+	weatherTool, err := New(&Config{Provider: server.Provider(), Now: func() time.Time { return date("2024-08-19") }})
+	if err != nil {
+		t.Fatalf("New 返回错误: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		req       Request
+		wantUnit  string
+		wantDays  int
+		wantErrIs error
+		wantErr   bool
+	}{
+		{name: "默认公制", req: Request{City: "北京", Date: "明天"}, wantUnit: "°C", wantDays: 1},
+		{name: "英制", req: Request{City: "Beijing", StartDate: "2024-08-19", EndDate: "2024-08-21", Units: UnitsImperial}, wantUnit: "°F", wantDays: 3},
+		{name: "城市不存在", req: Request{City: "Atlantis"}, wantErrIs: ErrCityNotFound},
+		{name: "缺少城市", req: Request{City: " "}, wantErr: true},
+		{name: "单位制无效", req: Request{City: "北京", Units: "kelvin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := weatherTool.Query(context.Background(), &tt.req)
+			switch {
+			case tt.wantErrIs != nil:
+				if !errors.Is(err, tt.wantErrIs) {
+					t.Fatalf("错误 = %v，期望 %v", err, tt.wantErrIs)
+				}
+				return
+			case tt.wantErr:
+				if err == nil {
+					t.Fatal("期望返回错误")
+				}
+				return
+			case err != nil:
+				t.Fatalf("Query 返回错误: %v", err)
+			}
+			if result.Unit != tt.wantUnit || len(result.Forecast) != tt.wantDays {
+				t.Errorf("结果单位 %s、%d 天，期望 %s、%d 天", result.Unit, len(result.Forecast), tt.wantUnit, tt.wantDays)
+			}
+		})
+	}
+}
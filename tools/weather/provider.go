@@ -0,0 +1,38 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/provider.go
+//  功能: 天气数据源抽象。工具只依赖 Provider 接口，数据可以来自 HTTP 服务 (HTTPProvider)、
+//        带缓存的包装 (CachedProvider) 或离线测试用的本地假服务 (FakeServer)。
+//  说明: 所有温度、风速等数值统一使用公制 (摄氏度、km/h、毫米)，单位换算由工具完成。
+//        日期为不带时区的自然日，用 UTC 零点的 time.Time 表示 (见 ParseDate)。
+//
+// =============================================================================
+
+// ErrCityNotFound 数据源不认识该城市
+var ErrCityNotFound = errors.New("未找到城市")
+
+// DailyWeather 一天的天气
+type DailyWeather struct {
+	Date          string  `json:"date"`          // YYYY-MM-DD
+	Condition     string  `json:"condition"`     // 天气现象，如 晴、多云、小雨
+	TempMax       float64 `json:"temp_max"`      // 最高温度 (°C)
+	TempMin       float64 `json:"temp_min"`      // 最低温度 (°C)
+	Humidity      int     `json:"humidity"`      // 相对湿度 (%)
+	WindSpeed     float64 `json:"wind_speed"`    // 风速 (km/h)
+	Precipitation float64 `json:"precipitation"` // 降水量 (mm)
+}
+
+// Provider 天气数据源
+type Provider interface {
+	// Forecast 返回城市在 [start, end] 闭区间内每一天的天气，按日期升序排列。
+	// start、end 为 UTC 零点，调用方保证 start 不晚于 end；不认识城市时返回 ErrCityNotFound。
+	Forecast(ctx context.Context, city *City, start, end time.Time) ([]*DailyWeather, error)
+}
@@ -0,0 +1,261 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// =============================================================================
+//
+//  文件: tools/weather/tool.go
+//  功能: 天气查询工具，实现 tool.InvokableTool，数据来自任意 Provider。
+//  参数: city (中文或英文名称) 以及 date 或 start_date / end_date (见 date.go)
+//  选项: WithUnits 设置单位制，metric (°C、km/h、mm，默认) 或 imperial (°F、mph、in)
+//
+// =============================================================================
+
+// 单位制
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+// 默认配置
+const (
+	DefaultToolName     = "get_weather"
+	DefaultMaxRangeDays = 7  // 一次最多查询 7 天
+	DefaultForecastDays = 14 // 最晚查询到今天之后 14 天
+)
+
+// Config 天气工具的配置
+type Config struct {
+	// Provider 天气数据源 (必填)，通常为 NewCachedProvider 包装的 HTTPProvider
+	Provider Provider
+	// Name 工具名称，默认 DefaultToolName
+	Name string
+	// MaxRangeDays 一次最多查询的天数，默认 DefaultMaxRangeDays
+	MaxRangeDays int
+	// ForecastDays 最晚可以查询今天之后多少天，默认 DefaultForecastDays
+	ForecastDays int
+	// Now 返回当前时间，用于计算 "今天"，默认 time.Now
+	Now func() time.Time
+}
+
+// Tool 天气查询工具
+type Tool struct {
+	config Config
+}
+
+// Options 天气工具的调用选项
+type Options struct {
+	Units string // 单位制，默认 UnitsMetric
+}
+
+// WithUnits 设置查询结果使用的单位制 (UnitsMetric 或 UnitsImperial)
+func WithUnits(units string) tool.Option {
+	return tool.WrapImplSpecificOptFn(func(o *Options) {
+		o.Units = units
+	})
+}
+
+// Request 查询请求
+type Request struct {
+	City      string `json:"city"`
+	Date      string `json:"date,omitempty"`
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+	Units     string `json:"-"` // 单位制，工具调用时由 WithUnits 选项设置
+}
+
+// UnitLabels 结果中各数值的单位
+type UnitLabels struct {
+	Temperature   string `json:"temperature"`
+	WindSpeed     string `json:"wind_speed"`
+	Precipitation string `json:"precipitation"`
+}
+
+// Result 查询结果。temperature、humidity、condition、wind_speed 为第一天的概况，
+// forecast 为范围内每一天的天气。
+type Result struct {
+	City        string          `json:"city"`               // 中文名称
+	CityEnglish string          `json:"city_en"`            // 英文名称
+	Date        string          `json:"date"`               // 开始日期
+	EndDate     string          `json:"end_date,omitempty"` // 结束日期，只有查询多天时返回
+	Units       string          `json:"units"`
+	Unit        string          `json:"unit"`        // 温度单位
+	Temperature float64         `json:"temperature"` // 第一天最高、最低温度的平均值
+	Humidity    int             `json:"humidity"`
+	Condition   string          `json:"condition"`
+	WindSpeed   float64         `json:"wind_speed"`
+	Forecast    []*DailyWeather `json:"forecast"`
+	UnitLabels  UnitLabels      `json:"unit_labels"`
+	Description string          `json:"description"`
+}
+
+// New 创建天气查询工具
+func New(config *Config) (*Tool, error) {
+	if config == nil || config.Provider == nil {
+		return nil, errors.New("必须配置天气数据源")
+	}
+	cfg := *config
+	if cfg.Name == "" {
+		cfg.Name = DefaultToolName
+	}
+	if cfg.MaxRangeDays <= 0 {
+		cfg.MaxRangeDays = DefaultMaxRangeDays
+	}
+	if cfg.ForecastDays <= 0 {
+		cfg.ForecastDays = DefaultForecastDays
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Tool{config: cfg}, nil
+}
+
+// Info 返回工具的元信息和参数定义
+func (t *Tool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{
+		Name: t.config.Name,
+		Desc: fmt.Sprintf("查询城市的天气，支持单日和最多 %d 天的日期范围", t.config.MaxRangeDays),
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"city": {
+				Type:     schema.String,
+				Desc:     "城市名称，中文或英文均可，如 北京、Beijing",
+				Required: true,
+			},
+			"date": {
+				Type: schema.String,
+				Desc: "查询单日 (YYYY-MM-DD，或 今天 / 明天 / 后天)，不填时为今天",
+			},
+			"start_date": {
+				Type: schema.String,
+				Desc: "查询日期范围的开始日期 (YYYY-MM-DD)，不能与 date 同时使用",
+			},
+			"end_date": {
+				Type: schema.String,
+				Desc: fmt.Sprintf("查询日期范围的结束日期 (YYYY-MM-DD)，最晚为今天之后 %d 天", t.config.ForecastDays),
+			},
+		}),
+	}, nil
+}
+
+// InvokableRun 执行天气查询，返回 JSON 格式的 Result
+func (t *Tool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var req Request
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+	req.Units = tool.GetImplSpecificOptions(&Options{Units: UnitsMetric}, opts...).Units
+
+	result, err := t.Query(ctx, &req)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("结果序列化失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// Query 查询天气
+func (t *Tool) Query(ctx context.Context, req *Request) (*Result, error) {
+	units := req.Units
+	if units == "" {
+		units = UnitsMetric
+	}
+	if units != UnitsMetric && units != UnitsImperial {
+		return nil, fmt.Errorf("不支持的单位制: %s (可选 %s、%s)", units, UnitsMetric, UnitsImperial)
+	}
+	city := ResolveCity(req.City)
+	if city == nil {
+		return nil, errors.New("缺少城市名称")
+	}
+	start, end, err := resolveDates(req.Date, req.StartDate, req.EndDate, t.config.Now(), dateLimits{
+		maxRangeDays: t.config.MaxRangeDays,
+		forecastDays: t.config.ForecastDays,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[WeatherTool] 查询 %s(%s) %s ~ %s 的天气 (%s)", city.Name, city.ID,
+		start.Format(DateLayout), end.Format(DateLayout), units)
+
+	days, err := t.config.Provider.Forecast(ctx, city, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 的天气失败: %w", req.City, err)
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("天气数据源没有返回 %s 的数据", req.City)
+	}
+	return buildResult(city, days, units), nil
+}
+
+// buildResult 按单位制换算并生成结果
+func buildResult(city *City, days []*DailyWeather, units string) *Result {
+	labels := UnitLabels{Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"}
+	forecast := make([]*DailyWeather, len(days))
+	for i, d := range days {
+		converted := *d
+		if units == UnitsImperial {
+			converted.TempMax = round1(d.TempMax*9/5 + 32)
+			converted.TempMin = round1(d.TempMin*9/5 + 32)
+			converted.WindSpeed = round1(d.WindSpeed / 1.609344)
+			converted.Precipitation = math.Round(d.Precipitation/25.4*100) / 100
+		}
+		forecast[i] = &converted
+	}
+	if units == UnitsImperial {
+		labels = UnitLabels{Temperature: "°F", WindSpeed: "mph", Precipitation: "in"}
+	}
+
+	first, last := forecast[0], forecast[len(forecast)-1]
+	result := &Result{
+		City:        city.Name,
+		CityEnglish: city.English,
+		Date:        first.Date,
+		Units:       units,
+		Unit:        labels.Temperature,
+		Temperature: round1((first.TempMax + first.TempMin) / 2),
+		Humidity:    first.Humidity,
+		Condition:   first.Condition,
+		WindSpeed:   first.WindSpeed,
+		Forecast:    forecast,
+		UnitLabels:  labels,
+	}
+	if len(forecast) == 1 {
+		result.Description = fmt.Sprintf("%s %s %s，%.1f~%.1f%s，湿度 %d%%，风速 %.1f %s",
+			city.Name, first.Date, first.Condition, first.TempMin, first.TempMax, labels.Temperature,
+			first.Humidity, first.WindSpeed, labels.WindSpeed)
+		return result
+	}
+
+	result.EndDate = last.Date
+	high, low := math.Inf(-1), math.Inf(1)
+	var wet []string
+	for _, d := range forecast {
+		high, low = math.Max(high, d.TempMax), math.Min(low, d.TempMin)
+		if d.Precipitation > 0 {
+			wet = append(wet, d.Date[5:]+" "+d.Condition)
+		}
+	}
+	result.Description = fmt.Sprintf("%s %s ~ %s 共 %d 天，气温 %.1f~%.1f%s", city.Name, first.Date, last.Date,
+		len(forecast), low, high, labels.Temperature)
+	if len(wet) > 0 {
+		result.Description += "，有降水: " + strings.Join(wet, "、")
+	} else {
+		result.Description += "，无降水"
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package webfetch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// =============================================================================
+//
+//  文件: tools/webfetch/fake.go
+//  功能: 基于 httptest 的本地文档站点和配套的域名解析，用于离线演示和测试。
+//  域名: docs.example.test 和 www.example.test 解析到本地站点；
+//        metadata.example.test 解析到云服务器元数据地址 169.254.169.254，
+//        intranet.example.test 解析到内网地址 10.0.0.8，用于演示 SSRF 防护 (不会真正连接)。
+//  页面: /guide (带导航、脚本、列表、代码和表格的文章)、/gbk (GBK 编码)、/api/status (JSON)、
+//        /old-guide (重定向到 /guide)、/metadata (重定向到元数据地址)、/loop (无限重定向)、
+//        /logo.png (二进制内容)，其他路径返回 404。
+//
+// =============================================================================
+
+// FakeSite 本地文档站点
+type FakeSite struct {
+	*httptest.Server
+	port string
+}
+
+// NewFakeSite 启动本地文档站点，使用完毕后调用 Close 关闭
+func NewFakeSite() *FakeSite {
+	s := &FakeSite{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /guide", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, fakeGuide)
+	})
+	mux.HandleFunc("GET /gbk", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := simplifiedchinese.GBK.NewEncoder().String(fakeGBKPage)
+		w.Header().Set("Content-Type", "text/html") // 没有声明编码，需要从 <meta> 中识别
+		fmt.Fprint(w, data)
+	})
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "ok", "version": "2.3.1", "services": {"search": "ok", "index": "degraded"}}`)
+	})
+	mux.HandleFunc("GET /old-guide", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/guide", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("GET /metadata", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://metadata.example.test/latest/meta-data/", http.StatusFound)
+	})
+	mux.HandleFunc("GET /loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("GET /logo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "页面不存在", http.StatusNotFound)
+	})
+
+	s.Server = httptest.NewServer(mux)
+	_, s.port, _ = net.SplitHostPort(s.Listener.Addr().String())
+	return s
+}
+
+// URL 返回站点中页面的地址，如 http://docs.example.test:端口/guide
+func (s *FakeSite) URL(path string) string {
+	return s.HostURL("docs.example.test", path)
+}
+
+// HostURL 返回指定域名下页面的地址，端口为本地站点的端口
+func (s *FakeSite) HostURL(host, path string) string {
+	return "http://" + net.JoinHostPort(host, s.port) + path
+}
+
+// Network 本地站点监听的地址，需要加入 Config.AllowedNetworks 才能访问
+func (s *FakeSite) Network() string {
+	host, _, _ := net.SplitHostPort(s.Listener.Addr().String())
+	return host
+}
+
+// LookupIP 解析 *.example.test，用作 Config.LookupIP
+func (s *FakeSite) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	switch strings.ToLower(host) {
+	case "docs.example.test", "www.example.test":
+		return []net.IP{net.ParseIP(s.Network())}, nil
+	case "metadata.example.test":
+		return []net.IP{net.ParseIP("169.254.169.254")}, nil
+	case "intranet.example.test":
+		return []net.IP{net.ParseIP("10.0.0.8")}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// fakeGuide 文档站点的文章页
+const fakeGuide = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>Eino 工具开发指南 - 文档中心</title>
+  <meta name="description" content="介绍如何为 Eino 编写、测试和注册工具">
+  <style>body { font-family: sans-serif; }</style>
+  <script>window.analytics = { track: function () {} };</script>
+</head>
+<body>
+  <nav><a href="/">首页</a> | <a href="/docs">文档</a> | <a href="/blog">博客</a></nav>
+  <main>
+    <h1>Eino 工具开发指南</h1>
+    <p>工具让模型可以<strong>调用外部能力</strong>，例如查询天气、
+       读取文件或者访问
+       <a href="/api/status">内部接口</a>。</p>
+    <h2>开发步骤</h2>
+    <ol>
+      <li>定义参数结构体</li>
+      <li>实现 <code>Info</code> 和 <code>InvokableRun</code>
+        <ul>
+          <li>参数校验</li>
+          <li>错误处理</li>
+        </ul>
+      </li>
+      <li>注册到 ToolsNode</li>
+    </ol>
+    <h2>示例代码</h2>
+    <pre><code class="language-go">func (t *Tool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return info, nil
+}</code></pre>
+    <blockquote>提示: 工具返回的 error 会中断整个 ToolsNode 调用。</blockquote>
+    <h2>内置工具</h2>
+    <table>
+      <tr><th>名称</th><th>说明</th></tr>
+      <tr><td>file_manager</td><td>限定目录的文件管理</td></tr>
+      <tr><td>http_fetch</td><td>读取网页和接口 | 支持白名单</td></tr>
+    </table>
+    <p><img src="/logo.png" alt="Eino 标志"> 更多内容见<a href="https://github.com/cloudwego/eino">项目主页</a>。</p>
+    <div hidden>隐藏的内容</div>
+  </main>
+  <aside>相关文章: ...</aside>
+  <footer>© 2024 文档中心</footer>
+</body>
+</html>`
+
+// fakeGBKPage GBK 编码的页面，编码只在 <meta> 中声明
+const fakeGBKPage = `<html><head><meta http-equiv="Content-Type" content="text/html; charset=gbk">
+<title>旧版公告</title></head>
+<body><h1>系统维护公告</h1><p>本周六凌晨两点至四点进行系统维护，期间暂停服务。</p></body></html>`
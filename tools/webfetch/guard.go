@@ -0,0 +1,177 @@
+package webfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// =============================================================================
+//
+//  文件: tools/webfetch/guard.go
+//  功能: 请求的安全检查: 域名白名单、协议、重定向和 SSRF 防护。
+//  说明: 域名在发出请求前和每次重定向时检查；IP 在建立连接时检查 —— 自行解析域名，
+//        只连接通过检查的 IP，DNS 重新绑定 (检查时解析到公网、连接时解析到内网) 无法绕过。
+//        不使用环境变量中的代理，否则连接的是代理而不是目标地址，IP 检查会失效。
+//
+// =============================================================================
+
+// ErrBlocked 请求被安全策略拒绝 (域名不在白名单、内网地址、不允许的方法等)
+var ErrBlocked = errors.New("请求被安全策略拒绝")
+
+// blockedPrefixes 默认禁止连接的地址段: 本机、内网、链路本地 (含云服务器元数据地址 169.254.169.254)、
+// 运营商级 NAT、组播和保留地址
+var blockedPrefixes = mustPrefixes(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8", "64:ff9b::/96",
+)
+
+func mustPrefixes(cidrs ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+	}
+	return prefixes
+}
+
+// parseNetworks 解析 AllowedNetworks，单个 IP 视为 /32 或 /128
+func parseNetworks(networks []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, n := range networks {
+		if !strings.Contains(n, "/") {
+			addr, err := netip.ParseAddr(n)
+			if err != nil {
+				return nil, fmt.Errorf("允许的网络 %s 无效: %w", n, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(n)
+		if err != nil {
+			return nil, fmt.Errorf("允许的网络 %s 无效: %w", n, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// domainAllowed 判断主机是否在白名单中。
+// "example.com" 只匹配该域名本身，"*.example.com" 匹配其所有子域名 (不含 example.com)，IP 按字面匹配。
+func (f *Fetcher) domainAllowed(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, pattern := range f.domains {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL 检查协议和域名
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: 只支持 http 和 https，不支持 %s", ErrBlocked, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: 地址中不能包含用户名和密码", ErrBlocked)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("地址 %s 缺少主机名", u.Redacted())
+	}
+	if !f.domainAllowed(u.Hostname()) {
+		return fmt.Errorf("%w: 域名 %s 不在允许访问的列表中", ErrBlocked, u.Hostname())
+	}
+	return nil
+}
+
+// ipAllowed 判断是否可以连接该 IP
+func (f *Fetcher) ipAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap() // ::ffff:127.0.0.1 按 IPv4 检查
+	for _, prefix := range f.networks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if f.config.AllowPrivateNetworks {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialContext 解析域名并只连接通过检查的 IP
+func (f *Fetcher) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		ips, err := f.lookupIP(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("解析域名 %s 失败: %w", host, err)
+		}
+		for _, ip := range ips {
+			if addr, ok := netip.AddrFromSlice(ip); ok {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		if !f.ipAllowed(addr) {
+			lastErr = fmt.Errorf("%w: %s 指向内网或保留地址 %s", ErrBlocked, host, addr.Unmap())
+			continue
+		}
+		conn, err := f.dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("域名 %s 没有可用的地址", host)
+	}
+	return nil, lastErr
+}
+
+// lookupIP 解析域名，可以通过 Config.LookupIP 替换 (测试和离线演示)
+func (f *Fetcher) lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if f.config.LookupIP != nil {
+		return f.config.LookupIP(ctx, host)
+	}
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// checkRedirect 限制重定向次数，并对重定向目标重新检查协议和域名。
+// MaxRedirects 小于 0 时不跟随重定向，把重定向响应本身返回给模型。
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if f.config.MaxRedirects < 0 {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > f.config.MaxRedirects {
+		return fmt.Errorf("%w: 重定向超过 %d 次", ErrBlocked, f.config.MaxRedirects)
+	}
+	if err := f.checkURL(req.URL); err != nil {
+		return fmt.Errorf("重定向到 %s 被拒绝: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
@@ -0,0 +1,581 @@
+package webfetch
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"Eini/tools/textutil"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// =============================================================================
+//
+//  文件: tools/webfetch/html.go
+//  功能: 把 HTML 页面转换为便于模型阅读的 Markdown 或纯文本。
+//  说明: 正文优先取 <main>，其次是唯一的 <article>，否则取 <body>；
+//        脚本、样式、导航、侧栏和隐藏元素不输出。链接和图片地址解析为绝对地址。
+//        纯文本模式保留段落、列表和表格的结构，只去掉 Markdown 标记。
+//
+// =============================================================================
+
+// page 从 HTML 中提取的内容
+type page struct {
+	Title       string
+	Description string
+	Content     string
+}
+
+// skippedElements 不输出内容的元素
+var skippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Canvas: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Nav: true, atom.Aside: true, atom.Button: true, atom.Select: true, atom.Input: true,
+	atom.Textarea: true, atom.Dialog: true,
+}
+
+// paragraphElements 前后空一行的块级元素
+var paragraphElements = map[atom.Atom]bool{
+	atom.P: true, atom.Figure: true, atom.Details: true, atom.Dl: true, atom.Address: true, atom.Fieldset: true,
+}
+
+// lineElements 前后换行的块级元素
+var lineElements = map[atom.Atom]bool{
+	atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true, atom.Header: true,
+	atom.Footer: true, atom.Figcaption: true, atom.Summary: true, atom.Dt: true, atom.Dd: true,
+	atom.Form: true, atom.Caption: true,
+}
+
+// extractPage 解析 HTML 并转换为 Markdown (markdown 为 true) 或纯文本
+func extractPage(data []byte, base *url.URL, markdown bool) (*page, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &page{}
+	var body, mainNode *html.Node
+	var articles []*html.Node
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom {
+		case atom.Title:
+			if p.Title == "" {
+				p.Title = collapseSpace(textOf(n))
+			}
+		case atom.Meta:
+			name := strings.ToLower(attr(n, "name") + attr(n, "property"))
+			if p.Description == "" && (name == "description" || name == "og:description") {
+				p.Description = collapseSpace(attr(n, "content"))
+			}
+		case atom.Base:
+			if href, err := url.Parse(attr(n, "href")); err == nil && attr(n, "href") != "" {
+				base = base.ResolveReference(href)
+			}
+		case atom.Body:
+			body = n
+		case atom.Main:
+			if mainNode == nil {
+				mainNode = n
+			}
+		case atom.Article:
+			articles = append(articles, n)
+		}
+		return true
+	})
+
+	root := body
+	switch {
+	case mainNode != nil:
+		root = mainNode
+	case len(articles) == 1:
+		root = articles[0]
+	}
+	if root == nil {
+		root = doc
+	}
+
+	r := &renderer{markdown: markdown, base: base, lineStart: true}
+	r.children(root)
+	p.Content = tidy(r.buf.String())
+	return p, nil
+}
+
+// renderer 把节点树输出为文本
+type renderer struct {
+	buf       bytes.Buffer
+	markdown  bool
+	base      *url.URL
+	prefix    string // 每行的前缀: 引用为 "> "，列表项的后续行为缩进
+	lineStart bool   // 当前位于行首，输出内容前要先输出前缀
+	space     bool   // 有待输出的空白 (连续空白合并为一个空格)
+	pre       int    // 位于 <pre> 内，保留原始空白
+	lists     []*listState
+}
+
+// listState 列表的类型和当前序号
+type listState struct {
+	ordered bool
+	n       int
+}
+
+// write 输出内容，行首先输出前缀
+func (r *renderer) write(s string) {
+	if s == "" {
+		return
+	}
+	if r.lineStart {
+		r.buf.WriteString(r.prefix)
+		r.lineStart = false
+	}
+	r.buf.WriteString(s)
+}
+
+// newline 换行
+func (r *renderer) newline() {
+	r.buf.WriteByte('\n')
+	r.lineStart, r.space = true, false
+}
+
+// ensureLine 当前行有内容时换行
+func (r *renderer) ensureLine() {
+	if !r.lineStart {
+		r.newline()
+	}
+	r.space = false
+}
+
+// blankLine 确保后面的内容与前面隔一个空行
+func (r *renderer) blankLine() {
+	r.ensureLine()
+	if r.buf.Len() == 0 || bytes.HasSuffix(r.buf.Bytes(), []byte("\n\n")) {
+		return
+	}
+	r.buf.WriteString(strings.TrimRight(r.prefix, " "))
+	r.newline()
+}
+
+// text 输出文本节点，<pre> 之外合并空白
+func (r *renderer) text(s string) {
+	if r.pre > 0 {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if i > 0 {
+				r.newline()
+			}
+			r.write(line)
+		}
+		return
+	}
+
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" {
+			r.space = true
+		}
+		return
+	}
+	if unicode.IsSpace(rune(s[0])) {
+		r.space = true
+	}
+	for i, w := range words {
+		if (i > 0 || r.space) && !r.lineStart && !r.joinsWithoutSpace(w) {
+			r.write(" ")
+		}
+		r.write(w)
+		r.space = false
+	}
+	if last, _ := utf8.DecodeLastRuneInString(s); unicode.IsSpace(last) {
+		r.space = true
+	}
+}
+
+// joinsWithoutSpace 中文 (含全角标点) 之间的换行和空白不输出为空格
+func (r *renderer) joinsWithoutSpace(next string) bool {
+	prev, _ := utf8.DecodeLastRune(r.buf.Bytes())
+	first, _ := utf8.DecodeRuneInString(next)
+	return isCJKText(prev) && isCJKText(first)
+}
+
+// isCJKText 中日文字和全角标点
+func isCJKText(r rune) bool {
+	return textutil.IsCJK(r) || (r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// children 依次输出子节点
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.node(c)
+	}
+}
+
+// node 输出一个节点
+func (r *renderer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.children(n)
+		return
+	}
+	if skippedElements[n.DataAtom] || hidden(n) {
+		return
+	}
+
+	switch a := n.DataAtom; {
+	case a == atom.H1 || a == atom.H2 || a == atom.H3 || a == atom.H4 || a == atom.H5 || a == atom.H6:
+		r.blankLine()
+		if r.markdown {
+			level, _ := strconv.Atoi(n.Data[1:])
+			r.write(strings.Repeat("#", level) + " ")
+		}
+		r.children(n)
+		r.blankLine()
+	case paragraphElements[a]:
+		r.blankLine()
+		r.children(n)
+		r.blankLine()
+	case lineElements[a]:
+		r.ensureLine()
+		r.children(n)
+		r.ensureLine()
+	case a == atom.Br:
+		r.newline()
+	case a == atom.Hr:
+		r.blankLine()
+		if r.markdown {
+			r.write("---")
+		}
+		r.blankLine()
+	case a == atom.Pre:
+		r.preformatted(n)
+	case a == atom.Blockquote:
+		r.blankLine()
+		saved := r.prefix
+		if r.markdown {
+			r.prefix += "> "
+		} else {
+			r.prefix += "    "
+		}
+		r.children(n)
+		r.ensureLine()
+		r.prefix = saved
+		r.blankLine()
+	case a == atom.Ul || a == atom.Ol:
+		r.list(n, a == atom.Ol)
+	case a == atom.Li:
+		r.listItem(n)
+	case a == atom.Table:
+		r.table(n)
+	case a == atom.A:
+		r.link(n)
+	case a == atom.Img:
+		r.image(n)
+	case a == atom.Strong || a == atom.B:
+		r.wrap(n, "**")
+	case a == atom.Em || a == atom.I:
+		r.wrap(n, "*")
+	case a == atom.Del || a == atom.S:
+		r.wrap(n, "~~")
+	case a == atom.Code || a == atom.Kbd || a == atom.Samp:
+		r.wrap(n, "`")
+	default:
+		r.children(n)
+	}
+}
+
+// capture 输出子节点并返回这部分内容，之后可以用 replace 替换
+func (r *renderer) capture(n *html.Node) (start int, content string) {
+	if r.lineStart {
+		r.write(" ") // 先输出行首前缀，替换时不包含前缀
+		r.buf.Truncate(r.buf.Len() - 1)
+	}
+	start = r.buf.Len()
+	r.children(n)
+	return start, r.buf.String()[start:]
+}
+
+// replace 把 start 之后输出的内容替换为 open + 内容 + close，内容首尾的空格留在标记之外
+func (r *renderer) replace(start int, content, open, close string) {
+	trimmed := strings.Trim(content, " ")
+	leading := content[:strings.Index(content, trimmed)]
+	trailing := content[len(leading)+len(trimmed):]
+	r.buf.Truncate(start)
+	r.buf.WriteString(leading + open + trimmed + close + trailing)
+}
+
+// wrap 用 Markdown 标记包围行内内容，纯文本模式或内容为空时不加标记
+func (r *renderer) wrap(n *html.Node, mark string) {
+	if !r.markdown || r.pre > 0 {
+		r.children(n)
+		return
+	}
+	start, content := r.capture(n)
+	if strings.TrimSpace(content) == "" || strings.Contains(content, "\n") {
+		return
+	}
+	r.replace(start, content, mark, mark)
+}
+
+// link 输出链接，Markdown 模式为 [文本](绝对地址)
+func (r *renderer) link(n *html.Node) {
+	href := r.resolve(attr(n, "href"))
+	if !r.markdown || href == "" {
+		r.children(n)
+		return
+	}
+	start, content := r.capture(n)
+	if strings.TrimSpace(content) == "" || strings.Contains(content, "\n") {
+		return
+	}
+	r.replace(start, strings.ReplaceAll(content, "]", `\]`), "[", "]("+href+")")
+}
+
+// image 输出图片: Markdown 模式为 ![说明](地址)，纯文本模式为 [图片: 说明]，没有说明的图片不输出
+func (r *renderer) image(n *html.Node) {
+	alt := collapseSpace(attr(n, "alt"))
+	if alt == "" {
+		return
+	}
+	if src := r.resolve(attr(n, "src")); r.markdown && src != "" {
+		r.text(" ")
+		r.write("![" + alt + "](" + src + ")")
+		return
+	}
+	r.text(" [图片: " + alt + "] ")
+}
+
+// resolve 把链接解析为绝对地址，忽略页内锚点和 javascript: 等非 http 链接
+func (r *renderer) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	u = r.base.ResolveReference(u)
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto" {
+		return ""
+	}
+	return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(u.String())
+}
+
+// preformatted 输出 <pre>，Markdown 模式使用代码块，语言取自 class="language-xxx"
+func (r *renderer) preformatted(n *html.Node) {
+	r.blankLine()
+	if r.markdown {
+		lang := ""
+		for _, node := range []*html.Node{n, n.FirstChild} {
+			if node == nil || node.Type != html.ElementNode {
+				continue
+			}
+			for _, class := range strings.Fields(attr(node, "class")) {
+				if l, ok := strings.CutPrefix(class, "language-"); ok && lang == "" {
+					lang = l
+				}
+			}
+		}
+		r.write("```" + lang)
+		r.newline()
+	}
+	r.pre++
+	r.children(n)
+	r.pre--
+	r.ensureLine()
+	if r.markdown {
+		r.write("```")
+	}
+	r.blankLine()
+}
+
+// list 输出列表，嵌套列表紧跟在上一级列表项之后
+func (r *renderer) list(n *html.Node, ordered bool) {
+	if len(r.lists) > 0 {
+		r.ensureLine()
+	} else {
+		r.blankLine()
+	}
+	start := 1
+	if s, err := strconv.Atoi(attr(n, "start")); err == nil {
+		start = s
+	}
+	r.lists = append(r.lists, &listState{ordered: ordered, n: start})
+	r.children(n)
+	r.lists = r.lists[:len(r.lists)-1]
+	if len(r.lists) > 0 {
+		r.ensureLine()
+	} else {
+		r.blankLine()
+	}
+}
+
+// listItem 输出列表项，后续行按标记的宽度缩进
+func (r *renderer) listItem(n *html.Node) {
+	r.ensureLine()
+	marker := "- "
+	if !r.markdown {
+		marker = "• "
+	}
+	if len(r.lists) > 0 {
+		if l := r.lists[len(r.lists)-1]; l.ordered {
+			marker = strconv.Itoa(l.n) + ". "
+			l.n++
+		}
+	}
+	r.write(marker)
+	saved := r.prefix
+	r.prefix += strings.Repeat(" ", utf8.RuneCountInString(marker))
+	r.children(n)
+	r.ensureLine()
+	r.prefix = saved
+}
+
+// table 输出表格: Markdown 模式第一行为表头，纯文本模式单元格之间用 " | " 分隔
+func (r *renderer) table(n *html.Node) {
+	var rows [][]string
+	walk(n, func(c *html.Node) bool {
+		if c != n && c.DataAtom == atom.Table {
+			return false // 嵌套表格作为单元格内容
+		}
+		if c.DataAtom != atom.Tr {
+			return true
+		}
+		var cells []string
+		for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				cells = append(cells, r.cell(cell))
+			}
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return
+	}
+
+	r.blankLine()
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		if r.markdown {
+			r.write("| " + strings.Join(row, " | ") + " |")
+		} else {
+			r.write(strings.Join(row, " | "))
+		}
+		r.newline()
+		if i == 0 && r.markdown {
+			r.write("|" + strings.Repeat(" --- |", width))
+			r.newline()
+		}
+	}
+	r.blankLine()
+}
+
+// cell 把单元格内容输出为一行
+func (r *renderer) cell(n *html.Node) string {
+	sub := &renderer{markdown: r.markdown, base: r.base, lineStart: true}
+	sub.children(n)
+	content := collapseSpace(sub.buf.String())
+	if r.markdown {
+		content = strings.ReplaceAll(content, "|", `\|`)
+	}
+	return content
+}
+
+// ================================
+// 辅助函数
+// ================================
+
+// walk 深度优先遍历，visit 返回 false 时不进入子节点
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, visit)
+	}
+}
+
+// attr 返回属性值
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// displayNone 内联样式中隐藏元素的写法
+var displayNone = regexp.MustCompile(`(?i)(display\s*:\s*none|visibility\s*:\s*hidden)`)
+
+// hidden 判断元素是否被隐藏
+func hidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "hidden":
+			return true
+		case a.Key == "aria-hidden" && a.Val == "true":
+			return true
+		case a.Key == "style" && displayNone.MatchString(a.Val):
+			return true
+		}
+	}
+	return false
+}
+
+// textOf 返回节点内的全部文本
+func textOf(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+		return true
+	})
+	return sb.String()
+}
+
+// collapseSpace 合并连续空白
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// tidy 去掉行尾空白，合并多余的空行
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
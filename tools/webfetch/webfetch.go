@@ -0,0 +1,523 @@
+package webfetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"golang.org/x/net/html/charset"
+)
+
+// =============================================================================
+//
+//  文件: tools/webfetch/webfetch.go
+//  功能: 读取网页或内部接口的 http_fetch 工具，实现 tool.InvokableTool。
+//  安全:
+//    - 只能访问白名单中的域名，重定向的每一跳都重新检查，并限制重定向次数
+//    - 默认禁止连接本机、内网和云服务器元数据等地址 (SSRF 防护)，见 guard.go
+//    - 限制请求方法、响应大小、返回给模型的字符数和请求总时间
+//  内容: HTML 转换为 Markdown (默认) 或纯文本，JSON 和其他文本原样返回，按声明或探测的编码转为 UTF-8。
+//        配置 DocumentTool 后可以把获取的内容直接交给文档处理工具索引到知识库。
+//
+// =============================================================================
+
+// 默认限制
+const (
+	DefaultMaxResponseBytes = 2 << 20 // 响应体最多读取 2MB
+	DefaultMaxContentChars  = 20000   // 返回给模型的内容最多 20000 个字符
+	DefaultTimeout          = 15 * time.Second
+	DefaultMaxRedirects     = 5
+	DefaultUserAgent        = "Eini-WebFetch/1.0"
+)
+
+// 输出格式
+const (
+	FormatMarkdown = "markdown" // HTML 转换为 Markdown
+	FormatText     = "text"     // HTML 转换为纯文本
+	FormatRaw      = "raw"      // 原始内容 (HTML 源码)
+)
+
+// Config http_fetch 工具的配置
+type Config struct {
+	// Name 工具名称，默认 http_fetch
+	Name string
+	// AllowedDomains 允许访问的域名 (必填)。"example.com" 只匹配该域名，"*.example.com" 匹配其子域名，也可以是 IP
+	AllowedDomains []string
+	// AllowedMethods 允许的请求方法，默认只允许 GET 和 HEAD
+	AllowedMethods []string
+	// AllowPrivateNetworks 允许连接本机和内网地址，关闭 SSRF 防护，只应在完全可信的环境中使用
+	AllowPrivateNetworks bool
+	// AllowedNetworks 例外允许连接的网段或 IP，如内部 API 所在的 "10.20.0.0/16"
+	AllowedNetworks []string
+
+	MaxResponseBytes int64         // 响应体最多读取的字节数，超出部分丢弃，默认 DefaultMaxResponseBytes
+	MaxContentChars  int           // 返回给模型的内容最多的字符数，默认 DefaultMaxContentChars
+	Timeout          time.Duration // 整个请求 (含重定向和读取响应) 的超时时间，默认 DefaultTimeout
+	MaxRedirects     int           // 最多跟随的重定向次数，默认 DefaultMaxRedirects，小于 0 时不跟随重定向
+
+	// UserAgent 请求的 User-Agent，默认 DefaultUserAgent
+	UserAgent string
+	// Headers 每个请求都附加的请求头，如内部 API 的认证信息 (模型无法修改)
+	Headers map[string]string
+
+	// DocumentTool 文档处理工具，参数为 {"content", "doc_id", "metadata"}，如 comprehensive_demo 的 document_processor；
+	// 配置后模型可以通过 index 参数把获取的内容索引到知识库
+	DocumentTool tool.InvokableTool
+
+	// LookupIP 解析域名，默认使用系统 DNS；测试和离线演示时可以替换
+	LookupIP func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// Fetcher http_fetch 工具
+type Fetcher struct {
+	config   Config
+	domains  []string
+	methods  []string
+	networks []netip.Prefix
+	dialer   *net.Dialer
+	client   *http.Client
+}
+
+// New 创建 http_fetch 工具
+func New(config *Config) (*Fetcher, error) {
+	if config == nil || len(config.AllowedDomains) == 0 {
+		return nil, errors.New("必须配置允许访问的域名")
+	}
+	cfg := *config
+	if cfg.Name == "" {
+		cfg.Name = "http_fetch"
+	}
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = []string{http.MethodGet, http.MethodHead}
+	}
+	if cfg.MaxResponseBytes <= 0 {
+		cfg.MaxResponseBytes = DefaultMaxResponseBytes
+	}
+	if cfg.MaxContentChars <= 0 {
+		cfg.MaxContentChars = DefaultMaxContentChars
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRedirects == 0 {
+		cfg.MaxRedirects = DefaultMaxRedirects
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+
+	networks, err := parseNetworks(cfg.AllowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fetcher{
+		config:   cfg,
+		networks: networks,
+		dialer:   &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	for _, d := range cfg.AllowedDomains {
+		f.domains = append(f.domains, strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), "."))
+	}
+	for _, m := range cfg.AllowedMethods {
+		f.methods = append(f.methods, strings.ToUpper(m))
+	}
+
+	f.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:               nil, // 不使用代理，见 guard.go
+			DialContext:         f.dialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		Timeout:       cfg.Timeout,
+		CheckRedirect: f.checkRedirect,
+	}
+	return f, nil
+}
+
+// Info 返回工具的元信息和参数定义
+func (f *Fetcher) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	desc := fmt.Sprintf("获取网页或接口的内容。网页转换为 Markdown 或纯文本，JSON 和其他文本原样返回。只能访问以下域名: %s",
+		strings.Join(f.config.AllowedDomains, ", "))
+	params := map[string]*schema.ParameterInfo{
+		"url": {
+			Type:     schema.String,
+			Desc:     "要获取的 http 或 https 地址",
+			Required: true,
+		},
+		"format": {
+			Type: schema.String,
+			Desc: "网页的输出格式: markdown (默认)、text 或 raw (HTML 源码)",
+			Enum: []string{FormatMarkdown, FormatText, FormatRaw},
+		},
+		"max_chars": {
+			Type: schema.Integer,
+			Desc: fmt.Sprintf("最多返回的字符数，默认且最多 %d", f.config.MaxContentChars),
+		},
+	}
+	if len(f.methods) > 1 {
+		params["method"] = &schema.ParameterInfo{
+			Type: schema.String,
+			Desc: "请求方法，默认 GET",
+			Enum: f.methods,
+		}
+	}
+	var bodyMethods []string
+	for _, m := range f.methods {
+		if hasBody(m) {
+			bodyMethods = append(bodyMethods, m)
+		}
+	}
+	if len(bodyMethods) > 0 {
+		params["body"] = &schema.ParameterInfo{
+			Type: schema.String,
+			Desc: "请求体，只用于 " + strings.Join(bodyMethods, " / ") + " 请求",
+		}
+		params["content_type"] = &schema.ParameterInfo{
+			Type: schema.String,
+			Desc: "请求体的类型，默认 application/json",
+		}
+	}
+	if f.config.DocumentTool != nil {
+		desc += "。设置 index 为 true 可以把获取的内容索引到知识库"
+		params["index"] = &schema.ParameterInfo{
+			Type: schema.Boolean,
+			Desc: "是否把获取的内容 (Markdown) 索引到知识库",
+		}
+		params["doc_id"] = &schema.ParameterInfo{
+			Type: schema.String,
+			Desc: "索引时使用的文档 ID，默认根据地址生成",
+		}
+	}
+
+	return &schema.ToolInfo{
+		Name:        f.config.Name,
+		Desc:        desc,
+		ParamsOneOf: schema.NewParamsOneOfByParams(params),
+	}, nil
+}
+
+// Request 工具参数
+type Request struct {
+	URL         string `json:"url"`
+	Method      string `json:"method,omitempty"`
+	Body        string `json:"body,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	MaxChars    int    `json:"max_chars,omitempty"`
+	Index       bool   `json:"index,omitempty"`
+	DocID       string `json:"doc_id,omitempty"`
+}
+
+// Result 返回给模型的结果
+type Result struct {
+	URL         string          `json:"url"`
+	FinalURL    string          `json:"final_url,omitempty"` // 跟随重定向后的地址，与 url 相同时省略
+	Redirects   []string        `json:"redirects,omitempty"` // 依次经过的重定向地址
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Format      string          `json:"format,omitempty"` // 网页的输出格式，其他内容原样返回时省略
+	Content     string          `json:"content"`
+	Bytes       int             `json:"bytes"`               // 读取的响应体字节数
+	Truncated   bool            `json:"truncated,omitempty"` // 响应体或内容超过限制，已被截断
+	Indexed     json.RawMessage `json:"indexed,omitempty"`   // 文档处理工具的返回结果
+}
+
+// fetchFailure 请求失败时返回给模型的结果
+type fetchFailure struct {
+	URL     string `json:"url"`
+	Error   string `json:"error"`
+	Blocked bool   `json:"blocked,omitempty"` // 被安全策略拒绝
+	Hint    string `json:"hint"`
+}
+
+// InvokableRun 获取内容，返回 Result 的 JSON。
+// 安全策略拒绝的请求、网络错误和 HTTP 状态码不是 2xx 时都不返回 error (ToolsNode 遇到 error 会中断整个调用)，
+// 而是作为结果返回给模型；只有参数无法解析、调用被取消或结果无法序列化时返回 error。
+func (f *Fetcher) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	var req Request
+	if err := json.Unmarshal([]byte(argumentsInJSON), &req); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	var output any
+	result, err := f.Fetch(ctx, &req)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return "", err
+	case errors.Is(err, ErrBlocked):
+		log.Printf("[WebFetch] 拒绝: %v", err)
+		output = &fetchFailure{URL: req.URL, Error: err.Error(), Blocked: true, Hint: "该地址不允许访问，不要换用其他地址绕过限制，请告诉用户无法获取该内容"}
+	case err != nil:
+		output = &fetchFailure{URL: req.URL, Error: err.Error(), Hint: "请检查地址和参数后重试，仍然失败时告诉用户无法获取该内容"}
+	default:
+		output = result
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		return "", fmt.Errorf("结果序列化失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// Fetch 获取内容，按需索引到知识库
+func (f *Fetcher) Fetch(ctx context.Context, req *Request) (*Result, error) {
+	httpReq, err := f.newRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	format := req.Format
+	if format == "" {
+		format = FormatMarkdown
+	}
+	if format != FormatMarkdown && format != FormatText && format != FormatRaw {
+		return nil, fmt.Errorf("不支持的输出格式: %s", format)
+	}
+	if req.Index && f.config.DocumentTool == nil {
+		return nil, errors.New("没有配置文档处理工具，无法索引")
+	}
+
+	log.Printf("[WebFetch] %s %s", httpReq.Method, httpReq.URL.Redacted())
+	resp, err := f.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("请求 %s 失败: %w", httpReq.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.config.MaxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	result := &Result{
+		URL:         httpReq.URL.String(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if int64(len(data)) > f.config.MaxResponseBytes {
+		data = data[:f.config.MaxResponseBytes]
+		result.Truncated = true
+	}
+	result.Bytes = len(data)
+	if final := resp.Request.URL.String(); final != result.URL {
+		result.FinalURL = final
+	}
+	result.Redirects = redirectsOf(resp)
+
+	content, err := f.convert(result, resp.Request.URL, data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	maxChars := f.config.MaxContentChars
+	if req.MaxChars > 0 && req.MaxChars < maxChars {
+		maxChars = req.MaxChars
+	}
+	if utf8.RuneCountInString(result.Content) > maxChars {
+		result.Content = string([]rune(result.Content)[:maxChars]) + "…"
+		result.Truncated = true
+	}
+	log.Printf("[WebFetch] %s: HTTP %d, %d 字节, 截断: %v", httpReq.URL.Redacted(), result.Status, result.Bytes, result.Truncated)
+
+	if req.Index {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 || content == "" {
+			return nil, fmt.Errorf("只能索引成功获取的文本内容 (HTTP %d)", resp.StatusCode)
+		}
+		result.Indexed, err = f.index(ctx, req.DocID, resp.Request.URL, result, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// newRequest 检查参数并创建请求
+func (f *Fetcher) newRequest(ctx context.Context, req *Request) (*http.Request, error) {
+	u, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || req.URL == "" {
+		return nil, fmt.Errorf("地址无效: %s", req.URL)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+	u.Fragment = ""
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !slices.Contains(f.methods, method) {
+		return nil, fmt.Errorf("%w: 不允许 %s 请求，只允许 %s", ErrBlocked, method, strings.Join(f.methods, ", "))
+	}
+	var body io.Reader
+	if req.Body != "" {
+		if !hasBody(method) {
+			return nil, fmt.Errorf("%s 请求不能包含请求体", method)
+		}
+		body = strings.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for name, value := range f.config.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	httpReq.Header.Set("User-Agent", f.config.UserAgent)
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "text/html,application/xhtml+xml,application/json;q=0.9,text/*;q=0.8,*/*;q=0.5")
+	}
+	if body != nil {
+		contentType := req.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	return httpReq, nil
+}
+
+// convert 按内容类型转换响应体，设置 result 的 Title、Description 和 Content，
+// 返回用于索引的内容 (网页为 Markdown，其他文本为原文，非文本为空)
+func (f *Fetcher) convert(result *Result, base *url.URL, data []byte, format string) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	contentType := result.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if !isText(mediaType) {
+		result.Content = fmt.Sprintf("[不支持的内容类型 %s: %d 字节]", mediaType, len(data))
+		return "", nil
+	}
+	reader, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return "", fmt.Errorf("转换响应编码失败: %w", err)
+	}
+	if data, err = io.ReadAll(reader); err != nil {
+		return "", fmt.Errorf("转换响应编码失败: %w", err)
+	}
+
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		result.Content = string(data)
+		return result.Content, nil
+	}
+	p, err := extractPage(data, base, true)
+	if err != nil {
+		return "", fmt.Errorf("解析网页失败: %w", err)
+	}
+	result.Title, result.Description, result.Content = p.Title, p.Description, p.Content
+	result.Format = format
+	switch format {
+	case FormatRaw:
+		result.Content = string(data)
+	case FormatText:
+		if text, err := extractPage(data, base, false); err == nil {
+			result.Content = text.Content
+		}
+	}
+	return p.Content, nil
+}
+
+// index 把内容交给文档处理工具索引，元数据记录来源地址、标题和获取时间
+func (f *Fetcher) index(ctx context.Context, docID string, source *url.URL, result *Result, content string) (json.RawMessage, error) {
+	if docID == "" {
+		docID = docIDOf(source)
+	}
+	metadata := map[string]any{
+		"source_url":   source.String(),
+		"content_type": result.ContentType,
+		"fetched_at":   time.Now().Format(time.RFC3339),
+	}
+	if result.Title != "" {
+		metadata["title"] = result.Title
+	}
+	args, err := json.Marshal(map[string]any{"content": content, "doc_id": docID, "metadata": metadata})
+	if err != nil {
+		return nil, fmt.Errorf("序列化索引参数失败: %w", err)
+	}
+
+	log.Printf("[WebFetch] 索引 %s 为文档 %s", source.Redacted(), docID)
+	output, err := f.config.DocumentTool.InvokableRun(ctx, string(args))
+	if err != nil {
+		return nil, fmt.Errorf("索引文档失败: %w", err)
+	}
+	if json.Valid([]byte(output)) {
+		return json.RawMessage(output), nil
+	}
+	data, _ := json.Marshal(output)
+	return data, nil
+}
+
+// ================================
+// 辅助函数
+// ================================
+
+// hasBody 请求方法是否可以带请求体
+func hasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// isText 判断内容类型是否为文本
+func isText(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/xhtml+xml", "application/javascript", "application/x-ndjson":
+		return true
+	}
+	return false
+}
+
+// redirectsOf 返回依次经过的重定向地址 (不含最终地址)
+func redirectsOf(resp *http.Response) []string {
+	var chain []string
+	for r := resp.Request; r != nil && r.Response != nil; r = r.Response.Request {
+		chain = append(chain, r.Response.Request.URL.String())
+	}
+	if len(chain) <= 1 {
+		return nil
+	}
+	slices.Reverse(chain)
+	return chain[1:]
+}
+
+// docIDOf 根据地址生成稳定的文档 ID，如 web_docs_example_com_1a2b3c4d
+func docIDOf(u *url.URL) string {
+	host := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(u.Hostname()))
+	sum := sha1.Sum([]byte(u.String()))
+	return "web_" + host + "_" + hex.EncodeToString(sum[:4])
+}